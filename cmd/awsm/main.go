@@ -3,17 +3,37 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/ao/awsm/internal/aws/cloudwatch"
+	"github.com/ao/awsm/internal/aws/dynamodb"
 	"github.com/ao/awsm/internal/aws/ec2"
 	"github.com/ao/awsm/internal/aws/lambda"
 	"github.com/ao/awsm/internal/aws/s3"
+	"github.com/ao/awsm/internal/aws/secretsmanager"
+	"github.com/ao/awsm/internal/aws/sqs"
+	"github.com/ao/awsm/internal/aws/ssm"
 	"github.com/ao/awsm/internal/config"
 	"github.com/ao/awsm/internal/logger"
 	"github.com/ao/awsm/internal/tui"
+	"github.com/ao/awsm/internal/tui/components"
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/ao/awsm/internal/utils"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	awsec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awslambda "github.com/aws/aws-sdk-go-v2/service/lambda"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +53,24 @@ var (
 	awsRegion    string
 	outputFormat string
 	tuiMode      bool
+	maxRetries   int
+	retryMode    string
+	timeout      time.Duration
+	yamlFlow     bool
+	compact      bool
+	outputFile   string
+	envOnly      bool
+	awsOnlyNet   bool
+	mfaToken     string
+	columns      []string
+	noHeaders    bool
+	envelope     bool
+	query        string
+	jsonPath     string
+
+	// outputFileHandle is the file opened for --output-file, closed by closeOutputFile
+	// once the command has run, whether or not it failed
+	outputFileHandle *os.File
 
 	// Root command
 	rootCmd = &cobra.Command{
@@ -48,6 +86,31 @@ It provides a more intuitive interface to AWS services with enhanced features:
 - Improved error messages`,
 		Version: Version,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// The YAML style, columns, headers, queries and output file apply to every command, including version
+			utils.SetYAMLFlowStyle(yamlFlow)
+			utils.SetCompactJSON(compact)
+			utils.SetColumns(columns)
+			utils.SetNoHeaders(noHeaders)
+			utils.SetEnvelope(envelope)
+			if query != "" && jsonPath != "" {
+				return fmt.Errorf("--query and --jsonpath cannot be used together")
+			}
+			if err := utils.SetQuery(query); err != nil {
+				return err
+			}
+			if err := utils.SetJSONPath(jsonPath); err != nil {
+				return err
+			}
+
+			if outputFile != "" {
+				f, err := os.Create(outputFile)
+				if err != nil {
+					return fmt.Errorf("failed to open output file: %w", err)
+				}
+				outputFileHandle = f
+				utils.SetOutputWriter(f)
+			}
+
 			// Skip for help and version commands
 			if cmd.Name() == "help" || cmd.Name() == "version" {
 				return nil
@@ -58,15 +121,24 @@ It provides a more intuitive interface to AWS services with enhanced features:
 				return fmt.Errorf("failed to initialize configuration: %w", err)
 			}
 
-			// Check if context flag is provided
-			if contextName, _ := cmd.Flags().GetString("context"); contextName != "" {
+			// Warnings about a context's role go to stderr; the TUI logs them instead
+			config.SetRoleVerifier(nil, utils.PrintWarning)
+
+			// Check if context flag is provided, unless the command has its own --context flag
+			ownContextFlag := cmd.LocalNonPersistentFlags().Lookup("context") != nil
+			if contextName, _ := cmd.Flags().GetString("context"); contextName != "" && !ownContextFlag {
 				if err := config.SetCurrentContext(contextName); err != nil {
 					return fmt.Errorf("failed to set context: %w", err)
 				}
 			} else {
-				// Update configuration with flag values if provided
+				// Update configuration with flag values if provided. With env-only
+				// credentials the profile isn't read, so it doesn't have to exist.
 				if awsProfile != "" {
-					if err := config.SetAWSProfile(awsProfile); err != nil {
+					setProfile := config.SetAWSProfile
+					if envOnly {
+						setProfile = config.SetAWSProfileUnchecked
+					}
+					if err := setProfile(awsProfile); err != nil {
 						return fmt.Errorf("failed to set AWS profile: %w", err)
 					}
 				}
@@ -79,25 +151,59 @@ It provides a more intuitive interface to AWS services with enhanced features:
 			}
 
 			if outputFormat != "" {
-				if !utils.IsValidOutputFormat(outputFormat) {
-					return fmt.Errorf("invalid output format: %s", outputFormat)
+				if err := applyOutputFormat(outputFormat); err != nil {
+					return err
 				}
-				if err := config.SetOutputFormat(outputFormat); err != nil {
-					return fmt.Errorf("failed to set output format: %w", err)
+			}
+
+			// Retry flags only apply to the current invocation and are not saved
+			if cmd.Flags().Changed("max-retries") {
+				if maxRetries < 0 {
+					return fmt.Errorf("invalid max retries: %d", maxRetries)
 				}
+				config.GlobalConfig.AWS.MaxRetries = maxRetries
 			}
 
-			return nil
+			if cmd.Flags().Changed("retry-mode") {
+				if !config.IsValidRetryMode(retryMode) {
+					return fmt.Errorf("invalid retry mode: %s (must be 'standard' or 'adaptive')", retryMode)
+				}
+				config.GlobalConfig.AWS.RetryMode = retryMode
+			}
+
+			// Like the retry flags, the timeout only applies to the current invocation
+			if cmd.Flags().Changed("timeout") {
+				if timeout <= 0 {
+					return fmt.Errorf("invalid timeout: %s (must be positive)", timeout)
+				}
+				config.GlobalConfig.AWS.Timeout = timeout
+			}
+
+			// Like the retry flags, env-only credentials are not saved
+			if envOnly {
+				config.SetEnvOnly(true)
+			}
+			if awsOnlyNet {
+				config.SetAWSOnlyNetwork(true)
+			}
+
+			// The MFA token code is only used if a role has to be assumed
+			if mfaToken != "" {
+				client.SetMFAToken(mfaToken)
+			}
+
+			return checkContextChosen(cmd)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Welcome to awsm - AWS CLI Made Awesome!")
-			fmt.Printf("Version: %s\n", Version)
-			fmt.Println("\nCurrent Settings:")
-			fmt.Printf("  Current Context: %s\n", config.GetCurrentContext())
-			fmt.Printf("  AWS Profile: %s\n", config.GetAWSProfile())
-			fmt.Printf("  AWS Region: %s\n", config.GetAWSRegion())
-			fmt.Printf("  Output Format: %s\n", config.GetOutputFormat())
-			fmt.Println("\nUse --help for more information about available commands.")
+			status := currentRootStatus()
+
+			// Format output based on format
+			switch {
+			case utils.IsStructuredFormat(config.GetOutputFormat()):
+				utils.PrintOutput(status, config.GetOutputFormat())
+			default:
+				utils.PrintOutput(welcomeText(status), "text")
+			}
 		},
 	}
 )
@@ -106,9 +212,23 @@ func init() {
 	// Add global flags
 	rootCmd.PersistentFlags().StringVar(&awsProfile, "profile", "", "AWS profile to use")
 	rootCmd.PersistentFlags().StringVar(&awsRegion, "region", "", "AWS region to use")
-	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "", "Output format (json, yaml, table, text)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "", "Output format (json, jsonl, yaml, table, wide, text, csv)")
 	rootCmd.PersistentFlags().BoolVar(&tuiMode, "tui", false, "Start in TUI mode")
 	rootCmd.PersistentFlags().String("context", "", "AWS context to use")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", 0, "Maximum number of retries for AWS API calls (overrides aws.maxRetries)")
+	rootCmd.PersistentFlags().StringVar(&retryMode, "retry-mode", "", "Retry mode for AWS API calls: standard or adaptive (overrides aws.retryMode)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Timeout for AWS operations, e.g. 45s or 2m (overrides aws.timeout)")
+	rootCmd.PersistentFlags().BoolVar(&envOnly, "no-config-credentials", false, "Only use credentials from the environment, ignoring ~/.aws files and the configured profile (also AWSM_ENV_ONLY=1)")
+	rootCmd.PersistentFlags().BoolVar(&awsOnlyNet, "no-network-except-aws", false, "Refuse network connections to any host that is not an AWS endpoint (also AWSM_NO_NETWORK_EXCEPT_AWS=1)")
+	rootCmd.PersistentFlags().StringVar(&mfaToken, "mfa-token", "", "MFA token code for assuming the configured role, if the profile has an mfa_serial (prompted for otherwise)")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "Write formatted output to a file instead of stdout")
+	rootCmd.PersistentFlags().BoolVar(&yamlFlow, "yaml-flow", false, "Use compact flow style for YAML output")
+	rootCmd.PersistentFlags().BoolVar(&compact, "compact", false, "Print JSON output on a single line instead of indented")
+	rootCmd.PersistentFlags().StringVar(&query, "query", "", "JMESPath expression applied to the JSON representation of the result (e.g. \"[?State=='running'].ID\")")
+	rootCmd.PersistentFlags().StringVar(&jsonPath, "jsonpath", "", "kubectl-style JSONPath template printed for the JSON representation of the result (e.g. '{.[*].ID}')")
+	rootCmd.PersistentFlags().StringSliceVar(&columns, "columns", nil, "Comma-separated fields to show in table and CSV output, in order (e.g. ID,Name,State)")
+	rootCmd.PersistentFlags().BoolVar(&noHeaders, "no-headers", false, "Omit the header row in table and CSV output")
+	rootCmd.PersistentFlags().BoolVar(&envelope, "envelope", false, `Wrap JSON and YAML list output as {"items": [...], "truncated": ...} to show whether --limit cut it short`)
 
 	// Add commands
 	addCommands()
@@ -120,6 +240,13 @@ func addCommands() {
 	rootCmd.AddCommand(newEC2Command())
 	rootCmd.AddCommand(newS3Command())
 	rootCmd.AddCommand(newLambdaCommand())
+	rootCmd.AddCommand(newDynamoDBCommand())
+	rootCmd.AddCommand(newSQSCommand())
+	rootCmd.AddCommand(newSecretsCommand())
+	rootCmd.AddCommand(newSSMCommand())
+
+	// Add check-permissions command
+	rootCmd.AddCommand(newCheckPermissionsCommand())
 
 	// Add mode command
 	rootCmd.AddCommand(newModeCommand())
@@ -132,12 +259,21 @@ func addCommands() {
 
 	// Add direct TUI command
 	rootCmd.AddCommand(newTUICommand())
+
+	// Add prompt command
+	rootCmd.AddCommand(newPromptCommand())
+
+	// Add shell-init command
+	rootCmd.AddCommand(newShellInitCommand())
+
+	// Add version command
+	rootCmd.AddCommand(newVersionCommand())
 }
 
 func main() {
 	// If --tui flag is provided, start in TUI mode
 	if tuiMode {
-		if err := launchTUI(); err != nil {
+		if err := launchTUI(rootCmd); err != nil {
 			utils.PrintError(err)
 			os.Exit(1)
 		}
@@ -145,14 +281,40 @@ func main() {
 	}
 
 	// Otherwise, execute the CLI command
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if closeErr := closeOutputFile(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		utils.PrintError(err)
 		os.Exit(1)
 	}
 }
 
-// launchTUI launches the TUI application
-func launchTUI() error {
+// closeOutputFile closes the file opened for --output-file, if any, and points
+// output back at stdout. It is called after every command, including those that
+// fail, so that the output written so far is kept.
+func closeOutputFile() error {
+	if outputFileHandle == nil {
+		return nil
+	}
+	f := outputFileHandle
+	outputFileHandle = nil
+	utils.SetOutputWriter(nil)
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	return nil
+}
+
+// launchTUI launches the TUI application from cmd. The TUI calls AWS, so with
+// requireContext set it only starts once a context has been chosen, even from
+// commands such as mode that don't call AWS themselves.
+func launchTUI(cmd *cobra.Command) error {
+	if err := requireChosenContext(cmd); err != nil {
+		return err
+	}
+
 	// Initialize the logger
 	if err := logger.Initialize(); err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
@@ -164,21 +326,76 @@ func launchTUI() error {
 	logger.Info("Launching TUI with Version=%s, BuildTime=%s, CommitHash=%s",
 		Version, BuildTime, CommitHash)
 
-	// Create a debug file to verify this function is being called
-	debugFile, err := os.Create("launch_debug.log")
-	if err == nil {
-		debugFile.WriteString(fmt.Sprintf("Version: %s\nBuildTime: %s\nCommitHash: %s\n",
-			Version, BuildTime, CommitHash))
-		debugFile.Close()
-	}
-
 	// Pass version information to the TUI package
 	tui.SetVersionInfo(Version, BuildTime, CommitHash)
 
+	// Writing warnings to stderr would garble the screen, so only log them
+	config.SetRoleVerifier(nil, nil)
+
+	// Reading an MFA token code from stdin would compete with the TUI for keys
+	client.SetMFAPrompt(false)
+
 	// Run the TUI application
 	return tui.Run()
 }
 
+// rootStatus is the status shown by awsm without a command: the effective
+// settings and the names of the available contexts
+type rootStatus struct {
+	Version         string `json:"version" yaml:"version"`
+	config.Settings `yaml:",inline"`
+	Contexts        []string `json:"contexts" yaml:"contexts"`
+}
+
+// currentRootStatus returns the status shown by awsm without a command
+func currentRootStatus() rootStatus {
+	contexts := make([]string, 0, len(config.GetContexts()))
+	for name := range config.GetContexts() {
+		contexts = append(contexts, name)
+	}
+	slices.Sort(contexts)
+
+	return rootStatus{
+		Version:  Version,
+		Settings: config.GetSettings(),
+		Contexts: contexts,
+	}
+}
+
+// welcomeText renders the status shown by awsm without a command in the
+// default (non-JSON/YAML) output formats. The current context is marked with *.
+func welcomeText(status rootStatus) string {
+	var b strings.Builder
+	b.WriteString("Welcome to awsm - AWS CLI Made Awesome!\n")
+	fmt.Fprintf(&b, "Version: %s\n", status.Version)
+	b.WriteString("\nCurrent Settings:\n")
+	fmt.Fprintf(&b, "  Current Context: %s\n", status.Context)
+	fmt.Fprintf(&b, "  AWS Profile: %s\n", status.Profile)
+	fmt.Fprintf(&b, "  AWS Region: %s\n", status.Region)
+	fmt.Fprintf(&b, "  Output Format: %s\n", status.Output)
+	if status.LocalFile != "" {
+		fmt.Fprintf(&b, "  Local Config: %s\n", status.LocalFile)
+	}
+
+	b.WriteString("\nContexts:\n")
+	if len(status.Contexts) == 0 {
+		b.WriteString("  None yet. Import your AWS profiles with 'awsm context import' or add one with 'awsm context create'.\n")
+	}
+	for _, name := range status.Contexts {
+		if name == status.Context {
+			fmt.Fprintf(&b, "* %s (current)\n", name)
+		} else {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+	}
+	if len(status.Contexts) > 0 {
+		b.WriteString("Switch with 'awsm context use <name>' or --context <name>.\n")
+	}
+
+	b.WriteString("\nUse --help for more information about available commands.")
+	return b.String()
+}
+
 // newEC2Command creates the ec2 command
 func newEC2Command() *cobra.Command {
 	cmd := &cobra.Command{
@@ -187,41 +404,440 @@ func newEC2Command() *cobra.Command {
 		Long:  `Manage EC2 instances, security groups, and related resources.`,
 	}
 
-	// Add subcommands
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List EC2 instances",
-			Long:  `List EC2 instances with optional filtering.`,
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List EC2 instances",
+		Long: `List EC2 instances with optional filtering.
 
-				// Create EC2 adapter
-				adapter, err := ec2.NewAdapter(ctx)
+Filters use the EC2 filter syntax name=value[,value...] and can be repeated, e.g.
+--filter instance-state-name=running --filter tag:Environment=Production
+
+The Name column shows the Name tag, or the tag given with --name-tag. Instances
+without it are shown by the start of their ID in table, text and CSV output.
+
+With --coverage, only running instances are listed, each with whether an active
+Reserved Instance of the region covers it or it runs at the on-demand rate. The
+reservations are fetched from EC2, or read from --reservations-file, a JSON array
+of records with InstanceType, Count and, for zonal reservations, AZ. Instance
+size flexibility and Savings Plans are not taken into account.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			filterExprs, _ := cmd.Flags().GetStringArray("filter")
+			countOnly, _ := cmd.Flags().GetBool("count")
+			withSpecs, _ := cmd.Flags().GetBool("with-specs")
+			withCoverage, _ := cmd.Flags().GetBool("coverage")
+			reservationsFile, _ := cmd.Flags().GetString("reservations-file")
+			nameTag, _ := cmd.Flags().GetString("name-tag")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
+			}
+
+			// Parse filters
+			var filters []types.Filter
+			for _, expr := range filterExprs {
+				filter, err := ec2.ParseFilter(expr)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+					utils.PrintError(err)
 					return
 				}
+				filters = append(filters, filter)
+			}
+
+			if reservationsFile != "" && !withCoverage {
+				utils.PrintError(fmt.Errorf("--reservations-file can only be used with --coverage"))
+				return
+			}
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return
+			}
 
-				// List EC2 instances
-				instances, err := adapter.ListInstances(ctx, nil, 0)
+			if withCoverage {
+				// Reservations cover the running instances of the whole region,
+				// so the coverage is worked out for all of them before --filter
+				// and --limit pick the rows to show
+				running := []types.Filter{ec2.CreateFilter("instance-state-name", "running")}
+				instances, err := adapter.ListInstances(ctx, running, 0)
 				if err != nil {
 					utils.PrintError(fmt.Errorf("failed to list EC2 instances: %w", err))
 					return
 				}
+				matched := instances
+				if len(filters) > 0 {
+					matched, err = adapter.ListInstances(ctx, append(filters, running...), 0)
+					if err != nil {
+						utils.PrintError(fmt.Errorf("failed to list EC2 instances: %w", err))
+						return
+					}
+				}
+
+				reservations, err := loadReservations(ctx, adapter, reservationsFile)
+				if err != nil {
+					utils.PrintError(err)
+					return
+				}
 
-				// Format and print the output
-				utils.PrintOutput(instances, config.GetOutputFormat())
-			},
+				setInstanceNames(instances, nameTag, config.GetOutputFormat())
+				coverage := coverageOf(ec2.Coverage(instances, reservations), matched)
+
+				uncovered := 0
+				for _, c := range coverage {
+					if !c.Covered {
+						uncovered++
+					}
+				}
+
+				rows, truncated := applyLimit(coverage, limit)
+				defer printLimitFooter(truncated, limit)
+				utils.PrintList(rows, limit, truncated, config.GetOutputFormat())
+				utils.PrintNotice(fmt.Sprintf("%d of %d running instances are not covered by a reservation", uncovered, len(coverage)))
+				return
+			}
+
+			// List EC2 instances
+			instances, err := adapter.ListInstances(ctx, filters, limitProbe(limit))
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to list EC2 instances: %w", err))
+				return
+			}
+			instances, truncated := applyLimit(instances, limit)
+			defer printLimitFooter(truncated, limit)
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(instances))
+				return
+			}
+
+			setInstanceNames(instances, nameTag, config.GetOutputFormat())
+
+			if withSpecs {
+				// Look up vCPU and memory for the instance types
+				enriched, err := adapter.AddInstanceSpecs(ctx, instances)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to look up instance type specs: %w", err))
+					return
+				}
+
+				utils.PrintList(enriched, limit, truncated, config.GetOutputFormat())
+				return
+			}
+
+			// Format and print the output
+			utils.PrintList(instances, limit, truncated, config.GetOutputFormat())
+		},
+	}
+	listCmd.Flags().StringArray("filter", nil, "Filter instances (name=value[,value...]); can be repeated")
+	listCmd.Flags().Bool("with-specs", false, "Include vCPU and memory of each instance type")
+	listCmd.Flags().Bool("coverage", false, "List running instances with whether a Reserved Instance covers them")
+	listCmd.Flags().String("reservations-file", "", "JSON file with the reservations to use for --coverage instead of fetching them (- for stdin)")
+	listCmd.Flags().String("name-tag", ec2.DefaultNameTag, "Tag to show as the instance name (e.g. app)")
+	listCmd.Flags().Bool("count", false, "Print only the number of matching instances")
+	listCmd.Flags().Int32("limit", 0, "Maximum number of instances to list (0 for all)")
+	listCmd.MarkFlagsMutuallyExclusive("coverage", "with-specs")
+	listCmd.MarkFlagsMutuallyExclusive("coverage", "count")
+
+	staleCmd := &cobra.Command{
+		Use:   "stale",
+		Short: "Report long-running EC2 instances with low CPU utilization",
+		Long: `List running EC2 instances launched more than --running-days days ago, with their
+average and maximum CPU utilization from CloudWatch over the last --cpu-days days.
+Instances whose average CPU utilization is below --cpu-threshold percent are flagged
+as idle and listed first, as candidates to stop or downsize.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			// Get flags
+			runningDays, _ := cmd.Flags().GetInt("running-days")
+			cpuDays, _ := cmd.Flags().GetInt("cpu-days")
+			cpuThreshold, _ := cmd.Flags().GetFloat64("cpu-threshold")
+			idleOnly, _ := cmd.Flags().GetBool("idle-only")
+
+			if runningDays < 0 {
+				utils.PrintError(fmt.Errorf("invalid --running-days: %d", runningDays))
+				return
+			}
+			if cpuDays < 1 || cpuDays > 60 {
+				utils.PrintError(fmt.Errorf("invalid --cpu-days: %d (must be between 1 and 60)", cpuDays))
+				return
+			}
+
+			// Create EC2 and CloudWatch adapters
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return
+			}
+			metrics, err := cloudwatch.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create CloudWatch adapter: %w", err))
+				return
+			}
+
+			// List long-running instances with their CPU utilization
+			stale, err := adapter.ListStaleInstances(ctx, metrics, ec2.StaleOptions{
+				RunningDays:  runningDays,
+				CPUDays:      cpuDays,
+				CPUThreshold: cpuThreshold,
+			})
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to list stale EC2 instances: %w", err))
+				return
+			}
+
+			if idleOnly {
+				idle := []ec2.StaleInstance{}
+				for _, inst := range stale {
+					if inst.Idle {
+						idle = append(idle, inst)
+					}
+				}
+				stale = idle
+			}
+
+			// Format and print the output
+			utils.PrintOutput(stale, config.GetOutputFormat())
+		},
+	}
+	staleCmd.Flags().Int("running-days", 30, "Only report instances launched more than this many days ago")
+	staleCmd.Flags().Int("cpu-days", 14, "Number of days of CPU utilization to look at (1-60)")
+	staleCmd.Flags().Float64("cpu-threshold", 5, "Average CPU utilization, in percent, below which an instance is flagged as idle")
+	staleCmd.Flags().Bool("idle-only", false, "Only report instances flagged as idle")
+
+	metricsCmd := newMetricsCommand("metrics [instance-id[@region]]", "Show CloudWatch metrics for an EC2 instance",
+		`Fetch the datapoints of an AWS/EC2 CloudWatch metric for an instance over the last
+--since, one per --period. Text output shows a sparkline of each statistic;
+other output formats list the datapoints.`,
+		"CPUUtilization", (*cloudwatch.Adapter).GetEC2InstanceMetric)
+
+	topologyCmd := &cobra.Command{
+		Use:   "topology [instance-id]",
+		Short: "Show how an EC2 instance is wired to other resources",
+		Long: `Show an EC2 instance together with its VPC, subnet, addresses (including Elastic
+IPs), security groups and attached EBS volumes as a tree. With --output json or
+--output yaml the same information is printed as a structured document.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			instanceID := args[0]
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return
+			}
+
+			// Describe the instance and its related resources
+			topology, err := adapter.GetTopology(ctx, instanceID)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Print a tree unless structured output was requested
+			switch {
+			case utils.IsStructuredFormat(config.GetOutputFormat()):
+				utils.PrintOutput(topology, config.GetOutputFormat())
+			default:
+				fmt.Fprint(utils.OutputWriter(), topology.Tree())
+			}
+		},
+	}
+
+	renameCmd := &cobra.Command{
+		Use:   "rename [instance-id] [new-name]",
+		Short: "Rename an EC2 instance",
+		Long: `Rename an EC2 instance by setting its Name tag, the name shown by ec2 list and
+the TUI. With --name-tag, the given tag is set instead, for teams that keep the
+instance name in another tag.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			instanceID, name := args[0], args[1]
+			nameTag, _ := cmd.Flags().GetString("name-tag")
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("the new name cannot be empty")
+			}
+
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return nil
+			}
+
+			printOperationBanner(ctx)
+
+			// Set the name tag
+			if err := adapter.RenameInstance(ctx, instanceID, nameTag, name); err != nil {
+				utils.PrintError(err)
+				return nil
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Renamed EC2 instance %s to %s\n", instanceID, name)
+			return nil
+		},
+	}
+	renameCmd.Flags().String("name-tag", ec2.DefaultNameTag, "Tag holding the instance name (e.g. app)")
+
+	startCmd := &cobra.Command{
+		Use:   "start [instance-id...]",
+		Short: "Start EC2 instances",
+		Long: `Start a stopped EC2 instance. Multiple instance IDs can be given; each is
+started independently and a summary of the results is printed at the end.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return nil
+			}
+
+			printOperationBanner(ctx)
+
+			// Start each EC2 instance
+			result := &utils.BulkResult{}
+			var started []string
+			for _, instanceID := range args {
+				err := adapter.StartInstance(ctx, instanceID)
+				reportBulkItem(err, "Successfully started EC2 instance %s", instanceID)
+				if err != nil {
+					result.Record(instanceID, err)
+					continue
+				}
+				started = append(started, instanceID)
+			}
+
+			// Wait until the instances are running, if requested
+			waitForBulk(cmd, result, started, "EC2 instance %s to be running", "EC2 instance %s is running",
+				func(ctx context.Context, instanceID string, interval time.Duration) error {
+					return adapter.WaitForInstanceState(ctx, instanceID, types.InstanceStateNameRunning, interval)
+				})
+
+			return printBulkResult(cmd, result)
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop [instance-id...]",
+		Short: "Stop EC2 instances",
+		Long: `Stop a running EC2 instance. Multiple instance IDs can be given; each is
+stopped independently and a summary of the results is printed at the end.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return nil
+			}
+
+			printOperationBanner(ctx)
+
+			// Stop each EC2 instance
+			result := &utils.BulkResult{}
+			var stopped []string
+			for _, instanceID := range args {
+				err := adapter.StopInstance(ctx, instanceID)
+				reportBulkItem(err, "Successfully stopped EC2 instance %s", instanceID)
+				if err != nil {
+					result.Record(instanceID, err)
+					continue
+				}
+				stopped = append(stopped, instanceID)
+			}
+
+			// Wait until the instances are stopped, if requested
+			waitForBulk(cmd, result, stopped, "EC2 instance %s to be stopped", "EC2 instance %s is stopped",
+				func(ctx context.Context, instanceID string, interval time.Duration) error {
+					return adapter.WaitForInstanceState(ctx, instanceID, types.InstanceStateNameStopped, interval)
+				})
+
+			return printBulkResult(cmd, result)
+		},
+	}
+
+	rebootCmd := &cobra.Command{
+		Use:   "reboot [instance-id...]",
+		Short: "Reboot EC2 instances",
+		Long: `Reboot running EC2 instances in a single step. Multiple instance IDs can be
+given; each is rebooted independently and a summary of the results is printed at the end.
+
+The reboot is queued by EC2, so --wait may find the status checks still passing
+from before the instance went down.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return nil
+			}
+
+			printOperationBanner(ctx)
+
+			// Reboot each EC2 instance
+			result := &utils.BulkResult{}
+			var rebooted []string
+			for _, instanceID := range args {
+				err := adapter.RebootInstance(ctx, instanceID)
+				reportBulkItem(err, "Successfully rebooted EC2 instance %s", instanceID)
+				if err != nil {
+					result.Record(instanceID, err)
+					continue
+				}
+				rebooted = append(rebooted, instanceID)
+			}
+
+			// Wait until the instances pass their status checks, if requested
+			waitForBulk(cmd, result, rebooted, "EC2 instance %s to pass its status checks", "EC2 instance %s passed its status checks",
+				func(ctx context.Context, instanceID string, interval time.Duration) error {
+					return adapter.WaitForInstanceStatusOK(ctx, instanceID, interval)
+				})
+
+			return printBulkResult(cmd, result)
 		},
+	}
+
+	addWaitFlags(startCmd)
+	addWaitFlags(stopCmd)
+	addWaitFlags(rebootCmd)
+
+	// Add subcommands
+	cmd.AddCommand(
+		listCmd,
+		staleCmd,
+		metricsCmd,
+		topologyCmd,
 		&cobra.Command{
-			Use:   "describe [instance-id]",
-			Short: "Describe an EC2 instance",
-			Long:  `Show detailed information about an EC2 instance.`,
-			Args:  cobra.ExactArgs(1),
+			Use:   "describe [instance-id...]",
+			Short: "Describe EC2 instances",
+			Long: `Show detailed information about one or more EC2 instances. Several instance
+IDs are described together in a single API call and returned as a list.`,
+			Args: cobra.MinimumNArgs(1),
 			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				instanceID := args[0]
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
 
 				// Create EC2 adapter
 				adapter, err := ec2.NewAdapter(ctx)
@@ -230,25 +846,42 @@ func newEC2Command() *cobra.Command {
 					return
 				}
 
-				// Describe EC2 instance
-				instance, err := adapter.DescribeInstance(ctx, instanceID)
+				if len(args) == 1 {
+					// Describe EC2 instance
+					instanceID := args[0]
+					instance, err := adapter.DescribeInstance(ctx, instanceID)
+					if err != nil {
+						utils.PrintError(fmt.Errorf("failed to describe EC2 instance %s: %w", instanceID, err))
+						return
+					}
+
+					// Format and print the output
+					utils.PrintOutput(instance, config.GetOutputFormat())
+					return
+				}
+
+				// Describe EC2 instances
+				instances, err := adapter.DescribeInstances(ctx, args)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to describe EC2 instance %s: %w", instanceID, err))
+					utils.PrintError(err)
 					return
 				}
 
 				// Format and print the output
-				utils.PrintOutput(instance, config.GetOutputFormat())
+				utils.PrintOutput(instances, config.GetOutputFormat())
 			},
 		},
+		startCmd,
+		stopCmd,
+		rebootCmd,
+		renameCmd,
 		&cobra.Command{
-			Use:   "start [instance-id]",
-			Short: "Start an EC2 instance",
-			Long:  `Start a stopped EC2 instance.`,
-			Args:  cobra.ExactArgs(1),
+			Use:   "keypairs",
+			Short: "List EC2 key pairs",
+			Long:  `List the EC2 key pairs that exist in the current region.`,
 			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				instanceID := args[0]
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
 
 				// Create EC2 adapter
 				adapter, err := ec2.NewAdapter(ctx)
@@ -257,23 +890,25 @@ func newEC2Command() *cobra.Command {
 					return
 				}
 
-				// Start EC2 instance
-				if err := adapter.StartInstance(ctx, instanceID); err != nil {
-					utils.PrintError(fmt.Errorf("failed to start EC2 instance %s: %w", instanceID, err))
+				// List EC2 key pairs
+				keyPairs, err := adapter.ListKeyPairs(ctx)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to list EC2 key pairs: %w", err))
 					return
 				}
 
-				fmt.Printf("Successfully started EC2 instance %s\n", instanceID)
+				// Format and print the output
+				utils.PrintOutput(keyPairs, config.GetOutputFormat())
 			},
 		},
 		&cobra.Command{
-			Use:   "stop [instance-id]",
-			Short: "Stop an EC2 instance",
-			Long:  `Stop a running EC2 instance.`,
-			Args:  cobra.ExactArgs(1),
+			Use:   "az-summary",
+			Short: "Summarize running EC2 instances by availability zone",
+			Long:  `Group running EC2 instances by availability zone and show the count per zone, to help spot AZ imbalances.`,
+			Args:  cobra.NoArgs,
 			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				instanceID := args[0]
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
 
 				// Create EC2 adapter
 				adapter, err := ec2.NewAdapter(ctx)
@@ -282,13 +917,15 @@ func newEC2Command() *cobra.Command {
 					return
 				}
 
-				// Stop EC2 instance
-				if err := adapter.StopInstance(ctx, instanceID); err != nil {
-					utils.PrintError(fmt.Errorf("failed to stop EC2 instance %s: %w", instanceID, err))
+				// Summarize running instances by AZ
+				summary, err := adapter.GetAZSummary(ctx)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to summarize EC2 instances by AZ: %w", err))
 					return
 				}
 
-				fmt.Printf("Successfully stopped EC2 instance %s\n", instanceID)
+				// Format and print the output
+				utils.PrintOutput(summary, config.GetOutputFormat())
 			},
 		},
 	)
@@ -296,6 +933,43 @@ func newEC2Command() *cobra.Command {
 	return cmd
 }
 
+// loadReservations returns the reservations used by ec2 list --coverage: read
+// from path (- for stdin) if it is set, otherwise the active Reserved Instances
+// of the current region.
+func loadReservations(ctx context.Context, adapter *ec2.Adapter, path string) ([]ec2.Reservation, error) {
+	if path == "" {
+		return adapter.ListReservations(ctx)
+	}
+	if path == "-" {
+		return ec2.ReadReservations(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reservations file: %w", err)
+	}
+	defer file.Close()
+
+	return ec2.ReadReservations(file)
+}
+
+// coverageOf returns the rows of coverage that belong to the given instances, in
+// the order of coverage.
+func coverageOf(coverage []ec2.InstanceCoverage, instances []ec2.Instance) []ec2.InstanceCoverage {
+	ids := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		ids[inst.ID] = true
+	}
+
+	var rows []ec2.InstanceCoverage
+	for _, c := range coverage {
+		if ids[c.ID] {
+			rows = append(rows, c)
+		}
+	}
+	return rows
+}
+
 // newS3Command creates the s3 command
 func newS3Command() *cobra.Command {
 	cmd := &cobra.Command{
@@ -304,556 +978,3531 @@ func newS3Command() *cobra.Command {
 		Long:  `Manage S3 buckets, objects, and related resources.`,
 	}
 
-	// Add subcommands
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "ls [bucket-name]",
-			Short: "List S3 buckets or objects",
-			Long:  `List S3 buckets or objects in a bucket.`,
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
+	cpCmd := &cobra.Command{
+		Use:   "cp [source] [destination]",
+		Short: "Copy objects to/from S3",
+		Long: `Copy objects to or from S3 buckets.
 
-				// Create S3 adapter
-				adapter, err := s3.NewAdapter(ctx)
-				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
-					return
-				}
+With --recursive, a local directory is uploaded to an S3 prefix, or an S3 prefix is
+downloaded to a local directory, preserving relative paths. Symbolic links are skipped.
 
-				if len(args) == 0 {
-					// List S3 buckets
-					buckets, err := adapter.ListBuckets(ctx)
-					if err != nil {
-						utils.PrintError(fmt.Errorf("failed to list S3 buckets: %w", err))
-						return
-					}
+Downloads are written to a .part file next to the destination and renamed when
+complete. If a download fails, the .part file is kept and --continue resumes it,
+unless the object has changed since, in which case it is downloaded again.
 
-					// Format and print the output
-					utils.PrintOutput(buckets, config.GetOutputFormat())
-				} else {
-					// List objects in bucket
-					bucketName := args[0]
-					objects, err := adapter.ListObjects(ctx, bucketName, "", 0)
-					if err != nil {
-						utils.PrintError(fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err))
-						return
-					}
+With --range, only part of the object is downloaded, e.g. --range bytes=0-1023
+for the first KB or --range bytes=-1024 for the last.
 
-					// Format and print the output
-					utils.PrintOutput(objects, config.GetOutputFormat())
-				}
-			},
-		},
-		&cobra.Command{
-			Use:   "cp [source] [destination]",
-			Short: "Copy objects to/from S3",
-			Long:  `Copy objects to or from S3 buckets.`,
-			Args:  cobra.ExactArgs(2),
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				source := args[0]
-				destination := args[1]
+Uploaded objects get a content type detected from the file extension or, if
+it is unknown, the file content. --content-type sets it instead.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			source := args[0]
+			destination := args[1]
+			acl, _ := cmd.Flags().GetString("acl")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			resume, _ := cmd.Flags().GetBool("continue")
+			byteRange, _ := cmd.Flags().GetString("range")
+			contentType, _ := cmd.Flags().GetString("content-type")
 
-				// Create S3 adapter
-				adapter, err := s3.NewAdapter(ctx)
+			if resume && (recursive || !strings.HasPrefix(source, "s3://")) {
+				utils.PrintError(fmt.Errorf("--continue can only be used when downloading a single object"))
+				return nil
+			}
+			if byteRange != "" && (recursive || !strings.HasPrefix(source, "s3://")) {
+				utils.PrintError(fmt.Errorf("--range can only be used when downloading a single object"))
+				return nil
+			}
+			if contentType != "" && (recursive || strings.HasPrefix(source, "s3://")) {
+				utils.PrintError(fmt.Errorf("--content-type can only be used when uploading a single file"))
+				return nil
+			}
+			if _, err := s3.ParseByteRange(byteRange); err != nil {
+				utils.PrintError(err)
+				return nil
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return nil
+			}
+
+			// Uploads and copies between buckets change S3, downloads don't
+			if strings.HasPrefix(destination, "s3://") {
+				printOperationBanner(ctx)
+			}
+
+			if recursive {
+				return copyRecursive(ctx, cmd, adapter, source, destination, acl)
+			}
+
+			// Check if source is an S3 URL (s3://bucket/key)
+			if strings.HasPrefix(source, "s3://") {
+				// Download from S3
+				bucketName, key, err := parseS3ObjectURL(source)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
-					return
+					utils.PrintError(err)
+					return nil
 				}
 
-				// Check if source is an S3 URL (s3://bucket/key)
-				if strings.HasPrefix(source, "s3://") {
-					// Download from S3
-					parts := strings.SplitN(strings.TrimPrefix(source, "s3://"), "/", 2)
-					if len(parts) != 2 {
-						utils.PrintError(fmt.Errorf("invalid S3 URL: %s", source))
-						return
-					}
+				if acl != "" {
+					utils.PrintError(fmt.Errorf("--acl can only be used when uploading to S3"))
+					return nil
+				}
 
-					bucketName := parts[0]
-					key := parts[1]
+				opts := s3.DownloadOptions{Resume: resume, Range: byteRange}
+				if err := adapter.DownloadObjectWithOptions(ctx, bucketName, key, destination, opts); err != nil {
+					utils.PrintError(fmt.Errorf("failed to download object: %w", err))
+					return nil
+				}
 
-					if err := adapter.DownloadObject(ctx, bucketName, key, destination); err != nil {
-						utils.PrintError(fmt.Errorf("failed to download object: %w", err))
-						return
-					}
+				fmt.Fprintf(utils.OutputWriter(), "Downloaded s3://%s/%s to %s\n", bucketName, key, destination)
+			} else {
+				// Upload to S3
+				bucketName, key, err := s3.ParseS3URL(destination)
+				if err != nil {
+					utils.PrintError(err)
+					return nil
+				}
 
-					fmt.Printf("Downloaded s3://%s/%s to %s\n", bucketName, key, destination)
-				} else {
-					// Upload to S3
-					parts := strings.SplitN(strings.TrimPrefix(destination, "s3://"), "/", 2)
-					if len(parts) != 2 {
-						utils.PrintError(fmt.Errorf("invalid S3 URL: %s", destination))
-						return
-					}
+				// Like a directory destination, a bucket or key prefix keeps the file name
+				if key == "" || strings.HasSuffix(key, "/") {
+					key += filepath.Base(source)
+				}
 
-					bucketName := parts[0]
-					key := parts[1]
+				opts := s3.UploadOptions{ACL: acl, ContentType: contentType}
+				if err := adapter.UploadObjectWithOptions(ctx, bucketName, key, source, opts); err != nil {
+					utils.PrintError(fmt.Errorf("failed to upload object: %w", err))
+					return nil
+				}
 
-					if err := adapter.UploadObject(ctx, bucketName, key, source); err != nil {
-						utils.PrintError(fmt.Errorf("failed to upload object: %w", err))
-						return
-					}
+				fmt.Fprintf(utils.OutputWriter(), "Uploaded %s to s3://%s/%s\n", source, bucketName, key)
+			}
 
-					fmt.Printf("Uploaded %s to s3://%s/%s\n", source, bucketName, key)
-				}
-			},
+			return nil
 		},
-		&cobra.Command{
-			Use:   "rm [bucket-name/object-key]",
-			Short: "Remove an S3 object",
-			Long:  `Remove an object from an S3 bucket.`,
-			Args:  cobra.ExactArgs(1),
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				s3Path := args[0]
+	}
+	cpCmd.Flags().String("acl", "", "Canned ACL to apply to uploaded objects (e.g. public-read)")
+	cpCmd.Flags().Bool("recursive", false, "Copy a directory to an S3 prefix or an S3 prefix to a directory")
+	cpCmd.Flags().Bool("continue", false, "Resume an interrupted download from its .part file")
+	cpCmd.Flags().String("range", "", "Download only part of the object, e.g. bytes=0-1023, bytes=1024- or bytes=-1024")
+	cpCmd.Flags().String("content-type", "", "Content type of the uploaded object (detected from the file by default)")
 
-				// Create S3 adapter
-				adapter, err := s3.NewAdapter(ctx)
+	catCmd := &cobra.Command{
+		Use:   "cat [s3://bucket/key]",
+		Short: "Print an S3 object",
+		Long: `Print the content of an S3 object to stdout, without saving it to a file.
+
+With --range, only part of the object is fetched, e.g. the first lines of a large
+log with --range bytes=0-4095, or its end with --range bytes=-4096.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			byteRange, _ := cmd.Flags().GetString("range")
+
+			// Parse the S3 path
+			bucketName, key, err := parseS3ObjectURL(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return
+			}
+
+			// Stream the object to the output writer
+			body, err := adapter.OpenObject(ctx, bucketName, key, byteRange)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			defer body.Close()
+
+			if _, err := io.Copy(utils.OutputWriter(), body); err != nil {
+				utils.PrintError(fmt.Errorf("failed to read object: %w", err))
+			}
+		},
+	}
+	catCmd.Flags().String("range", "", "Print only part of the object, e.g. bytes=0-1023, bytes=1024- or bytes=-1024")
+
+	syncCmd := &cobra.Command{
+		Use:   "sync [directory] [s3://bucket/prefix]",
+		Short: "Sync a local directory to S3",
+		Long: `Synchronize a local directory to an S3 bucket or prefix.
+
+A file is uploaded when there is no object for it yet, or when the object differs
+in size or is older than the local file; otherwise it is skipped. With --delete,
+objects below the prefix that have no local counterpart are removed. Symbolic
+links are skipped.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			source := args[0]
+			acl, _ := cmd.Flags().GetString("acl")
+			deleteExtra, _ := cmd.Flags().GetBool("delete")
+
+			if info, err := os.Stat(source); err != nil || !info.IsDir() {
+				utils.PrintError(fmt.Errorf("%s is not a directory", source))
+				return nil
+			}
+
+			bucketName, prefix, err := s3.ParseS3URL(args[1])
+			if err != nil {
+				utils.PrintError(err)
+				return nil
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return nil
+			}
+
+			printOperationBanner(ctx)
+
+			opts := s3.SyncOptions{Delete: deleteExtra, Upload: s3.UploadOptions{ACL: acl}}
+			actions, err := adapter.SyncLocalToBucket(ctx, source, bucketName, prefix, opts)
+			return printSyncActions(cmd, bucketName, actions, err)
+		},
+	}
+	syncCmd.Flags().String("acl", "", "Canned ACL to apply to uploaded objects (e.g. public-read)")
+	syncCmd.Flags().Bool("delete", false, "Delete objects that have no local counterpart")
+
+	rmCmd := &cobra.Command{
+		Use:   "rm [s3://bucket/key...]",
+		Short: "Remove S3 objects",
+		Long: `Remove one or more objects from S3 buckets. When several objects are given,
+each is deleted independently and a summary of the results is printed at the end.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return nil
+			}
+
+			// Parse all S3 paths before deleting anything
+			type objectPath struct{ bucketName, key string }
+			paths := make([]objectPath, 0, len(args))
+			for _, s3Path := range args {
+				bucketName, key, err := parseS3ObjectURL(s3Path)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
-					return
+					utils.PrintError(err)
+					return nil
 				}
+				paths = append(paths, objectPath{bucketName, key})
+			}
 
-				// Parse the S3 path
-				parts := strings.SplitN(strings.TrimPrefix(s3Path, "s3://"), "/", 2)
-				if len(parts) != 2 {
-					utils.PrintError(fmt.Errorf("invalid S3 path: %s", s3Path))
-					return
+			// Confirm the deletion
+			printOperationBanner(ctx)
+			confirmName := fmt.Sprintf("s3://%s/%s", paths[0].bucketName, paths[0].key)
+			if len(paths) > 1 {
+				confirmName = fmt.Sprintf("%d objects", len(paths))
+			}
+			if !confirmDestructiveAction(cmd, confirmName) {
+				fmt.Fprintln(utils.OutputWriter(), "Aborted")
+				return nil
+			}
+
+			// Delete each object
+			result := &utils.BulkResult{}
+			for _, path := range paths {
+				s3URL := fmt.Sprintf("s3://%s/%s", path.bucketName, path.key)
+				err := adapter.DeleteObject(ctx, path.bucketName, path.key)
+				result.Record(s3URL, err)
+				reportBulkItem(err, "Removed %s", s3URL)
+			}
+
+			return printBulkResult(cmd, result)
+		},
+	}
+	rmCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [s3://bucket/key]",
+		Short: "Restore an archived S3 object",
+		Long: `Initiate a restore of an archived (e.g. Glacier) S3 object. The restored copy
+remains available for the number of days given by --days. Use 's3 head' to check
+the restore status.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			s3Path := args[0]
+			days, _ := cmd.Flags().GetInt("days")
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return
+			}
+
+			// Parse the S3 path
+			bucketName, key, err := parseS3ObjectURL(s3Path)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Initiate the restore
+			if err := adapter.RestoreObject(ctx, bucketName, key, days); err != nil {
+				utils.PrintError(fmt.Errorf("failed to restore object: %w", err))
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Restore initiated for s3://%s/%s (available for %d days)\n", bucketName, key, days)
+		},
+	}
+	restoreCmd.Flags().Int("days", 1, "Number of days the restored copy remains available")
+
+	mbCmd := &cobra.Command{
+		Use:   "mb [s3://bucket]",
+		Short: "Create an S3 bucket",
+		Long:  `Create an S3 bucket in the current region.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Parse the bucket name
+			bucketName, key, err := s3.ParseS3URL(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			if key != "" {
+				utils.PrintError(fmt.Errorf("invalid bucket URL %q: unexpected object key", args[0]))
+				return
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Create the bucket
+			region := config.GetAWSRegion()
+			if err := adapter.CreateBucket(ctx, bucketName, region); err != nil {
+				utils.PrintError(fmt.Errorf("failed to create bucket: %w", err))
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Created bucket s3://%s in %s\n", bucketName, region)
+
+			// Wait until the bucket exists, if requested
+			if shouldWait(cmd) {
+				err := waitForChange(cmd, fmt.Sprintf("bucket s3://%s to exist", bucketName), func(ctx context.Context, interval time.Duration) error {
+					return adapter.WaitForBucketCreated(ctx, bucketName, interval)
+				})
+				if err != nil {
+					utils.PrintError(err)
 				}
+			}
+		},
+	}
+
+	rbCmd := &cobra.Command{
+		Use:   "rb [s3://bucket]",
+		Short: "Remove an S3 bucket",
+		Long: `Remove an empty S3 bucket. With --force, all objects in the bucket are deleted first.
+Object versions in versioned buckets are not deleted.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			force, _ := cmd.Flags().GetBool("force")
+
+			// Parse the bucket name
+			bucketName, key, err := s3.ParseS3URL(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			if key != "" {
+				utils.PrintError(fmt.Errorf("invalid bucket URL %q: unexpected object key", args[0]))
+				return
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return
+			}
 
-				bucketName := parts[0]
-				key := parts[1]
+			// Confirm the deletion
+			printOperationBanner(ctx)
+			if !confirmDestructiveAction(cmd, bucketName) {
+				fmt.Fprintln(utils.OutputWriter(), "Aborted")
+				return
+			}
 
-				// Delete the object
-				if err := adapter.DeleteObject(ctx, bucketName, key); err != nil {
-					utils.PrintError(fmt.Errorf("failed to delete object: %w", err))
+			// Delete all objects first, if requested
+			if force {
+				deleted, err := adapter.EmptyBucket(ctx, bucketName)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to empty bucket after deleting %d objects: %w", deleted, err))
 					return
 				}
+				fmt.Fprintf(utils.OutputWriter(), "Deleted %d objects from s3://%s\n", deleted, bucketName)
+			}
 
-				fmt.Printf("Removed s3://%s/%s\n", bucketName, key)
-			},
-		},
-	)
+			// Delete the bucket
+			if err := adapter.DeleteBucket(ctx, bucketName); err != nil {
+				utils.PrintError(fmt.Errorf("failed to remove bucket: %w", err))
+				return
+			}
 
-	return cmd
-}
+			fmt.Fprintf(utils.OutputWriter(), "Removed bucket s3://%s\n", bucketName)
 
-// newLambdaCommand creates the lambda command
-func newLambdaCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "lambda",
-		Short: "Lambda function management",
-		Long:  `Manage Lambda functions, layers, and related resources.`,
+			// Wait until the bucket is gone, if requested
+			if shouldWait(cmd) {
+				err := waitForChange(cmd, fmt.Sprintf("bucket s3://%s to be gone", bucketName), func(ctx context.Context, interval time.Duration) error {
+					return adapter.WaitForBucketDeleted(ctx, bucketName, interval)
+				})
+				if err != nil {
+					utils.PrintError(err)
+				}
+			}
+		},
 	}
+	rbCmd.Flags().Bool("force", false, "Delete all objects in the bucket before removing it")
+	rbCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	addWaitFlags(mbCmd)
+	addWaitFlags(rbCmd)
 
-	// Add subcommands
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List Lambda functions",
-			Long:  `List Lambda functions with optional filtering.`,
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
+	lsCmd := &cobra.Command{
+		Use:   "ls [bucket-name | s3://bucket/prefix/]",
+		Short: "List S3 buckets or objects",
+		Long: `List S3 buckets or objects in a bucket.
 
-				// Create Lambda adapter
-				adapter, err := lambda.NewAdapter(ctx)
+The bucket can be given as an S3 URL, whose path is used as the prefix unless
+--prefix is set. With --delimiter, only one level of the bucket is listed: keys
+that contain the delimiter after the prefix are grouped into common prefixes
+("folders"), shown with the type PRE before the objects (OBJ).
+
+With --interactive, a filterable list of buckets is shown to pick the bucket
+whose objects are listed; it cannot be combined with a bucket name.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			prefix, _ := cmd.Flags().GetString("prefix")
+			countOnly, _ := cmd.Flags().GetBool("count")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			pageSize, _ := cmd.Flags().GetInt32("page-size")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			sortBy, _ := cmd.Flags().GetString("sort")
+			delimiter, _ := cmd.Flags().GetString("delimiter")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
+			}
+			if pageSize < 0 || pageSize > s3.MaxObjectsPageSize {
+				utils.PrintError(fmt.Errorf("invalid page size: %d (must be between 0 and %d, 0 for the maximum)", pageSize, s3.MaxObjectsPageSize))
+				return
+			}
+			if !s3.IsValidBucketSortKey(sortBy) {
+				utils.PrintError(fmt.Errorf("invalid sort order: %s (must be %s, %s or %s)", sortBy, s3.BucketSortName, s3.BucketSortCreated, s3.BucketSortRegion))
+				return
+			}
+			if interactive && len(args) > 0 {
+				utils.PrintError(fmt.Errorf("--interactive cannot be used with a bucket name"))
+				return
+			}
+			if interactive && !utils.IsInteractive() {
+				utils.PrintError(fmt.Errorf("--interactive requires a terminal"))
+				return
+			}
+
+			// Create S3 adapter
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+				return
+			}
+
+			if interactive {
+				// Pick a bucket and list its objects
+				bucketName, err := pickBucket(ctx, adapter)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+					utils.PrintError(err)
+					return
+				}
+				if bucketName == "" {
 					return
 				}
+				args = []string{bucketName}
+			}
 
-				// List Lambda functions
-				functions, err := adapter.ListFunctions(ctx, 0)
+			if len(args) == 0 {
+				// List S3 buckets
+				buckets, err := adapter.ListBuckets(ctx)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to list Lambda functions: %w", err))
+					utils.PrintError(fmt.Errorf("failed to list S3 buckets: %w", err))
 					return
 				}
+				s3.SortBuckets(buckets, sortBy)
 
-				// Format and print the output
-				utils.PrintOutput(functions, config.GetOutputFormat())
-			},
-		},
-		&cobra.Command{
-			Use:   "invoke [function-name]",
-			Short: "Invoke a Lambda function",
-			Long:  `Invoke a Lambda function and display the result.`,
-			Args:  cobra.ExactArgs(1),
-			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				functionName := args[0]
+				// Buckets are returned in a single response, so the limit is applied here
+				buckets, truncated := applyLimit(buckets, limit)
+				defer printLimitFooter(truncated, limit)
 
-				// Create Lambda adapter
-				adapter, err := lambda.NewAdapter(ctx)
-				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				if countOnly {
+					fmt.Fprintln(utils.OutputWriter(), len(buckets))
 					return
 				}
 
-				// Create empty payload
-				payload, err := lambda.FormatPayload(map[string]interface{}{})
+				// Format and print the output
+				utils.PrintList(buckets, limit, truncated, config.GetOutputFormat())
+			} else {
+				// List objects in bucket
+				bucketName, urlPrefix, err := s3.ParseS3URL(args[0])
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to format payload: %w", err))
+					utils.PrintError(err)
 					return
 				}
+				if !cmd.Flags().Changed("prefix") {
+					prefix = urlPrefix
+				}
 
-				// Invoke Lambda function
-				result, err := adapter.InvokeFunction(ctx, functionName, payload)
-				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to invoke Lambda function %s: %w", functionName, err))
+				if delimiter != "" {
+					// List one level of the bucket
+					listing, err := adapter.ListObjectsWithDelimiter(ctx, bucketName, prefix, delimiter, limitProbe(limit))
+					if err != nil {
+						utils.PrintError(fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err))
+						return
+					}
+					entries, truncated := applyLimit(listing.Entries(), limit)
+					defer printLimitFooter(truncated, limit)
+
+					if countOnly {
+						fmt.Fprintln(utils.OutputWriter(), len(entries))
+						return
+					}
+
+					// Format and print the output
+					utils.PrintList(entries, limit, truncated, config.GetOutputFormat())
 					return
 				}
 
-				// Check for function error
-				if result.FunctionError != "" {
-					utils.PrintError(fmt.Errorf("function execution error: %s", result.FunctionError))
+				if countOnly {
+					// Count the objects without listing them
+					count, err := adapter.CountObjects(ctx, bucketName, prefix, limitProbe(limit), pageSize)
+					if err != nil {
+						utils.PrintError(fmt.Errorf("failed to count objects in bucket %s: %w", bucketName, err))
+						return
+					}
+					truncated := limit > 0 && count > int(limit)
+					if truncated {
+						count = int(limit)
+					}
+					defer printLimitFooter(truncated, limit)
+
+					fmt.Fprintln(utils.OutputWriter(), count)
 					return
 				}
 
-				// Format and print the output
-				var responseData interface{}
-				if err := lambda.ParsePayload(result.Payload, &responseData); err != nil {
-					utils.PrintError(fmt.Errorf("failed to parse response: %w", err))
+				objects, err := adapter.ListObjectsWithPageSize(ctx, bucketName, prefix, limitProbe(limit), pageSize)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err))
 					return
 				}
+				objects, truncated := applyLimit(objects, limit)
+				defer printLimitFooter(truncated, limit)
 
-				utils.PrintOutput(responseData, config.GetOutputFormat())
-			},
+				// Format and print the output
+				utils.PrintList(objects, limit, truncated, config.GetOutputFormat())
+			}
 		},
+	}
+	lsCmd.Flags().String("prefix", "", "Only list objects whose key starts with this prefix")
+	lsCmd.Flags().Bool("count", false, "Print only the number of matching buckets or objects")
+	lsCmd.Flags().Int32("limit", 0, "Maximum number of buckets or objects to list (0 for all)")
+	lsCmd.Flags().Int32("page-size", 0, "Number of objects fetched per API call, up to 1000 (0 for the maximum)")
+	lsCmd.Flags().String("delimiter", "", "List one level of the bucket, grouping keys into common prefixes at this delimiter (e.g. /)")
+	lsCmd.Flags().BoolP("interactive", "i", false, "Pick a bucket from a list and show its objects")
+	lsCmd.Flags().String("sort", s3.BucketSortName, "Order of the bucket list: name, created or region")
+
+	// Add subcommands
+	cmd.AddCommand(
+		lsCmd,
+		cpCmd,
+		catCmd,
+		syncCmd,
+		rmCmd,
+		mbCmd,
+		rbCmd,
 		&cobra.Command{
-			Use:   "logs [function-name]",
-			Short: "Show logs for a Lambda function",
-			Long:  `Display CloudWatch logs for a Lambda function.`,
+			Use:   "head [s3://bucket/key]",
+			Short: "Show S3 object metadata",
+			Long:  `Show the metadata of an S3 object, including the restore status of archived objects.`,
 			Args:  cobra.ExactArgs(1),
 			Run: func(cmd *cobra.Command, args []string) {
-				ctx := context.Background()
-				functionName := args[0]
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
+				s3Path := args[0]
 
-				// Create Lambda adapter
-				adapter, err := lambda.NewAdapter(ctx)
+				// Create S3 adapter
+				adapter, err := s3.NewAdapter(ctx)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+					utils.PrintError(fmt.Errorf("failed to create S3 adapter: %w", err))
+					return
+				}
+
+				// Parse the S3 path
+				bucketName, key, err := parseS3ObjectURL(s3Path)
+				if err != nil {
+					utils.PrintError(err)
 					return
 				}
 
-				// Get logs for Lambda function (last 100 events)
-				logs, err := adapter.GetFunctionLogs(ctx, functionName, time.Time{}, 100)
+				// Get the object metadata
+				metadata, err := adapter.HeadObject(ctx, bucketName, key)
 				if err != nil {
-					utils.PrintError(fmt.Errorf("failed to get logs for Lambda function %s: %w", functionName, err))
+					utils.PrintError(fmt.Errorf("failed to get object metadata: %w", err))
 					return
 				}
 
 				// Format and print the output
-				utils.PrintOutput(logs, config.GetOutputFormat())
+				utils.PrintOutput(metadata, config.GetOutputFormat())
 			},
 		},
+		restoreCmd,
 	)
 
 	return cmd
 }
 
-// newModeCommand creates the mode command for switching between CLI and TUI modes
-func newModeCommand() *cobra.Command {
+// newLambdaCommand creates the lambda command
+func newLambdaCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "mode [cli|tui]",
-		Short: "Switch between CLI and TUI modes",
-		Long:  `Switch between command-line interface (CLI) and terminal user interface (TUI) modes.`,
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			mode := args[0]
-			if mode != "cli" && mode != "tui" {
-				return fmt.Errorf("invalid mode: %s (must be 'cli' or 'tui')", mode)
+		Use:   "lambda",
+		Short: "Lambda function management",
+		Long:  `Manage Lambda functions, layers, and related resources.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Lambda functions",
+		Long:  `List Lambda functions with optional filtering.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			countOnly, _ := cmd.Flags().GetBool("count")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
 			}
 
-			if err := config.SetAppMode(mode); err != nil {
-				return fmt.Errorf("failed to set mode: %w", err)
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
 			}
 
-			fmt.Printf("Switched to %s mode\n", mode)
+			// List Lambda functions
+			functions, err := adapter.ListFunctions(ctx, limitProbe(limit))
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to list Lambda functions: %w", err))
+				return
+			}
+			functions, truncated := applyLimit(functions, limit)
+			defer printLimitFooter(truncated, limit)
 
-			if mode == "tui" {
-				// Launch the TUI
-				return launchTUI()
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(functions))
+				return
 			}
 
-			return nil
+			// Format and print the output
+			utils.PrintList(functions, limit, truncated, config.GetOutputFormat())
+
+			// Call out functions on deprecated runtimes
+			warnDeprecatedRuntimes(functions)
 		},
 	}
+	listCmd.Flags().Bool("count", false, "Print only the number of functions")
+	listCmd.Flags().Int32("limit", 0, "Maximum number of functions to list (0 for all)")
 
-	return cmd
-}
+	logsCmd := &cobra.Command{
+		Use:   "logs [function-name[@region]...]",
+		Short: "Show logs for one or more Lambda functions",
+		Long: `Display CloudWatch logs for one or more Lambda functions.
 
-// newConfigCommand creates the config command for managing configuration
-func newConfigCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "config",
-		Short: "Manage configuration",
-		Long:  `View and modify configuration settings.`,
-	}
+With several functions, their events are merged in timestamp order and each line is
+prefixed with the name of the function that logged it, e.g.
+"awsm lambda logs ingest transform load --follow" to watch a pipeline together.
+All functions are read from the same region.
 
-	// Add subcommands
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "get [key]",
-			Short: "Get a configuration value",
-			Long:  `Get the value of a configuration setting.`,
-			Args:  cobra.ExactArgs(1),
-			Run: func(cmd *cobra.Command, args []string) {
-				key := args[0]
-				switch key {
-				case "profile":
-					fmt.Println(config.GetAWSProfile())
-				case "region":
-					fmt.Println(config.GetAWSRegion())
-				case "output":
-					fmt.Println(config.GetOutputFormat())
-				case "mode":
-					fmt.Println(config.GetAppMode())
-				default:
-					fmt.Printf("Unknown configuration key: %s\n", key)
-				}
-			},
-		},
-		&cobra.Command{
-			Use:   "set [key] [value]",
-			Short: "Set a configuration value",
-			Long:  `Set the value of a configuration setting.`,
-			Args:  cobra.ExactArgs(2),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				key := args[0]
-				value := args[1]
+Events follow --output like any other listing, and --format is an alias of --output.
+With --output text each event is printed as a "[timestamp] message" line, and with
+--output json, jsonl or yaml the messages that are JSON are also included parsed
+under Data, ready to be piped into jq.
 
-				var err error
-				switch key {
-				case "profile":
-					err = config.SetAWSProfile(value)
-				case "region":
-					err = config.SetAWSRegion(value)
-				case "output":
-					if !utils.IsValidOutputFormat(value) {
-						return fmt.Errorf("invalid output format: %s", value)
-					}
-					err = config.SetOutputFormat(value)
-				case "mode":
-					if value != "cli" && value != "tui" {
-						return fmt.Errorf("invalid mode: %s (must be 'cli' or 'tui')", value)
+Use --since for a window relative to now (e.g. --since 2h), or --start and --end for
+an absolute window. With --follow, new events are printed as they arrive until
+interrupted with Ctrl-C.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			functionNames, err := applyRegionSuffixes(args)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			follow, _ := cmd.Flags().GetBool("follow")
+			since, _ := cmd.Flags().GetString("since")
+			start, _ := cmd.Flags().GetString("start")
+			end, _ := cmd.Flags().GetString("end")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
+			}
+			if follow && (since != "" || start != "" || end != "") {
+				utils.PrintError(fmt.Errorf("--follow cannot be combined with --since, --start or --end"))
+				return
+			}
+			if err := applyLogFormat(cmd); err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Work out the time window before creating any client
+			startTime, endTime, err := logTimeWindow(since, start, end, time.Now())
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			if follow {
+				// Stream new events until interrupted
+				ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+
+				fmt.Fprintf(os.Stderr, "Tailing logs for %s (Ctrl-C to stop)\n", strings.Join(functionNames, ", "))
+				events, errs := adapter.TailFunctionsLogs(ctx, functionNames, lambda.DefaultTailInterval)
+				for events != nil {
+					select {
+					case event, ok := <-events:
+						if !ok {
+							events = nil
+							continue
+						}
+						printFollowedLogEvent(event)
+					case err, ok := <-errs:
+						if !ok {
+							errs = nil
+							continue
+						}
+						utils.PrintError(err)
 					}
-					err = config.SetAppMode(value)
-				default:
-					return fmt.Errorf("unknown configuration key: %s", key)
 				}
+				return
+			}
 
+			// Get logs for Lambda functions
+			var logs []lambda.LogEvent
+			if len(functionNames) == 1 {
+				logs, err = adapter.GetFunctionLogs(ctx, functionNames[0], startTime, endTime, limit)
 				if err != nil {
-					return fmt.Errorf("failed to set %s: %w", key, err)
+					err = fmt.Errorf("failed to get logs for Lambda function %s: %w", functionNames[0], err)
 				}
+			} else {
+				// The error already names the function whose logs failed
+				logs, err = adapter.GetFunctionsLogs(ctx, functionNames, startTime, endTime, limit)
+			}
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
 
-				fmt.Printf("Set %s to %s\n", key, value)
-				return nil
-			},
-		},
-		&cobra.Command{
-			Use:   "list",
-			Short: "List all configuration values",
-			Long:  `List all configuration settings and their values.`,
-			Run: func(cmd *cobra.Command, args []string) {
-				fmt.Println("Configuration:")
-				fmt.Printf("  profile: %s\n", config.GetAWSProfile())
-				fmt.Printf("  region: %s\n", config.GetAWSRegion())
-				fmt.Printf("  output: %s\n", config.GetOutputFormat())
-				fmt.Printf("  mode: %s\n", config.GetAppMode())
-			},
+			printLogEvents(logs)
 		},
-	)
+	}
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep polling and print new log events as they arrive until Ctrl-C")
+	logsCmd.Flags().String("since", "", "Only show events newer than this duration ago (e.g. 30m, 2h, 24h)")
+	logsCmd.Flags().String("start", "", "Only show events at or after this time (RFC 3339 or YYYY-MM-DD[ HH:MM:SS])")
+	logsCmd.Flags().String("end", "", "Only show events at or before this time (RFC 3339 or YYYY-MM-DD[ HH:MM:SS])")
+	logsCmd.Flags().Int32("limit", 100, "Maximum number of log events to show in total (0 for all)")
+	logsCmd.Flags().String("format", "", "Alias of --output for the log events (e.g. text, json)")
+	logsCmd.MarkFlagsMutuallyExclusive("since", "start")
 
-	return cmd
-}
+	invokeCmd := &cobra.Command{
+		Use:   "invoke [function-name[@region]]",
+		Short: "Invoke a Lambda function",
+		Long: `Invoke a Lambda function and display the result.
 
-// newContextCommand creates the context command for managing AWS contexts
-func newContextCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "context",
-		Short: "Manage AWS contexts",
-		Long:  `Create, switch, and manage AWS contexts (combinations of profile, region, and role).`,
-	}
+The payload is given inline with --payload or read from a file with --payload-file
+(use - to read it from stdin); it defaults to an empty JSON object.
 
-	// Add subcommands
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List available contexts",
-			Long:  `List all available AWS contexts.`,
-			Run: func(cmd *cobra.Command, args []string) {
-				// Get contexts
-				contexts := config.ListContexts()
+With --expand-env, ${NAME} references in the payload are replaced with the values
+of environment variables before it is sent, e.g. --payload '{"user":"${USER}"}'.
 
-				// Format output based on format
-				switch config.GetOutputFormat() {
-				case "json", "yaml":
-					utils.PrintOutput(contexts, config.GetOutputFormat())
-				default:
-					// Print table format
-					fmt.Println("Available Contexts:")
-					fmt.Println("-------------------")
-					fmt.Printf("%-20s %-15s %-15s %-30s\n", "NAME", "PROFILE", "REGION", "ROLE")
-					for _, ctx := range contexts {
-						current := " "
-						if ctx.Current {
-							current = "*"
-						}
-						fmt.Printf("%s %-19s %-15s %-15s %-30s\n",
-							current, ctx.Name, ctx.Profile, ctx.Region, ctx.Role)
-					}
+With --unwrap-body, JSON that the response carries as a string, such as the body
+of an API Gateway proxy response, is shown as nested data instead of escaped text.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			inlinePayload, _ := cmd.Flags().GetString("payload")
+			payloadFile, _ := cmd.Flags().GetString("payload-file")
+			raw, _ := cmd.Flags().GetBool("raw")
+			showLogs, _ := cmd.Flags().GetBool("show-logs")
+			expandEnv, _ := cmd.Flags().GetBool("expand-env")
+			unwrapBody, _ := cmd.Flags().GetBool("unwrap-body")
+
+			var lookupEnv func(string) (string, bool)
+			if expandEnv {
+				lookupEnv = os.LookupEnv
+			}
+
+			// Read and validate the payload before creating any client
+			payload, err := lambda.ReadPayload(inlinePayload, payloadFile, os.Stdin, lookupEnv)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Invoke Lambda function
+			result, err := adapter.InvokeFunction(ctx, functionName, payload)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to invoke Lambda function %s: %w", functionName, err))
+				return
+			}
+
+			// Print the execution log after the response (or the function error)
+			if showLogs {
+				defer printExecutionLog(result)
+			}
+
+			// Check for function error
+			if result.FunctionError != "" {
+				utils.PrintError(fmt.Errorf("function execution error: %s", result.FunctionError))
+				return
+			}
+
+			// Print the response exactly as the function returned it
+			if raw {
+				if err := utils.PrintRaw(result.Payload); err != nil {
+					utils.PrintError(err)
 				}
-			},
+				return
+			}
+
+			// Format and print the output
+			var responseData interface{}
+			if err := lambda.ParsePayload(result.Payload, &responseData); err != nil {
+				utils.PrintError(fmt.Errorf("failed to parse response: %w", err))
+				return
+			}
+			if unwrapBody {
+				responseData = lambda.UnwrapBody(responseData)
+			}
+
+			utils.PrintOutput(responseData, config.GetOutputFormat())
 		},
+	}
+	invokeCmd.Flags().String("payload", "", "JSON payload to send to the function")
+	invokeCmd.Flags().String("payload-file", "", "File containing the JSON payload to send (- for stdin)")
+	invokeCmd.Flags().Bool("raw", false, "Print the response payload unmodified")
+	invokeCmd.Flags().Bool("show-logs", false, "Print the execution log (last 4KB) to stderr after the response")
+	invokeCmd.Flags().Bool("expand-env", false, "Replace ${NAME} references in the payload with environment variable values")
+	invokeCmd.Flags().Bool("unwrap-body", false, "Show JSON nested as a string in the response, such as an API Gateway body, as data")
+	invokeCmd.MarkFlagsMutuallyExclusive("payload", "payload-file")
+	invokeCmd.MarkFlagsMutuallyExclusive("raw", "unwrap-body")
+
+	// Add subcommands
+	cmd.AddCommand(
+		listCmd,
 		&cobra.Command{
-			Use:   "current",
-			Short: "Show current context",
-			Long:  `Display information about the current AWS context.`,
+			Use:   "describe [function-name[@region]]",
+			Short: "Describe a Lambda function",
+			Long:  `Show detailed information about a Lambda function, including a warning if its runtime is deprecated.`,
+			Args:  cobra.ExactArgs(1),
 			Run: func(cmd *cobra.Command, args []string) {
-				// Get current context
-				ctx, err := config.GetCurrentContextInfo()
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
+				functionName, err := applyRegionSuffix(args[0])
 				if err != nil {
 					utils.PrintError(err)
 					return
 				}
 
-				// Format output based on format
-				switch config.GetOutputFormat() {
-				case "json", "yaml":
-					utils.PrintOutput(ctx, config.GetOutputFormat())
-				default:
-					fmt.Println("Current Context:")
-					fmt.Printf("  Name:    %s\n", ctx.Name)
-					fmt.Printf("  Profile: %s\n", ctx.Profile)
-					fmt.Printf("  Region:  %s\n", ctx.Region)
-					if ctx.Role != "" {
-						fmt.Printf("  Role:    %s\n", ctx.Role)
-					}
+				// Create Lambda adapter
+				adapter, err := lambda.NewAdapter(ctx)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+					return
 				}
-			},
-		},
-		&cobra.Command{
-			Use:   "use [context-name]",
-			Short: "Switch to a different context",
-			Long:  `Switch to a different AWS context.`,
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				contextName := args[0]
 
-				// Switch context
-				if err := config.SwitchContext(contextName); err != nil {
-					return fmt.Errorf("failed to switch context: %w", err)
+				// Get Lambda function
+				function, err := adapter.GetFunction(ctx, functionName)
+				if err != nil {
+					utils.PrintError(fmt.Errorf("failed to describe Lambda function %s: %w", functionName, err))
+					return
 				}
 
-				fmt.Printf("Switched to context '%s'\n", contextName)
-				return nil
+				// Format and print the output
+				utils.PrintOutput(function, config.GetOutputFormat())
+
+				// Call out a deprecated runtime
+				warnDeprecatedRuntimes([]lambda.Function{*function})
 			},
 		},
-		&cobra.Command{
-			Use:   "create [context-name]",
-			Short: "Create a new context",
-			Long:  `Create a new AWS context with specified profile, region, and optional role.`,
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				contextName := args[0]
+		invokeCmd,
+		logsCmd,
+		newLambdaEnvCommand(),
+		newLambdaConcurrencyCommand(),
+		newMetricsCommand("metrics [function-name[@region]]", "Show CloudWatch metrics for a Lambda function",
+			`Fetch the datapoints of an AWS/Lambda CloudWatch metric, such as Duration,
+Invocations or Errors, for a function over the last --since, one per --period.
+Text output shows a sparkline of each statistic; other output formats list the
+datapoints.`,
+			"Duration", (*cloudwatch.Adapter).GetLambdaFunctionMetric),
+	)
 
-				// Get flags
-				profile, _ := cmd.Flags().GetString("profile")
-				region, _ := cmd.Flags().GetString("region")
-				role, _ := cmd.Flags().GetString("role")
+	return cmd
+}
 
-				// Validate required flags
-				if profile == "" {
-					return fmt.Errorf("profile is required")
-				}
-				if region == "" {
-					return fmt.Errorf("region is required")
-				}
+// newLambdaEnvCommand creates the lambda env command
+func newLambdaEnvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Manage the environment variables of a Lambda function",
+		Long:  `List, set and unset the environment variables of a Lambda function.`,
+	}
 
-				// Create context
-				if err := config.NewContext(contextName, profile, region, role); err != nil {
-					return fmt.Errorf("failed to create context: %w", err)
-				}
+	listCmd := &cobra.Command{
+		Use:   "list [function-name[@region]]",
+		Short: "List the environment variables of a function",
+		Long:  `List the environment variables of a Lambda function, sorted by name.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
 
-				fmt.Printf("Created context '%s'\n", contextName)
-				return nil
-			},
-		},
-		&cobra.Command{
-			Use:   "delete [context-name]",
-			Short: "Delete a context",
-			Long:  `Delete an AWS context.`,
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				contextName := args[0]
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
 
-				// Delete context
-				if err := config.RemoveContext(contextName); err != nil {
-					return fmt.Errorf("failed to delete context: %w", err)
-				}
+			// Get Lambda function
+			function, err := adapter.GetFunction(ctx, functionName)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to describe Lambda function %s: %w", functionName, err))
+				return
+			}
 
-				fmt.Printf("Deleted context '%s'\n", contextName)
-				return nil
-			},
+			// Format and print the output
+			utils.PrintList(lambda.EnvVars(function.Environment), 0, false, config.GetOutputFormat())
 		},
-		&cobra.Command{
-			Use:   "import",
-			Short: "Import contexts from AWS config",
-			Long:  `Import contexts from the AWS config file.`,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				// Import contexts
-				count, err := config.ImportContextsFromAWS()
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set [function-name[@region]] KEY=VALUE...",
+		Short: "Set environment variables of a function",
+		Long: `Set environment variables of a Lambda function. The variables are merged with
+the function's current ones; with --replace they replace all of them, so any
+variable not given is removed.
+
+The update is rejected if the function is changed by someone else between
+reading its variables and writing them back; run the command again to retry.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			replace, _ := cmd.Flags().GetBool("replace")
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			vars, err := lambda.ParseEnvAssignments(args[1:])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Update the environment
+			env, err := adapter.UpdateEnvironment(ctx, functionName, vars, replace)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Wait until the function runs with the new environment, if requested
+			if shouldWait(cmd) {
+				err := waitForChange(cmd, fmt.Sprintf("Lambda function %s to be updated", functionName), func(ctx context.Context, interval time.Duration) error {
+					return adapter.WaitForFunctionActive(ctx, functionName, interval)
+				})
 				if err != nil {
-					return fmt.Errorf("failed to import contexts: %w", err)
+					utils.PrintError(err)
+					return
 				}
+			}
 
-				fmt.Printf("Imported %d contexts from AWS config\n", count)
-				return nil
-			},
+			// Format and print the output
+			utils.PrintList(lambda.EnvVars(env), 0, false, config.GetOutputFormat())
 		},
-		&cobra.Command{
-			Use:   "export",
-			Short: "Export contexts to AWS config",
-			Long:  `Export contexts to the AWS config file.`,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				// Get flags
-				overwrite, _ := cmd.Flags().GetBool("overwrite")
+	}
+	setCmd.Flags().Bool("replace", false, "Replace all environment variables of the function instead of merging")
+
+	unsetCmd := &cobra.Command{
+		Use:   "unset [function-name[@region]] KEY...",
+		Short: "Remove environment variables from a function",
+		Long: `Remove environment variables from a Lambda function. Variables that are not
+set are ignored.`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Update the environment
+			env, err := adapter.UnsetEnvironment(ctx, functionName, args[1:])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
 
-				// Export contexts
-				if err := config.ExportContextsToAWS(overwrite); err != nil {
-					return fmt.Errorf("failed to export contexts: %w", err)
+			// Wait until the function runs with the new environment, if requested
+			if shouldWait(cmd) {
+				err := waitForChange(cmd, fmt.Sprintf("Lambda function %s to be updated", functionName), func(ctx context.Context, interval time.Duration) error {
+					return adapter.WaitForFunctionActive(ctx, functionName, interval)
+				})
+				if err != nil {
+					utils.PrintError(err)
+					return
 				}
+			}
 
-				fmt.Println("Exported contexts to AWS config")
-				return nil
-			},
+			// Format and print the output
+			utils.PrintList(lambda.EnvVars(env), 0, false, config.GetOutputFormat())
 		},
-	)
+	}
 
-	// Add flags to create command
-	createCmd := cmd.Commands()[3] // The create command
-	createCmd.Flags().String("profile", "", "AWS profile to use")
-	createCmd.Flags().String("region", "", "AWS region to use")
-	createCmd.Flags().String("role", "", "AWS role to assume (optional)")
+	addWaitFlags(setCmd)
+	addWaitFlags(unsetCmd)
 
-	// Add flags to export command
-	exportCmd := cmd.Commands()[6] // The export command
-	exportCmd.Flags().Bool("overwrite", false, "Overwrite existing AWS config file")
+	cmd.AddCommand(listCmd, setCmd, unsetCmd)
 
 	return cmd
 }
 
-// newTUICommand creates a direct command to launch the TUI
-func newTUICommand() *cobra.Command {
+// newLambdaConcurrencyCommand creates the lambda concurrency command
+func newLambdaConcurrencyCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "tui",
-		Short: "Launch the Terminal User Interface",
-		Long:  `Launch the Terminal User Interface (TUI) for interactive AWS management.`,
+		Use:   "concurrency",
+		Short: "Manage the reserved concurrency of a Lambda function",
+		Long: `Set or clear the reserved concurrency of a Lambda function. A function with
+reserved concurrency runs at most that many instances at once, which protects
+downstream systems, and that many are set aside from the account's unreserved
+concurrency. The current value is the ReservedConcurrency field of lambda describe.`,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set [function-name[@region]] [limit]",
+		Short: "Reserve concurrency for a function",
+		Long:  `Reserve concurrency for a Lambda function. A limit of 0 throttles all invocations of the function.`,
+		Args:  cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := launchTUI(); err != nil {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
 				utils.PrintError(err)
-				os.Exit(1)
+				return
+			}
+
+			limit, err := strconv.ParseInt(args[1], 10, 32)
+			if err != nil || limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %s (must be a non-negative number)", args[1]))
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Set the reserved concurrency
+			if err := adapter.SetReservedConcurrency(ctx, functionName, int32(limit)); err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Reserved concurrency of %s set to %d\n", functionName, limit)
+		},
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear [function-name[@region]]",
+		Short: "Remove the reserved concurrency of a function",
+		Long:  `Remove the reserved concurrency of a Lambda function, so that it uses the account's unreserved concurrency again.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			functionName, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Create Lambda adapter
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Lambda adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Clear the reserved concurrency
+			if err := adapter.DeleteReservedConcurrency(ctx, functionName); err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Cleared reserved concurrency of %s\n", functionName)
+		},
+	}
+
+	cmd.AddCommand(setCmd, clearCmd)
+
+	return cmd
+}
+
+// newDynamoDBCommand creates the dynamodb command
+func newDynamoDBCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dynamodb",
+		Short: "DynamoDB table management",
+		Long:  `List, describe and scan DynamoDB tables.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List DynamoDB tables",
+		Long:  `List the names of the DynamoDB tables in the current region.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			countOnly, _ := cmd.Flags().GetBool("count")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
+			}
+
+			// Create DynamoDB adapter
+			adapter, err := dynamodb.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create DynamoDB adapter: %w", err))
+				return
+			}
+
+			// List DynamoDB tables
+			tables, err := adapter.ListTables(ctx, limitProbe(limit))
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to list DynamoDB tables: %w", err))
+				return
+			}
+			tables, truncated := applyLimit(tables, limit)
+			defer printLimitFooter(truncated, limit)
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(tables))
+				return
+			}
+
+			// Format and print the output
+			utils.PrintList(tables, limit, truncated, config.GetOutputFormat())
+		},
+	}
+	listCmd.Flags().Bool("count", false, "Print only the number of tables")
+	listCmd.Flags().Int32("limit", 0, "Maximum number of tables to list (0 for all)")
+
+	describeCmd := &cobra.Command{
+		Use:   "describe [table]",
+		Short: "Describe a DynamoDB table",
+		Long:  `Show the keys, billing mode, capacity, size and indexes of a DynamoDB table.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			tableName := args[0]
+
+			// Create DynamoDB adapter
+			adapter, err := dynamodb.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create DynamoDB adapter: %w", err))
+				return
+			}
+
+			// Describe DynamoDB table
+			table, err := adapter.DescribeTable(ctx, tableName)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(table, config.GetOutputFormat())
+		},
+	}
+
+	scanCmd := &cobra.Command{
+		Use:   "scan [table]",
+		Short: "Scan the items of a DynamoDB table",
+		Long: `Read items from a DynamoDB table. Attribute values are shown as plain
+strings, numbers, booleans, lists and maps, without DynamoDB type descriptors.
+
+Scans read the whole table, so the number of items is limited to 25 by default.
+When more items are left, a token is printed that resumes the scan after the
+last item with --starting-token.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			tableName := args[0]
+			countOnly, _ := cmd.Flags().GetBool("count")
+			limit, _ := cmd.Flags().GetInt32("limit")
+			startingToken, _ := cmd.Flags().GetString("starting-token")
+			if limit < 0 {
+				utils.PrintError(fmt.Errorf("invalid limit: %d", limit))
+				return
+			}
+
+			// Create DynamoDB adapter
+			adapter, err := dynamodb.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create DynamoDB adapter: %w", err))
+				return
+			}
+
+			// Scan DynamoDB table
+			items, nextToken, err := adapter.ScanPage(ctx, tableName, limit, startingToken)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			if nextToken != "" {
+				defer utils.PrintNotice(fmt.Sprintf("More items are left, continue with --starting-token %s", nextToken))
+			}
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(items))
+				return
+			}
+
+			// Format and print the output
+			utils.PrintListPage(items, limit, nextToken, config.GetOutputFormat())
+		},
+	}
+	scanCmd.Flags().Bool("count", false, "Print only the number of items read")
+	scanCmd.Flags().Int32("limit", 25, "Maximum number of items to read (0 for all)")
+	scanCmd.Flags().String("starting-token", "", "Resume a scan after the last item read by an earlier one")
+
+	// Add subcommands
+	cmd.AddCommand(listCmd, describeCmd, scanCmd)
+
+	return cmd
+}
+
+// newSQSCommand creates the sqs command
+func newSQSCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sqs",
+		Short: "SQS queue management",
+		Long:  `List SQS queues, send and receive messages, and purge queues.`,
+	}
+
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List SQS queues",
+		Long:  `List the names and URLs of the SQS queues in the current region.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			countOnly, _ := cmd.Flags().GetBool("count")
+
+			// Create SQS adapter
+			adapter, err := sqs.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SQS adapter: %w", err))
+				return
+			}
+
+			// List SQS queues
+			queues, err := adapter.ListQueues(ctx)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(queues))
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(queues, config.GetOutputFormat())
+		},
+	}
+	lsCmd.Flags().Bool("count", false, "Print only the number of queues")
+
+	sendCmd := &cobra.Command{
+		Use:   "send [queue-url] [body]",
+		Short: "Send a message to an SQS queue",
+		Long:  `Send a message with the given body to an SQS queue and print its message ID.`,
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			queueURL, body := args[0], args[1]
+
+			// Create SQS adapter
+			adapter, err := sqs.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SQS adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Send the message
+			messageID, err := adapter.SendMessage(ctx, queueURL, body)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Sent message %s to %s\n", messageID, sqs.QueueName(queueURL))
+		},
+	}
+
+	receiveCmd := &cobra.Command{
+		Use:   "receive [queue-url]",
+		Short: "Receive messages from an SQS queue",
+		Long: `Receive up to --max messages from an SQS queue and print their message ID, body
+and receipt handle. Received messages stay in the queue, hidden from other
+consumers until their visibility timeout expires, unless --delete is passed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			queueURL := args[0]
+			maxMessages, _ := cmd.Flags().GetInt32("max")
+			deleteAfter, _ := cmd.Flags().GetBool("delete")
+
+			// Create SQS adapter
+			adapter, err := sqs.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SQS adapter: %w", err))
+				return
+			}
+
+			// Deleting the received messages changes the queue
+			if deleteAfter {
+				printOperationBanner(ctx)
+			}
+
+			// Receive messages
+			messages, err := adapter.ReceiveMessages(ctx, queueURL, maxMessages)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+			if len(messages) == 0 {
+				fmt.Fprintln(os.Stderr, "No messages available")
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(messages, config.GetOutputFormat())
+
+			// Delete the messages only after they have been printed
+			if deleteAfter {
+				result := &utils.BulkResult{}
+				for _, msg := range messages {
+					result.Record(msg.ID, adapter.DeleteMessage(ctx, queueURL, msg.ReceiptHandle))
+				}
+				if err := result.Err(); err != nil {
+					utils.PrintError(fmt.Errorf("failed to delete messages: %s", result.Summary()))
+					return
+				}
+				fmt.Fprintf(os.Stderr, "Deleted %d messages\n", len(result.Succeeded))
+			}
+		},
+	}
+	receiveCmd.Flags().Int32("max", 1, fmt.Sprintf("Maximum number of messages to receive (1-%d)", sqs.MaxReceiveMessages))
+	receiveCmd.Flags().Bool("delete", false, "Delete the messages from the queue after printing them")
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge [queue-url]",
+		Short: "Delete all messages in an SQS queue",
+		Long: `Delete all messages in an SQS queue. You are asked to type the queue name to
+confirm, unless --yes is passed. SQS allows one purge per queue every 60 seconds.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			queueURL := args[0]
+			queueName := sqs.QueueName(queueURL)
+
+			// Create SQS adapter
+			adapter, err := sqs.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SQS adapter: %w", err))
+				return
+			}
+
+			// Confirm the purge
+			printOperationBanner(ctx)
+			if !confirmDestructiveAction(cmd, queueName) {
+				fmt.Fprintln(utils.OutputWriter(), "Aborted")
+				return
+			}
+
+			// Purge the queue
+			if err := adapter.PurgeQueue(ctx, queueURL); err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Purged queue %s\n", queueName)
+		},
+	}
+	purgeCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+
+	cmd.AddCommand(lsCmd, sendCmd, receiveCmd, purgeCmd)
+
+	return cmd
+}
+
+// newSecretsCommand creates the secrets command
+func newSecretsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Secrets Manager secrets",
+		Long:  `List Secrets Manager secrets and read their values.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List secrets",
+		Long:  `List the name, ARN and last change time of the secrets in the current region. Secret values are not read.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			countOnly, _ := cmd.Flags().GetBool("count")
+
+			// Create Secrets Manager adapter
+			adapter, err := secretsmanager.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Secrets Manager adapter: %w", err))
+				return
+			}
+
+			// List secrets
+			secrets, err := adapter.ListSecrets(ctx)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(secrets))
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(secrets, config.GetOutputFormat())
+		},
+	}
+	listCmd.Flags().Bool("count", false, "Print only the number of secrets")
+
+	getCmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Show a secret",
+		Long: `Show the current version of a secret. The value is redacted unless --reveal is
+passed, so the metadata can be checked without exposing the secret on screen.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			reveal, _ := cmd.Flags().GetBool("reveal")
+
+			// Create Secrets Manager adapter
+			adapter, err := secretsmanager.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create Secrets Manager adapter: %w", err))
+				return
+			}
+
+			// Read the secret
+			secret, err := getSecretForOutput(ctx, adapter, args[0], reveal)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(secret, config.GetOutputFormat())
+		},
+	}
+	getCmd.Flags().Bool("reveal", false, "Print the secret value instead of a redacted placeholder")
+
+	cmd.AddCommand(listCmd, getCmd)
+
+	return cmd
+}
+
+// newSSMCommand creates the ssm command
+func newSSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssm",
+		Short: "SSM Parameter Store management",
+		Long:  `List, read and write Systems Manager Parameter Store parameters.`,
+	}
+
+	lsCmd := &cobra.Command{
+		Use:   "ls [path]",
+		Short: "List parameters",
+		Long:  `List the parameters under a path (e.g. /prod/app), including nested paths, or all parameters. Values are not shown.`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			countOnly, _ := cmd.Flags().GetBool("count")
+
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if path != "" && !strings.HasPrefix(path, "/") {
+				utils.PrintError(fmt.Errorf("invalid path %q: must start with /", path))
+				return
+			}
+
+			// Create SSM adapter
+			adapter, err := ssm.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SSM adapter: %w", err))
+				return
+			}
+
+			// List parameters
+			parameters, err := adapter.ListParameters(ctx, path)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			if countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(parameters))
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(parameters, config.GetOutputFormat())
+		},
+	}
+	lsCmd.Flags().Bool("count", false, "Print only the number of parameters")
+
+	getCmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Show a parameter",
+		Long: `Show a parameter with its value. SecureString values are only decrypted when
+--decrypt is passed; otherwise the encrypted value is shown.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			decrypt, _ := cmd.Flags().GetBool("decrypt")
+
+			// Create SSM adapter
+			adapter, err := ssm.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SSM adapter: %w", err))
+				return
+			}
+
+			// Get the parameter
+			parameter, err := adapter.GetParameter(ctx, args[0], decrypt)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Format and print the output
+			utils.PrintOutput(parameter, config.GetOutputFormat())
+		},
+	}
+	getCmd.Flags().Bool("decrypt", false, "Decrypt the value of a SecureString parameter")
+
+	putCmd := &cobra.Command{
+		Use:   "put [name] [value]",
+		Short: "Create or update a parameter",
+		Long: `Create a parameter with the given value, or update an existing one with
+--overwrite. Use --type SecureString to store the value encrypted.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			name, value := args[0], args[1]
+			paramType, _ := cmd.Flags().GetString("type")
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+			if !ssm.IsValidParameterType(paramType) {
+				utils.PrintError(fmt.Errorf("invalid parameter type: %s (must be %s, %s or %s)", paramType, ssm.TypeString, ssm.TypeStringList, ssm.TypeSecureString))
+				return
+			}
+
+			// Create SSM adapter
+			adapter, err := ssm.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create SSM adapter: %w", err))
+				return
+			}
+
+			printOperationBanner(ctx)
+
+			// Put the parameter
+			version, err := adapter.PutParameter(ctx, name, value, paramType, overwrite)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Stored %s (%s, version %d)\n", name, paramType, version)
+		},
+	}
+	putCmd.Flags().String("type", ssm.TypeString, "Parameter type: String, StringList or SecureString")
+	putCmd.Flags().Bool("overwrite", false, "Replace the value of an existing parameter")
+
+	cmd.AddCommand(lsCmd, getCmd, putCmd)
+
+	return cmd
+}
+
+// newModeCommand creates the mode command for switching between CLI and TUI modes
+func newModeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mode [cli|tui]",
+		Short: "Switch between CLI and TUI modes",
+		Long:  `Switch between command-line interface (CLI) and terminal user interface (TUI) modes.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := args[0]
+			if mode != "cli" && mode != "tui" {
+				return fmt.Errorf("invalid mode: %s (must be 'cli' or 'tui')", mode)
+			}
+
+			// Switching to the TUI launches it, so it needs a context like the tui command
+			if mode == "tui" {
+				if err := requireChosenContext(cmd); err != nil {
+					return err
+				}
+			}
+
+			if err := config.SetAppMode(mode); err != nil {
+				return fmt.Errorf("failed to set mode: %w", err)
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Switched to %s mode\n", mode)
+
+			if mode == "tui" {
+				// Launch the TUI
+				return launchTUI(cmd)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// newConfigCommand creates the config command for managing configuration
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+		Long:  `View and modify configuration settings.`,
+	}
+
+	validateContextCmd := &cobra.Command{
+		Use:   "validate-context [context-name]",
+		Short: "Validate a context",
+		Long: `Check that a context is usable: its profile must exist in the AWS credentials
+or config file, its region must be a valid AWS region and its role, if any, must be
+a valid IAM role ARN. With --check-credentials, the credentials are also verified
+against AWS, by assuming the role if there is one. Defaults to the current context.
+
+Exits with a non-zero status if the context is not usable.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := ""
+			if len(args) > 0 {
+				contextName = args[0]
+			}
+			checkCredentials, _ := cmd.Flags().GetBool("check-credentials")
+
+			// Validate the context configuration
+			result, err := config.ValidateContext(contextName)
+			if err != nil {
+				return fmt.Errorf("failed to validate context: %w", err)
+			}
+
+			// Verify the credentials, if requested and the configuration is usable
+			if checkCredentials && result.Valid() {
+				ctx, cancel := client.WithTimeout(context.Background())
+				defer cancel()
+				result.CredentialsChecked = true
+
+				// With a role, the credentials are those of the role
+				awsClient, err := client.NewClientForContext(ctx, config.Context{Profile: result.Profile, Region: result.Region, Role: result.Role})
+				if err == nil {
+					result.Identity, err = awsClient.GetCallerIdentity(ctx)
+				}
+				if err != nil {
+					result.Problems = append(result.Problems, fmt.Sprintf("credentials check failed: %v", err))
+				}
+			}
+
+			// Format and print the output
+			utils.PrintOutput(result, config.GetOutputFormat())
+
+			if !result.Valid() {
+				return fmt.Errorf("context %s is not valid", result.Name)
+			}
+
+			return nil
+		},
+	}
+	validateContextCmd.Flags().Bool("check-credentials", false, "Also verify the credentials against AWS")
+
+	matrixCmd := &cobra.Command{
+		Use:   "matrix",
+		Short: "Print a table of all contexts for documentation",
+		Long: `Print a table of all contexts with their profile, region and role, the AWS
+account they use and whether their credentials validate, as a Markdown table
+that can be pasted into team documentation. With -o json, yaml or csv the rows
+are printed in that format instead.
+
+Pass --skip-credentials to leave out the credentials check, e.g. when offline.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skipCredentials, _ := cmd.Flags().GetBool("skip-credentials")
+
+			var check contextCredentialsCheck
+			if !skipCredentials {
+				check = func(awsContext config.Context) (*client.Identity, error) {
+					ctx, cancel := client.WithTimeout(context.Background())
+					defer cancel()
+
+					awsClient, err := client.NewClientForContext(ctx, awsContext)
+					if err != nil {
+						return nil, err
+					}
+					return awsClient.GetIdentity(ctx)
+				}
+			}
+			rows := contextMatrix(config.GetContexts(), check)
+
+			// Format output based on format
+			switch format := config.GetOutputFormat(); {
+			case utils.IsStructuredFormat(format) || utils.OutputFormat(format) == utils.FormatCSV:
+				utils.PrintOutput(rows, format)
+			default:
+				fmt.Fprint(utils.OutputWriter(), contextMatrixMarkdown(rows))
+			}
+			return nil
+		},
+	}
+	matrixCmd.Flags().Bool("skip-credentials", false, "Don't verify the credentials of each context against AWS")
+
+	setCmd := &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Set a configuration value",
+		Long: `Set the value of a configuration setting.
+
+With --context, the output format is set for a single context and is used
+instead of the global output format while that context is current. Set it to
+"" to make the context use the global output format again.
+
+The profile must exist in the AWS credentials or config file. Pass --force to set
+a profile anyway, e.g. one that is only known to the environment.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			value := args[1]
+
+			// Set the output format of a single context
+			if contextName, _ := cmd.Flags().GetString("context"); contextName != "" {
+				if key != "output" {
+					return fmt.Errorf("--context is only supported for the output key")
+				}
+				if value != "" && !utils.IsValidOutputFormat(value) {
+					return fmt.Errorf("invalid output format: %s", value)
+				}
+				if err := config.SetContextOutputFormat(contextName, value); err != nil {
+					return fmt.Errorf("failed to set %s: %w", key, err)
+				}
+				fmt.Fprintf(utils.OutputWriter(), "Set %s to %q for context %s\n", key, value, contextName)
+				return nil
+			}
+
+			var err error
+			switch key {
+			case "profile":
+				if force, _ := cmd.Flags().GetBool("force"); force {
+					err = config.SetAWSProfileUnchecked(value)
+				} else {
+					err = config.SetAWSProfile(value)
+				}
+			case "region":
+				err = config.SetAWSRegion(value)
+			case "output":
+				if !utils.IsValidOutputFormat(value) {
+					return fmt.Errorf("invalid output format: %s", value)
+				}
+				err = config.SetOutputFormat(value)
+			case "mode":
+				if value != "cli" && value != "tui" {
+					return fmt.Errorf("invalid mode: %s (must be 'cli' or 'tui')", value)
+				}
+				err = config.SetAppMode(value)
+			case "alt-screen":
+				enabled, convErr := strconv.ParseBool(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid alt-screen value: %s (must be 'true' or 'false')", value)
+				}
+				err = config.SetAltScreen(enabled)
+			case "theme":
+				if !theme.IsValid(value) {
+					return fmt.Errorf("invalid theme: %s (must be one of %s)", value, strings.Join(theme.Names(), ", "))
+				}
+				err = config.SetTheme(value)
+			case "refresh-interval":
+				interval, convErr := time.ParseDuration(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid refresh interval: %s (e.g. 30s or 5m)", value)
+				}
+				err = config.SetRefreshInterval(interval)
+			case "require-context":
+				enabled, convErr := strconv.ParseBool(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid require-context value: %s (must be 'true' or 'false')", value)
+				}
+				err = config.SetRequireContext(enabled)
+			case "wait":
+				enabled, convErr := strconv.ParseBool(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid wait value: %s (must be 'true' or 'false')", value)
+				}
+				err = config.SetWait(enabled)
+			case "max-retries":
+				retries, convErr := strconv.Atoi(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid max retries: %s", value)
+				}
+				err = config.SetMaxRetries(retries)
+			case "retry-mode":
+				err = config.SetRetryMode(value)
+			case "timeout":
+				duration, convErr := time.ParseDuration(value)
+				if convErr != nil {
+					return fmt.Errorf("invalid timeout: %s (e.g. 30s or 2m)", value)
+				}
+				err = config.SetAWSTimeout(duration)
+			default:
+				return fmt.Errorf("unknown configuration key: %s", key)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to set %s: %w", key, err)
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Set %s to %s\n", key, value)
+			return nil
+		},
+	}
+	setCmd.Flags().String("context", "", "Set the output format for this context only")
+	setCmd.Flags().Bool("force", false, "Set a profile that is not in the AWS credentials or config file")
+
+	// Add subcommands
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "get [key]",
+			Short: "Get a configuration value",
+			Long:  `Get the value of a configuration setting.`,
+			Args:  cobra.ExactArgs(1),
+			Run: func(cmd *cobra.Command, args []string) {
+				key := args[0]
+				switch key {
+				case "profile":
+					fmt.Fprintln(utils.OutputWriter(), config.GetAWSProfile())
+				case "region":
+					fmt.Fprintln(utils.OutputWriter(), config.GetAWSRegion())
+				case "output":
+					fmt.Fprintln(utils.OutputWriter(), config.GetOutputFormat())
+				case "mode":
+					fmt.Fprintln(utils.OutputWriter(), config.GetAppMode())
+				case "alt-screen":
+					fmt.Fprintln(utils.OutputWriter(), config.GetAltScreen())
+				case "theme":
+					fmt.Fprintln(utils.OutputWriter(), config.GetTheme())
+				case "refresh-interval":
+					fmt.Fprintln(utils.OutputWriter(), config.GetRefreshInterval())
+				case "require-context":
+					fmt.Fprintln(utils.OutputWriter(), config.GetRequireContext())
+				case "wait":
+					fmt.Fprintln(utils.OutputWriter(), config.GetWait())
+				case "max-retries":
+					fmt.Fprintln(utils.OutputWriter(), config.GetMaxRetries())
+				case "retry-mode":
+					fmt.Fprintln(utils.OutputWriter(), config.GetRetryMode())
+				case "timeout":
+					fmt.Fprintln(utils.OutputWriter(), config.GetAWSTimeout())
+				default:
+					fmt.Fprintf(utils.OutputWriter(), "Unknown configuration key: %s\n", key)
+				}
+			},
+		},
+		setCmd,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List all configuration values",
+			Long:  `List all configuration settings and their values.`,
+			Run: func(cmd *cobra.Command, args []string) {
+				settings := config.GetSettings()
+
+				// Format output based on format
+				switch {
+				case utils.IsStructuredFormat(config.GetOutputFormat()):
+					utils.PrintOutput(settings, config.GetOutputFormat())
+				default:
+					fmt.Fprintln(utils.OutputWriter(), "Configuration:")
+					fmt.Fprintf(utils.OutputWriter(), "  profile: %s\n", settings.Profile)
+					fmt.Fprintf(utils.OutputWriter(), "  region: %s\n", settings.Region)
+					fmt.Fprintf(utils.OutputWriter(), "  output: %s\n", settings.Output)
+					fmt.Fprintf(utils.OutputWriter(), "  mode: %s\n", settings.Mode)
+					fmt.Fprintf(utils.OutputWriter(), "  alt-screen: %t\n", settings.AltScreen)
+					fmt.Fprintf(utils.OutputWriter(), "  theme: %s\n", settings.Theme)
+					fmt.Fprintf(utils.OutputWriter(), "  refresh-interval: %s\n", settings.RefreshInterval)
+					fmt.Fprintf(utils.OutputWriter(), "  require-context: %t\n", settings.RequireContext)
+					fmt.Fprintf(utils.OutputWriter(), "  wait: %t\n", settings.Wait)
+					fmt.Fprintf(utils.OutputWriter(), "  max-retries: %d\n", settings.MaxRetries)
+					fmt.Fprintf(utils.OutputWriter(), "  retry-mode: %s\n", settings.RetryMode)
+					fmt.Fprintf(utils.OutputWriter(), "  timeout: %s\n", settings.Timeout)
+					if settings.LocalFile != "" {
+						fmt.Fprintf(utils.OutputWriter(), "  local-file: %s\n", settings.LocalFile)
+					}
+				}
+			},
+		},
+		validateContextCmd,
+		matrixCmd,
+	)
+
+	return cmd
+}
+
+// newContextCommand creates the context command for managing AWS contexts
+func newContextCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage AWS contexts",
+		Long:  `Create, switch, and manage AWS contexts (combinations of profile, region, and role).`,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create [context-name]",
+		Short: "Create a new context",
+		Long:  `Create a new AWS context with specified profile, region, and optional role.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := args[0]
+
+			// Get flags
+			profile, _ := cmd.Flags().GetString("profile")
+			region, _ := cmd.Flags().GetString("region")
+			role, _ := cmd.Flags().GetString("role")
+
+			// Validate required flags
+			if profile == "" {
+				return fmt.Errorf("profile is required")
+			}
+			if region == "" {
+				return fmt.Errorf("region is required")
+			}
+
+			// Create context
+			if err := config.NewContext(contextName, profile, region, role); err != nil {
+				return fmt.Errorf("failed to create context: %w", err)
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Created context '%s'\n", contextName)
+			return nil
+		},
+	}
+	createCmd.Flags().String("profile", "", "AWS profile to use")
+	createCmd.Flags().String("region", "", "AWS region to use")
+	createCmd.Flags().String("role", "", "AWS role to assume (optional)")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [context-name]",
+		Short: "Delete a context",
+		Long:  `Delete an AWS context.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := args[0]
+
+			// Confirm the deletion
+			if !confirmDestructiveAction(cmd, contextName) {
+				fmt.Fprintln(utils.OutputWriter(), "Aborted")
+				return nil
+			}
+
+			// Delete context
+			if err := config.RemoveContext(contextName); err != nil {
+				return fmt.Errorf("failed to delete context: %w", err)
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Deleted context '%s'\n", contextName)
+			return nil
+		},
+	}
+	deleteCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+
+	shellCmd := &cobra.Command{
+		Use:   "shell [context-name]",
+		Short: "Start a subshell for a context",
+		Long: `Start a subshell with AWS_PROFILE and AWS_REGION exported for the given context
+(defaults to the current context). Any tool run in the subshell uses the context;
+exiting the subshell restores the previous environment. The awsm current context
+is not changed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := config.GetCurrentContext()
+			if len(args) > 0 {
+				contextName = args[0]
+			}
+
+			// Look up the context
+			ctx, exists := config.GetContexts()[contextName]
+			if !exists {
+				return fmt.Errorf("context %s does not exist", contextName)
+			}
+
+			// Start the subshell with the context environment
+			shell := userShell()
+			subshell := exec.Command(shell)
+			subshell.Stdin = os.Stdin
+			subshell.Stdout = os.Stdout
+			subshell.Stderr = os.Stderr
+			subshell.Env = contextShellEnv(os.Environ(), contextName, ctx)
+
+			fmt.Fprintf(os.Stderr, "Starting %s for context '%s' (exit to return)\n", shell, contextName)
+			if err := subshell.Run(); err != nil {
+				// A non-zero exit status of the last command in the subshell is not an error
+				if _, ok := err.(*exec.ExitError); !ok {
+					return fmt.Errorf("failed to run subshell: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export contexts to AWS config",
+		Long:  `Export contexts to the AWS config file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get flags
+			overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+			// Export contexts
+			if err := config.ExportContextsToAWS(overwrite); err != nil {
+				return fmt.Errorf("failed to export contexts: %w", err)
+			}
+
+			fmt.Fprintln(utils.OutputWriter(), "Exported contexts to AWS config")
+			return nil
+		},
+	}
+	exportCmd.Flags().Bool("overwrite", false, "Overwrite existing AWS config file")
+
+	importFileCmd := &cobra.Command{
+		Use:   "import-file [file]",
+		Short: "Create contexts from a YAML or JSON file",
+		Long: `Create contexts from a YAML or JSON file holding a list of context definitions,
+each with a name, profile, region and optional role. All definitions are checked
+before any context is created. Existing contexts are skipped unless --update is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get flags
+			update, _ := cmd.Flags().GetBool("update")
+
+			// Import contexts
+			result, err := config.ImportContextsFromFile(args[0], update)
+			if err != nil {
+				return fmt.Errorf("failed to import contexts: %w", err)
+			}
+
+			// Format output based on format
+			switch {
+			case utils.IsStructuredFormat(config.GetOutputFormat()):
+				utils.PrintOutput(result, config.GetOutputFormat())
+			default:
+				for _, name := range result.Created {
+					fmt.Fprintf(utils.OutputWriter(), "Created context '%s'\n", name)
+				}
+				for _, name := range result.Updated {
+					fmt.Fprintf(utils.OutputWriter(), "Updated context '%s'\n", name)
+				}
+				for _, name := range result.Skipped {
+					fmt.Fprintf(utils.OutputWriter(), "Skipped context '%s' (already exists, use --update to replace it)\n", name)
+				}
+				fmt.Fprintf(utils.OutputWriter(), "Imported %d contexts: %d created, %d updated, %d skipped\n",
+					len(result.Created)+len(result.Updated), len(result.Created), len(result.Updated), len(result.Skipped))
+			}
+			return nil
+		},
+	}
+	importFileCmd.Flags().Bool("update", false, "Replace existing contexts with the definitions from the file")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List available contexts",
+		Long:  `List all available AWS contexts.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get contexts
+			contexts := config.ListContexts()
+
+			if countOnly, _ := cmd.Flags().GetBool("count"); countOnly {
+				fmt.Fprintln(utils.OutputWriter(), len(contexts))
+				return
+			}
+
+			// Format output based on format
+			switch {
+			case utils.IsStructuredFormat(config.GetOutputFormat()):
+				utils.PrintOutput(contexts, config.GetOutputFormat())
+			default:
+				// Print table format
+				fmt.Fprintln(utils.OutputWriter(), "Available Contexts:")
+				fmt.Fprintln(utils.OutputWriter(), "-------------------")
+				fmt.Fprintf(utils.OutputWriter(), "%-20s %-15s %-15s %-30s\n", "NAME", "PROFILE", "REGION", "ROLE")
+				for _, ctx := range contexts {
+					current := " "
+					if ctx.Current {
+						current = "*"
+					}
+					fmt.Fprintf(utils.OutputWriter(), "%s %-19s %-15s %-15s %-30s\n",
+						current, ctx.Name, ctx.Profile, ctx.Region, ctx.Role)
+				}
+			}
+		},
+	}
+	listCmd.Flags().Bool("count", false, "Print only the number of contexts")
+
+	diffCmd := &cobra.Command{
+		Use:   "diff [context-a] [context-b]",
+		Short: "Compare the resources of two contexts",
+		Long: `List the resources of a service in two contexts and show the ones that exist
+in only one of them, e.g. to compare the staging and production inventories.
+
+Resources are matched by name: EC2 instances by their Name tag (or instance ID
+if they have none), S3 buckets and Lambda functions by their name. Names are
+counted, so three instances named web in one context and one in the other show
+web twice as only in the first.
+
+S3 buckets are global rather than regional: each context lists all buckets of
+its account, and bucket names are unique across accounts, so buckets are only
+in both contexts when the two contexts use the same account.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get flags
+			service, _ := cmd.Flags().GetString("service")
+			if !slices.Contains(contextDiffServices, service) {
+				return fmt.Errorf("unsupported service %s (supported: %s)", service, strings.Join(contextDiffServices, ", "))
+			}
+
+			// Look up the contexts
+			contexts := config.GetContexts()
+			for _, name := range args {
+				if _, exists := contexts[name]; !exists {
+					return fmt.Errorf("context %s does not exist", name)
+				}
+			}
+
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// List the resources of each context
+			names := make([][]string, len(args))
+			for i, name := range args {
+				awsClient, err := client.NewClientForContext(ctx, contexts[name])
+				if err != nil {
+					return fmt.Errorf("failed to create AWS client for context %s: %w", name, err)
+				}
+				if names[i], err = contextResourceNames(ctx, awsClient, service); err != nil {
+					return fmt.Errorf("failed to list %s resources in context %s: %w", service, name, err)
+				}
+			}
+
+			diff := diffResourceNames(names[0], names[1])
+			diff.Service = service
+			diff.ContextA = args[0]
+			diff.ContextB = args[1]
+			if service == "s3" {
+				diff.Note = s3DiffNote
+			}
+
+			// Format output based on format
+			switch {
+			case utils.IsStructuredFormat(config.GetOutputFormat()):
+				utils.PrintOutput(diff, config.GetOutputFormat())
+			default:
+				fmt.Fprintf(utils.OutputWriter(), "Only in %s (%d):\n", diff.ContextA, len(diff.OnlyInA))
+				for _, name := range diff.OnlyInA {
+					fmt.Fprintf(utils.OutputWriter(), "  - %s\n", name)
+				}
+				fmt.Fprintf(utils.OutputWriter(), "Only in %s (%d):\n", diff.ContextB, len(diff.OnlyInB))
+				for _, name := range diff.OnlyInB {
+					fmt.Fprintf(utils.OutputWriter(), "  + %s\n", name)
+				}
+				fmt.Fprintf(utils.OutputWriter(), "In both: %d\n", len(diff.InBoth))
+				if diff.Note != "" {
+					fmt.Fprintf(utils.OutputWriter(), "Note: %s\n", diff.Note)
+				}
+			}
+			return nil
+		},
+	}
+	diffCmd.Flags().String("service", "ec2", "Service whose resources to compare ("+strings.Join(contextDiffServices, ", ")+")")
+
+	useCmd := &cobra.Command{
+		Use:   "use [context-name]",
+		Short: "Switch to a different context",
+		Long: `Switch to a different AWS context.
+
+If the context has a role, it is assumed right away to check that it can be,
+and a warning is printed if it cannot. The context is switched to either way;
+use --no-verify-role to skip the check, e.g. when offline.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := args[0]
+
+			// Assume the role of the context, unless told not to
+			if noVerifyRole, _ := cmd.Flags().GetBool("no-verify-role"); !noVerifyRole {
+				config.SetRoleVerifier(client.VerifyRole, utils.PrintWarning)
+			}
+
+			// Switch context
+			if err := config.SwitchContext(contextName); err != nil {
+				return fmt.Errorf("failed to switch context: %w", err)
+			}
+
+			fmt.Fprintf(utils.OutputWriter(), "Switched to context '%s'\n", contextName)
+			return nil
+		},
+	}
+	useCmd.Flags().Bool("no-verify-role", false, "Don't assume the context's role to check that it can be")
+
+	// Add subcommands
+	cmd.AddCommand(
+		listCmd,
+		&cobra.Command{
+			Use:   "current",
+			Short: "Show current context",
+			Long:  `Display information about the current AWS context.`,
+			Run: func(cmd *cobra.Command, args []string) {
+				// Get current context
+				ctx, err := config.GetCurrentContextInfo()
+				if err != nil {
+					utils.PrintError(err)
+					return
+				}
+
+				// Format output based on format
+				switch {
+				case utils.IsStructuredFormat(config.GetOutputFormat()):
+					utils.PrintOutput(ctx, config.GetOutputFormat())
+				default:
+					fmt.Fprintln(utils.OutputWriter(), "Current Context:")
+					fmt.Fprintf(utils.OutputWriter(), "  Name:    %s\n", ctx.Name)
+					fmt.Fprintf(utils.OutputWriter(), "  Profile: %s\n", ctx.Profile)
+					fmt.Fprintf(utils.OutputWriter(), "  Region:  %s\n", ctx.Region)
+					if ctx.Role != "" {
+						fmt.Fprintf(utils.OutputWriter(), "  Role:    %s\n", ctx.Role)
+					}
+					if ctx.Output != "" {
+						fmt.Fprintf(utils.OutputWriter(), "  Output:  %s\n", ctx.Output)
+					}
+				}
+			},
+		},
+		useCmd,
+		createCmd,
+		deleteCmd,
+		&cobra.Command{
+			Use:   "import",
+			Short: "Import contexts from AWS config",
+			Long:  `Import contexts from the AWS config file.`,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				// Import contexts
+				count, err := config.ImportContextsFromAWS()
+				if err != nil {
+					return fmt.Errorf("failed to import contexts: %w", err)
+				}
+
+				fmt.Fprintf(utils.OutputWriter(), "Imported %d contexts from AWS config\n", count)
+				return nil
+			},
+		},
+		importFileCmd,
+		exportCmd,
+		shellCmd,
+		diffCmd,
+	)
+
+	return cmd
+}
+
+// pickBucket lets the user choose a bucket from an interactive list.
+// It returns an empty name if the user cancels.
+func pickBucket(ctx context.Context, adapter *s3.Adapter) (string, error) {
+	buckets, err := adapter.ListBuckets(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list S3 buckets: %w", err)
+	}
+	if len(buckets) == 0 {
+		return "", fmt.Errorf("no S3 buckets found")
+	}
+
+	items := make([]components.PickerItem, 0, len(buckets))
+	for _, bucket := range buckets {
+		detail := "created " + bucket.CreationDate.Format("2006-01-02")
+		if bucket.Region != "" {
+			detail = bucket.Region + " · " + detail
+		}
+		items = append(items, components.PickerItem{Name: bucket.Name, Detail: detail})
+	}
+
+	choice, err := components.RunPicker("S3 Buckets", items)
+	if err != nil || choice < 0 {
+		return "", err
+	}
+
+	return buckets[choice].Name, nil
+}
+
+// copyRecursive uploads a local directory to an S3 prefix, or downloads an S3 prefix
+// to a local directory, and prints a summary of the transferred files
+func copyRecursive(ctx context.Context, cmd *cobra.Command, adapter *s3.Adapter, source, destination, acl string) error {
+	var transfers []s3.Transfer
+	var err error
+
+	if strings.HasPrefix(source, "s3://") {
+		// Download from S3
+		if acl != "" {
+			utils.PrintError(fmt.Errorf("--acl can only be used when uploading to S3"))
+			return nil
+		}
+
+		bucketName, prefix, parseErr := s3.ParseS3URL(source)
+		if parseErr != nil {
+			utils.PrintError(parseErr)
+			return nil
+		}
+
+		transfers, err = adapter.DownloadPrefix(ctx, bucketName, prefix, destination)
+	} else {
+		// Upload to S3
+		if info, statErr := os.Stat(source); statErr != nil || !info.IsDir() {
+			utils.PrintError(fmt.Errorf("%s is not a directory", source))
+			return nil
+		}
+
+		bucketName, prefix, parseErr := s3.ParseS3URL(destination)
+		if parseErr != nil {
+			utils.PrintError(parseErr)
+			return nil
+		}
+
+		transfers, err = adapter.UploadDirectory(ctx, bucketName, prefix, source, s3.UploadOptions{ACL: acl})
+	}
+
+	// Report each transferred file
+	result := &utils.BulkResult{}
+	for _, transfer := range transfers {
+		result.Record(transfer.Source, transfer.Error)
+		reportBulkItem(transfer.Error, "Copied %s", fmt.Sprintf("%s to %s", transfer.Source, transfer.Destination))
+	}
+
+	if err != nil {
+		utils.PrintError(err)
+		result.Record(source, err)
+	}
+
+	return printBulkResult(cmd, result)
+}
+
+// printSyncActions prints the action taken for each file of an s3 sync, followed by
+// a summary. It returns an error if any upload or deletion failed.
+func printSyncActions(cmd *cobra.Command, bucketName string, actions []s3.SyncAction, err error) error {
+	counts := make(map[string]int)
+	failed := 0
+	for _, action := range actions {
+		if action.Error != "" {
+			failed++
+			continue
+		}
+		counts[action.Action]++
+	}
+
+	switch {
+	case utils.IsStructuredFormat(config.GetOutputFormat()):
+		utils.PrintOutput(actions, config.GetOutputFormat())
+	default:
+		for _, action := range actions {
+			target := fmt.Sprintf("s3://%s/%s", bucketName, action.Key)
+			if action.Path != "" {
+				target = fmt.Sprintf("%s to %s", action.Path, target)
+			}
+
+			if action.Error != "" {
+				utils.PrintError(fmt.Errorf("%s failed: %s: %s", action.Action, target, action.Error))
+				continue
+			}
+			fmt.Fprintf(utils.OutputWriter(), "%s: %s\n", action.Action, target)
+		}
+
+		fmt.Fprintf(utils.OutputWriter(), "\n%d uploaded, %d skipped, %d deleted, %d failed\n",
+			counts[s3.SyncActionUpload], counts[s3.SyncActionSkip], counts[s3.SyncActionDelete], failed)
+	}
+
+	if err != nil {
+		utils.PrintError(err)
+		failed++
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return fmt.Errorf("%d sync operations failed", failed)
+	}
+	return nil
+}
+
+// logTimeLayouts are the layouts accepted by --start and --end, besides RFC 3339.
+// They are interpreted in the local time zone.
+var logTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseLogTime parses a --start or --end value
+func parseLogTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range logTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %s (use RFC 3339 or YYYY-MM-DD[ HH:MM:SS])", value)
+}
+
+// parseRegionSuffix splits a resource reference of the form name@region, such as
+// myfn@us-west-2, into the name and the region. The region is empty if the
+// reference has no suffix.
+func parseRegionSuffix(ref string) (string, string, error) {
+	name, region, found := strings.Cut(ref, "@")
+	if !found {
+		return ref, "", nil
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("invalid reference %q: missing name before @", ref)
+	}
+	if !config.IsValidRegion(region) {
+		return "", "", fmt.Errorf("invalid region %q in %q", region, ref)
+	}
+	return name, region, nil
+}
+
+// applyRegionSuffix parses a resource reference with parseRegionSuffix and, if it
+// names a region, makes the current invocation use that region. Like the retry
+// flags, the region is not saved.
+//
+// Returns the resource name without the suffix.
+func applyRegionSuffix(ref string) (string, error) {
+	name, region, err := parseRegionSuffix(ref)
+	if err != nil {
+		return "", err
+	}
+	if region != "" {
+		config.GlobalConfig.AWS.Region = region
+	}
+	return name, nil
+}
+
+// applyRegionSuffixes is applyRegionSuffix for several resource references,
+// which must not name different regions since a command runs in one region.
+//
+// Returns the resource names without their suffixes.
+func applyRegionSuffixes(refs []string) ([]string, error) {
+	names := make([]string, len(refs))
+	var region string
+	for i, ref := range refs {
+		name, refRegion, err := parseRegionSuffix(ref)
+		if err != nil {
+			return nil, err
+		}
+		if refRegion != "" {
+			if region != "" && refRegion != region {
+				return nil, fmt.Errorf("references name different regions (%s and %s)", region, refRegion)
+			}
+			region = refRegion
+		}
+		names[i] = name
+	}
+	if region != "" {
+		config.GlobalConfig.AWS.Region = region
+	}
+	return names, nil
+}
+
+// logTimeWindow computes the start and end of a log query from the --since, --start
+// and --end flags. Unset bounds are returned as zero times.
+func logTimeWindow(since, start, end string, now time.Time) (time.Time, time.Time, error) {
+	var startTime, endTime time.Time
+
+	if since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 {
+			return startTime, endTime, fmt.Errorf("invalid duration for --since: %s (e.g. 30m, 2h, 24h)", since)
+		}
+		startTime = now.Add(-duration)
+	}
+
+	if start != "" {
+		t, err := parseLogTime(start)
+		if err != nil {
+			return startTime, endTime, err
+		}
+		startTime = t
+	}
+
+	if end != "" {
+		t, err := parseLogTime(end)
+		if err != nil {
+			return startTime, endTime, err
+		}
+		endTime = t
+	}
+
+	if !startTime.IsZero() && !endTime.IsZero() && endTime.Before(startTime) {
+		return startTime, endTime, fmt.Errorf("end time %s is before start time %s", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339))
+	}
+
+	return startTime, endTime, nil
+}
+
+// applyOutputFormat applies the --output flag to the current invocation. The
+// format is not saved and overrides the output format of the current context.
+func applyOutputFormat(format string) error {
+	if !utils.IsValidOutputFormat(format) {
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+	config.GlobalConfig.Output.Format = format
+	return nil
+}
+
+// applyLogFormat applies the --format flag of lambda logs, an alias of --output,
+// to the current invocation
+func applyLogFormat(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("format") {
+		return nil
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if cmd.Flags().Changed("output") {
+		if output, _ := cmd.Flags().GetString("output"); output != format {
+			return fmt.Errorf("--format %s conflicts with --output %s", format, output)
+		}
+	}
+	if !utils.IsValidOutputFormat(format) {
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+	config.GlobalConfig.Output.Format = format
+	return nil
+}
+
+// printLogEvents prints Lambda log events in the configured output format, as
+// "[timestamp] message" lines for text output
+func printLogEvents(events []lambda.LogEvent) {
+	outputFormat := config.GetOutputFormat()
+	if outputFormat != "text" {
+		utils.PrintOutput(events, outputFormat)
+		return
+	}
+
+	for _, event := range events {
+		fmt.Fprintln(utils.OutputWriter(), lambda.FormatLogEvent(event))
+	}
+}
+
+// printFollowedLogEvent prints one event streamed by --follow. Structured formats
+// print it as a record; the others print a "[timestamp] message" line, since a
+// table per event would repeat the header for every line.
+func printFollowedLogEvent(event lambda.LogEvent) {
+	switch {
+	case utils.IsStructuredFormat(config.GetOutputFormat()):
+		utils.PrintOutput([]lambda.LogEvent{event}, config.GetOutputFormat())
+	default:
+		fmt.Fprintln(utils.OutputWriter(), lambda.FormatLogEvent(event))
+	}
+}
+
+// printExecutionLog prints the decoded execution log of a Lambda invocation to stderr,
+// keeping stdout free for the response
+func printExecutionLog(result *lambda.InvokeResult) {
+	log, err := lambda.DecodeLogResult(result)
+	if err != nil {
+		utils.PrintError(err)
+		return
+	}
+
+	if log == "" {
+		fmt.Fprintln(os.Stderr, "No execution log returned")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\n--- Execution log ---\n%s", log)
+	if !strings.HasSuffix(log, "\n") {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// setInstanceNames sets the Name of each instance from the tag nameTag. In table,
+// text and CSV output, instances without the tag are named by the start of their
+// ID so the column isn't blank; JSON and YAML keep the name empty for scripts.
+func setInstanceNames(instances []ec2.Instance, nameTag, format string) {
+	if nameTag == "" {
+		nameTag = ec2.DefaultNameTag
+	}
+	for i := range instances {
+		if utils.IsStructuredFormat(format) {
+			instances[i].Name = instances[i].Tags[nameTag]
+		} else {
+			instances[i].Name = instances[i].DisplayName(nameTag)
+		}
+	}
+}
+
+// limitProbe returns how many results to fetch for a --limit of limit: one more
+// than the limit, so that applyLimit can tell whether there are more results
+// than the limit, or 0 for no limit
+func limitProbe(limit int32) int32 {
+	if limit > 0 {
+		return limit + 1
+	}
+	return 0
+}
+
+// applyLimit cuts results fetched with limitProbe down to limit, and reports
+// whether any were cut. A list with exactly limit results isn't truncated.
+func applyLimit[T any](items []T, limit int32) ([]T, bool) {
+	if limit > 0 && len(items) > int(limit) {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// printLimitFooter tells the user on stderr that the results were truncated by --limit
+func printLimitFooter(truncated bool, limit int32) {
+	if truncated {
+		fmt.Fprintf(os.Stderr, "Showing first %d results (use --limit 0 for all)\n", limit)
+	}
+}
+
+// redactedSecretValue is shown in place of a secret value unless --reveal is passed
+const redactedSecretValue = "<redacted, use --reveal to show>"
+
+// getSecretForOutput reads the current version of a secret if reveal is true.
+// Otherwise the secret is only described, so its value is never fetched, and a
+// placeholder is shown in place of the value.
+func getSecretForOutput(ctx context.Context, adapter *secretsmanager.Adapter, name string, reveal bool) (*secretsmanager.SecretValue, error) {
+	if reveal {
+		return adapter.GetSecretValue(ctx, name)
+	}
+
+	secret, err := adapter.DescribeSecret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	secret.Value = redactedSecretValue
+
+	return secret, nil
+}
+
+// metricFetcher fetches the datapoints of a metric for a single resource
+type metricFetcher func(a *cloudwatch.Adapter, ctx context.Context, resource, metricName string, startTime, endTime time.Time, period time.Duration) ([]cloudwatch.Datapoint, error)
+
+// newMetricsCommand creates a metrics subcommand that prints the datapoints of a
+// metric, fetched with fetch, for the resource given as its argument
+func newMetricsCommand(use, short, long, defaultMetric string, fetch metricFetcher) *cobra.Command {
+	metricsCmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+			resource, err := applyRegionSuffix(args[0])
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Get flags
+			metricName, _ := cmd.Flags().GetString("metric")
+			period, _ := cmd.Flags().GetDuration("period")
+			since, _ := cmd.Flags().GetDuration("since")
+
+			// CloudWatch periods are whole minutes, and a request returns at most 1440 datapoints
+			if period < time.Minute || period%time.Minute != 0 {
+				utils.PrintError(fmt.Errorf("invalid --period: %s (must be a whole number of minutes)", period))
+				return
+			}
+			if since < period {
+				utils.PrintError(fmt.Errorf("invalid --since: %s (must be at least --period)", since))
+				return
+			}
+			if since/period > 1440 {
+				utils.PrintError(fmt.Errorf("--since %s with --period %s covers more than 1440 periods; use a longer period", since, period))
+				return
+			}
+
+			// Create CloudWatch adapter
+			adapter, err := cloudwatch.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create CloudWatch adapter: %w", err))
+				return
+			}
+
+			// Fetch the datapoints
+			endTime := time.Now()
+			datapoints, err := fetch(adapter, ctx, resource, metricName, endTime.Add(-since), endTime, period)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			// Show a sparkline for text output, the datapoints otherwise
+			if config.GetOutputFormat() == string(utils.FormatText) {
+				printMetricSparklines(metricName, resource, datapoints)
+				return
+			}
+			utils.PrintOutput(datapoints, config.GetOutputFormat())
+		},
+	}
+	metricsCmd.Flags().String("metric", defaultMetric, "Name of the metric")
+	metricsCmd.Flags().Duration("period", 5*time.Minute, "Length of each datapoint's period (whole minutes)")
+	metricsCmd.Flags().Duration("since", 3*time.Hour, "How far back to fetch datapoints")
+
+	return metricsCmd
+}
+
+// printMetricSparklines prints a sparkline, with the lowest, highest and latest
+// value, for each statistic of the datapoints
+func printMetricSparklines(metricName, resource string, datapoints []cloudwatch.Datapoint) {
+	if len(datapoints) == 0 {
+		fmt.Fprintf(utils.OutputWriter(), "No datapoints for %s of %s\n", metricName, resource)
+		return
+	}
+
+	fmt.Fprintf(utils.OutputWriter(), "%s of %s, %s to %s\n", metricName, resource,
+		datapoints[0].Timestamp.Local().Format("2006-01-02 15:04"),
+		datapoints[len(datapoints)-1].Timestamp.Local().Format("2006-01-02 15:04"))
+
+	statistics := []struct {
+		name  string
+		value func(cloudwatch.Datapoint) float64
+	}{
+		{"Average", func(d cloudwatch.Datapoint) float64 { return d.Average }},
+		{"Maximum", func(d cloudwatch.Datapoint) float64 { return d.Maximum }},
+		{"Sum", func(d cloudwatch.Datapoint) float64 { return d.Sum }},
+	}
+	for _, statistic := range statistics {
+		values := make([]float64, len(datapoints))
+		for i, d := range datapoints {
+			values[i] = statistic.value(d)
+		}
+		fmt.Fprintf(utils.OutputWriter(), "  %-8s %s  min %.2f  max %.2f  last %.2f\n", statistic.name, utils.Sparkline(values),
+			slices.Min(values), slices.Max(values), values[len(values)-1])
+	}
+}
+
+// warnDeprecatedRuntimes prints a warning to stderr for each function running
+// on a runtime that AWS has deprecated
+func warnDeprecatedRuntimes(functions []lambda.Function) {
+	for _, function := range functions {
+		if date, ok := lambda.RuntimeDeprecationDate(function.Runtime); ok && lambda.IsDeprecatedRuntime(function.Runtime) {
+			utils.PrintWarning(fmt.Sprintf("function %s uses runtime %s, deprecated since %s", function.Name, function.Runtime, date))
+		}
+	}
+}
+
+// reportBulkItem prints the outcome of one item of a bulk operation as it completes.
+// Nothing is printed for JSON and YAML output, which only get the final result.
+func reportBulkItem(err error, successFormat string, item string) {
+	if utils.IsStructuredFormat(config.GetOutputFormat()) {
+		return
+	}
+
+	if err != nil {
+		utils.PrintError(err)
+		return
+	}
+	fmt.Fprintf(utils.OutputWriter(), successFormat+"\n", item)
+}
+
+// printBulkResult prints the summary of a bulk operation and returns an error if
+// any item failed, so the command exits with a non-zero status. The summary is only
+// printed when more than one item was processed.
+func printBulkResult(cmd *cobra.Command, result *utils.BulkResult) error {
+	switch {
+	case utils.IsStructuredFormat(config.GetOutputFormat()):
+		utils.PrintOutput(result, config.GetOutputFormat())
+	default:
+		if result.Total() > 1 {
+			fmt.Fprintf(utils.OutputWriter(), "\n%s\n", result.Summary())
+		}
+	}
+
+	// Skip the usage and let main report the error only once
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	return result.Err()
+}
+
+// defaultWaitTimeout is how long a mutating command waits for its change to
+// complete, unless --wait-timeout is passed
+const defaultWaitTimeout = 10 * time.Minute
+
+// addWaitFlags adds the --wait, --no-wait and --wait-timeout flags to a mutating
+// command. Without either flag, the command waits if the wait setting is on.
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "Wait until the change is complete")
+	cmd.Flags().Bool("no-wait", false, "Return as soon as the change is requested, even if the wait setting is on")
+	cmd.Flags().Duration("wait-timeout", defaultWaitTimeout, "How long to wait for the change to complete")
+	cmd.MarkFlagsMutuallyExclusive("wait", "no-wait")
+}
+
+// shouldWait returns whether a command with the wait flags waits for its change
+// to complete
+func shouldWait(cmd *cobra.Command) bool {
+	if noWait, _ := cmd.Flags().GetBool("no-wait"); noWait {
+		return false
+	}
+	if wait, _ := cmd.Flags().GetBool("wait"); wait {
+		return true
+	}
+	return config.GetWait()
+}
+
+// waitForChange waits for the change of a command with wait, printing what it
+// waits for on stderr. The wait is limited by --wait-timeout rather than the
+// timeout of AWS operations, as changes such as starting an instance take longer.
+func waitForChange(cmd *cobra.Command, what string, wait func(ctx context.Context, interval time.Duration) error) error {
+	ctx, cancel := waitContext(cmd)
+	defer cancel()
+
+	return waitWithin(ctx, what, wait)
+}
+
+// waitContext returns the context a command with wait waits within, which
+// expires after --wait-timeout
+func waitContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// waitWithin waits for a change within ctx, printing what it waits for on stderr
+func waitWithin(ctx context.Context, what string, wait func(ctx context.Context, interval time.Duration) error) error {
+	utils.PrintNotice(fmt.Sprintf("Waiting for %s...", what))
+	if err := wait(ctx, client.DefaultWaitInterval); err != nil {
+		return fmt.Errorf("failed waiting for %s: %w", what, err)
+	}
+	return nil
+}
+
+// waitForBulk records the items of a bulk operation whose change was requested.
+// If the command waits, it waits for the change of each item in turn and records
+// whether it completed; whatFormat and doneFormat describe the change of an item
+// while and after waiting for it. --wait-timeout limits the wait for all the
+// items together, so items still waited for when it expires are recorded as
+// failed.
+func waitForBulk(cmd *cobra.Command, result *utils.BulkResult, items []string, whatFormat, doneFormat string, wait func(ctx context.Context, item string, interval time.Duration) error) {
+	if !shouldWait(cmd) {
+		for _, item := range items {
+			result.Record(item, nil)
+		}
+		return
+	}
+
+	ctx, cancel := waitContext(cmd)
+	defer cancel()
+
+	for _, item := range items {
+		err := waitWithin(ctx, fmt.Sprintf(whatFormat, item), func(ctx context.Context, interval time.Duration) error {
+			return wait(ctx, item, interval)
+		})
+		result.Record(item, err)
+		reportBulkItem(err, doneFormat, item)
+	}
+}
+
+// parseS3ObjectURL parses an S3 URL that must name an object, not just a bucket
+func parseS3ObjectURL(raw string) (string, string, error) {
+	bucketName, key, err := s3.ParseS3URL(raw)
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %q: missing object key", raw)
+	}
+	return bucketName, key, nil
+}
+
+// userShell returns the user's preferred shell, falling back to the platform default
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if comspec := os.Getenv("COMSPEC"); comspec != "" {
+		return comspec
+	}
+	return "/bin/sh"
+}
+
+// contextEnvKeys are the environment variables set for a context, in order
+var contextEnvKeys = []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWSM_CONTEXT"}
+
+// contextEnvValues returns the values of the environment variables set for the
+// given context, keyed by variable name
+func contextEnvValues(name string, ctx config.Context) map[string]string {
+	return map[string]string{
+		"AWS_PROFILE":        ctx.Profile,
+		"AWS_REGION":         ctx.Region,
+		"AWS_DEFAULT_REGION": ctx.Region,
+		"AWSM_CONTEXT":       name,
+	}
+}
+
+// contextShellEnv returns a copy of the given environment with the AWS variables
+// replaced by the ones for the given context
+func contextShellEnv(environ []string, name string, ctx config.Context) []string {
+	overrides := contextEnvValues(name, ctx)
+
+	env := make([]string, 0, len(environ)+len(overrides))
+	for _, entry := range environ {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, overridden := overrides[key]; !overridden {
+			env = append(env, entry)
+		}
+	}
+
+	for _, key := range contextEnvKeys {
+		env = append(env, fmt.Sprintf("%s=%s", key, overrides[key]))
+	}
+
+	return env
+}
+
+// contextMatrixRow is a row of the table printed by config matrix
+type contextMatrixRow struct {
+	Context     string `json:"context" yaml:"context"`
+	Profile     string `json:"profile" yaml:"profile"`
+	Region      string `json:"region" yaml:"region"`
+	Role        string `json:"role" yaml:"role"`
+	Account     string `json:"account" yaml:"account"`         // AWS account ID, if the credentials were checked
+	Credentials string `json:"credentials" yaml:"credentials"` // "valid", "not checked", or why they are invalid
+}
+
+// contextCredentialsCheck verifies the credentials of a context and returns the
+// identity they belong to
+type contextCredentialsCheck func(awsContext config.Context) (*client.Identity, error)
+
+// contextMatrix returns a row for each context, sorted by name. The credentials
+// are verified with check, unless it is nil.
+func contextMatrix(contexts map[string]config.Context, check contextCredentialsCheck) []contextMatrixRow {
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	rows := make([]contextMatrixRow, 0, len(names))
+	for _, name := range names {
+		awsContext := contexts[name]
+		row := contextMatrixRow{
+			Context:     name,
+			Profile:     awsContext.Profile,
+			Region:      awsContext.Region,
+			Role:        awsContext.Role,
+			Credentials: "not checked",
+		}
+
+		if check != nil {
+			identity, err := check(awsContext)
+			if err != nil {
+				row.Credentials = fmt.Sprintf("invalid: %v", err)
+			} else {
+				row.Account = identity.Account
+				row.Credentials = "valid"
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// contextMatrixMarkdown renders the rows of config matrix as a Markdown table
+func contextMatrixMarkdown(rows []contextMatrixRow) string {
+	// Pipes and newlines in a cell would break the table
+	escape := strings.NewReplacer("|", "\\|", "\n", " ")
+
+	var b strings.Builder
+	b.WriteString("| Context | Profile | Region | Role | Account | Credentials |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, row := range rows {
+		cells := []string{row.Context, row.Profile, row.Region, row.Role, row.Account, row.Credentials}
+		for i, cell := range cells {
+			cells[i] = escape.Replace(cell)
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return b.String()
+}
+
+// contextDiffServices are the services supported by context diff
+var contextDiffServices = []string{"ec2", "s3", "lambda"}
+
+// contextDiff is the result of comparing the resources of two contexts
+type contextDiff struct {
+	Service  string   `json:"service" yaml:"service"`
+	ContextA string   `json:"contextA" yaml:"contextA"`
+	ContextB string   `json:"contextB" yaml:"contextB"`
+	OnlyInA  []string `json:"onlyInA" yaml:"onlyInA"` // Resources that only exist in ContextA
+	OnlyInB  []string `json:"onlyInB" yaml:"onlyInB"` // Resources that only exist in ContextB
+	InBoth   []string `json:"inBoth" yaml:"inBoth"`   // Resources that exist in both contexts
+	Note     string   `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// s3DiffNote explains how to read a diff of S3 buckets, which unlike the other
+// services aren't regional resources
+const s3DiffNote = "S3 buckets are global: each context lists all buckets of its account, whatever its region, and bucket names are unique across accounts, so buckets are only in both contexts when they use the same account"
+
+// contextResourceNames returns the names of the resources of a service that the
+// client can see, used to match resources across contexts
+func contextResourceNames(ctx context.Context, awsClient *client.Client, service string) ([]string, error) {
+	var names []string
+	switch service {
+	case "ec2":
+		instances, err := ec2.NewAdapterWithClient(awsec2.NewFromConfig(awsClient.Config)).ListInstances(ctx, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			// Instance IDs differ between accounts, so match by name where there is one
+			name := instance.Name
+			if name == "" {
+				name = instance.ID
+			}
+			names = append(names, name)
+		}
+	case "s3":
+		buckets, err := s3.NewAdapterWithClient(awss3.NewFromConfig(awsClient.Config)).ListBuckets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range buckets {
+			names = append(names, bucket.Name)
+		}
+	case "lambda":
+		functions, err := lambda.NewAdapterWithClients(awslambda.NewFromConfig(awsClient.Config), cloudwatchlogs.NewFromConfig(awsClient.Config)).ListFunctions(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, function := range functions {
+			names = append(names, function.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported service %s", service)
+	}
+	return names, nil
+}
+
+// diffResourceNames compares two lists of resource names and returns the sorted
+// names that are only in a, only in b, and in both. Names are compared by count,
+// since EC2 instances can share a Name tag: with three instances named web in a
+// and one in b, web is once in InBoth and twice in OnlyInA.
+func diffResourceNames(a, b []string) contextDiff {
+	countA := make(map[string]int, len(a))
+	for _, name := range a {
+		countA[name]++
+	}
+	countB := make(map[string]int, len(b))
+	for _, name := range b {
+		countB[name]++
+	}
+
+	diff := contextDiff{OnlyInA: []string{}, OnlyInB: []string{}, InBoth: []string{}}
+	for name, n := range countA {
+		matched := min(n, countB[name])
+		for range matched {
+			diff.InBoth = append(diff.InBoth, name)
+		}
+		for range n - matched {
+			diff.OnlyInA = append(diff.OnlyInA, name)
+		}
+	}
+	for name, n := range countB {
+		for range n - min(n, countA[name]) {
+			diff.OnlyInB = append(diff.OnlyInB, name)
+		}
+	}
+
+	slices.Sort(diff.OnlyInA)
+	slices.Sort(diff.OnlyInB)
+	slices.Sort(diff.InBoth)
+	return diff
+}
+
+// contextFreeCommands are the top-level commands that don't call AWS, which run
+// even when requireContext is set and no context has been chosen
+var contextFreeCommands = map[string]bool{
+	"mode":       true,
+	"config":     true,
+	"context":    true,
+	"prompt":     true,
+	"shell-init": true,
+	"version":    true,
+	"help":       true,
+	"completion": true,
+}
+
+// checkContextChosen returns an error if requireContext is set and cmd would call
+// AWS with the default context, without a context or profile having been chosen
+func checkContextChosen(cmd *cobra.Command) error {
+	// Find the top-level command
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if !top.HasParent() || contextFreeCommands[top.Name()] {
+		return nil
+	}
+
+	return requireChosenContext(cmd)
+}
+
+// requireChosenContext returns an error if requireContext is set and the current
+// context is still the default one, unless cmd was given --context or --profile
+func requireChosenContext(cmd *cobra.Command) error {
+	if !config.GetRequireContext() || config.GetCurrentContext() != config.DefaultConfig.CurrentContext {
+		return nil
+	}
+
+	// A context or profile passed on the command line is an explicit choice
+	if cmd.Flags().Changed("context") || cmd.Flags().Changed("profile") {
+		return nil
+	}
+
+	return fmt.Errorf("no context selected: requireContext is set, so choose one with 'awsm context use <name>' or pass --context")
+}
+
+// printOperationBanner prints the account, context and region a mutating command
+// is about to change, and the identity it acts as, so the blast radius of the
+// command is always visible. It is printed to stderr to keep it out of the output.
+func printOperationBanner(ctx context.Context) {
+	awsClient, err := client.NewClient(ctx)
+	var identity *client.Identity
+	if err == nil {
+		identity, err = awsClient.GetIdentity(ctx)
+	}
+	if err != nil {
+		logger.Debug("Failed to look up the caller identity: %v", err)
+	}
+
+	utils.PrintNotice(operationBanner(identity, config.GetCurrentContext(), config.GetAWSRegion()))
+}
+
+// operationBanner returns the banner printed by printOperationBanner. identity is
+// nil when it could not be looked up.
+func operationBanner(identity *client.Identity, contextName, region string) string {
+	if identity == nil {
+		return fmt.Sprintf("Operating on unknown account (%s/%s)", contextName, region)
+	}
+	return fmt.Sprintf("Operating on account %s (%s/%s) as %s", identity.Account, contextName, region, identity.ARN)
+}
+
+// confirmDestructiveAction asks the user to confirm a destructive operation on the named
+// resource by typing its name. The prompt is skipped when --yes is passed or stdin is not
+// a terminal.
+//
+// Returns true if the operation should proceed.
+func confirmDestructiveAction(cmd *cobra.Command, name string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+
+	if !utils.IsInteractive() {
+		return true
+	}
+
+	return utils.ConfirmDestructive(name)
+}
+
+// newTUICommand creates a direct command to launch the TUI
+func newTUICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Launch the Terminal User Interface",
+		Long: `Launch the Terminal User Interface (TUI) for interactive AWS management.
+
+By default the TUI uses the terminal's alternate screen. With --inline (or the
+app.altScreen setting set to false) it runs inline instead, so its final view
+remains in the scrollback after quitting.
+
+With --accessible the TUI uses only high-contrast colors, avoids dim text, and
+marks the selected row with a ">" marker so it doesn't rely on color alone.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			inline, _ := cmd.Flags().GetBool("inline")
+			tui.SetInline(inline)
+			accessible, _ := cmd.Flags().GetBool("accessible")
+			tui.SetAccessible(accessible)
+
+			if err := launchTUI(cmd); err != nil {
+				utils.PrintError(err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().Bool("inline", false, "Run without the alternate screen so the final view stays in the scrollback")
+	cmd.Flags().Bool("accessible", false, "Use high-contrast colors and mark the selected row without relying on color")
+
+	return cmd
+}
+
+// newCheckPermissionsCommand creates the check-permissions command
+func newCheckPermissionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-permissions [service]",
+		Short: "Check which awsm commands the current identity can use",
+		Long: `Check which API actions used by the awsm commands of a service the current
+identity is allowed to call, to find out before running a command whether it
+will fail with an authorization error.
+
+Each action is called as a dry run, so nothing is changed. Actions on an
+instance (start, stop, reboot, rename) are checked against the instance given
+with --instance, or the first instance found, since IAM policies can allow them
+for some instances only.
+
+Supported services: ec2`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"ec2"},
+		Run: func(cmd *cobra.Command, args []string) {
+			if args[0] != "ec2" {
+				utils.PrintError(fmt.Errorf("checking permissions is not supported for %s (supported: ec2)", args[0]))
+				return
+			}
+			instanceID, _ := cmd.Flags().GetString("instance")
+
+			ctx, cancel := client.WithTimeout(context.Background())
+			defer cancel()
+
+			// Create EC2 adapter
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				utils.PrintError(fmt.Errorf("failed to create EC2 adapter: %w", err))
+				return
+			}
+
+			// Check the permissions
+			checks := adapter.CheckPermissions(ctx, instanceID)
+
+			// Format and print the output
+			utils.PrintOutput(checks, config.GetOutputFormat())
+		},
+	}
+	cmd.Flags().String("instance", "", "Instance to check instance actions against (default: the first instance found)")
+
+	return cmd
+}
+
+// newPromptCommand creates the prompt command for embedding the active context in a shell prompt
+func newPromptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print the active context for a shell prompt",
+		Long: `Print a compact string describing the active context (e.g. prod:us-east-1),
+suitable for embedding in a shell prompt such as PS1:
+
+  PS1='[$(awsm prompt)] \$ '`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintln(utils.OutputWriter(), config.PromptString())
+		},
+	}
+
+	return cmd
+}
+
+// shellInitShells are the shells shell-init generates a snippet for
+var shellInitShells = []string{"bash", "zsh"}
+
+// newShellInitCommand creates the shell-init command
+func newShellInitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell-init [bash|zsh]",
+		Short: "Print a snippet that sets up awsm in a shell",
+		Long: `Print a snippet to source in the shell's startup file, which sets up command
+completion and exports AWS_PROFILE, AWS_REGION, AWS_DEFAULT_REGION and
+AWSM_CONTEXT for the current context, so that every new shell uses it:
+
+  eval "$(awsm shell-init bash)"    # in ~/.bashrc
+  eval "$(awsm shell-init zsh)"     # in ~/.zshrc
+
+With --use, the snippet also defines an awsm_use function that switches the
+awsm context and exports its variables in the current shell in one step:
+
+  awsm_use prod
+
+With --env-only, only the exports are printed.`,
+		ValidArgs: shellInitShells,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			withUse, _ := cmd.Flags().GetBool("use")
+			envOnly, _ := cmd.Flags().GetBool("env-only")
+
+			contextName := config.GetCurrentContext()
+			ctx, exists := config.GetContexts()[contextName]
+			if !exists {
+				utils.PrintError(fmt.Errorf("context %s does not exist", contextName))
+				return
+			}
+
+			script, err := shellInitScript(cmd.Root(), args[0], contextName, ctx, withUse, envOnly)
+			if err != nil {
+				utils.PrintError(err)
+				return
+			}
+
+			fmt.Fprint(utils.OutputWriter(), script)
+		},
+	}
+	cmd.Flags().Bool("use", false, "Also define an awsm_use function that switches the context and exports its variables")
+	cmd.Flags().Bool("env-only", false, "Print only the exports for the current context")
+
+	return cmd
+}
+
+// shellInitScript returns the snippet printed by shell-init for the given shell:
+// the completion script of root, the exports for the given context and, if
+// withUse is set, the awsm_use function. With envOnly, only the exports are
+// included.
+//
+// Returns an error if the shell is not supported.
+func shellInitScript(root *cobra.Command, shell, contextName string, ctx config.Context, withUse, envOnly bool) (string, error) {
+	if !slices.Contains(shellInitShells, shell) {
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(shellInitShells, ", "))
+	}
+
+	var b strings.Builder
+
+	// Export the variables of the context, leaving out unset ones such as the
+	// profile of a context using the default credentials
+	exports := func() {
+		// The context name is only written quoted in AWSM_CONTEXT: in a comment, a
+		// newline in it would end the comment and run the rest as a command
+		values := contextEnvValues(contextName, ctx)
+		b.WriteString("# Environment of the current awsm context\n")
+		for _, key := range contextEnvKeys {
+			if values[key] != "" {
+				fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(values[key]))
+			}
+		}
+	}
+	if envOnly {
+		exports()
+		return b.String(), nil
+	}
+
+	fmt.Fprintf(&b, "# awsm shell integration, generated by: awsm shell-init %s\n", shell)
+	fmt.Fprintf(&b, "# Load it in new shells with: eval \"$(awsm shell-init %s)\"\n\n", shell)
+
+	b.WriteString("# Command completion\n")
+	var err error
+	if shell == "zsh" {
+		err = root.GenZshCompletion(&b)
+	} else {
+		err = root.GenBashCompletionV2(&b, true)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	b.WriteString("\n")
+
+	exports()
+
+	if withUse {
+		fmt.Fprintf(&b, `
+# awsm_use <context> switches the awsm context and exports its environment
+awsm_use() {
+    if [ "$#" -ne 1 ]; then
+        echo "usage: awsm_use <context>" >&2
+        return 2
+    fi
+    command awsm context use "$1" && eval "$(command awsm shell-init %s --env-only)"
+}
+`, shell)
+	}
+
+	return b.String(), nil
+}
+
+// shellQuote quotes s for a POSIX shell, in single quotes
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// versionInfo holds the version information printed by the version command
+type versionInfo struct {
+	Version    string `json:"version" yaml:"version"`
+	BuildTime  string `json:"buildTime" yaml:"buildTime"`
+	CommitHash string `json:"commitHash" yaml:"commitHash"`
+	GoVersion  string `json:"goVersion" yaml:"goVersion"`
+	Platform   string `json:"platform" yaml:"platform"`
+}
+
+// newVersionCommand creates the version command
+func newVersionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version information",
+		Long:  `Show the awsm version, build time, and commit hash.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			info := versionInfo{
+				Version:    Version,
+				BuildTime:  BuildTime,
+				CommitHash: CommitHash,
+				GoVersion:  runtime.Version(),
+				Platform:   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+			}
+
+			// The configuration is not loaded for this command, so only the --output flag applies
+			switch {
+			case utils.IsStructuredFormat(outputFormat):
+				utils.PrintOutput(info, outputFormat)
+			default:
+				fmt.Fprintf(utils.OutputWriter(), "awsm %s (built: %s, commit: %s, %s, %s)\n",
+					info.Version, info.BuildTime, info.CommitHash, info.GoVersion, info.Platform)
 			}
 		},
 	}
@@ -3,12 +3,26 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"testing"
-
+	"time"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/ao/awsm/internal/aws/secretsmanager"
+	"github.com/ao/awsm/internal/config"
+	"github.com/ao/awsm/internal/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssecretsmanager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // executeCommand is a helper function that executes a Cobra command with the given arguments
@@ -134,6 +148,36 @@ func TestRootCommand(t *testing.T) {
 	assert.Contains(t, output, "Root command executed")
 }
 
+// TestWelcomeText tests the status shown by awsm without a command.
+// It verifies that the contexts are listed with the current one marked, that
+// new users are told how to create contexts, and that JSON and YAML output
+// keep the settings at the top level next to the contexts.
+func TestWelcomeText(t *testing.T) {
+	status := rootStatus{
+		Version:  "1.2.3",
+		Settings: config.Settings{Context: "prod", Profile: "prod", Region: "eu-west-1", Output: "table"},
+		Contexts: []string{"dev", "prod"},
+	}
+
+	text := welcomeText(status)
+	assert.Contains(t, text, "Version: 1.2.3")
+	assert.Contains(t, text, "  AWS Region: eu-west-1")
+	assert.Contains(t, text, "\nContexts:\n  dev\n* prod (current)\n")
+	assert.NotContains(t, text, "Local Config")
+
+	assert.Contains(t, welcomeText(rootStatus{}), "awsm context import")
+
+	output, err := utils.FormatOutput(status, "json")
+	assert.NoError(t, err)
+	assert.Contains(t, output, `"region": "eu-west-1"`)
+	assert.Contains(t, output, `"contexts": [`)
+
+	output, err = utils.FormatOutput(status, "yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "\nregion: eu-west-1\n")
+	assert.Contains(t, output, "contexts:\n")
+}
+
 // TestEC2Command tests the EC2 command of the CLI.
 // It verifies that the command executes without errors and produces the expected output.
 func TestEC2Command(t *testing.T) {
@@ -416,3 +460,679 @@ func TestModeCommand(t *testing.T) {
 	// Assert output
 	assert.Contains(t, output, "Mode command executed")
 }
+
+// TestContextSubcommandFlags tests that the context subcommands register their own flags.
+// It verifies that flags are attached to the intended subcommands rather than to
+// whichever subcommand happens to be at a given position.
+func TestContextSubcommandFlags(t *testing.T) {
+	// Create a new context command for testing
+	cmd := newContextCommand()
+
+	// Check the flags of each subcommand
+	testCases := []struct {
+		subcommand string
+		flags      []string
+	}{
+		{subcommand: "create", flags: []string{"profile", "region", "role"}},
+		{subcommand: "delete", flags: []string{"yes"}},
+		{subcommand: "export", flags: []string{"overwrite"}},
+	}
+
+	for _, tc := range testCases {
+		subCmd, _, err := cmd.Find([]string{tc.subcommand})
+		assert.NoError(t, err)
+
+		for _, flag := range tc.flags {
+			assert.NotNil(t, subCmd.Flags().Lookup(flag), "flag --%s not found on %s", flag, tc.subcommand)
+		}
+	}
+}
+
+// TestVersionCommand tests the version command of the CLI.
+// It verifies that the version information can be printed as JSON.
+func TestVersionCommand(t *testing.T) {
+	// Save the original output format
+	originalOutputFormat := outputFormat
+	defer func() {
+		outputFormat = originalOutputFormat
+	}()
+	outputFormat = "json"
+
+	// Capture the output of the command
+	output := captureOutput(func() {
+		_, err := executeCommand(newVersionCommand())
+		assert.NoError(t, err)
+	})
+
+	// Assert output
+	var info map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(output), &info))
+	assert.Equal(t, Version, info["version"])
+	assert.Contains(t, info, "commitHash")
+}
+
+// TestCloseOutputFile tests closing the file opened for --output-file.
+// It verifies that lines printed by a command go to the file, and that the file
+// is closed and output restored to stdout even when the command fails.
+func TestCloseOutputFile(t *testing.T) {
+	path := t.TempDir() + "/out.txt"
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	outputFileHandle = f
+	utils.SetOutputWriter(f)
+
+	// A command printing its own lines and then failing
+	cmd := &cobra.Command{
+		Use: "fail",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintln(utils.OutputWriter(), "partial result")
+			return errors.New("failed")
+		},
+	}
+	_, err = executeCommand(cmd)
+	assert.Error(t, err)
+
+	// Close the file as main does after every command
+	assert.NoError(t, closeOutputFile())
+	assert.Nil(t, outputFileHandle)
+	assert.Equal(t, os.Stdout, utils.OutputWriter())
+
+	// The output written before the failure is in the file
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "partial result\n", string(content))
+
+	// The file is closed, and closing again is a no-op
+	_, err = f.WriteString("more")
+	assert.Error(t, err)
+	assert.NoError(t, closeOutputFile())
+}
+
+// TestContextShellEnv tests the environment passed to context subshells.
+// It verifies that the AWS variables are replaced by the ones of the context
+// while the rest of the environment is preserved.
+func TestContextShellEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/home/test",
+		"AWS_PROFILE=old-profile",
+		"AWS_REGION=eu-west-1",
+		"PATH=/usr/bin",
+	}
+
+	env := contextShellEnv(environ, "prod", config.Context{Profile: "production", Region: "us-east-1"})
+
+	assert.ElementsMatch(t, []string{
+		"HOME=/home/test",
+		"PATH=/usr/bin",
+		"AWS_PROFILE=production",
+		"AWS_REGION=us-east-1",
+		"AWS_DEFAULT_REGION=us-east-1",
+		"AWSM_CONTEXT=prod",
+	}, env)
+}
+
+// TestShellInitScript tests the snippet printed by shell-init.
+// It verifies that it sets up completion and exports the context's variables,
+// quoting them for the shell and keeping the context name out of comments,
+// that awsm_use is only defined with --use, and
+// that --env-only prints only the exports.
+func TestShellInitScript(t *testing.T) {
+	ctx := config.Context{Profile: "o'brien", Region: "eu-west-1"}
+
+	// Call the function
+	script, err := shellInitScript(rootCmd, "bash", "prod", ctx, false, false)
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Contains(t, script, "complete -o default -F __start_awsm awsm")
+	assert.Contains(t, script, "export AWS_PROFILE='o'\\''brien'\n")
+	assert.Contains(t, script, "export AWS_REGION='eu-west-1'\n")
+	assert.Contains(t, script, "export AWSM_CONTEXT='prod'\n")
+	assert.NotContains(t, script, "awsm_use")
+
+	// --use defines awsm_use
+	script, err = shellInitScript(rootCmd, "zsh", "prod", ctx, true, false)
+	assert.NoError(t, err)
+	assert.Contains(t, script, "compdef _awsm awsm")
+	assert.Contains(t, script, "awsm_use() {")
+	assert.Contains(t, script, "shell-init zsh --env-only")
+
+	// --env-only prints only the exports, leaving out unset variables
+	script, err = shellInitScript(rootCmd, "bash", "default", config.Context{Region: "us-east-1"}, true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Environment of the current awsm context\n"+
+		"export AWS_REGION='us-east-1'\n"+
+		"export AWS_DEFAULT_REGION='us-east-1'\n"+
+		"export AWSM_CONTEXT='default'\n", script)
+
+	// A context name with a newline stays inside the quoted value
+	script, err = shellInitScript(rootCmd, "bash", "x\nrm -rf ~", config.Context{}, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Environment of the current awsm context\n"+
+		"export AWSM_CONTEXT='x\nrm -rf ~'\n", script)
+
+	// Unsupported shells
+	_, err = shellInitScript(rootCmd, "fish", "prod", ctx, false, false)
+	assert.Error(t, err)
+}
+
+// TestApplyOutputFormat tests the --output flag.
+// It verifies that the format overrides the output format of the current
+// context for the invocation without being saved to the configuration file,
+// and that invalid formats are rejected.
+func TestApplyOutputFormat(t *testing.T) {
+	// Use a temporary home directory with a default configuration file
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	originalDisableCache := homedir.DisableCache
+	homedir.DisableCache = true
+	originalConfig := config.GlobalConfig
+	t.Cleanup(func() {
+		homedir.DisableCache = originalDisableCache
+		config.GlobalConfig = originalConfig
+	})
+	assert.NoError(t, config.Initialize())
+
+	// Make a context with its own output format current
+	assert.NoError(t, config.CreateContext("prod", "default", "us-east-1", ""))
+	assert.NoError(t, config.SetContextOutputFormat("prod", "yaml"))
+	assert.NoError(t, config.SetCurrentContext("prod"))
+	assert.Equal(t, "yaml", config.GetOutputFormat())
+	saved, err := os.ReadFile(config.GetConfigPath(home))
+	assert.NoError(t, err)
+
+	// --output wins over the context's format and is not saved
+	assert.NoError(t, applyOutputFormat("json"))
+	assert.Equal(t, "json", config.GetOutputFormat())
+	current, err := os.ReadFile(config.GetConfigPath(home))
+	assert.NoError(t, err)
+	assert.Equal(t, string(saved), string(current))
+
+	// Invalid formats
+	assert.Error(t, applyOutputFormat("xml"))
+}
+
+// TestApplyLogFormat tests the --format alias of --output for lambda logs.
+// It verifies that --format sets the output format of the invocation, that it
+// may repeat --output but not contradict it, and that invalid formats are
+// rejected.
+func TestApplyLogFormat(t *testing.T) {
+	original := config.GlobalConfig.Output.Format
+	t.Cleanup(func() { config.GlobalConfig.Output.Format = original })
+
+	// newCmd creates a command with the output flags, parsed from args
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("output", "", "")
+		cmd.Flags().String("format", "", "")
+		assert.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	// Without --format the output format is left alone
+	config.GlobalConfig.Output.Format = "table"
+	assert.NoError(t, applyLogFormat(newCmd()))
+	assert.Equal(t, "table", config.GlobalConfig.Output.Format)
+
+	// --format sets it
+	assert.NoError(t, applyLogFormat(newCmd("--format", "json")))
+	assert.Equal(t, "json", config.GlobalConfig.Output.Format)
+
+	// Together with --output, both must agree
+	assert.NoError(t, applyLogFormat(newCmd("--format", "text", "--output", "text")))
+	assert.Error(t, applyLogFormat(newCmd("--format", "json", "--output", "yaml")))
+
+	// Invalid formats
+	assert.Error(t, applyLogFormat(newCmd("--format", "xml")))
+}
+
+// TestLogTimeWindow tests the time window computed from the lambda logs flags.
+// It verifies relative and absolute windows, and that invalid values are rejected.
+func TestLogTimeWindow(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	// No flags means an open window
+	start, end, err := logTimeWindow("", "", "", now)
+	assert.NoError(t, err)
+	assert.True(t, start.IsZero())
+	assert.True(t, end.IsZero())
+
+	// --since is relative to now
+	start, end, err = logTimeWindow("2h", "", "", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), start)
+	assert.True(t, end.IsZero())
+
+	// --start and --end are absolute
+	start, end, err = logTimeWindow("", "2024-01-01T10:00:00Z", "2024-01-01T11:30:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1704103200000), start.UnixMilli())
+	assert.Equal(t, int64(1704108600000), end.UnixMilli())
+
+	// Dates without a time zone are local
+	start, _, err = logTimeWindow("", "2024-01-01", "", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), start)
+
+	// Invalid values
+	for _, tc := range [][3]string{
+		{"yesterday", "", ""},
+		{"-1h", "", ""},
+		{"", "01/02/2024", ""},
+		{"", "2024-01-02T00:00:00Z", "2024-01-01T00:00:00Z"},
+	} {
+		_, _, err := logTimeWindow(tc[0], tc[1], tc[2], now)
+		assert.Error(t, err, tc)
+	}
+}
+
+// mockSecretsManagerClient implements the secretsmanager.SecretsManagerClient interface
+// for testing the secrets commands without calling AWS.
+type mockSecretsManagerClient struct {
+	mock.Mock
+}
+
+func (m *mockSecretsManagerClient) ListSecrets(ctx context.Context, params *awssecretsmanager.ListSecretsInput, optFns ...func(*awssecretsmanager.Options)) (*awssecretsmanager.ListSecretsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awssecretsmanager.ListSecretsOutput), args.Error(1)
+}
+
+func (m *mockSecretsManagerClient) GetSecretValue(ctx context.Context, params *awssecretsmanager.GetSecretValueInput, optFns ...func(*awssecretsmanager.Options)) (*awssecretsmanager.GetSecretValueOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awssecretsmanager.GetSecretValueOutput), args.Error(1)
+}
+
+func (m *mockSecretsManagerClient) DescribeSecret(ctx context.Context, params *awssecretsmanager.DescribeSecretInput, optFns ...func(*awssecretsmanager.Options)) (*awssecretsmanager.DescribeSecretOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awssecretsmanager.DescribeSecretOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that mockSecretsManagerClient implements the interface.
+var _ secretsmanager.SecretsManagerClient = (*mockSecretsManagerClient)(nil)
+
+// TestGetSecretForOutput tests how secrets get prints a secret.
+// It verifies that without --reveal the secret is only described and its value
+// replaced with a placeholder, while the metadata is kept.
+func TestGetSecretForOutput(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSecretsManagerClient)
+	mockClient.On("DescribeSecret", mock.Anything, mock.Anything, mock.Anything).Return(&awssecretsmanager.DescribeSecretOutput{
+		Name:               aws.String("prod/db"),
+		VersionIdsToStages: map[string][]string{"v-1": {"AWSCURRENT"}},
+	}, nil)
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(&awssecretsmanager.GetSecretValueOutput{
+		Name:         aws.String("prod/db"),
+		VersionId:    aws.String("v-1"),
+		SecretString: aws.String("hunter2"),
+	}, nil)
+
+	// Create adapter with mock client
+	adapter := secretsmanager.NewAdapterWithClient(mockClient)
+	ctx := context.Background()
+
+	// Without --reveal the value is redacted
+	secret, err := getSecretForOutput(ctx, adapter, "prod/db", false)
+	assert.NoError(t, err)
+	assert.Equal(t, redactedSecretValue, secret.Value)
+	assert.Equal(t, "prod/db", secret.Name)
+	assert.Equal(t, "v-1", secret.VersionID)
+	mockClient.AssertNotCalled(t, "GetSecretValue", mock.Anything, mock.Anything, mock.Anything)
+
+	// The redacted value doesn't show up in any output format
+	data, err := json.Marshal(secret)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	// With --reveal the value is shown
+	secret, err = getSecretForOutput(ctx, adapter, "prod/db", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", secret.Value)
+}
+
+// TestParseRegionSuffix tests parsing of name@region resource references.
+// It verifies that the suffix is optional and that invalid regions are rejected.
+func TestParseRegionSuffix(t *testing.T) {
+	name, region, err := parseRegionSuffix("my-function")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-function", name)
+	assert.Empty(t, region)
+
+	name, region, err = parseRegionSuffix("my-function@us-west-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-function", name)
+	assert.Equal(t, "us-west-2", region)
+
+	for _, ref := range []string{"@us-west-2", "my-function@", "my-function@mars-1", "my-function@us-west-2@eu-west-1"} {
+		_, _, err := parseRegionSuffix(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+// TestApplyRegionSuffix tests the applyRegionSuffix function.
+// It verifies that the region is only overridden when the reference has a suffix.
+func TestApplyRegionSuffix(t *testing.T) {
+	original := config.GlobalConfig.AWS.Region
+	t.Cleanup(func() { config.GlobalConfig.AWS.Region = original })
+	config.GlobalConfig.AWS.Region = "us-east-1"
+
+	// Without a suffix the region is unchanged
+	name, err := applyRegionSuffix("my-function")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-function", name)
+	assert.Equal(t, "us-east-1", config.GetAWSRegion())
+
+	// With a suffix the region is overridden in memory
+	name, err = applyRegionSuffix("my-function@eu-central-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-function", name)
+	assert.Equal(t, "eu-central-1", config.GetAWSRegion())
+}
+
+// TestApplyRegionSuffixes tests the applyRegionSuffixes function.
+// It verifies that references may name the region once or repeat it, and that
+// references naming different regions are rejected.
+func TestApplyRegionSuffixes(t *testing.T) {
+	original := config.GlobalConfig.AWS.Region
+	t.Cleanup(func() { config.GlobalConfig.AWS.Region = original })
+	config.GlobalConfig.AWS.Region = "us-east-1"
+
+	// Without suffixes the region is unchanged
+	names, err := applyRegionSuffixes([]string{"ingest", "load"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ingest", "load"}, names)
+	assert.Equal(t, "us-east-1", config.GetAWSRegion())
+
+	// A suffix on any reference applies to all of them
+	names, err = applyRegionSuffixes([]string{"ingest", "load@eu-central-1", "report@eu-central-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ingest", "load", "report"}, names)
+	assert.Equal(t, "eu-central-1", config.GetAWSRegion())
+
+	// Different regions are rejected
+	config.GlobalConfig.AWS.Region = "us-east-1"
+	_, err = applyRegionSuffixes([]string{"ingest@eu-west-1", "load@eu-central-1"})
+	assert.Error(t, err)
+	assert.Equal(t, "us-east-1", config.GetAWSRegion())
+}
+
+// TestCheckContextChosen tests the requireContext check run before each command.
+// It verifies that commands calling AWS are refused with the default context,
+// unless a context or profile is passed, or the command doesn't call AWS, and
+// that launching the TUI is refused even from a command exempt from the check.
+func TestCheckContextChosen(t *testing.T) {
+	original := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = original })
+	config.GlobalConfig.CurrentContext = "default"
+
+	root := &cobra.Command{Use: "awsm"}
+	root.PersistentFlags().String("context", "", "")
+	root.PersistentFlags().String("profile", "", "")
+	ec2Cmd := &cobra.Command{Use: "ec2"}
+	listCmd := &cobra.Command{Use: "list", Run: func(cmd *cobra.Command, args []string) {}}
+	ec2Cmd.AddCommand(listCmd)
+	contextCmd := &cobra.Command{Use: "context"}
+	useCmd := &cobra.Command{Use: "use", Run: func(cmd *cobra.Command, args []string) {}}
+	contextCmd.AddCommand(useCmd)
+	modeCmd := &cobra.Command{Use: "mode", Run: func(cmd *cobra.Command, args []string) {}}
+	root.AddCommand(ec2Cmd, contextCmd, modeCmd)
+
+	// Without requireContext every command runs
+	config.GlobalConfig.RequireContext = false
+	assert.NoError(t, checkContextChosen(listCmd))
+
+	// With requireContext commands calling AWS are refused
+	config.GlobalConfig.RequireContext = true
+	assert.Error(t, checkContextChosen(listCmd))
+
+	// Commands managing contexts still run
+	assert.NoError(t, checkContextChosen(useCmd))
+	assert.NoError(t, checkContextChosen(root))
+	assert.NoError(t, checkContextChosen(modeCmd))
+
+	// The TUI calls AWS, so launching it is refused, also from the mode command
+	err := launchTUI(modeCmd)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no context selected")
+
+	// A profile passed on the command line is a choice
+	assert.NoError(t, listCmd.ParseFlags([]string{"--profile", "dev"}))
+	assert.NoError(t, checkContextChosen(listCmd))
+
+	// So is a context other than the default
+	config.GlobalConfig.CurrentContext = "prod"
+	listCmd.Flags().Lookup("profile").Changed = false
+	assert.NoError(t, checkContextChosen(listCmd))
+}
+
+// TestOperationBanner tests the banner printed before mutating commands.
+// It verifies that the account, context, region and identity are shown, and
+// that the account is reported as unknown when it could not be looked up.
+func TestOperationBanner(t *testing.T) {
+	identity := &client.Identity{
+		Account: "123456789012",
+		ARN:     "arn:aws:sts::123456789012:assumed-role/admin/jane",
+	}
+
+	assert.Equal(t,
+		"Operating on account 123456789012 (prod/eu-west-1) as arn:aws:sts::123456789012:assumed-role/admin/jane",
+		operationBanner(identity, "prod", "eu-west-1"))
+	assert.Equal(t, "Operating on unknown account (prod/eu-west-1)", operationBanner(nil, "prod", "eu-west-1"))
+}
+
+// TestSetInstanceNames tests naming the instances listed by ec2 list.
+// It verifies that the name comes from the chosen tag, and that instances
+// without it are named by their ID in table output only.
+func TestSetInstanceNames(t *testing.T) {
+	newInstances := func() []ec2.Instance {
+		return []ec2.Instance{
+			{ID: "i-0123456789abcdef0", Name: "web-1", Tags: map[string]string{"Name": "web-1", "app": "checkout"}},
+			{ID: "i-0fedcba9876543210", Tags: map[string]string{}},
+		}
+	}
+
+	// Table output falls back to the instance ID
+	instances := newInstances()
+	setInstanceNames(instances, "Name", "table")
+	assert.Equal(t, "web-1", instances[0].Name)
+	assert.Equal(t, "i-0fedcba9…", instances[1].Name)
+
+	// Another tag can be used as the name
+	instances = newInstances()
+	setInstanceNames(instances, "app", "table")
+	assert.Equal(t, "checkout", instances[0].Name)
+
+	// JSON output keeps missing names empty
+	instances = newInstances()
+	setInstanceNames(instances, "app", "json")
+	assert.Equal(t, "checkout", instances[0].Name)
+	assert.Equal(t, "", instances[1].Name)
+}
+
+// TestApplyLimit tests cutting results fetched with limitProbe down to --limit.
+// It verifies that results are only truncated when more than limit were found,
+// so a list with exactly limit results is complete, and that 0 means no limit.
+func TestApplyLimit(t *testing.T) {
+	assert.Equal(t, int32(4), limitProbe(3))
+	assert.Equal(t, int32(0), limitProbe(0))
+
+	testCases := []struct {
+		name      string
+		items     []string
+		limit     int32
+		expected  []string
+		truncated bool
+	}{
+		{"more than the limit", []string{"a", "b", "c", "d"}, 3, []string{"a", "b", "c"}, true},
+		{"count equals the limit", []string{"a", "b", "c"}, 3, []string{"a", "b", "c"}, false},
+		{"fewer than the limit", []string{"a"}, 3, []string{"a"}, false},
+		{"no limit", []string{"a", "b", "c", "d"}, 0, []string{"a", "b", "c", "d"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			items, truncated := applyLimit(tc.items, tc.limit)
+			assert.Equal(t, tc.expected, items)
+			assert.Equal(t, tc.truncated, truncated)
+		})
+	}
+}
+
+// TestCoverageOf tests picking the coverage rows of the instances --filter matched.
+// It verifies that only their rows are kept, in the order of the coverage, with
+// the coverage worked out over all running instances.
+func TestCoverageOf(t *testing.T) {
+	reservations := []ec2.Reservation{{ID: "ri-1", InstanceType: "t3.micro", Count: 1}}
+	instances := []ec2.Instance{
+		{ID: "i-1", Type: "t3.micro"},
+		{ID: "i-2", Type: "t3.micro"},
+		{ID: "i-3", Type: "m5.large"},
+	}
+	coverage := ec2.Coverage(instances, reservations)
+
+	// Call the function
+	rows := coverageOf(coverage, []ec2.Instance{instances[2], instances[0]})
+
+	// Assert the rows of the matched instances are kept with their coverage
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "i-1", rows[0].ID)
+	assert.True(t, rows[0].Covered)
+	assert.Equal(t, "i-3", rows[1].ID)
+	assert.False(t, rows[1].Covered)
+
+	// No matched instances keep no rows
+	assert.Empty(t, coverageOf(coverage, nil))
+}
+
+// TestDiffResourceNames tests comparing the resources of two contexts.
+// It verifies that names are split into the ones only in either context and
+// the ones in both, sorted, and that names shared by several resources are
+// compared by count rather than as a set.
+func TestDiffResourceNames(t *testing.T) {
+	diff := diffResourceNames(
+		[]string{"web", "api", "worker", "web", "web"},
+		[]string{"api", "db", "web", "db"},
+	)
+
+	assert.Equal(t, []string{"web", "web", "worker"}, diff.OnlyInA)
+	assert.Equal(t, []string{"db", "db"}, diff.OnlyInB)
+	assert.Equal(t, []string{"api", "web"}, diff.InBoth)
+
+	// The same names with different counts
+	diff = diffResourceNames([]string{"web"}, []string{"web", "web"})
+	assert.Empty(t, diff.OnlyInA)
+	assert.Equal(t, []string{"web"}, diff.OnlyInB)
+	assert.Equal(t, []string{"web"}, diff.InBoth)
+
+	// Nothing in common
+	diff = diffResourceNames(nil, []string{"api"})
+	assert.Empty(t, diff.OnlyInA)
+	assert.Equal(t, []string{"api"}, diff.OnlyInB)
+	assert.Empty(t, diff.InBoth)
+}
+
+// TestContextMatrix tests the table printed by config matrix.
+// It verifies that contexts are sorted by name, that the account and the
+// result of the credentials check are shown, and that the Markdown table
+// escapes pipes in cells.
+func TestContextMatrix(t *testing.T) {
+	contexts := map[string]config.Context{
+		"prod":    {Profile: "prod", Region: "us-east-1", Role: "arn:aws:iam::222222222222:role/admin"},
+		"staging": {Profile: "staging", Region: "eu-west-1"},
+		"dev":     {Profile: "dev", Region: "us-west-2"},
+	}
+
+	// Call the function
+	rows := contextMatrix(contexts, func(awsContext config.Context) (*client.Identity, error) {
+		if awsContext.Profile == "staging" {
+			return nil, errors.New("ExpiredToken | session expired")
+		}
+		return &client.Identity{Account: "111111111111"}, nil
+	})
+
+	// Assert the rows
+	assert.Equal(t, []contextMatrixRow{
+		{Context: "dev", Profile: "dev", Region: "us-west-2", Account: "111111111111", Credentials: "valid"},
+		{Context: "prod", Profile: "prod", Region: "us-east-1", Role: "arn:aws:iam::222222222222:role/admin", Account: "111111111111", Credentials: "valid"},
+		{Context: "staging", Profile: "staging", Region: "eu-west-1", Credentials: "invalid: ExpiredToken | session expired"},
+	}, rows)
+
+	// Assert the Markdown table
+	table := contextMatrixMarkdown(rows)
+	assert.Equal(t, `| Context | Profile | Region | Role | Account | Credentials |
+|---|---|---|---|---|---|
+| dev | dev | us-west-2 |  | 111111111111 | valid |
+| prod | prod | us-east-1 | arn:aws:iam::222222222222:role/admin | 111111111111 | valid |
+| staging | staging | eu-west-1 |  |  | invalid: ExpiredToken \| session expired |
+`, table)
+
+	// Without a check, the credentials are not checked
+	rows = contextMatrix(contexts, nil)
+	assert.Equal(t, "not checked", rows[0].Credentials)
+	assert.Empty(t, rows[0].Account)
+}
+
+// TestShouldWait tests the --wait and --no-wait convention of mutating commands.
+// It verifies that the flags override the wait setting, which applies without them.
+func TestShouldWait(t *testing.T) {
+	original := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = original })
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "start"}
+		addWaitFlags(cmd)
+		assert.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	// Without the wait setting, only --wait waits
+	config.GlobalConfig.Wait = false
+	assert.False(t, shouldWait(newCmd()))
+	assert.True(t, shouldWait(newCmd("--wait")))
+
+	// With it, only --no-wait doesn't
+	config.GlobalConfig.Wait = true
+	assert.True(t, shouldWait(newCmd()))
+	assert.False(t, shouldWait(newCmd("--no-wait")))
+
+	// --wait-timeout limits the wait
+	timeout, _ := newCmd().Flags().GetDuration("wait-timeout")
+	assert.Equal(t, defaultWaitTimeout, timeout)
+	timeout, _ = newCmd("--wait-timeout", "30s").Flags().GetDuration("wait-timeout")
+	assert.Equal(t, 30*time.Second, timeout)
+}
+
+// TestWaitForBulk tests waiting for the items of a bulk operation.
+// It verifies that --wait-timeout limits the wait for all the items together,
+// so that items left when it expires fail instead of getting a timeout each.
+func TestWaitForBulk(t *testing.T) {
+	cmd := &cobra.Command{Use: "start"}
+	addWaitFlags(cmd)
+	assert.NoError(t, cmd.ParseFlags([]string{"--wait", "--wait-timeout", "50ms"}))
+
+	// The first item completes and the second one waits until the timeout
+	var deadlines []time.Time
+	wait := func(ctx context.Context, item string, interval time.Duration) error {
+		deadline, _ := ctx.Deadline()
+		deadlines = append(deadlines, deadline)
+		if item == "i-1" {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// Call the function
+	result := &utils.BulkResult{}
+	waitForBulk(cmd, result, []string{"i-1", "i-2", "i-3"}, "EC2 instance %s to be running", "EC2 instance %s is running", wait)
+
+	// Assert the items shared one deadline
+	assert.Len(t, deadlines, 3)
+	assert.Equal(t, deadlines[0], deadlines[1])
+	assert.Equal(t, deadlines[0], deadlines[2])
+
+	// Assert the items left when it expired failed
+	assert.Equal(t, []string{"i-1"}, result.Succeeded)
+	assert.Len(t, result.Failed, 2)
+	assert.Contains(t, result.Failed[1].Error, "context deadline exceeded")
+}
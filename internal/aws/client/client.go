@@ -2,13 +2,17 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	appconfig "github.com/ao/awsm/internal/config"
+	"github.com/ao/awsm/internal/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
@@ -24,41 +28,100 @@ type Client struct {
 	Config aws.Config
 }
 
-// NewClient creates a new AWS client with the given options
+// NewClient creates a new AWS client with the given options. If a role is
+// configured (aws.role), the client uses credentials for that role.
 func NewClient(ctx context.Context) (*Client, error) {
-	// Get AWS profile and region from config
-	profile := appconfig.GetAWSProfile()
-	region := appconfig.GetAWSRegion()
+	// Get AWS profile, region and role from config
+	return NewClientForContext(ctx, appconfig.Context{
+		Profile: appconfig.GetAWSProfile(),
+		Region:  appconfig.GetAWSRegion(),
+		Role:    appconfig.GetAWSRole(),
+	})
+}
+
+// NewClientForContext creates a new AWS client for the profile, region and role
+// of the given context instead of the current one, e.g. to compare the
+// resources of two contexts.
+func NewClientForContext(ctx context.Context, awsContext appconfig.Context) (*Client, error) {
+	c, err := NewClientWithProfile(ctx, awsContext.Profile, awsContext.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	if awsContext.Role != "" {
+		logger.Debug("Assuming role %s", awsContext.Role)
+		c.Config = c.assumeRole(awsContext.Role, awsContext.Profile)
+	}
+
+	return c, nil
+}
 
-	fmt.Printf("\n\nDEBUG: Creating AWS client with profile=%s, region=%s\n\n", profile, region)
+// NewClientWithProfile creates a new AWS client for the given profile and region
+// instead of the ones from the current configuration
+func NewClientWithProfile(ctx context.Context, profile, region string) (*Client, error) {
+	logger.Debug("Creating AWS client with profile=%s, region=%s", profile, region)
 
 	// Load AWS configuration
-	cfg, err := loadConfig(ctx, profile, region)
+	cfg, err := loadConfig(ctx, profile, region, appconfig.GetMaxRetries(), appconfig.GetRetryMode(), appconfig.IsEnvOnly())
 	if err != nil {
-		fmt.Printf("\n\nDEBUG: Error loading AWS config: %v\n\n", err)
+		logger.Error("Error loading AWS config: %v", err)
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	fmt.Println("\n\nDEBUG: AWS client created successfully")
+	// SSO profiles get their credentials from the token cached by `aws sso login`;
+	// check it now so an expired session is reported clearly
+	if !appconfig.IsEnvOnly() && appconfig.IsSSOProfile(profile) {
+		logger.Debug("Profile %s is an SSO profile", profile)
+		if err := checkSSOCredentials(ctx, cfg, profile); err != nil {
+			logger.Error("Error getting SSO credentials: %v", err)
+			return nil, err
+		}
+	}
+
+	logger.Debug("AWS client created successfully")
 
 	return &Client{
 		Config: cfg,
 	}, nil
 }
 
-// loadConfig loads the AWS configuration with the specified profile and region
-func loadConfig(ctx context.Context, profile, region string) (aws.Config, error) {
-	// Create a retryer to handle retries
-	retryer := retry.NewStandard(func(o *retry.StandardOptions) {
-		o.MaxAttempts = DefaultRetryMaxAttempts
-	})
+// WithTimeout returns a copy of ctx that is cancelled once the configured
+// timeout for AWS operations (aws.timeout) has elapsed, and its cancel function.
+func WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, appconfig.GetAWSTimeout())
+}
 
-	// Load the configuration with the specified profile and region
-	cfg, err := awsconfig.LoadDefaultConfig(ctx,
+// loadConfig loads the AWS configuration with the specified profile, region and retry settings.
+// With envOnly, the shared config and credentials files are not read and the profile is
+// ignored, so credentials only come from the environment (or the instance/container role).
+func loadConfig(ctx context.Context, profile, region string, maxRetries int, retryMode string, envOnly bool) (aws.Config, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(region),
-		awsconfig.WithSharedConfigProfile(profile),
-		awsconfig.WithRetryer(func() aws.Retryer { return retryer }),
-	)
+		awsconfig.WithRetryer(newRetryer(maxRetries, retryMode)),
+	}
+
+	if envOnly {
+		// Without shared files there are no profiles to select
+		if envProfile := os.Getenv("AWS_PROFILE"); envProfile != "" {
+			return aws.Config{}, fmt.Errorf("AWS_PROFILE=%s cannot be used with env-only credentials", envProfile)
+		}
+
+		opts = append(opts,
+			awsconfig.WithSharedConfigFiles([]string{}),
+			awsconfig.WithSharedCredentialsFiles([]string{}),
+		)
+	} else {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(profile))
+	}
+
+	// Refuse to connect to anything but AWS endpoints, including from the
+	// credential providers created while loading the configuration
+	if appconfig.IsAWSOnlyNetwork() {
+		opts = append(opts, awsconfig.WithHTTPClient(newAWSOnlyHTTPClient()))
+	}
+
+	// Load the configuration with the specified options
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
 	}
@@ -66,19 +129,139 @@ func loadConfig(ctx context.Context, profile, region string) (aws.Config, error)
 	return cfg, nil
 }
 
-// AssumeRole creates a new AWS config with assumed role credentials
-func (c *Client) AssumeRole(ctx context.Context, roleARN string) (aws.Config, error) {
-	// Create an STS client
-	stsClient := sts.NewFromConfig(c.Config)
+// checkSSOCredentials retrieves the credentials of an SSO profile. The SDK's SSO
+// credential provider, selected by LoadDefaultConfig for profiles with SSO
+// settings, exchanges the cached SSO token for role credentials, which are then
+// cached in cfg for the API calls.
+//
+// Returns an error telling the user to run aws sso login if the cached token is
+// missing or expired.
+func checkSSOCredentials(ctx context.Context, cfg aws.Config, profile string) error {
+	_, err := cfg.Credentials.Retrieve(ctx)
+	if err == nil {
+		return nil
+	}
+
+	var tokenErr *ssocreds.InvalidTokenError
+	if errors.As(err, &tokenErr) {
+		return fmt.Errorf("SSO session for profile %s has expired or was never started, run \"aws sso login --profile %s\": %w", profile, profile, err)
+	}
+	return fmt.Errorf("failed to get SSO credentials for profile %s: %w", profile, err)
+}
 
+// newRetryer returns a retryer factory for the given number of retries and retry mode.
+// A negative maxRetries falls back to DefaultRetryMaxAttempts, and any mode other than
+// adaptive uses the standard retryer.
+func newRetryer(maxRetries int, retryMode string) func() aws.Retryer {
+	maxAttempts := DefaultRetryMaxAttempts
+	if maxRetries >= 0 {
+		// The SDK counts the initial attempt, so retries + 1
+		maxAttempts = maxRetries + 1
+	}
+
+	setMaxAttempts := func(o *retry.StandardOptions) {
+		o.MaxAttempts = maxAttempts
+	}
+
+	if retryMode == appconfig.RetryModeAdaptive {
+		return func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, setMaxAttempts)
+			})
+		}
+	}
+
+	return func() aws.Retryer {
+		return retry.NewStandard(setMaxAttempts)
+	}
+}
+
+// AssumeRole creates a new AWS config with assumed role credentials. If the
+// current profile has an MFA device (mfa_serial), the MFA token code is taken
+// from SetMFAToken or asked for on the terminal. The temporary credentials are
+// cached on disk, so the role is only assumed again once they expire. The role
+// is assumed lazily, when the credentials are first used.
+func (c *Client) AssumeRole(roleARN string) aws.Config {
+	return c.assumeRole(roleARN, appconfig.GetAWSProfile())
+}
+
+// assumeRole returns a copy of the client config with credentials for the role,
+// using the MFA device of the given profile
+func (c *Client) assumeRole(roleARN, profile string) aws.Config {
 	// Create the credentials provider
-	provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN)
+	provider := newProfileRoleProvider(sts.NewFromConfig(c.Config), roleARN, profile, appconfig.IsEnvOnly())
 
 	// Create a new config with the assumed role credentials
 	cfg := c.Config.Copy()
 	cfg.Credentials = aws.NewCredentialsCache(provider)
 
-	return cfg, nil
+	return cfg
+}
+
+// newProfileRoleProvider creates a provider that assumes roleARN with the MFA
+// device of the given profile and caches the credentials on disk. In env-only
+// mode, neither the AWS config file nor the cache is read or written.
+func newProfileRoleProvider(client stscreds.AssumeRoleAPIClient, roleARN, profile string, envOnly bool) *roleProvider {
+	if envOnly {
+		return newRoleProvider(client, roleARN, "", nil)
+	}
+
+	// Without a cache directory, the role is assumed for every command
+	cache, err := newRoleCache()
+	if err != nil {
+		logger.Warn("Not caching role credentials: %v", err)
+	}
+
+	return newRoleProvider(client, roleARN, appconfig.GetMFASerial(profile), cache)
+}
+
+// Identity is the AWS account and identity that the client credentials belong to
+type Identity struct {
+	Account string // AWS account ID
+	ARN     string // ARN of the user or assumed role
+}
+
+// GetIdentity verifies the client credentials with STS and returns the account
+// and identity they belong to
+func (c *Client) GetIdentity(ctx context.Context) (*Identity, error) {
+	stsClient := sts.NewFromConfig(c.Config)
+
+	output, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	return &Identity{
+		Account: aws.ToString(output.Account),
+		ARN:     aws.ToString(output.Arn),
+	}, nil
+}
+
+// VerifyRole checks that the role of a context can be assumed, by assuming it and
+// asking STS who the credentials belong to. It is meant to be passed to
+// appconfig.SetRoleVerifier.
+func VerifyRole(awsContext appconfig.Context) error {
+	ctx, cancel := WithTimeout(context.Background())
+	defer cancel()
+
+	c, err := NewClientForContext(ctx, awsContext)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.GetIdentity(ctx)
+	return err
+}
+
+// GetCallerIdentity verifies the client credentials with STS and returns the ARN
+// of the identity they belong to
+func (c *Client) GetCallerIdentity(ctx context.Context) (string, error) {
+	identity, err := c.GetIdentity(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return identity.ARN, nil
 }
 
 // GetRegion returns the region from the client config
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appconfig "github.com/ao/awsm/internal/config"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewRetryer tests the newRetryer function.
+// It verifies that the configured number of retries and retry mode are
+// translated into the corresponding SDK retryer.
+func TestNewRetryer(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name                string
+		maxRetries          int
+		retryMode           string
+		expectedMaxAttempts int
+		expectAdaptive      bool
+	}{
+		{
+			name:                "Standard mode",
+			maxRetries:          5,
+			retryMode:           appconfig.RetryModeStandard,
+			expectedMaxAttempts: 6,
+			expectAdaptive:      false,
+		},
+		{
+			name:                "Adaptive mode",
+			maxRetries:          2,
+			retryMode:           appconfig.RetryModeAdaptive,
+			expectedMaxAttempts: 3,
+			expectAdaptive:      true,
+		},
+		{
+			name:                "Fail fast",
+			maxRetries:          0,
+			retryMode:           appconfig.RetryModeStandard,
+			expectedMaxAttempts: 1,
+			expectAdaptive:      false,
+		},
+		{
+			name:                "Negative retries use default",
+			maxRetries:          -1,
+			retryMode:           "",
+			expectedMaxAttempts: DefaultRetryMaxAttempts,
+			expectAdaptive:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create the retryer
+			retryer := newRetryer(tc.maxRetries, tc.retryMode)()
+
+			// Assert retry settings
+			assert.Equal(t, tc.expectedMaxAttempts, retryer.MaxAttempts())
+			_, isAdaptive := retryer.(*retry.AdaptiveMode)
+			assert.Equal(t, tc.expectAdaptive, isAdaptive)
+		})
+	}
+}
+
+// TestLoadConfigEnvOnly tests loading the AWS configuration in env-only mode.
+// It verifies that credentials come from the environment even when the shared
+// files define the requested profile, and that AWS_PROFILE is rejected.
+func TestLoadConfigEnvOnly(t *testing.T) {
+	// Write shared files with a stale profile
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("[stale]\naws_access_key_id = STALEKEY\naws_secret_access_key = stalesecret\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+
+	// Inject credentials through the environment
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	t.Setenv("AWS_PROFILE", "")
+
+	ctx := context.Background()
+
+	// Load the configuration in env-only mode
+	cfg, err := loadConfig(ctx, "stale", "us-east-1", 0, appconfig.RetryModeStandard, true)
+	require.NoError(t, err)
+
+	// Assert the environment credentials are used
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ENVKEY", creds.AccessKeyID)
+
+	// A profile selected through the environment can't be honored
+	t.Setenv("AWS_PROFILE", "stale")
+	_, err = loadConfig(ctx, "", "us-east-1", 0, appconfig.RetryModeStandard, true)
+	assert.ErrorContains(t, err, "AWS_PROFILE=stale")
+	t.Setenv("AWS_PROFILE", "")
+
+	// Without env-only, the profile from the shared files wins
+	cfg, err = loadConfig(ctx, "stale", "us-east-1", 0, appconfig.RetryModeStandard, false)
+	require.NoError(t, err)
+	creds, err = cfg.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "STALEKEY", creds.AccessKeyID)
+}
+
+// TestCheckSSOCredentials tests retrieving the credentials of an SSO profile.
+// It verifies that a missing SSO token is reported with a hint to run aws sso login.
+func TestCheckSSOCredentials(t *testing.T) {
+	// Write a config file with an SSO profile and no cached SSO token
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(`[profile sso-dev]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 123456789012
+sso_role_name = ReadOnly
+`), 0600))
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	ctx := context.Background()
+
+	// Load the configuration for the SSO profile
+	cfg, err := loadConfig(ctx, "sso-dev", "us-east-1", 0, appconfig.RetryModeStandard, false)
+	require.NoError(t, err)
+
+	// Assert the missing token is reported clearly
+	err = checkSSOCredentials(ctx, cfg, "sso-dev")
+	assert.ErrorContains(t, err, `run "aws sso login --profile sso-dev"`)
+}
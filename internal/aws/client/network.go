@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// awsHostSuffixes are the domains of the AWS service endpoints, including the
+// China regions and the dual-stack endpoints
+var awsHostSuffixes = []string{".amazonaws.com", ".amazonaws.com.cn", ".api.aws"}
+
+// awsMetadataHosts are the addresses of the instance metadata service (IMDS) and
+// the ECS and EKS container credential endpoints, which provide credentials on AWS
+var awsMetadataHosts = map[string]bool{
+	"169.254.169.254": true, // IMDS
+	"fd00:ec2::254":   true, // IMDS over IPv6
+	"169.254.170.2":   true, // ECS container credentials
+	"169.254.170.23":  true, // EKS Pod Identity
+	"fd00:ec2::23":    true, // EKS Pod Identity over IPv6
+}
+
+// IsAWSHost reports whether host, a host name or IP address without a port, is an
+// AWS service endpoint or an AWS metadata endpoint
+func IsAWSHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if awsMetadataHosts[host] {
+		return true
+	}
+
+	for _, suffix := range awsHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialContext is the signature of http.Transport.DialContext
+type dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// awsOnlyDialContext wraps dial so that it refuses to open connections to hosts
+// other than AWS endpoints. Because it applies to the connection, a proxy that is
+// not an AWS endpoint is refused too.
+func awsOnlyDialContext(dial dialContext) dialContext {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if !IsAWSHost(host) {
+			return nil, fmt.Errorf("refusing to connect to %s: only AWS endpoints are allowed (--no-network-except-aws)", host)
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// newAWSOnlyHTTPClient creates an HTTP client for the AWS SDK that only connects
+// to AWS endpoints, used with --no-network-except-aws. It stays a BuildableClient
+// so that the SDK can still apply options such as a custom CA bundle to it.
+func newAWSOnlyHTTPClient() *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.DialContext = awsOnlyDialContext(tr.DialContext)
+	})
+}
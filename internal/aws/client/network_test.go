@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	appconfig "github.com/ao/awsm/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsAWSHost tests recognizing AWS endpoints.
+// It verifies that service and metadata endpoints are allowed and that other
+// hosts, including look-alikes, are not.
+func TestIsAWSHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"sts.us-east-1.amazonaws.com":          true,
+		"my-bucket.s3.eu-west-1.amazonaws.com": true,
+		"EC2.US-WEST-2.AMAZONAWS.COM":          true,
+		"s3.cn-north-1.amazonaws.com.cn":       true,
+		"s3.dualstack.us-east-1.api.aws":       true,
+		"portal.sso.us-east-1.amazonaws.com.":  true,
+		"169.254.169.254":                      true,
+		"fd00:ec2::254":                        true,
+		"169.254.170.2":                        true,
+		"example.com":                          false,
+		"amazonaws.com.evil.example":           false,
+		"notamazonaws.com":                     false,
+		"github.com":                           false,
+		"10.0.0.1":                             false,
+		"":                                     false,
+	} {
+		assert.Equal(t, want, IsAWSHost(host), host)
+	}
+}
+
+// TestAWSOnlyDialContext tests the dialer used with --no-network-except-aws.
+// It verifies that connections to AWS endpoints are opened and that
+// connections to any other host fail without being opened.
+func TestAWSOnlyDialContext(t *testing.T) {
+	var dialed []string
+	dial := awsOnlyDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, nil
+	})
+
+	// A connection to AWS is opened
+	_, err := dial(context.Background(), "tcp", "sts.us-east-1.amazonaws.com:443")
+	require.NoError(t, err)
+	_, err = dial(context.Background(), "tcp", "[fd00:ec2::254]:80")
+	require.NoError(t, err)
+
+	// A connection to another host is refused
+	_, err = dial(context.Background(), "tcp", "telemetry.example.com:443")
+	assert.ErrorContains(t, err, "refusing to connect to telemetry.example.com")
+
+	// Assert only the AWS connections were opened
+	assert.Equal(t, []string{"sts.us-east-1.amazonaws.com:443", "[fd00:ec2::254]:80"}, dialed)
+}
+
+// TestLoadConfigAWSOnlyNetwork tests loading the AWS configuration with the
+// network restricted to AWS endpoints.
+// It verifies that the SDK's HTTP client refuses to connect to other hosts,
+// even with a custom CA bundle.
+func TestLoadConfigAWSOnlyNetwork(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not have been sent")
+	}))
+	defer server.Close()
+
+	t.Setenv(appconfig.AWSOnlyNetworkEnvVar, "1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	// Trust the test server's certificate through a custom CA bundle
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, certificate, 0600))
+	t.Setenv("AWS_CA_BUNDLE", bundle)
+
+	// Call the function
+	cfg, err := loadConfig(context.Background(), "", "us-east-1", 0, appconfig.RetryModeStandard, true)
+	require.NoError(t, err)
+
+	// Assert a request to a host that is not AWS fails
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = cfg.HTTPClient.Do(req)
+	assert.ErrorContains(t, err, "refusing to connect to 127.0.0.1")
+}
+
+// allowedNetworkAPIs are the identifiers of the standard networking packages
+// that may be used outside the AWS client, by import path. They make no network
+// calls, such as http.DetectContentType. Any other use of the net packages is
+// refused, so new ones have to be added here deliberately.
+var allowedNetworkAPIs = map[string][]string{
+	"net/http": {"DetectContentType"},
+}
+
+// TestNoNetworkOutsideAWSClient tests that awsm makes no network calls except
+// through the AWS SDK.
+// It fails if a package other than the AWS client imports net or one of its
+// subpackages for anything but the APIs in allowedNetworkAPIs, so that every
+// connection goes through the client that --no-network-except-aws restricts to
+// AWS endpoints.
+func TestNoNetworkOutsideAWSClient(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "go.mod"))
+	require.NoError(t, err, "the module root should contain go.mod")
+
+	clientDir := filepath.Join(root, "internal", "aws", "client")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "tests") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || filepath.Dir(path) == clientDir {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+
+		// Find the names the networking packages are imported as
+		imported := make(map[string]string)
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			if importPath != "net" && !strings.HasPrefix(importPath, "net/") {
+				continue
+			}
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if _, ok := allowedNetworkAPIs[importPath]; !ok || name == "." || name == "_" {
+				t.Errorf("%s imports %s; network calls must go through the AWS client", rel, importPath)
+				continue
+			}
+			imported[name] = importPath
+		}
+
+		// Refuse the uses of their APIs that are not allowed
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			ident, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if importPath, ok := imported[ident.Name]; ok && !slices.Contains(allowedNetworkAPIs[importPath], sel.Sel.Name) {
+				t.Errorf("%s:%d uses %s.%s; network calls must go through the AWS client", rel, fset.Position(sel.Pos()).Line, importPath, sel.Sel.Name)
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+}
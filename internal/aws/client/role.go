@@ -0,0 +1,211 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ao/awsm/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/mattn/go-isatty"
+)
+
+// RoleCredentialsExpiryWindow is how long before they expire cached role
+// credentials stop being used, so they don't expire in the middle of a command
+const RoleCredentialsExpiryWindow = 5 * time.Minute
+
+// mfaToken is the MFA token code used when assuming a role, set with SetMFAToken
+var mfaToken string
+
+// mfaPrompt is whether a missing MFA token code may be asked for on the terminal,
+// set with SetMFAPrompt
+var mfaPrompt = true
+
+// SetMFAToken sets the MFA token code used when assuming a role for a profile
+// with an MFA device (mfa_serial). Without one, the code is asked for on the terminal.
+func SetMFAToken(token string) {
+	mfaToken = token
+}
+
+// SetMFAPrompt sets whether a missing MFA token code may be asked for on the
+// terminal. The TUI disables it, since reading stdin would compete with its
+// key handling; the code must then be passed with --mfa-token.
+func SetMFAPrompt(enabled bool) {
+	mfaPrompt = enabled
+}
+
+// roleCache stores temporary role credentials on disk, one file per role ARN,
+// so that a role is only assumed again once its credentials expire
+type roleCache struct {
+	dir string           // Directory holding the cache files
+	now func() time.Time // Current time, replaced in tests
+}
+
+// cachedRoleCredentials is the content of a role cache file
+type cachedRoleCredentials struct {
+	AccessKeyID     string    `json:"accessKeyId"`
+	SecretAccessKey string    `json:"secretAccessKey"`
+	SessionToken    string    `json:"sessionToken"`
+	Expires         time.Time `json:"expires"`
+}
+
+// roleCacheFileName turns a role ARN into a file name. The ARN is hashed, so
+// different ARNs never share a file.
+func roleCacheFileName(roleARN string) string {
+	sum := sha256.Sum256([]byte(roleARN))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// newRoleCache creates a role cache in the user's cache directory
+// (e.g. ~/.cache/awsm/roles on Linux)
+func newRoleCache() (*roleCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+
+	return &roleCache{
+		dir: filepath.Join(dir, "awsm", "roles"),
+		now: time.Now,
+	}, nil
+}
+
+// path returns the path of the cache file for a role
+func (c *roleCache) path(roleARN string) string {
+	return filepath.Join(c.dir, roleCacheFileName(roleARN))
+}
+
+// Get returns the cached credentials for a role. It returns false if there are
+// none, they cannot be read, or they expire within RoleCredentialsExpiryWindow.
+func (c *roleCache) Get(roleARN string) (aws.Credentials, bool) {
+	data, err := os.ReadFile(c.path(roleARN))
+	if err != nil {
+		return aws.Credentials{}, false
+	}
+
+	var cached cachedRoleCredentials
+	if err := json.Unmarshal(data, &cached); err != nil {
+		logger.Debug("Ignoring invalid cached credentials for role %s: %v", roleARN, err)
+		return aws.Credentials{}, false
+	}
+
+	if !c.now().Add(RoleCredentialsExpiryWindow).Before(cached.Expires) {
+		return aws.Credentials{}, false
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     cached.AccessKeyID,
+		SecretAccessKey: cached.SecretAccessKey,
+		SessionToken:    cached.SessionToken,
+		Source:          "awsm role cache",
+		CanExpire:       true,
+		Expires:         cached.Expires,
+	}, true
+}
+
+// Put stores the credentials for a role in a file only the user can read
+func (c *roleCache) Put(roleARN string, creds aws.Credentials) error {
+	if !creds.CanExpire {
+		return errors.New("credentials without an expiry time are not cached")
+	}
+
+	data, err := json.Marshal(cachedRoleCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expires:         creds.Expires,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path(roleARN), data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return nil
+}
+
+// roleProvider is a credentials provider that assumes a role, reusing the
+// credentials cached for the role while they are valid
+type roleProvider struct {
+	roleARN   string                  // ARN of the role to assume
+	mfaSerial string                  // MFA device used to assume the role (empty for none)
+	assume    aws.CredentialsProvider // Provider that calls STS AssumeRole
+	cache     *roleCache              // Cache of role credentials (nil to disable caching)
+}
+
+// newRoleProvider creates a provider that assumes roleARN with the STS client.
+// If mfaSerial is not empty, the MFA token code is taken from SetMFAToken or
+// asked for on the terminal.
+func newRoleProvider(client stscreds.AssumeRoleAPIClient, roleARN, mfaSerial string, cache *roleCache) *roleProvider {
+	assume := stscreds.NewAssumeRoleProvider(client, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		if mfaSerial != "" {
+			o.SerialNumber = aws.String(mfaSerial)
+			o.TokenProvider = readMFAToken
+		}
+	})
+
+	return &roleProvider{
+		roleARN:   roleARN,
+		mfaSerial: mfaSerial,
+		assume:    assume,
+		cache:     cache,
+	}
+}
+
+// Retrieve returns the cached credentials for the role, or assumes the role and
+// caches the new credentials
+func (p *roleProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if p.cache != nil {
+		if creds, ok := p.cache.Get(p.roleARN); ok {
+			logger.Debug("Using cached credentials for role %s", p.roleARN)
+			return creds, nil
+		}
+	}
+
+	creds, err := p.assume.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to assume role %s: %w", p.roleARN, err)
+	}
+
+	if p.cache != nil {
+		if err := p.cache.Put(p.roleARN, creds); err != nil {
+			logger.Warn("Failed to cache credentials for role %s: %v", p.roleARN, err)
+		}
+	}
+
+	return creds, nil
+}
+
+// readMFAToken returns the MFA token code set with SetMFAToken, or asks for it
+// on the terminal unless prompting is disabled with SetMFAPrompt
+func readMFAToken() (string, error) {
+	if mfaToken != "" {
+		return mfaToken, nil
+	}
+
+	fd := os.Stdin.Fd()
+	if !mfaPrompt || (!isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd)) {
+		return "", errors.New("an MFA token code is required to assume the role, pass it with --mfa-token")
+	}
+
+	fmt.Fprint(os.Stderr, "MFA token code: ")
+	var token string
+	if _, err := fmt.Fscanln(os.Stdin, &token); err != nil {
+		return "", fmt.Errorf("failed to read MFA token code: %w", err)
+	}
+
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSTSClient is a mock implementation of the STS AssumeRole API
+type mockSTSClient struct {
+	mock.Mock
+}
+
+// Ensure mockSTSClient implements stscreds.AssumeRoleAPIClient
+var _ stscreds.AssumeRoleAPIClient = (*mockSTSClient)(nil)
+
+func (m *mockSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sts.AssumeRoleOutput), args.Error(1)
+}
+
+const testRoleARN = "arn:aws:iam::123456789012:role/admin"
+
+// TestRoleCache tests the role credentials cache.
+// It verifies that stored credentials are read back until they are about to
+// expire, and that the cache file is only readable by the user.
+func TestRoleCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := &roleCache{dir: t.TempDir(), now: func() time.Time { return now }}
+
+	// Nothing is cached yet
+	_, ok := cache.Get(testRoleARN)
+	assert.False(t, ok)
+
+	// Store credentials valid for an hour
+	err := cache.Put(testRoleARN, aws.Credentials{
+		AccessKeyID:     "ASIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		CanExpire:       true,
+		Expires:         now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Assert the cache file is private
+	info, err := os.Stat(cache.path(testRoleARN))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The credentials are read back
+	creds, ok := cache.Get(testRoleARN)
+	require.True(t, ok)
+	assert.Equal(t, "ASIAEXAMPLE", creds.AccessKeyID)
+	assert.Equal(t, "secret", creds.SecretAccessKey)
+	assert.Equal(t, "token", creds.SessionToken)
+	assert.True(t, creds.Expires.Equal(now.Add(time.Hour)))
+
+	// Credentials about to expire are not used
+	now = now.Add(time.Hour - RoleCredentialsExpiryWindow)
+	_, ok = cache.Get(testRoleARN)
+	assert.False(t, ok)
+
+	// Neither are expired credentials
+	now = now.Add(time.Hour)
+	_, ok = cache.Get(testRoleARN)
+	assert.False(t, ok)
+
+	// Other roles have their own entries
+	_, ok = cache.Get("arn:aws:iam::123456789012:role/other")
+	assert.False(t, ok)
+
+	// Even roles whose ARNs only differ in separators
+	assert.NotEqual(t, cache.path("arn:aws:iam::123456789012:role/a/b"), cache.path("arn:aws:iam::123456789012:role/a_b"))
+
+	// Credentials that don't expire are not cached
+	err = cache.Put(testRoleARN, aws.Credentials{AccessKeyID: "AKIAEXAMPLE"})
+	assert.Error(t, err)
+}
+
+// TestRoleProvider tests assuming a role through the role provider.
+// It verifies that the role is assumed with the MFA device and token code,
+// and that the cached credentials are reused by the next provider.
+func TestRoleProvider(t *testing.T) {
+	ctx := context.Background()
+	cache := &roleCache{dir: t.TempDir(), now: time.Now}
+	expires := time.Now().Add(time.Hour)
+
+	// Create mock client
+	mockClient := new(mockSTSClient)
+
+	// Set up expectations
+	mockClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return *input.RoleArn == testRoleARN &&
+			aws.ToString(input.SerialNumber) == "arn:aws:iam::123456789012:mfa/jane" &&
+			aws.ToString(input.TokenCode) == "123456"
+	})).Return(&sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{
+			AccessKeyId:     aws.String("ASIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("token"),
+			Expiration:      aws.Time(expires),
+		},
+	}, nil).Once()
+
+	// Use an MFA token code given on the command line
+	SetMFAToken("123456")
+	t.Cleanup(func() { SetMFAToken("") })
+
+	// Call the function
+	provider := newRoleProvider(mockClient, testRoleARN, "arn:aws:iam::123456789012:mfa/jane", cache)
+	creds, err := provider.Retrieve(ctx)
+
+	// Assert no error
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAEXAMPLE", creds.AccessKeyID)
+
+	// A new provider, as in the next command, uses the cache instead of STS
+	provider = newRoleProvider(mockClient, testRoleARN, "arn:aws:iam::123456789012:mfa/jane", cache)
+	creds, err = provider.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAEXAMPLE", creds.AccessKeyID)
+	assert.Equal(t, "awsm role cache", creds.Source)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestReadMFAToken tests reading the MFA token code.
+// It verifies that the code given with SetMFAToken is used, and that without
+// one an error is returned when prompting is disabled, as in the TUI.
+func TestReadMFAToken(t *testing.T) {
+	t.Cleanup(func() {
+		SetMFAToken("")
+		SetMFAPrompt(true)
+	})
+
+	// The code given on the command line is used
+	SetMFAToken("123456")
+	token, err := readMFAToken()
+	require.NoError(t, err)
+	assert.Equal(t, "123456", token)
+
+	// Without a code, stdin is not read when prompting is disabled
+	SetMFAToken("")
+	SetMFAPrompt(false)
+	_, err = readMFAToken()
+	assert.ErrorContains(t, err, "--mfa-token")
+}
+
+// TestNewProfileRoleProvider tests creating the role provider for a profile.
+// It verifies that the profile's MFA device and the credentials cache are used,
+// except in env-only mode, where neither the AWS config file nor the cache is read.
+func TestNewProfileRoleProvider(t *testing.T) {
+	// Write an AWS config file with an MFA device for the profile
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile base]\nmfa_serial = arn:aws:iam::123456789012:mfa/jane\n"), 0600))
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	// Create mock client
+	mockClient := new(mockSTSClient)
+
+	// The profile's MFA device and the cache are used
+	provider := newProfileRoleProvider(mockClient, testRoleARN, "base", false)
+	assert.Equal(t, "arn:aws:iam::123456789012:mfa/jane", provider.mfaSerial)
+	assert.NotNil(t, provider.cache)
+
+	// In env-only mode they are not
+	provider = newProfileRoleProvider(mockClient, testRoleARN, "base", true)
+	assert.Empty(t, provider.mfaSerial)
+	assert.Nil(t, provider.cache)
+}
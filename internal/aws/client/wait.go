@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultWaitInterval is the default time before the first retry of a waiter
+// while waiting for a change to complete; later retries back off
+const DefaultWaitInterval = 5 * time.Second
+
+// DefaultMaxWait is how long a waiter waits when its context has no deadline
+const DefaultMaxWait = 10 * time.Minute
+
+// MaxWait returns the maximum wait duration to pass to an SDK waiter, the way
+// mutating commands wait with --wait: the time left until the deadline of
+// ctx, or DefaultMaxWait if it has none.
+//
+// Parameters:
+//   - ctx: Context of the wait
+//
+// Returns a duration greater than zero, as the SDK waiters require.
+func MaxWait(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return DefaultMaxWait
+	}
+	return max(time.Until(deadline), time.Nanosecond)
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMaxWait tests the maximum wait duration passed to SDK waiters.
+// It verifies that it is the time left until the deadline of the context,
+// the default without a deadline, and still positive once the deadline passed.
+func TestMaxWait(t *testing.T) {
+	// Without a deadline
+	assert.Equal(t, DefaultMaxWait, MaxWait(context.Background()))
+
+	// Until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	wait := MaxWait(ctx)
+	assert.LessOrEqual(t, wait, time.Minute)
+	assert.Greater(t, wait, 50*time.Second)
+
+	// After the deadline
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	assert.Positive(t, MaxWait(ctx))
+}
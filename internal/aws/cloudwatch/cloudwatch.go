@@ -0,0 +1,177 @@
+// Package cloudwatch provides functionality for reading AWS CloudWatch metrics.
+// It includes operations for fetching metric statistics for resources such as
+// EC2 instances and Lambda functions.
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// Namespaces of the metrics published by AWS services
+const (
+	NamespaceEC2    = "AWS/EC2"    // Metrics of EC2 instances
+	NamespaceLambda = "AWS/Lambda" // Metrics of Lambda functions
+)
+
+// CloudWatchClient defines the interface for CloudWatch client operations.
+// This interface allows for easy mocking in tests.
+type CloudWatchClient interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
+// Adapter represents a CloudWatch service adapter that provides
+// higher-level operations for reading CloudWatch metrics.
+type Adapter struct {
+	client CloudWatchClient // AWS CloudWatch client implementation
+}
+
+// Datapoint represents the statistics of a metric over one period.
+type Datapoint struct {
+	Timestamp time.Time // Start of the period
+	Average   float64   // Average value over the period
+	Maximum   float64   // Maximum value over the period
+	Sum       float64   // Sum of the values over the period, e.g. the number of Lambda invocations
+}
+
+// NewAdapter creates a new CloudWatch adapter using the AWS credentials
+// from the current context configuration.
+//
+// The context is used for AWS client creation and configuration.
+// Returns an error if the AWS client cannot be created.
+func NewAdapter(ctx context.Context) (*Adapter, error) {
+	// Create AWS client
+	awsClient, err := client.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Create CloudWatch client
+	cwClient := cloudwatch.NewFromConfig(awsClient.Config)
+
+	return &Adapter{
+		client: cwClient,
+	}, nil
+}
+
+// NewAdapterWithClient creates a new CloudWatch adapter with a provided client.
+// This is particularly useful for testing with mock clients.
+func NewAdapterWithClient(cwClient CloudWatchClient) *Adapter {
+	return &Adapter{
+		client: cwClient,
+	}
+}
+
+// GetMetricStatistics gets the average, maximum and sum of a metric for each
+// period between startTime and endTime.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - namespace: The metric namespace (e.g., AWS/EC2)
+//   - metricName: The name of the metric (e.g., CPUUtilization)
+//   - dimensions: The dimensions identifying the resource (e.g., InstanceId)
+//   - startTime: The start of the time range
+//   - endTime: The end of the time range
+//   - period: The length of each period; CloudWatch requires a multiple of 60 seconds
+//
+// Returns the datapoints ordered by timestamp, and an error if the operation fails.
+func (a *Adapter) GetMetricStatistics(ctx context.Context, namespace, metricName string, dimensions map[string]string, startTime, endTime time.Time, period time.Duration) ([]Datapoint, error) {
+	// Sort the dimension names so requests are deterministic
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metricDimensions := make([]types.Dimension, 0, len(names))
+	for _, name := range names {
+		metricDimensions = append(metricDimensions, types.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(dimensions[name]),
+		})
+	}
+
+	// Create the input for the GetMetricStatistics API
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metricName),
+		Dimensions: metricDimensions,
+		StartTime:  aws.Time(startTime),
+		EndTime:    aws.Time(endTime),
+		Period:     aws.Int32(int32(period.Seconds())),
+		Statistics: []types.Statistic{types.StatisticAverage, types.StatisticMaximum, types.StatisticSum},
+	}
+
+	// Call the GetMetricStatistics API
+	output, err := a.client.GetMetricStatistics(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s statistics: %w", namespace, metricName, err)
+	}
+
+	// CloudWatch doesn't return the datapoints in order
+	datapoints := make([]Datapoint, 0, len(output.Datapoints))
+	for _, dp := range output.Datapoints {
+		datapoints = append(datapoints, Datapoint{
+			Timestamp: aws.ToTime(dp.Timestamp),
+			Average:   aws.ToFloat64(dp.Average),
+			Maximum:   aws.ToFloat64(dp.Maximum),
+			Sum:       aws.ToFloat64(dp.Sum),
+		})
+	}
+	sort.Slice(datapoints, func(i, j int) bool {
+		return datapoints[i].Timestamp.Before(datapoints[j].Timestamp)
+	})
+
+	return datapoints, nil
+}
+
+// GetEC2InstanceMetric gets the statistics of an AWS/EC2 metric, such as
+// CPUUtilization or NetworkIn, for a single instance.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instanceID: The ID of the EC2 instance
+//   - metricName: The name of the metric
+//   - startTime: The start of the time range
+//   - endTime: The end of the time range
+//   - period: The length of each period; CloudWatch requires a multiple of 60 seconds
+//
+// Returns the datapoints ordered by timestamp, and an error if the operation fails.
+func (a *Adapter) GetEC2InstanceMetric(ctx context.Context, instanceID, metricName string, startTime, endTime time.Time, period time.Duration) ([]Datapoint, error) {
+	return a.GetMetricStatistics(ctx, NamespaceEC2, metricName, EC2InstanceDimensions(instanceID), startTime, endTime, period)
+}
+
+// GetLambdaFunctionMetric gets the statistics of an AWS/Lambda metric, such as
+// Duration, Invocations or Errors, for a single function.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - functionName: The name of the Lambda function
+//   - metricName: The name of the metric
+//   - startTime: The start of the time range
+//   - endTime: The end of the time range
+//   - period: The length of each period; CloudWatch requires a multiple of 60 seconds
+//
+// Returns the datapoints ordered by timestamp, and an error if the operation fails.
+func (a *Adapter) GetLambdaFunctionMetric(ctx context.Context, functionName, metricName string, startTime, endTime time.Time, period time.Duration) ([]Datapoint, error) {
+	return a.GetMetricStatistics(ctx, NamespaceLambda, metricName, LambdaFunctionDimensions(functionName), startTime, endTime, period)
+}
+
+// EC2InstanceDimensions returns the dimensions identifying an EC2 instance in
+// the AWS/EC2 namespace
+func EC2InstanceDimensions(instanceID string) map[string]string {
+	return map[string]string{"InstanceId": instanceID}
+}
+
+// LambdaFunctionDimensions returns the dimensions identifying a Lambda function
+// in the AWS/Lambda namespace
+func LambdaFunctionDimensions(functionName string) map[string]string {
+	return map[string]string{"FunctionName": functionName}
+}
@@ -0,0 +1,141 @@
+// Package cloudwatch provides tests for the CloudWatch adapter functionality.
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockCloudWatchClient implements the CloudWatchClient interface for testing purposes.
+// It uses the testify/mock package to mock AWS CloudWatch API calls.
+type mockCloudWatchClient struct {
+	mock.Mock
+}
+
+func (m *mockCloudWatchClient) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*cloudwatch.GetMetricStatisticsOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that mockCloudWatchClient implements the CloudWatchClient interface.
+var _ CloudWatchClient = (*mockCloudWatchClient)(nil)
+
+// TestGetMetricStatistics tests the GetMetricStatistics method of the CloudWatch Adapter.
+// It verifies that the adapter builds the request from the namespace, dimensions
+// and period, and returns the datapoints ordered by timestamp.
+func TestGetMetricStatistics(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockCloudWatchClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	endTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	startTime := endTime.Add(-3 * time.Hour)
+
+	// Set up expectations
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return aws.ToString(input.Namespace) == "AWS/EC2" &&
+			aws.ToString(input.MetricName) == "CPUUtilization" &&
+			len(input.Dimensions) == 1 &&
+			aws.ToString(input.Dimensions[0].Name) == "InstanceId" &&
+			aws.ToString(input.Dimensions[0].Value) == "i-12345" &&
+			aws.ToTime(input.StartTime).Equal(startTime) &&
+			aws.ToTime(input.EndTime).Equal(endTime) &&
+			aws.ToInt32(input.Period) == 3600 &&
+			len(input.Statistics) == 3
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []types.Datapoint{
+			{Timestamp: aws.Time(endTime.Add(-1 * time.Hour)), Average: aws.Float64(4), Maximum: aws.Float64(9)},
+			{Timestamp: aws.Time(startTime), Average: aws.Float64(2), Maximum: aws.Float64(5)},
+		},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	datapoints, err := adapter.GetMetricStatistics(ctx, "AWS/EC2", "CPUUtilization",
+		map[string]string{"InstanceId": "i-12345"}, startTime, endTime, time.Hour)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the datapoints are in order
+	assert.Equal(t, []Datapoint{
+		{Timestamp: startTime, Average: 2, Maximum: 5},
+		{Timestamp: endTime.Add(-1 * time.Hour), Average: 4, Maximum: 9},
+	}, datapoints)
+
+	// Set up expectations for an API error
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*cloudwatch.GetMetricStatisticsOutput)(nil), errors.New("access denied")).Once()
+
+	// Call the function
+	_, err = adapter.GetMetricStatistics(ctx, "AWS/EC2", "CPUUtilization",
+		map[string]string{"InstanceId": "i-12345"}, startTime, endTime, time.Hour)
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestGetResourceMetric tests the GetEC2InstanceMetric and GetLambdaFunctionMetric
+// methods of the CloudWatch Adapter. It verifies that each builds the namespace
+// and dimension identifying the resource.
+func TestGetResourceMetric(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockCloudWatchClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	endTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	startTime := endTime.Add(-3 * time.Hour)
+
+	// requestFor matches a request for a metric of a single resource
+	requestFor := func(namespace, metricName, dimension, value string) interface{} {
+		return mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+			return aws.ToString(input.Namespace) == namespace &&
+				aws.ToString(input.MetricName) == metricName &&
+				len(input.Dimensions) == 1 &&
+				aws.ToString(input.Dimensions[0].Name) == dimension &&
+				aws.ToString(input.Dimensions[0].Value) == value &&
+				aws.ToInt32(input.Period) == 300
+		})
+	}
+
+	// Set up expectations
+	mockClient.On("GetMetricStatistics", mock.Anything, requestFor("AWS/EC2", "NetworkIn", "InstanceId", "i-12345"), mock.Anything).
+		Return(&cloudwatch.GetMetricStatisticsOutput{
+			Datapoints: []types.Datapoint{{Timestamp: aws.Time(startTime), Average: aws.Float64(1024)}},
+		}, nil).Once()
+	mockClient.On("GetMetricStatistics", mock.Anything, requestFor("AWS/Lambda", "Invocations", "FunctionName", "my-function"), mock.Anything).
+		Return(&cloudwatch.GetMetricStatisticsOutput{
+			Datapoints: []types.Datapoint{{Timestamp: aws.Time(startTime), Sum: aws.Float64(42)}},
+		}, nil).Once()
+
+	ctx := context.Background()
+
+	// Call the function for an EC2 instance
+	datapoints, err := adapter.GetEC2InstanceMetric(ctx, "i-12345", "NetworkIn", startTime, endTime, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024.0, datapoints[0].Average)
+
+	// Call the function for a Lambda function
+	datapoints, err = adapter.GetLambdaFunctionMetric(ctx, "my-function", "Invocations", startTime, endTime, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 42.0, datapoints[0].Sum)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,266 @@
+// Package dynamodb provides functionality for interacting with Amazon DynamoDB tables.
+// It includes operations for listing tables, getting table details, and scanning
+// table items.
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBClient defines the interface for DynamoDB client operations.
+// This interface allows for easy mocking in tests.
+type DynamoDBClient interface {
+	ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// Adapter represents a DynamoDB service adapter that provides
+// higher-level operations for interacting with DynamoDB tables.
+type Adapter struct {
+	client DynamoDBClient // AWS DynamoDB client implementation
+}
+
+// Table represents a DynamoDB table with relevant information.
+// This is a simplified representation of the AWS table description
+// that includes only the most commonly used fields.
+type Table struct {
+	Name           string   // Name of the table
+	ARN            string   // Amazon Resource Name of the table
+	Status         string   // Table status (e.g., ACTIVE, CREATING)
+	PartitionKey   string   // Name of the partition (hash) key attribute
+	SortKey        string   // Name of the sort (range) key attribute, empty if none
+	BillingMode    string   // PROVISIONED or PAY_PER_REQUEST
+	ReadCapacity   int64    // Provisioned read capacity units (0 for on-demand tables)
+	WriteCapacity  int64    // Provisioned write capacity units (0 for on-demand tables)
+	ItemCount      int64    // Approximate number of items, updated about every six hours
+	SizeBytes      int64    // Approximate size of the table in bytes
+	CreationTime   string   // When the table was created
+	Indexes        []string // Names of the global and local secondary indexes
+	StreamsEnabled bool     // Whether DynamoDB Streams is enabled
+}
+
+// Item represents a DynamoDB item, with attribute values converted to plain
+// Go values: strings, numbers (int64 or float64), booleans, nil, []byte,
+// lists and maps. String, number and binary sets are converted to slices.
+type Item map[string]interface{}
+
+// NewAdapter creates a new DynamoDB adapter using the AWS credentials
+// from the current context configuration.
+//
+// The context is used for AWS client creation and configuration.
+// Returns an error if the AWS client cannot be created.
+func NewAdapter(ctx context.Context) (*Adapter, error) {
+	// Create AWS client
+	awsClient, err := client.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Create DynamoDB client
+	dynamoClient := dynamodb.NewFromConfig(awsClient.Config)
+
+	return &Adapter{
+		client: dynamoClient,
+	}, nil
+}
+
+// NewAdapterWithClient creates a new DynamoDB adapter with a provided client.
+// This is particularly useful for testing with mock clients.
+func NewAdapterWithClient(dynamoClient DynamoDBClient) *Adapter {
+	return &Adapter{
+		client: dynamoClient,
+	}
+}
+
+// ListTables lists the names of the DynamoDB tables in the current region.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - maxItems: Maximum number of tables to return (0 for no limit)
+//
+// Returns the table names in alphabetical order and an error if the operation fails.
+func (a *Adapter) ListTables(ctx context.Context, maxItems int32) ([]string, error) {
+	// Create the input for the ListTables API
+	input := &dynamodb.ListTablesInput{}
+
+	// Don't fetch larger pages than needed (the API returns at most 100 tables per page)
+	if maxItems > 0 {
+		input.Limit = aws.Int32(min(maxItems, 100))
+	}
+
+	// Create paginator
+	paginator := dynamodb.NewListTablesPaginator(a.client, input)
+
+	var tables []string
+
+	// Iterate through pages
+	for paginator.HasMorePages() && (maxItems == 0 || int32(len(tables)) < maxItems) {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list DynamoDB tables: %w", err)
+		}
+
+		for _, name := range output.TableNames {
+			// Skip if we've reached the maximum number of items
+			if maxItems > 0 && int32(len(tables)) >= maxItems {
+				break
+			}
+			tables = append(tables, name)
+		}
+	}
+
+	return tables, nil
+}
+
+// DescribeTable gets detailed information about a specific DynamoDB table.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - tableName: The name or ARN of the table
+//
+// Returns a pointer to a Table struct with the table details
+// or an error if the table cannot be found or retrieved.
+func (a *Adapter) DescribeTable(ctx context.Context, tableName string) (*Table, error) {
+	// Create the input for the DescribeTable API
+	input := &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}
+
+	// Call the DescribeTable API
+	output, err := a.client.DescribeTable(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe DynamoDB table %s: %w", tableName, err)
+	}
+	if output.Table == nil {
+		return nil, fmt.Errorf("DynamoDB table %s not found", tableName)
+	}
+
+	table := extractTableInfo(output.Table)
+	return &table, nil
+}
+
+// Scan reads items from a DynamoDB table.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - tableName: The name or ARN of the table
+//   - limit: Maximum number of items to return (0 for no limit)
+//
+// Returns the items in the order DynamoDB returned them and an error if the operation fails.
+func (a *Adapter) Scan(ctx context.Context, tableName string, limit int32) ([]Item, error) {
+	items, _, err := a.ScanPage(ctx, tableName, limit, "")
+	return items, err
+}
+
+// extractTableInfo extracts the relevant information from a DynamoDB table description
+func extractTableInfo(desc *types.TableDescription) Table {
+	table := Table{
+		Name:        aws.ToString(desc.TableName),
+		ARN:         aws.ToString(desc.TableArn),
+		Status:      string(desc.TableStatus),
+		ItemCount:   aws.ToInt64(desc.ItemCount),
+		SizeBytes:   aws.ToInt64(desc.TableSizeBytes),
+		BillingMode: string(types.BillingModeProvisioned),
+	}
+
+	// Tables created before on-demand billing existed have no billing mode summary
+	if desc.BillingModeSummary != nil && desc.BillingModeSummary.BillingMode != "" {
+		table.BillingMode = string(desc.BillingModeSummary.BillingMode)
+	}
+
+	if desc.ProvisionedThroughput != nil {
+		table.ReadCapacity = aws.ToInt64(desc.ProvisionedThroughput.ReadCapacityUnits)
+		table.WriteCapacity = aws.ToInt64(desc.ProvisionedThroughput.WriteCapacityUnits)
+	}
+
+	if desc.CreationDateTime != nil {
+		table.CreationTime = desc.CreationDateTime.Format("2006-01-02 15:04:05")
+	}
+
+	for _, key := range desc.KeySchema {
+		switch key.KeyType {
+		case types.KeyTypeHash:
+			table.PartitionKey = aws.ToString(key.AttributeName)
+		case types.KeyTypeRange:
+			table.SortKey = aws.ToString(key.AttributeName)
+		}
+	}
+
+	for _, index := range desc.GlobalSecondaryIndexes {
+		table.Indexes = append(table.Indexes, aws.ToString(index.IndexName))
+	}
+	for _, index := range desc.LocalSecondaryIndexes {
+		table.Indexes = append(table.Indexes, aws.ToString(index.IndexName))
+	}
+
+	if desc.StreamSpecification != nil {
+		table.StreamsEnabled = aws.ToBool(desc.StreamSpecification.StreamEnabled)
+	}
+
+	return table
+}
+
+// convertItem converts the attribute values of a DynamoDB item to plain Go values
+func convertItem(attributes map[string]types.AttributeValue) Item {
+	item := make(Item, len(attributes))
+	for name, value := range attributes {
+		item[name] = convertAttributeValue(value)
+	}
+	return item
+}
+
+// convertAttributeValue converts a DynamoDB attribute value to a plain Go value
+func convertAttributeValue(value types.AttributeValue) interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return convertNumber(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberB:
+		return v.Value
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]interface{}, 0, len(v.Value))
+		for _, n := range v.Value {
+			numbers = append(numbers, convertNumber(n))
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		return v.Value
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, element := range v.Value {
+			list = append(list, convertAttributeValue(element))
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		return map[string]interface{}(convertItem(v.Value))
+	default:
+		return nil
+	}
+}
+
+// convertNumber converts a DynamoDB number to an int64 or float64, keeping the
+// original string if it doesn't fit either type
+func convertNumber(value string) interface{} {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
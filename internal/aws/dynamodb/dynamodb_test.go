@@ -0,0 +1,361 @@
+// Package dynamodb provides tests for the DynamoDB adapter functionality.
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDynamoDBClient implements the DynamoDBClient interface for testing purposes.
+// It uses the testify/mock package to mock AWS DynamoDB API calls.
+type mockDynamoDBClient struct {
+	mock.Mock
+}
+
+func (m *mockDynamoDBClient) ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*dynamodb.ListTablesOutput), args.Error(1)
+}
+
+func (m *mockDynamoDBClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*dynamodb.DescribeTableOutput), args.Error(1)
+}
+
+func (m *mockDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*dynamodb.ScanOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that the mock client implements the interface.
+var _ DynamoDBClient = (*mockDynamoDBClient)(nil)
+
+// TestListTables tests the ListTables method of the DynamoDB Adapter.
+// It verifies that table names from all pages are returned.
+func TestListTables(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations for two pages
+	mockClient.On("ListTables", mock.Anything, mock.MatchedBy(func(input *dynamodb.ListTablesInput) bool {
+		return input.ExclusiveStartTableName == nil
+	}), mock.Anything).Return(&dynamodb.ListTablesOutput{
+		TableNames:             []string{"orders", "sessions"},
+		LastEvaluatedTableName: aws.String("sessions"),
+	}, nil).Once()
+	mockClient.On("ListTables", mock.Anything, mock.MatchedBy(func(input *dynamodb.ListTablesInput) bool {
+		return aws.ToString(input.ExclusiveStartTableName) == "sessions"
+	}), mock.Anything).Return(&dynamodb.ListTablesOutput{
+		TableNames: []string{"users"},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	tables, err := adapter.ListTables(ctx, 0)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert tables
+	assert.Equal(t, []string{"orders", "sessions", "users"}, tables)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListTablesLimit tests the ListTables method of the DynamoDB Adapter with a limit.
+// It verifies that the page size is capped to the limit and that no further
+// pages are fetched once the limit is reached.
+func TestListTablesLimit(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListTables", mock.Anything, mock.MatchedBy(func(input *dynamodb.ListTablesInput) bool {
+		return aws.ToInt32(input.Limit) == 1
+	}), mock.Anything).Return(&dynamodb.ListTablesOutput{
+		TableNames:             []string{"orders"},
+		LastEvaluatedTableName: aws.String("orders"),
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	tables, err := adapter.ListTables(ctx, 1)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert only the first page was fetched
+	assert.Equal(t, []string{"orders"}, tables)
+	mockClient.AssertNumberOfCalls(t, "ListTables", 1)
+}
+
+// TestDescribeTable tests the DescribeTable method of the DynamoDB Adapter.
+// It verifies that the keys, capacity, indexes and stream settings are
+// extracted from the table description.
+func TestDescribeTable(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Set up expectations
+	mockClient.On("DescribeTable", mock.Anything, mock.MatchedBy(func(input *dynamodb.DescribeTableInput) bool {
+		return aws.ToString(input.TableName) == "orders"
+	}), mock.Anything).Return(&dynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{
+			TableName:   aws.String("orders"),
+			TableArn:    aws.String("arn:aws:dynamodb:us-east-1:123456789012:table/orders"),
+			TableStatus: types.TableStatusActive,
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("customerId"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("orderId"), KeyType: types.KeyTypeRange},
+			},
+			BillingModeSummary: &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+			ItemCount:          aws.Int64(1200),
+			TableSizeBytes:     aws.Int64(524288),
+			CreationDateTime:   &created,
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+				{IndexName: aws.String("by-status")},
+			},
+			StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true)},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	table, err := adapter.DescribeTable(ctx, "orders")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert table details
+	assert.Equal(t, "orders", table.Name)
+	assert.Equal(t, "ACTIVE", table.Status)
+	assert.Equal(t, "customerId", table.PartitionKey)
+	assert.Equal(t, "orderId", table.SortKey)
+	assert.Equal(t, "PAY_PER_REQUEST", table.BillingMode)
+	assert.Equal(t, int64(1200), table.ItemCount)
+	assert.Equal(t, int64(524288), table.SizeBytes)
+	assert.Equal(t, "2024-03-01 12:00:00", table.CreationTime)
+	assert.Equal(t, []string{"by-status"}, table.Indexes)
+	assert.True(t, table.StreamsEnabled)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDescribeTableError tests the DescribeTable method of the DynamoDB Adapter
+// when the API call fails. It verifies that the error names the table.
+func TestDescribeTableError(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeTable", mock.Anything, mock.Anything, mock.Anything).
+		Return((*dynamodb.DescribeTableOutput)(nil), errors.New("ResourceNotFoundException"))
+
+	// Call the function
+	ctx := context.Background()
+	table, err := adapter.DescribeTable(ctx, "missing")
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Nil(t, table)
+	assert.Contains(t, err.Error(), "missing")
+}
+
+// TestScan tests the Scan method of the DynamoDB Adapter.
+// It verifies that attribute values are converted to plain Go values and
+// that scanning stops once the limit is reached.
+func TestScan(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("Scan", mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return aws.ToString(input.TableName) == "orders" && aws.ToInt32(input.Limit) == 2
+	}), mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"orderId": &types.AttributeValueMemberS{Value: "o-1"},
+				"total":   &types.AttributeValueMemberN{Value: "42"},
+				"price":   &types.AttributeValueMemberN{Value: "9.99"},
+				"paid":    &types.AttributeValueMemberBOOL{Value: true},
+				"note":    &types.AttributeValueMemberNULL{Value: true},
+				"tags":    &types.AttributeValueMemberSS{Value: []string{"gift", "express"}},
+				"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"city": &types.AttributeValueMemberS{Value: "Berlin"},
+				}},
+				"lines": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberN{Value: "1"},
+					&types.AttributeValueMemberS{Value: "two"},
+				}},
+			},
+			{
+				"orderId": &types.AttributeValueMemberS{Value: "o-2"},
+			},
+		},
+		LastEvaluatedKey: map[string]types.AttributeValue{
+			"orderId": &types.AttributeValueMemberS{Value: "o-2"},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	items, err := adapter.Scan(ctx, "orders", 2)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert items
+	assert.Len(t, items, 2)
+	assert.Equal(t, "o-1", items[0]["orderId"])
+	assert.Equal(t, int64(42), items[0]["total"])
+	assert.Equal(t, 9.99, items[0]["price"])
+	assert.Equal(t, true, items[0]["paid"])
+	assert.Nil(t, items[0]["note"])
+	assert.Equal(t, []string{"gift", "express"}, items[0]["tags"])
+	assert.Equal(t, map[string]interface{}{"city": "Berlin"}, items[0]["address"])
+	assert.Equal(t, []interface{}{int64(1), "two"}, items[0]["lines"])
+	assert.Equal(t, Item{"orderId": "o-2"}, items[1])
+
+	// Assert no further pages were fetched
+	mockClient.AssertNumberOfCalls(t, "Scan", 1)
+}
+
+// TestScanPage tests the ScanPage method of the DynamoDB Adapter.
+// It verifies that a scan resumes after the key of the starting token, reads
+// no more items than the limit across pages and returns a token for the rest.
+func TestScanPage(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	startKey := map[string]types.AttributeValue{"orderId": &types.AttributeValueMemberS{Value: "o-2"}}
+	token, err := EncodeStartingToken(startKey)
+	require.NoError(t, err)
+
+	// Set up expectations
+	mockClient.On("Scan", mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return aws.ToInt32(input.Limit) == 3 && assert.ObjectsAreEqual(startKey, input.ExclusiveStartKey)
+	}), mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{"orderId": &types.AttributeValueMemberS{Value: "o-3"}},
+		},
+		LastEvaluatedKey: map[string]types.AttributeValue{"orderId": &types.AttributeValueMemberS{Value: "o-3"}},
+	}, nil).Once()
+	mockClient.On("Scan", mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return aws.ToInt32(input.Limit) == 2 && input.ExclusiveStartKey != nil
+	}), mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{"orderId": &types.AttributeValueMemberS{Value: "o-4"}},
+			{"orderId": &types.AttributeValueMemberS{Value: "o-5"}},
+		},
+		LastEvaluatedKey: map[string]types.AttributeValue{"orderId": &types.AttributeValueMemberS{Value: "o-5"}},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	items, next, err := adapter.ScanPage(ctx, "orders", 3, token)
+
+	// Assert no error
+	require.NoError(t, err)
+
+	// Assert items and the token of the next page
+	assert.Equal(t, []Item{{"orderId": "o-3"}, {"orderId": "o-4"}, {"orderId": "o-5"}}, items)
+	nextKey, err := DecodeStartingToken(next)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{"orderId": &types.AttributeValueMemberS{Value: "o-5"}}, nextKey)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+
+	// An invalid token is rejected before the table is scanned
+	_, _, err = adapter.ScanPage(ctx, "orders", 3, "not a token")
+	assert.EqualError(t, err, "invalid starting token")
+	mockClient.AssertNumberOfCalls(t, "Scan", 2)
+}
+
+// TestScanPageEnd tests that ScanPage returns no token once the whole table
+// has been read.
+func TestScanPageEnd(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockDynamoDBClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("Scan", mock.Anything, mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{"orderId": &types.AttributeValueMemberS{Value: "o-1"}},
+		},
+	}, nil)
+
+	// Call the function
+	items, next, err := adapter.ScanPage(context.Background(), "orders", 25, "")
+
+	// Assert no error
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Empty(t, next)
+}
+
+// TestStartingToken tests encoding and decoding starting tokens.
+// It verifies that keys of every key attribute type round-trip.
+func TestStartingToken(t *testing.T) {
+	key := map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "customer#1"},
+		"sk": &types.AttributeValueMemberN{Value: "1700000000"},
+		"id": &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+	}
+
+	token, err := EncodeStartingToken(key)
+	require.NoError(t, err)
+	assert.NotContains(t, token, "=")
+
+	decoded, err := DecodeStartingToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, key, decoded)
+
+	// Empty keys and tokens stand for the start of the table
+	token, err = EncodeStartingToken(nil)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	decoded, err = DecodeStartingToken("")
+	require.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	// Tokens without key attributes are invalid
+	_, err = DecodeStartingToken("e30")
+	assert.Error(t, err)
+	_, err = DecodeStartingToken("eyJwayI6e319")
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ScanPage reads items from a DynamoDB table, starting where an earlier scan
+// stopped, so that a large table can be read over several calls.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - tableName: The name or ARN of the table
+//   - limit: Maximum number of items to return (0 for no limit)
+//   - startingToken: Token returned by an earlier scan of the table, or empty to
+//     start at the beginning
+//
+// Returns the items in the order DynamoDB returned them, a token to pass to the
+// next call (empty if the whole table has been read) and an error if the token
+// is invalid or the operation fails.
+func (a *Adapter) ScanPage(ctx context.Context, tableName string, limit int32, startingToken string) ([]Item, string, error) {
+	startKey, err := DecodeStartingToken(startingToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var items []Item
+	for limit == 0 || int32(len(items)) < limit {
+		input := &dynamodb.ScanInput{
+			TableName:         aws.String(tableName),
+			ExclusiveStartKey: startKey,
+		}
+
+		// Don't read more items than needed, so that the last evaluated key
+		// of the page is where the next scan has to resume
+		if limit > 0 {
+			input.Limit = aws.Int32(limit - int32(len(items)))
+		}
+
+		output, err := a.client.Scan(ctx, input)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan DynamoDB table %s: %w", tableName, err)
+		}
+
+		for _, attributes := range output.Items {
+			items = append(items, convertItem(attributes))
+		}
+
+		startKey = output.LastEvaluatedKey
+		if len(startKey) == 0 {
+			return items, "", nil
+		}
+	}
+
+	token, err := EncodeStartingToken(startKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return items, token, nil
+}
+
+// tokenAttribute is a key attribute value in a starting token. Key attributes
+// are always strings, numbers or binary values.
+type tokenAttribute struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// EncodeStartingToken encodes the last evaluated key of a scan as an opaque
+// token that can be passed on the command line.
+//
+// Returns an empty token for an empty key, and an error if the key has an
+// attribute that is not a string, number or binary value.
+func EncodeStartingToken(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	attributes := make(map[string]tokenAttribute, len(key))
+	for name, value := range key {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			attributes[name] = tokenAttribute{S: aws.String(v.Value)}
+		case *types.AttributeValueMemberN:
+			attributes[name] = tokenAttribute{N: aws.String(v.Value)}
+		case *types.AttributeValueMemberB:
+			attributes[name] = tokenAttribute{B: v.Value}
+		default:
+			return "", fmt.Errorf("unsupported type of key attribute %s", name)
+		}
+	}
+
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode starting token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeStartingToken decodes a token made by EncodeStartingToken back into
+// the key a scan resumes after.
+//
+// Returns nil for an empty token, and an error if the token is invalid.
+func DecodeStartingToken(token string) (map[string]types.AttributeValue, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	errInvalid := errors.New("invalid starting token")
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalid
+	}
+	var attributes map[string]tokenAttribute
+	if err := json.Unmarshal(data, &attributes); err != nil || len(attributes) == 0 {
+		return nil, errInvalid
+	}
+
+	key := make(map[string]types.AttributeValue, len(attributes))
+	for name, attribute := range attributes {
+		switch {
+		case attribute.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *attribute.S}
+		case attribute.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *attribute.N}
+		case attribute.B != nil:
+			key[name] = &types.AttributeValueMemberB{Value: attribute.B}
+		default:
+			return nil, errInvalid
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,155 @@
+package ec2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Reservation represents active EC2 Reserved Instances: a number of instances
+// of one type whose usage is billed at the reserved rate.
+type Reservation struct {
+	ID           string // Reserved Instances ID
+	InstanceType string // Instance type (e.g., m5.large)
+	AZ           string // Availability Zone of a zonal reservation (empty for a regional one)
+	Count        int    // Number of instances covered
+	End          string // When the reservation expires (formatted)
+}
+
+// InstanceCoverage tells whether a running instance is covered by a reservation
+// or billed at the on-demand rate.
+type InstanceCoverage struct {
+	ID          string // EC2 instance ID (i-xxxxxxxx)
+	Name        string // Name tag value if available
+	Type        string // Instance type (e.g., t2.micro)
+	AZ          string // Availability Zone
+	Covered     bool   // Whether a reservation covers the instance
+	Reservation string // ID of the covering reservation, empty for on-demand usage
+}
+
+// ListReservations lists the active Reserved Instances of the current region.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//
+// Returns a slice of Reservation structs and an error if the operation fails.
+func (a *Adapter) ListReservations(ctx context.Context) ([]Reservation, error) {
+	output, err := a.client.DescribeReservedInstances(ctx, &ec2.DescribeReservedInstancesInput{
+		Filters: []types.Filter{CreateFilter("state", string(types.ReservedInstanceStateActive))},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe reserved instances: %w", err)
+	}
+
+	reservations := make([]Reservation, 0, len(output.ReservedInstances))
+	for _, ri := range output.ReservedInstances {
+		reservation := Reservation{
+			ID:           aws.ToString(ri.ReservedInstancesId),
+			InstanceType: string(ri.InstanceType),
+			Count:        int(aws.ToInt32(ri.InstanceCount)),
+		}
+		if ri.Scope == types.ScopeAvailabilityZone {
+			reservation.AZ = aws.ToString(ri.AvailabilityZone)
+		}
+		if ri.End != nil {
+			reservation.End = ri.End.Format("2006-01-02 15:04:05")
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+// ReadReservations reads reservations from a JSON array of Reservation records,
+// for reservations that awsm cannot list itself, such as those bought in
+// another account of an organization. Only InstanceType and Count are required;
+// a reservation without AZ is regional.
+//
+// Returns an error if the JSON is invalid or a reservation has no instance type
+// or a negative count.
+func ReadReservations(r io.Reader) ([]Reservation, error) {
+	var reservations []Reservation
+	if err := json.NewDecoder(r).Decode(&reservations); err != nil {
+		return nil, fmt.Errorf("failed to read reservations: %w", err)
+	}
+
+	for i, reservation := range reservations {
+		if reservation.InstanceType == "" {
+			return nil, fmt.Errorf("reservation %d has no InstanceType", i+1)
+		}
+		if reservation.Count < 0 {
+			return nil, fmt.Errorf("reservation %d has a negative Count", i+1)
+		}
+	}
+
+	return reservations, nil
+}
+
+// Coverage matches running instances against reservations, the way EC2 applies
+// them to usage: zonal reservations cover instances of their type in their
+// Availability Zone first, then regional reservations cover instances of their
+// type in any zone. Each reservation covers at most Count instances.
+//
+// Instance size flexibility of regional reservations and the platform
+// (Linux, Windows) are not taken into account, so an instance flagged as not
+// covered may still be billed at a reserved rate.
+//
+// Parameters:
+//   - instances: The running instances
+//   - reservations: The active reservations of the same region
+//
+// Returns the coverage of each instance, in the order of instances.
+func Coverage(instances []Instance, reservations []Reservation) []InstanceCoverage {
+	// Remaining capacity of each reservation
+	remaining := make([]int, len(reservations))
+	for i, reservation := range reservations {
+		remaining[i] = reservation.Count
+	}
+
+	// reserve uses up one instance of a zonal or regional reservation with
+	// capacity left that matches the instance, and returns its index
+	reserve := func(inst Instance, zonal bool) (int, bool) {
+		for i, reservation := range reservations {
+			if remaining[i] == 0 || reservation.InstanceType != inst.Type || (reservation.AZ != "") != zonal {
+				continue
+			}
+			if zonal && reservation.AZ != inst.AZ {
+				continue
+			}
+			remaining[i]--
+			return i, true
+		}
+		return 0, false
+	}
+
+	coverage := make([]InstanceCoverage, len(instances))
+	for i, inst := range instances {
+		coverage[i] = InstanceCoverage{
+			ID:   inst.ID,
+			Name: inst.Name,
+			Type: inst.Type,
+			AZ:   inst.AZ,
+		}
+	}
+
+	// Apply all zonal reservations before the regional ones, so that a regional
+	// reservation isn't used up by an instance a zonal one would have covered
+	for _, zonal := range []bool{true, false} {
+		for i, inst := range instances {
+			if coverage[i].Covered {
+				continue
+			}
+			if r, ok := reserve(inst, zonal); ok {
+				coverage[i].Covered = true
+				coverage[i].Reservation = reservations[r].ID
+			}
+		}
+	}
+
+	return coverage
+}
@@ -1,12 +1,16 @@
 // Package ec2 provides functionality for interacting with AWS EC2 instances.
-// It includes operations for listing, describing, starting, and stopping EC2 instances.
+// It includes operations for listing, describing, starting, stopping, and rebooting EC2 instances.
 package ec2
 
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ao/awsm/internal/aws/client"
+	"github.com/ao/awsm/internal/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -18,12 +22,26 @@ type EC2Client interface {
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 	StartInstances(ctx context.Context, params *ec2.StartInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error)
 	StopInstances(ctx context.Context, params *ec2.StopInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error)
+	RebootInstances(ctx context.Context, params *ec2.RebootInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RebootInstancesOutput, error)
+	CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	DescribeKeyPairs(ctx context.Context, params *ec2.DescribeKeyPairsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeKeyPairsOutput, error)
+	DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+	DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+	DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)
+	DescribeReservedInstances(ctx context.Context, params *ec2.DescribeReservedInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeReservedInstancesOutput, error)
+	DescribeInstanceStatus(ctx context.Context, params *ec2.DescribeInstanceStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error)
 }
 
 // Adapter represents an EC2 service adapter that provides
 // higher-level operations for interacting with EC2 instances.
 type Adapter struct {
 	client EC2Client // AWS EC2 client implementation
+
+	specsMu   sync.Mutex                  // Guards specCache
+	specCache map[string]InstanceTypeSpec // Instance type specs already looked up, by type name
 }
 
 // Instance represents an EC2 instance with relevant information.
@@ -36,12 +54,53 @@ type Instance struct {
 	State       string            // Current state (running, stopped, etc.)
 	PublicIP    string            // Public IP address if available
 	PrivateIP   string            // Private IP address
-	LaunchTime  string            // When the instance was launched (formatted)
+	LaunchTime  string            // When the instance was launched (formatted, UTC)
 	AZ          string            // Availability Zone
 	VpcID       string            // VPC ID
 	SubnetID    string            // Subnet ID
 	Tags        map[string]string // All instance tags
 	SecurityIDs []string          // Security group IDs
+	ElasticIPs  []ElasticIP       // Elastic IPs associated with the instance (set by DescribeInstance/DescribeInstances)
+}
+
+// ElasticIP represents an Elastic IP address associated with an EC2 instance.
+type ElasticIP struct {
+	PublicIP      string // Elastic IP address
+	AllocationID  string // Allocation ID (eipalloc-xxxxxxxx)
+	AssociationID string // Association ID (eipassoc-xxxxxxxx)
+	PrivateIP     string // Private IP address the Elastic IP is associated with
+}
+
+// AZSummary represents the number of instances in a single availability zone.
+type AZSummary struct {
+	AZ         string  // Availability Zone
+	Count      int     // Number of instances in the Availability Zone
+	Percentage float64 // Share of all summarized instances, in percent
+}
+
+// InstanceTypeSpec represents the hardware specification of an EC2 instance type.
+type InstanceTypeSpec struct {
+	Type      string // Instance type (e.g., t3.micro)
+	VCPUs     int32  // Default number of vCPUs
+	MemoryMiB int64  // Memory size in MiB
+}
+
+// InstanceWithSpecs represents an EC2 instance together with the
+// vCPU and memory of its instance type.
+type InstanceWithSpecs struct {
+	Instance  `yaml:",inline"`
+	VCPUs     int32 // Default number of vCPUs of the instance type
+	MemoryMiB int64 // Memory size of the instance type in MiB
+}
+
+// KeyPair represents an EC2 key pair.
+type KeyPair struct {
+	Name        string            // Name of the key pair
+	ID          string            // Key pair ID (key-xxxxxxxx)
+	Type        string            // Key type (rsa or ed25519)
+	Fingerprint string            // Fingerprint of the key
+	CreateTime  string            // When the key pair was created (formatted)
+	Tags        map[string]string // All key pair tags
 }
 
 // NewAdapter creates a new EC2 adapter using the AWS credentials
@@ -89,6 +148,11 @@ func (a *Adapter) ListInstances(ctx context.Context, filters []types.Filter, max
 		input.Filters = filters
 	}
 
+	// Don't fetch larger pages than needed (the API accepts 5 to 1000 results per page)
+	if maxItems > 0 {
+		input.MaxResults = aws.Int32(min(max(maxItems, 5), 1000))
+	}
+
 	// Call the DescribeInstances API
 	paginator := ec2.NewDescribeInstancesPaginator(a.client, input)
 
@@ -149,7 +213,118 @@ func (a *Adapter) DescribeInstance(ctx context.Context, instanceID string) (*Ins
 	// Extract instance information
 	instance := extractInstanceInfo(output.Reservations[0].Instances[0])
 
-	return &instance, nil
+	// Add the Elastic IPs associated with the instance
+	instances := []Instance{instance}
+	a.addElasticIPs(ctx, instances)
+
+	return &instances[0], nil
+}
+
+// DescribeInstances gets detailed information about several EC2 instances
+// in a single API call.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instanceIDs: The IDs of the EC2 instances to describe
+//
+// Returns a slice of Instance structs in the order of the given IDs
+// or an error if any of the instances cannot be found or described.
+func (a *Adapter) DescribeInstances(ctx context.Context, instanceIDs []string) ([]Instance, error) {
+	if len(instanceIDs) == 0 {
+		return []Instance{}, nil
+	}
+
+	// Create the input for the DescribeInstances API
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	}
+
+	// Collect the instances from all pages, keyed by ID
+	found := make(map[string]Instance, len(instanceIDs))
+	paginator := ec2.NewDescribeInstancesPaginator(a.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe EC2 instances %s: %w", strings.Join(instanceIDs, ", "), err)
+		}
+
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				inst := extractInstanceInfo(instance)
+				found[inst.ID] = inst
+			}
+		}
+	}
+
+	// Return the instances in the requested order
+	instances := make([]Instance, 0, len(instanceIDs))
+	var missing []string
+	for _, id := range instanceIDs {
+		inst, ok := found[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		instances = append(instances, inst)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("EC2 instances not found: %s", strings.Join(missing, ", "))
+	}
+
+	// Add the Elastic IPs associated with the instances
+	a.addElasticIPs(ctx, instances)
+
+	return instances, nil
+}
+
+// addElasticIPs looks up the Elastic IPs associated with the given instances
+// in a single DescribeAddresses call and sets their ElasticIPs field. The
+// Elastic IPs are optional detail, so when the lookup fails (for example when
+// ec2:DescribeAddresses is denied) a warning is logged and the instances are
+// left without them.
+func (a *Adapter) addElasticIPs(ctx context.Context, instances []Instance) {
+	if len(instances) == 0 {
+		return
+	}
+
+	instanceIDs := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		instanceIDs = append(instanceIDs, inst.ID)
+	}
+
+	// Create the input for the DescribeAddresses API
+	input := &ec2.DescribeAddressesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs,
+			},
+		},
+	}
+
+	// Call the DescribeAddresses API
+	output, err := a.client.DescribeAddresses(ctx, input)
+	if err != nil {
+		logger.Warn("Failed to describe Elastic IPs for EC2 instances %s: %v", strings.Join(instanceIDs, ", "), err)
+		return
+	}
+
+	// Group the addresses by instance
+	byInstance := make(map[string][]ElasticIP)
+	for _, address := range output.Addresses {
+		instanceID := aws.ToString(address.InstanceId)
+		byInstance[instanceID] = append(byInstance[instanceID], ElasticIP{
+			PublicIP:      aws.ToString(address.PublicIp),
+			AllocationID:  aws.ToString(address.AllocationId),
+			AssociationID: aws.ToString(address.AssociationId),
+			PrivateIP:     aws.ToString(address.PrivateIpAddress),
+		})
+	}
+
+	for i := range instances {
+		instances[i].ElasticIPs = byInstance[instances[i].ID]
+	}
 }
 
 // StartInstance starts an EC2 instance.
@@ -196,6 +371,272 @@ func (a *Adapter) StopInstance(ctx context.Context, instanceID string) error {
 	return nil
 }
 
+// RebootInstance reboots an EC2 instance.
+// The reboot is queued by AWS; the instance stays in the running state.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instanceID: The ID of the EC2 instance to reboot
+//
+// Returns an error if the instance cannot be rebooted.
+func (a *Adapter) RebootInstance(ctx context.Context, instanceID string) error {
+	// Create the input for the RebootInstances API
+	input := &ec2.RebootInstancesInput{
+		InstanceIds: []string{instanceID},
+	}
+
+	// Call the RebootInstances API
+	_, err := a.client.RebootInstances(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to reboot EC2 instance %s: %w", instanceID, err)
+	}
+
+	return nil
+}
+
+// RenameInstance sets the name of an EC2 instance by setting its name tag,
+// replacing the tag if it already exists.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instanceID: The ID of the EC2 instance to rename
+//   - nameTag: The tag holding the instance name (usually DefaultNameTag)
+//   - name: The new name of the instance
+//
+// Returns an error if the tag cannot be set.
+func (a *Adapter) RenameInstance(ctx context.Context, instanceID, nameTag, name string) error {
+	// Create the input for the CreateTags API
+	input := &ec2.CreateTagsInput{
+		Resources: []string{instanceID},
+		Tags: []types.Tag{
+			{Key: aws.String(nameTag), Value: aws.String(name)},
+		},
+	}
+
+	// Call the CreateTags API
+	_, err := a.client.CreateTags(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to rename EC2 instance %s: %w", instanceID, err)
+	}
+
+	return nil
+}
+
+// ListKeyPairs lists the EC2 key pairs in the current region.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//
+// Returns a slice of KeyPair structs sorted by name and an error if the operation fails.
+func (a *Adapter) ListKeyPairs(ctx context.Context) ([]KeyPair, error) {
+	// Create the input for the DescribeKeyPairs API
+	input := &ec2.DescribeKeyPairsInput{}
+
+	// Call the DescribeKeyPairs API
+	output, err := a.client.DescribeKeyPairs(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EC2 key pairs: %w", err)
+	}
+
+	// Extract key pair information
+	keyPairs := make([]KeyPair, 0, len(output.KeyPairs))
+	for _, keyPair := range output.KeyPairs {
+		kp := KeyPair{
+			Name:        aws.ToString(keyPair.KeyName),
+			ID:          aws.ToString(keyPair.KeyPairId),
+			Type:        string(keyPair.KeyType),
+			Fingerprint: aws.ToString(keyPair.KeyFingerprint),
+			Tags:        make(map[string]string),
+		}
+
+		// Format create time if available
+		if keyPair.CreateTime != nil {
+			kp.CreateTime = keyPair.CreateTime.Format("2006-01-02 15:04:05")
+		}
+
+		// Extract tags
+		for _, tag := range keyPair.Tags {
+			kp.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+
+		keyPairs = append(keyPairs, kp)
+	}
+
+	// Sort key pairs by name
+	sort.Slice(keyPairs, func(i, j int) bool {
+		return keyPairs[i].Name < keyPairs[j].Name
+	})
+
+	return keyPairs, nil
+}
+
+// GetInstanceTypeSpecs looks up the vCPU and memory specification of instance types.
+// Results are cached on the adapter, so each instance type is only described once.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instanceTypes: The instance types to look up (duplicates are ignored)
+//
+// Returns a map of instance type to InstanceTypeSpec and an error if the operation fails.
+func (a *Adapter) GetInstanceTypeSpecs(ctx context.Context, instanceTypes []string) (map[string]InstanceTypeSpec, error) {
+	a.specsMu.Lock()
+	defer a.specsMu.Unlock()
+
+	if a.specCache == nil {
+		a.specCache = make(map[string]InstanceTypeSpec)
+	}
+
+	// Collect the instance types that haven't been looked up yet
+	var missing []types.InstanceType
+	seen := make(map[string]bool)
+	for _, instanceType := range instanceTypes {
+		if _, cached := a.specCache[instanceType]; cached || seen[instanceType] || instanceType == "" {
+			continue
+		}
+		seen[instanceType] = true
+		missing = append(missing, types.InstanceType(instanceType))
+	}
+
+	// DescribeInstanceTypes accepts at most 100 instance types per request
+	const batchSize = 100
+	for start := 0; start < len(missing); start += batchSize {
+		// Create the input for the DescribeInstanceTypes API
+		input := &ec2.DescribeInstanceTypesInput{
+			InstanceTypes: missing[start:min(start+batchSize, len(missing))],
+		}
+
+		// Call the DescribeInstanceTypes API, following pagination
+		paginator := ec2.NewDescribeInstanceTypesPaginator(a.client, input)
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe EC2 instance types: %w", err)
+			}
+
+			for _, info := range output.InstanceTypes {
+				spec := InstanceTypeSpec{Type: string(info.InstanceType)}
+				if info.VCpuInfo != nil {
+					spec.VCPUs = aws.ToInt32(info.VCpuInfo.DefaultVCpus)
+				}
+				if info.MemoryInfo != nil {
+					spec.MemoryMiB = aws.ToInt64(info.MemoryInfo.SizeInMiB)
+				}
+				a.specCache[spec.Type] = spec
+			}
+		}
+	}
+
+	// Return the requested specs from the cache
+	specs := make(map[string]InstanceTypeSpec)
+	for _, instanceType := range instanceTypes {
+		if spec, ok := a.specCache[instanceType]; ok {
+			specs[instanceType] = spec
+		}
+	}
+
+	return specs, nil
+}
+
+// AddInstanceSpecs enriches instances with the vCPU and memory of their instance type.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - instances: The instances to enrich
+//
+// Returns a slice of InstanceWithSpecs in the same order as instances and an error
+// if the instance types cannot be described. Instances of an unknown type have
+// zero specs.
+func (a *Adapter) AddInstanceSpecs(ctx context.Context, instances []Instance) ([]InstanceWithSpecs, error) {
+	instanceTypes := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		instanceTypes = append(instanceTypes, instance.Type)
+	}
+
+	specs, err := a.GetInstanceTypeSpecs(ctx, instanceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InstanceWithSpecs, 0, len(instances))
+	for _, instance := range instances {
+		spec := specs[instance.Type]
+		result = append(result, InstanceWithSpecs{
+			Instance:  instance,
+			VCPUs:     spec.VCPUs,
+			MemoryMiB: spec.MemoryMiB,
+		})
+	}
+
+	return result, nil
+}
+
+// GetAZSummary groups running EC2 instances by availability zone.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//
+// Returns a slice of AZSummary structs sorted by availability zone
+// and an error if the instances cannot be listed.
+func (a *Adapter) GetAZSummary(ctx context.Context) ([]AZSummary, error) {
+	// Only running instances contribute to AZ capacity
+	filters := []types.Filter{CreateFilter("instance-state-name", "running")}
+
+	instances, err := a.ListInstances(ctx, filters, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByAZ(instances), nil
+}
+
+// SummarizeByAZ counts the given instances per availability zone.
+//
+// Returns a slice of AZSummary structs sorted by availability zone.
+func SummarizeByAZ(instances []Instance) []AZSummary {
+	counts := make(map[string]int)
+	for _, instance := range instances {
+		counts[instance.AZ]++
+	}
+
+	summaries := make([]AZSummary, 0, len(counts))
+	for az, count := range counts {
+		summaries = append(summaries, AZSummary{
+			AZ:         az,
+			Count:      count,
+			Percentage: float64(count) * 100 / float64(len(instances)),
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].AZ < summaries[j].AZ
+	})
+
+	return summaries
+}
+
+// DefaultNameTag is the tag holding the name of an instance
+const DefaultNameTag = "Name"
+
+// displayIDLength is how much of the instance ID DisplayName shows for an
+// instance without a name
+const displayIDLength = 10
+
+// DisplayName returns the name to show for the instance: the value of the tag
+// nameTag (DefaultNameTag if empty), or the start of its ID if it has no such tag.
+func (i Instance) DisplayName(nameTag string) string {
+	if nameTag == "" {
+		nameTag = DefaultNameTag
+	}
+	if name := i.Tags[nameTag]; name != "" {
+		return name
+	}
+
+	if len(i.ID) <= displayIDLength {
+		return i.ID
+	}
+	return i.ID[:displayIDLength] + "…"
+}
+
 // extractInstanceInfo extracts relevant information from an EC2 instance
 // and converts it to our simplified Instance struct.
 //
@@ -223,7 +664,7 @@ func extractInstanceInfo(instance types.Instance) Instance {
 
 	// Extract launch time if available
 	if instance.LaunchTime != nil {
-		inst.LaunchTime = instance.LaunchTime.Format("2006-01-02 15:04:05")
+		inst.LaunchTime = instance.LaunchTime.UTC().Format("2006-01-02 15:04:05")
 	}
 
 	// Extract tags
@@ -260,3 +701,17 @@ func CreateFilter(name string, values ...string) types.Filter {
 		Values: values,
 	}
 }
+
+// ParseFilter parses a filter expression of the form "name=value1,value2"
+// (e.g., "instance-state-name=running" or "tag:Environment=Production")
+// into an EC2 filter.
+//
+// Returns an error if the expression has no name or no values.
+func ParseFilter(expr string) (types.Filter, error) {
+	name, values, found := strings.Cut(expr, "=")
+	if !found || name == "" || values == "" {
+		return types.Filter{}, fmt.Errorf("invalid filter %q (expected name=value[,value...])", expr)
+	}
+
+	return CreateFilter(name, strings.Split(values, ",")...), nil
+}
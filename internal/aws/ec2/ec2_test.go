@@ -3,12 +3,16 @@ package ec2
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ao/awsm/internal/aws/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -34,9 +38,74 @@ func (m *mockEC2Client) StopInstances(ctx context.Context, params *ec2.StopInsta
 	return args.Get(0).(*ec2.StopInstancesOutput), args.Error(1)
 }
 
+func (m *mockEC2Client) RebootInstances(ctx context.Context, params *ec2.RebootInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RebootInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.RebootInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.CreateTagsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeKeyPairs(ctx context.Context, params *ec2.DescribeKeyPairsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeKeyPairsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeKeyPairsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeInstanceTypesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeAddressesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeVpcsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeSubnetsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeSecurityGroupsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeVolumesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeReservedInstances(ctx context.Context, params *ec2.DescribeReservedInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeReservedInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeReservedInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeInstanceStatus(ctx context.Context, params *ec2.DescribeInstanceStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ec2.DescribeInstanceStatusOutput), args.Error(1)
+}
+
 // This static assertion verifies at compile time that mockEC2Client implements the EC2Client interface.
 var _ EC2Client = (*mockEC2Client)(nil)
 
+// mockMetricsGetter implements the MetricsGetter interface for testing purposes.
+type mockMetricsGetter struct {
+	mock.Mock
+}
+
+func (m *mockMetricsGetter) GetMetricStatistics(ctx context.Context, namespace, metricName string, dimensions map[string]string, startTime, endTime time.Time, period time.Duration) ([]cloudwatch.Datapoint, error) {
+	args := m.Called(ctx, namespace, metricName, dimensions, startTime, endTime, period)
+	return args.Get(0).([]cloudwatch.Datapoint), args.Error(1)
+}
+
 // createMockInstance is a helper function that creates a mock EC2 instance with the specified parameters.
 // This function simplifies the creation of test data for EC2 instance tests.
 //
@@ -203,6 +272,44 @@ func TestListInstances(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestListInstancesLimit tests the ListInstances method of the EC2 Adapter with a limit.
+// It verifies that the page size is capped to the limit and that no further
+// pages are fetched once the limit is reached.
+func TestListInstancesLimit(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock response with more pages available
+	mockResponse := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{Instances: []types.Instance{
+				createMockInstance("i-1", "one", "t2.micro", "running", "", "10.0.0.1", "us-east-1a", "vpc-1", "subnet-1", nil),
+				createMockInstance("i-2", "two", "t2.micro", "running", "", "10.0.0.2", "us-east-1a", "vpc-1", "subnet-1", nil),
+			}},
+		},
+		NextToken: aws.String("next"),
+	}
+
+	// Set up expectations (the API accepts no fewer than 5 results per page)
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return aws.ToInt32(input.MaxResults) == 5
+	}), mock.Anything).Return(mockResponse, nil)
+
+	// Call the function
+	ctx := context.Background()
+	instances, err := adapter.ListInstances(ctx, nil, 2)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert only the first page was fetched
+	assert.Len(t, instances, 2)
+	mockClient.AssertNumberOfCalls(t, "DescribeInstances", 1)
+}
+
 // TestDescribeInstance tests the DescribeInstance method of the EC2 Adapter.
 // It verifies that the adapter correctly processes the AWS API response
 // and returns the expected instance details.
@@ -238,6 +345,7 @@ func TestDescribeInstance(t *testing.T) {
 
 	// Set up expectations
 	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).Return(mockResponse, nil)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeAddressesOutput{}, nil)
 
 	// Call the function
 	ctx := context.Background()
@@ -257,6 +365,154 @@ func TestDescribeInstance(t *testing.T) {
 	assert.Equal(t, "vpc-12345", result.VpcID)
 	assert.Equal(t, "subnet-12345", result.SubnetID)
 	assert.Equal(t, "test", result.Tags["Environment"])
+	assert.Empty(t, result.ElasticIPs)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDescribeInstanceElasticIPs tests the Elastic IP lookup of DescribeInstance.
+// It verifies that the adapter filters DescribeAddresses by the instance ID
+// and reports the allocation and association of each Elastic IP.
+func TestDescribeInstanceElasticIPs(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock instance
+	instance := createMockInstance("i-12345", "web", "t3.micro", "running", "52.1.2.3", "10.0.0.1", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{instance}}},
+	}, nil)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeAddressesInput) bool {
+		return len(input.Filters) == 1 &&
+			aws.ToString(input.Filters[0].Name) == "instance-id" &&
+			len(input.Filters[0].Values) == 1 && input.Filters[0].Values[0] == "i-12345"
+	}), mock.Anything).Return(&ec2.DescribeAddressesOutput{
+		Addresses: []types.Address{
+			{
+				PublicIp:         aws.String("52.1.2.3"),
+				AllocationId:     aws.String("eipalloc-12345"),
+				AssociationId:    aws.String("eipassoc-12345"),
+				InstanceId:       aws.String("i-12345"),
+				PrivateIpAddress: aws.String("10.0.0.1"),
+			},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	result, err := adapter.DescribeInstance(ctx, "i-12345")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert Elastic IP
+	assert.Equal(t, []ElasticIP{
+		{
+			PublicIP:      "52.1.2.3",
+			AllocationID:  "eipalloc-12345",
+			AssociationID: "eipassoc-12345",
+			PrivateIP:     "10.0.0.1",
+		},
+	}, result.ElasticIPs)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDescribeInstanceElasticIPsDenied tests DescribeInstance when the Elastic IP
+// lookup is denied. It verifies that the instance is still returned, without
+// Elastic IPs, instead of failing the whole call.
+func TestDescribeInstanceElasticIPsDenied(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock instance
+	instance := createMockInstance("i-12345", "web", "t3.micro", "running", "52.1.2.3", "10.0.0.1", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{instance}}},
+	}, nil)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeAddressesOutput)(nil), &smithy.GenericAPIError{
+		Code:    "UnauthorizedOperation",
+		Message: "You are not authorized to perform this operation.",
+	})
+
+	// Call the function
+	ctx := context.Background()
+	result, err := adapter.DescribeInstance(ctx, "i-12345")
+
+	// Assert the instance is returned without Elastic IPs
+	assert.NoError(t, err)
+	assert.Equal(t, "i-12345", result.ID)
+	assert.Empty(t, result.ElasticIPs)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDescribeInstances tests the DescribeInstances method of the EC2 Adapter.
+// It verifies that the adapter describes all instances in a single API call,
+// returns them in the requested order, and reports instances that were not found.
+func TestDescribeInstances(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock instances
+	first := createMockInstance("i-11111", "first", "t2.micro", "running", "", "10.0.0.1", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+	second := createMockInstance("i-22222", "second", "t3.small", "stopped", "", "10.0.0.2", "us-east-1b", "vpc-12345", "subnet-67890", nil)
+
+	// Create mock response with the instances in separate reservations
+	mockResponse := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{Instances: []types.Instance{first}},
+			{Instances: []types.Instance{second}},
+		},
+	}
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 2 && input.InstanceIds[0] == "i-22222" && input.InstanceIds[1] == "i-11111"
+	}), mock.Anything).Return(mockResponse, nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 2 && input.InstanceIds[1] == "i-33333"
+	}), mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{first}}},
+	}, nil).Once()
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeAddressesOutput{}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	result, err := adapter.DescribeInstances(ctx, []string{"i-22222", "i-11111"})
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert instances are in the requested order
+	assert.Len(t, result, 2)
+	assert.Equal(t, "i-22222", result[0].ID)
+	assert.Equal(t, "second", result[0].Name)
+	assert.Equal(t, "i-11111", result[1].ID)
+	assert.Equal(t, "first", result[1].Name)
+
+	// Call the function with an instance that doesn't exist
+	_, err = adapter.DescribeInstances(ctx, []string{"i-11111", "i-33333"})
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "i-33333")
 
 	// Verify expectations
 	mockClient.AssertExpectations(t)
@@ -340,6 +596,195 @@ func TestStopInstance(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestRebootInstance tests the RebootInstance method of the EC2 Adapter.
+// It verifies that the adapter calls the AWS API with the instance ID
+// and wraps errors returned by the API.
+func TestRebootInstance(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("RebootInstances", mock.Anything, mock.MatchedBy(func(input *ec2.RebootInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-12345"
+	}), mock.Anything).Return(&ec2.RebootInstancesOutput{}, nil)
+	mockClient.On("RebootInstances", mock.Anything, mock.MatchedBy(func(input *ec2.RebootInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-missing"
+	}), mock.Anything).Return(&ec2.RebootInstancesOutput{}, errors.New("InvalidInstanceID.NotFound"))
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.RebootInstance(ctx, "i-12345")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Call the function with an unknown instance
+	err = adapter.RebootInstance(ctx, "i-missing")
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reboot EC2 instance i-missing")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestRenameInstance tests the RenameInstance method of the EC2 Adapter.
+// It verifies that the adapter sets the name tag of the instance and wraps
+// errors returned by the API.
+func TestRenameInstance(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("CreateTags", mock.Anything, mock.MatchedBy(func(input *ec2.CreateTagsInput) bool {
+		return len(input.Resources) == 1 && input.Resources[0] == "i-12345" &&
+			len(input.Tags) == 1 && aws.ToString(input.Tags[0].Key) == "Name" && aws.ToString(input.Tags[0].Value) == "web-1"
+	}), mock.Anything).Return(&ec2.CreateTagsOutput{}, nil)
+	mockClient.On("CreateTags", mock.Anything, mock.MatchedBy(func(input *ec2.CreateTagsInput) bool {
+		return len(input.Resources) == 1 && input.Resources[0] == "i-missing"
+	}), mock.Anything).Return(&ec2.CreateTagsOutput{}, errors.New("InvalidInstanceID.NotFound"))
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.RenameInstance(ctx, "i-12345", DefaultNameTag, "web-1")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Call the function with an unknown instance
+	err = adapter.RenameInstance(ctx, "i-missing", DefaultNameTag, "web-1")
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to rename EC2 instance i-missing")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListKeyPairs tests the ListKeyPairs method of the EC2 Adapter.
+// It verifies that the adapter converts the key pairs returned by the
+// AWS API and sorts them by name.
+func TestListKeyPairs(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock response
+	createTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockResponse := &ec2.DescribeKeyPairsOutput{
+		KeyPairs: []types.KeyPairInfo{
+			{
+				KeyName:        aws.String("prod-key"),
+				KeyPairId:      aws.String("key-22222"),
+				KeyType:        types.KeyTypeEd25519,
+				KeyFingerprint: aws.String("fp-2"),
+				CreateTime:     aws.Time(createTime),
+				Tags:           []types.Tag{{Key: aws.String("Environment"), Value: aws.String("prod")}},
+			},
+			{
+				KeyName:        aws.String("dev-key"),
+				KeyPairId:      aws.String("key-11111"),
+				KeyType:        types.KeyTypeRsa,
+				KeyFingerprint: aws.String("fp-1"),
+			},
+		},
+	}
+
+	// Set up expectations
+	mockClient.On("DescribeKeyPairs", mock.Anything, mock.Anything, mock.Anything).Return(mockResponse, nil)
+
+	// Call the function
+	ctx := context.Background()
+	keyPairs, err := adapter.ListKeyPairs(ctx)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert key pairs are sorted by name
+	assert.Len(t, keyPairs, 2)
+	assert.Equal(t, "dev-key", keyPairs[0].Name)
+	assert.Equal(t, "rsa", keyPairs[0].Type)
+	assert.Empty(t, keyPairs[0].CreateTime)
+	assert.Equal(t, "prod-key", keyPairs[1].Name)
+	assert.Equal(t, "key-22222", keyPairs[1].ID)
+	assert.Equal(t, "ed25519", keyPairs[1].Type)
+	assert.Equal(t, "fp-2", keyPairs[1].Fingerprint)
+	assert.Equal(t, "2024-01-02 03:04:05", keyPairs[1].CreateTime)
+	assert.Equal(t, "prod", keyPairs[1].Tags["Environment"])
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestAddInstanceSpecs tests the AddInstanceSpecs method of the EC2 Adapter.
+// It verifies that each instance gets the vCPU and memory of its type, that
+// duplicate types are described once, and that specs are cached between calls.
+func TestAddInstanceSpecs(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeInstanceTypes", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstanceTypesInput) bool {
+		return assert.ObjectsAreEqual([]types.InstanceType{"t3.micro", "m5.large"}, input.InstanceTypes)
+	}), mock.Anything).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{
+			{
+				InstanceType: types.InstanceTypeT3Micro,
+				VCpuInfo:     &types.VCpuInfo{DefaultVCpus: aws.Int32(2)},
+				MemoryInfo:   &types.MemoryInfo{SizeInMiB: aws.Int64(1024)},
+			},
+			{
+				InstanceType: types.InstanceTypeM5Large,
+				VCpuInfo:     &types.VCpuInfo{DefaultVCpus: aws.Int32(2)},
+				MemoryInfo:   &types.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+			},
+		},
+	}, nil).Once()
+
+	instances := []Instance{
+		{ID: "i-1", Type: "t3.micro"},
+		{ID: "i-2", Type: "m5.large"},
+		{ID: "i-3", Type: "t3.micro"},
+	}
+
+	// Call the function
+	ctx := context.Background()
+	enriched, err := adapter.AddInstanceSpecs(ctx, instances)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert each instance got the specs of its type
+	assert.Len(t, enriched, 3)
+	assert.Equal(t, "i-1", enriched[0].ID)
+	assert.Equal(t, int32(2), enriched[0].VCPUs)
+	assert.Equal(t, int64(1024), enriched[0].MemoryMiB)
+	assert.Equal(t, int64(8192), enriched[1].MemoryMiB)
+	assert.Equal(t, int64(1024), enriched[2].MemoryMiB)
+
+	// Call the function again; the cached specs are used
+	enriched, err = adapter.AddInstanceSpecs(ctx, instances[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), enriched[0].VCPUs)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "DescribeInstanceTypes", 1)
+}
+
 // TestCreateFilter tests the CreateFilter function.
 // It verifies that the function correctly creates an EC2 filter
 // with the specified name and values.
@@ -352,6 +797,29 @@ func TestCreateFilter(t *testing.T) {
 	assert.Equal(t, []string{"test-instance"}, filter.Values)
 }
 
+// TestParseFilter tests the ParseFilter function.
+// It verifies that filter expressions are split into a name and values,
+// and that malformed expressions are rejected.
+func TestParseFilter(t *testing.T) {
+	// Single value
+	filter, err := ParseFilter("instance-state-name=running")
+	assert.NoError(t, err)
+	assert.Equal(t, "instance-state-name", *filter.Name)
+	assert.Equal(t, []string{"running"}, filter.Values)
+
+	// Multiple values and a tag filter containing a colon
+	filter, err = ParseFilter("tag:Environment=Production,Staging")
+	assert.NoError(t, err)
+	assert.Equal(t, "tag:Environment", *filter.Name)
+	assert.Equal(t, []string{"Production", "Staging"}, filter.Values)
+
+	// Invalid expressions
+	for _, expr := range []string{"", "running", "=running", "instance-state-name="} {
+		_, err = ParseFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
 // TestExtractInstanceInfo tests the extractInstanceInfo function.
 // It verifies that the function correctly extracts information from
 // an AWS EC2 instance and converts it to our simplified Instance struct.
@@ -385,3 +853,529 @@ func TestExtractInstanceInfo(t *testing.T) {
 	assert.Equal(t, "subnet-12345", result.SubnetID)
 	assert.Equal(t, "test", result.Tags["Environment"])
 }
+
+// TestGetAZSummary tests the GetAZSummary method of the EC2 Adapter.
+// It verifies that running instances are requested and grouped by
+// availability zone in sorted order with their share of the total.
+func TestGetAZSummary(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock response
+	mockResponse := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					createMockInstance("i-1", "web-1", "t3.micro", "running", "", "10.0.0.1", "us-east-1b", "vpc-12345", "subnet-1", nil),
+					createMockInstance("i-2", "web-2", "t3.micro", "running", "", "10.0.0.2", "us-east-1a", "vpc-12345", "subnet-2", nil),
+					createMockInstance("i-3", "web-3", "t3.micro", "running", "", "10.0.0.3", "us-east-1a", "vpc-12345", "subnet-2", nil),
+					createMockInstance("i-4", "web-4", "t3.micro", "running", "", "10.0.0.4", "us-east-1a", "vpc-12345", "subnet-2", nil),
+				},
+			},
+		},
+	}
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.Filters) == 1 &&
+			aws.ToString(input.Filters[0].Name) == "instance-state-name" &&
+			input.Filters[0].Values[0] == "running"
+	}), mock.Anything).Return(mockResponse, nil)
+
+	// Call the function
+	ctx := context.Background()
+	summary, err := adapter.GetAZSummary(ctx)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert summary
+	assert.Equal(t, []AZSummary{
+		{AZ: "us-east-1a", Count: 3, Percentage: 75},
+		{AZ: "us-east-1b", Count: 1, Percentage: 25},
+	}, summary)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestSummarizeByAZ tests the SummarizeByAZ function with no instances.
+// It verifies that an empty, non-nil summary is returned.
+func TestSummarizeByAZ(t *testing.T) {
+	summary := SummarizeByAZ(nil)
+
+	assert.NotNil(t, summary)
+	assert.Empty(t, summary)
+}
+
+// TestListStaleInstances tests the ListStaleInstances method of the EC2 Adapter.
+// It verifies that recently launched instances are skipped, that CPU utilization
+// is averaged from the CloudWatch datapoints, that idle instances are flagged,
+// and that instances without a launch time are skipped.
+func TestListStaleInstances(t *testing.T) {
+	// Create mock clients
+	mockClient := new(mockEC2Client)
+	mockMetrics := new(mockMetricsGetter)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock instances launched 90, 60 and 5 days ago, and one without a launch time
+	idle := createMockInstance("i-idle", "idle", "m5.large", "running", "", "10.0.0.1", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+	idle.LaunchTime = aws.Time(time.Now().AddDate(0, 0, -90))
+	busy := createMockInstance("i-busy", "busy", "c5.xlarge", "running", "", "10.0.0.2", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+	busy.LaunchTime = aws.Time(time.Now().AddDate(0, 0, -60))
+	recent := createMockInstance("i-recent", "recent", "t3.micro", "running", "", "10.0.0.3", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+	recent.LaunchTime = aws.Time(time.Now().AddDate(0, 0, -5))
+	unknown := createMockInstance("i-unknown", "unknown", "t3.micro", "running", "", "10.0.0.4", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+	unknown.LaunchTime = nil
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.Filters) == 1 && aws.ToString(input.Filters[0].Name) == "instance-state-name" && input.Filters[0].Values[0] == "running"
+	}), mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{busy, idle, recent, unknown}}},
+	}, nil)
+	mockMetrics.On("GetMetricStatistics", mock.Anything, "AWS/EC2", "CPUUtilization", map[string]string{"InstanceId": "i-busy"}, mock.Anything, mock.Anything, time.Hour).
+		Return([]cloudwatch.Datapoint{{Average: 40, Maximum: 95}, {Average: 60, Maximum: 80}}, nil)
+	mockMetrics.On("GetMetricStatistics", mock.Anything, "AWS/EC2", "CPUUtilization", map[string]string{"InstanceId": "i-idle"}, mock.Anything, mock.Anything, time.Hour).
+		Return([]cloudwatch.Datapoint{{Average: 1, Maximum: 3}, {Average: 2, Maximum: 7}}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	result, err := adapter.ListStaleInstances(ctx, mockMetrics, StaleOptions{RunningDays: 30, CPUDays: 14, CPUThreshold: 5})
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the idle instance comes first and the recent and unknown instances are skipped
+	assert.Len(t, result, 2)
+	assert.Equal(t, "i-idle", result[0].ID)
+	assert.Equal(t, 90, result[0].AgeDays)
+	assert.Equal(t, 1.5, result[0].AvgCPU)
+	assert.Equal(t, 7.0, result[0].MaxCPU)
+	assert.True(t, result[0].Idle)
+	assert.Equal(t, "i-busy", result[1].ID)
+	assert.Equal(t, 50.0, result[1].AvgCPU)
+	assert.False(t, result[1].Idle)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
+
+// TestGetTopology tests the GetTopology method of the EC2 Adapter.
+// It verifies that the instance's VPC, subnet, security groups, volumes and
+// Elastic IPs are looked up and rendered as a tree.
+func TestGetTopology(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock instance
+	instance := createMockInstance("i-12345", "web-1", "t3.micro", "running", "", "10.0.1.10", "us-east-1a", "vpc-12345", "subnet-12345", nil)
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{instance}}},
+	}, nil)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeAddressesOutput{
+		Addresses: []types.Address{{
+			InstanceId:   aws.String("i-12345"),
+			PublicIp:     aws.String("3.3.3.3"),
+			AllocationId: aws.String("eipalloc-1"),
+		}},
+	}, nil)
+	mockClient.On("DescribeVpcs", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeVpcsInput) bool {
+		return len(input.VpcIds) == 1 && input.VpcIds[0] == "vpc-12345"
+	}), mock.Anything).Return(&ec2.DescribeVpcsOutput{
+		Vpcs: []types.Vpc{{
+			VpcId:     aws.String("vpc-12345"),
+			CidrBlock: aws.String("10.0.0.0/16"),
+			Tags:      []types.Tag{{Key: aws.String("Name"), Value: aws.String("main")}},
+		}},
+	}, nil)
+	mockClient.On("DescribeSubnets", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeSubnetsInput) bool {
+		return len(input.SubnetIds) == 1 && input.SubnetIds[0] == "subnet-12345"
+	}), mock.Anything).Return(&ec2.DescribeSubnetsOutput{
+		Subnets: []types.Subnet{{
+			SubnetId:         aws.String("subnet-12345"),
+			CidrBlock:        aws.String("10.0.1.0/24"),
+			AvailabilityZone: aws.String("us-east-1a"),
+		}},
+	}, nil)
+	mockClient.On("DescribeSecurityGroups", mock.Anything, mock.Anything, mock.Anything).Return(&ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{{
+			GroupId:       aws.String("sg-12345"),
+			GroupName:     aws.String("default"),
+			IpPermissions: []types.IpPermission{{}, {}},
+		}},
+	}, nil)
+	mockClient.On("DescribeVolumes", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeVolumesInput) bool {
+		return len(input.Filters) == 1 && aws.ToString(input.Filters[0].Name) == "attachment.instance-id"
+	}), mock.Anything).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId:    aws.String("vol-data"),
+				Size:        aws.Int32(100),
+				VolumeType:  types.VolumeTypeGp3,
+				State:       types.VolumeStateInUse,
+				Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-12345"), Device: aws.String("/dev/xvdf")}},
+			},
+			{
+				VolumeId:    aws.String("vol-root"),
+				Size:        aws.Int32(8),
+				VolumeType:  types.VolumeTypeGp3,
+				State:       types.VolumeStateInUse,
+				Encrypted:   aws.Bool(true),
+				Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-12345"), Device: aws.String("/dev/xvda"), DeleteOnTermination: aws.Bool(true)}},
+			},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	topology, err := adapter.GetTopology(ctx, "i-12345")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert related resources
+	assert.Equal(t, &VPC{ID: "vpc-12345", Name: "main", CIDR: "10.0.0.0/16"}, topology.VPC)
+	assert.Equal(t, "10.0.1.0/24", topology.Subnet.CIDR)
+	assert.Equal(t, []SecurityGroup{{ID: "sg-12345", Name: "default", InboundRules: 2}}, topology.SecurityGroups)
+	assert.Len(t, topology.Volumes, 2)
+	assert.Equal(t, "/dev/xvda", topology.Volumes[0].Device)
+	assert.True(t, topology.Volumes[0].DeleteOnTermination)
+
+	// Assert tree rendering
+	assert.Equal(t, `Instance i-12345 (web-1) t3.micro running
+├── VPC vpc-12345 (main) 10.0.0.0/16
+│   └── Subnet subnet-12345 10.0.1.0/24 us-east-1a
+├── Addresses
+│   ├── Private 10.0.1.10
+│   └── Elastic IP 3.3.3.3 (eipalloc-1)
+├── Security groups
+│   └── sg-12345 (default) 2 inbound, 0 outbound rules
+└── Volumes
+    ├── vol-root /dev/xvda 8 GiB gp3 in-use encrypted
+    └── vol-data /dev/xvdf 100 GiB gp3 in-use
+`, topology.Tree())
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestInstanceDisplayName tests the DisplayName method of Instance.
+// It verifies that the name is taken from the given tag, and that instances
+// without it are shown by the start of their ID.
+func TestInstanceDisplayName(t *testing.T) {
+	instance := Instance{
+		ID:   "i-0123456789abcdef0",
+		Name: "web-1",
+		Tags: map[string]string{"Name": "web-1", "app": "checkout"},
+	}
+
+	assert.Equal(t, "web-1", instance.DisplayName(""))
+	assert.Equal(t, "web-1", instance.DisplayName("Name"))
+	assert.Equal(t, "checkout", instance.DisplayName("app"))
+	assert.Equal(t, "i-01234567…", instance.DisplayName("team"))
+
+	// Short IDs are not truncated
+	assert.Equal(t, "i-12345", Instance{ID: "i-12345"}.DisplayName(""))
+}
+
+// TestCheckPermissions tests the CheckPermissions function.
+// It verifies that each action is called as a dry run, that DryRunOperation is
+// reported as allowed and UnauthorizedOperation as denied, and that instance
+// actions are checked against the first instance found.
+func TestCheckPermissions(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+	adapter := NewAdapterWithClient(mockClient)
+
+	allowed := &smithy.GenericAPIError{Code: "DryRunOperation", Message: "Request would have succeeded, but DryRun flag is set."}
+	denied := &smithy.GenericAPIError{Code: "UnauthorizedOperation", Message: "You are not authorized to perform this operation."}
+	isDryRun := func(dryRun *bool) bool { return aws.ToBool(dryRun) }
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return !isDryRun(input.DryRun)
+	}), mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{{
+			InstanceId: aws.String("i-12345"),
+			State:      &types.InstanceState{Name: types.InstanceStateNameRunning},
+			Placement:  &types.Placement{AvailabilityZone: aws.String("us-east-1a")},
+		}}}},
+	}, nil)
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return isDryRun(input.DryRun)
+	}), mock.Anything).Return((*ec2.DescribeInstancesOutput)(nil), allowed)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeAddressesOutput)(nil), allowed)
+	mockClient.On("DescribeInstanceTypes", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeInstanceTypesOutput)(nil), allowed)
+	mockClient.On("DescribeKeyPairs", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeKeyPairsOutput)(nil), denied)
+	mockClient.On("DescribeVpcs", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeVpcsOutput)(nil), allowed)
+	mockClient.On("DescribeSubnets", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeSubnetsOutput)(nil), allowed)
+	mockClient.On("DescribeSecurityGroups", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeSecurityGroupsOutput)(nil), allowed)
+	mockClient.On("DescribeVolumes", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeVolumesOutput)(nil), errors.New("connection reset"))
+	mockClient.On("DescribeReservedInstances", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeReservedInstancesOutput)(nil), allowed)
+	mockClient.On("StartInstances", mock.Anything, mock.MatchedBy(func(input *ec2.StartInstancesInput) bool {
+		return isDryRun(input.DryRun) && input.InstanceIds[0] == "i-12345"
+	}), mock.Anything).Return((*ec2.StartInstancesOutput)(nil), allowed)
+	mockClient.On("StopInstances", mock.Anything, mock.MatchedBy(func(input *ec2.StopInstancesInput) bool {
+		return isDryRun(input.DryRun) && input.InstanceIds[0] == "i-12345"
+	}), mock.Anything).Return((*ec2.StopInstancesOutput)(nil), allowed)
+	mockClient.On("RebootInstances", mock.Anything, mock.MatchedBy(func(input *ec2.RebootInstancesInput) bool {
+		return isDryRun(input.DryRun) && input.InstanceIds[0] == "i-12345"
+	}), mock.Anything).Return((*ec2.RebootInstancesOutput)(nil), denied)
+	mockClient.On("CreateTags", mock.Anything, mock.MatchedBy(func(input *ec2.CreateTagsInput) bool {
+		return isDryRun(input.DryRun) && input.Resources[0] == "i-12345"
+	}), mock.Anything).Return((*ec2.CreateTagsOutput)(nil), allowed)
+
+	// Call the function
+	checks := adapter.CheckPermissions(context.Background(), "")
+
+	// Assert the result of each action
+	results := make(map[string]string, len(checks))
+	for _, check := range checks {
+		results[check.Action] = check.Result
+		assert.NotEmpty(t, check.Commands)
+	}
+	assert.Len(t, checks, 13)
+	assert.Equal(t, PermissionAllowed, results["ec2:DescribeInstances"])
+	assert.Equal(t, PermissionDenied, results["ec2:DescribeKeyPairs"])
+	assert.Equal(t, PermissionUnknown, results["ec2:DescribeVolumes"])
+	assert.Equal(t, PermissionAllowed, results["ec2:StartInstances"])
+	assert.Equal(t, PermissionDenied, results["ec2:RebootInstances"])
+	assert.Equal(t, PermissionAllowed, results["ec2:CreateTags"])
+	assert.Equal(t, "UnauthorizedOperation", checks[3].Detail)
+	assert.Equal(t, "connection reset", checks[7].Detail)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestCheckPermissionsWithoutInstances tests CheckPermissions in an account
+// without instances. It verifies that instance actions are reported as unknown
+// rather than called.
+func TestCheckPermissionsWithoutInstances(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+	adapter := NewAdapterWithClient(mockClient)
+	allowed := &smithy.GenericAPIError{Code: "DryRunOperation"}
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return !aws.ToBool(input.DryRun)
+	}), mock.Anything).Return(&ec2.DescribeInstancesOutput{}, nil)
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeInstancesOutput)(nil), allowed)
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeAddressesOutput)(nil), allowed)
+	mockClient.On("DescribeInstanceTypes", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeInstanceTypesOutput)(nil), allowed)
+	mockClient.On("DescribeKeyPairs", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeKeyPairsOutput)(nil), allowed)
+	mockClient.On("DescribeVpcs", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeVpcsOutput)(nil), allowed)
+	mockClient.On("DescribeSubnets", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeSubnetsOutput)(nil), allowed)
+	mockClient.On("DescribeSecurityGroups", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeSecurityGroupsOutput)(nil), allowed)
+	mockClient.On("DescribeVolumes", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeVolumesOutput)(nil), allowed)
+	mockClient.On("DescribeReservedInstances", mock.Anything, mock.Anything, mock.Anything).Return((*ec2.DescribeReservedInstancesOutput)(nil), allowed)
+
+	// Call the function
+	checks := adapter.CheckPermissions(context.Background(), "")
+
+	// Assert instance actions are not checked
+	for _, check := range checks[9:] {
+		assert.Equal(t, PermissionUnknown, check.Result, check.Action)
+		assert.Equal(t, "no instance to check against", check.Detail)
+	}
+	mockClient.AssertNotCalled(t, "StartInstances", mock.Anything, mock.Anything, mock.Anything)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListReservations tests the ListReservations method of the EC2 Adapter.
+// It verifies that only active reservations are requested and that only zonal
+// reservations keep their Availability Zone.
+func TestListReservations(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeReservedInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeReservedInstancesInput) bool {
+		return len(input.Filters) == 1 && aws.ToString(input.Filters[0].Name) == "state" && input.Filters[0].Values[0] == "active"
+	}), mock.Anything).Return(&ec2.DescribeReservedInstancesOutput{
+		ReservedInstances: []types.ReservedInstances{
+			{
+				ReservedInstancesId: aws.String("ri-zonal"),
+				InstanceType:        types.InstanceTypeM5Large,
+				InstanceCount:       aws.Int32(2),
+				Scope:               types.ScopeAvailabilityZone,
+				AvailabilityZone:    aws.String("us-east-1a"),
+				End:                 aws.Time(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)),
+			},
+			{
+				ReservedInstancesId: aws.String("ri-regional"),
+				InstanceType:        types.InstanceTypeT3Micro,
+				InstanceCount:       aws.Int32(1),
+				Scope:               types.ScopeRegional,
+			},
+		},
+	}, nil)
+
+	// Call the function
+	reservations, err := adapter.ListReservations(context.Background())
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Equal(t, []Reservation{
+		{ID: "ri-zonal", InstanceType: "m5.large", AZ: "us-east-1a", Count: 2, End: "2027-01-01 00:00:00"},
+		{ID: "ri-regional", InstanceType: "t3.micro", Count: 1},
+	}, reservations)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestCoverage tests the Coverage function.
+// It verifies that zonal reservations are applied before regional ones, that
+// each reservation covers at most its count, and that other types are not covered.
+func TestCoverage(t *testing.T) {
+	instances := []Instance{
+		{ID: "i-1", Type: "m5.large", AZ: "us-east-1b"},
+		{ID: "i-2", Type: "m5.large", AZ: "us-east-1a"},
+		{ID: "i-3", Type: "m5.large", AZ: "us-east-1a"},
+		{ID: "i-4", Type: "c5.large", AZ: "us-east-1a"},
+	}
+	reservations := []Reservation{
+		{ID: "ri-regional", InstanceType: "m5.large", Count: 1},
+		{ID: "ri-zonal", InstanceType: "m5.large", AZ: "us-east-1a", Count: 1},
+	}
+
+	coverage := Coverage(instances, reservations)
+
+	// i-2 takes the zonal reservation, so the regional one goes to i-1
+	assert.Len(t, coverage, 4)
+	assert.Equal(t, "ri-regional", coverage[0].Reservation)
+	assert.Equal(t, "ri-zonal", coverage[1].Reservation)
+	assert.False(t, coverage[2].Covered)
+	assert.False(t, coverage[3].Covered)
+	assert.True(t, coverage[0].Covered && coverage[1].Covered)
+}
+
+// TestReadReservations tests the ReadReservations function.
+// It verifies that records without an ID are accepted and that records without
+// an instance type are rejected.
+func TestReadReservations(t *testing.T) {
+	reservations, err := ReadReservations(strings.NewReader(`[{"InstanceType": "m5.large", "Count": 3}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Reservation{{InstanceType: "m5.large", Count: 3}}, reservations)
+
+	// A reservation read from a file covers instances without an ID
+	coverage := Coverage([]Instance{{ID: "i-1", Type: "m5.large"}}, reservations)
+	assert.True(t, coverage[0].Covered)
+
+	_, err = ReadReservations(strings.NewReader(`[{"Count": 3}]`))
+	assert.Error(t, err)
+
+	_, err = ReadReservations(strings.NewReader(`{"InstanceType": "m5.large"}`))
+	assert.Error(t, err)
+}
+
+// TestWaitForInstanceState tests waiting for an EC2 instance to reach a state.
+// It verifies that the SDK waiters poll the instance until it is running or
+// stopped, that waiting stops if the instance is terminated first, and that
+// other states are refused.
+func TestWaitForInstanceState(t *testing.T) {
+	// describeOutput creates a DescribeInstances response for an instance in the given state
+	describeOutput := func(id string, state types.InstanceStateName) *ec2.DescribeInstancesOutput {
+		return &ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{{
+				Instances: []types.Instance{{InstanceId: aws.String(id), State: &types.InstanceState{Name: state}}},
+			}},
+		}
+	}
+
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-1"}}, mock.Anything).
+		Return(describeOutput("i-1", types.InstanceStateNamePending), nil).Twice()
+	mockClient.On("DescribeInstances", mock.Anything, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-1"}}, mock.Anything).
+		Return(describeOutput("i-1", types.InstanceStateNameRunning), nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-2"}}, mock.Anything).
+		Return(describeOutput("i-2", types.InstanceStateNameTerminated), nil)
+	mockClient.On("DescribeInstances", mock.Anything, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-3"}}, mock.Anything).
+		Return(describeOutput("i-3", types.InstanceStateNameStopping), nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-3"}}, mock.Anything).
+		Return(describeOutput("i-3", types.InstanceStateNameStopped), nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.WaitForInstanceState(ctx, "i-1", types.InstanceStateNameRunning, time.Millisecond)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// A terminated instance is never running
+	err = adapter.WaitForInstanceState(ctx, "i-2", types.InstanceStateNameRunning, time.Millisecond)
+	assert.EqualError(t, err, "EC2 instance i-2 did not become running: waiter state transitioned to Failure")
+
+	// Stopping ends once the instance is stopped
+	err = adapter.WaitForInstanceState(ctx, "i-3", types.InstanceStateNameStopped, time.Millisecond)
+	assert.NoError(t, err)
+
+	// Other states have no waiter
+	err = adapter.WaitForInstanceState(ctx, "i-1", types.InstanceStateNamePending, time.Millisecond)
+	assert.ErrorContains(t, err, "only running and stopped are supported")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestWaitForInstanceStatusOK tests waiting for the status checks of an EC2
+// instance to pass after a reboot.
+// It verifies that the instance check is polled until it passes, and that the
+// system check is then waited for as well.
+func TestWaitForInstanceStatusOK(t *testing.T) {
+	// statusOutput creates a DescribeInstanceStatus response with the given check results
+	statusOutput := func(instance, system types.SummaryStatus) *ec2.DescribeInstanceStatusOutput {
+		return &ec2.DescribeInstanceStatusOutput{
+			InstanceStatuses: []types.InstanceStatus{{
+				InstanceId:     aws.String("i-1"),
+				InstanceStatus: &types.InstanceStatusSummary{Status: instance},
+				SystemStatus:   &types.InstanceStatusSummary{Status: system},
+			}},
+		}
+	}
+
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeInstanceStatus", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstanceStatusInput) bool {
+		return input.InstanceIds[0] == "i-1" && aws.ToBool(input.IncludeAllInstances)
+	}), mock.Anything).Return(statusOutput(types.SummaryStatusInitializing, types.SummaryStatusOk), nil).Once()
+	mockClient.On("DescribeInstanceStatus", mock.Anything, mock.Anything, mock.Anything).
+		Return(statusOutput(types.SummaryStatusOk, types.SummaryStatusOk), nil).Twice()
+
+	// Call the function
+	err := adapter.WaitForInstanceStatusOK(context.Background(), "i-1", time.Millisecond)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,226 @@
+package ec2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+// Results of a permission check
+const (
+	PermissionAllowed = "allowed" // The dry run succeeded
+	PermissionDenied  = "denied"  // The dry run was refused by IAM
+	PermissionUnknown = "unknown" // The permission could not be checked
+)
+
+// PermissionCheck is the result of checking whether the current identity may
+// call an EC2 API action used by awsm.
+type PermissionCheck struct {
+	Action   string // IAM action, e.g. ec2:DescribeInstances
+	Commands string // awsm commands that need the action
+	Result   string // PermissionAllowed, PermissionDenied or PermissionUnknown
+	Detail   string // Why the action is denied or could not be checked
+}
+
+// permissionCheck describes how to check one action with a dry-run call
+type permissionCheck struct {
+	action   string
+	commands string
+	instance bool // Whether the call acts on an instance
+	call     func(ctx context.Context, client EC2Client, instanceID string) error
+}
+
+// permissionChecks are the EC2 actions used by awsm, in the order they are reported
+var permissionChecks = []permissionCheck{
+	{
+		action:   "ec2:DescribeInstances",
+		commands: "ec2 list, describe, stale, topology, az-summary",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeAddresses",
+		commands: "ec2 list, describe",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeInstanceTypes",
+		commands: "ec2 list --with-specs",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeKeyPairs",
+		commands: "ec2 keypairs",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeKeyPairs(ctx, &ec2.DescribeKeyPairsInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeVpcs",
+		commands: "ec2 topology",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeSubnets",
+		commands: "ec2 topology",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeSecurityGroups",
+		commands: "ec2 topology",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeVolumes",
+		commands: "ec2 topology",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:DescribeReservedInstances",
+		commands: "ec2 list --coverage",
+		call: func(ctx context.Context, client EC2Client, _ string) error {
+			_, err := client.DescribeReservedInstances(ctx, &ec2.DescribeReservedInstancesInput{DryRun: aws.Bool(true)})
+			return err
+		},
+	},
+	{
+		action:   "ec2:StartInstances",
+		commands: "ec2 start",
+		instance: true,
+		call: func(ctx context.Context, client EC2Client, instanceID string) error {
+			_, err := client.StartInstances(ctx, &ec2.StartInstancesInput{
+				InstanceIds: []string{instanceID},
+				DryRun:      aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:   "ec2:StopInstances",
+		commands: "ec2 stop",
+		instance: true,
+		call: func(ctx context.Context, client EC2Client, instanceID string) error {
+			_, err := client.StopInstances(ctx, &ec2.StopInstancesInput{
+				InstanceIds: []string{instanceID},
+				DryRun:      aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:   "ec2:RebootInstances",
+		commands: "ec2 reboot",
+		instance: true,
+		call: func(ctx context.Context, client EC2Client, instanceID string) error {
+			_, err := client.RebootInstances(ctx, &ec2.RebootInstancesInput{
+				InstanceIds: []string{instanceID},
+				DryRun:      aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:   "ec2:CreateTags",
+		commands: "ec2 rename",
+		instance: true,
+		call: func(ctx context.Context, client EC2Client, instanceID string) error {
+			_, err := client.CreateTags(ctx, &ec2.CreateTagsInput{
+				Resources: []string{instanceID},
+				Tags:      []types.Tag{{Key: aws.String("Name"), Value: aws.String("awsm-permission-check")}},
+				DryRun:    aws.Bool(true),
+			})
+			return err
+		},
+	},
+}
+
+// CheckPermissions checks which EC2 API actions used by awsm the current
+// identity may call, by making each call as a dry run: EC2 checks the caller's
+// permissions and returns DryRunOperation instead of performing the action.
+//
+// Actions on an instance (start, stop, reboot, rename) are checked against
+// instanceID, or the first instance found if it is empty, since IAM policies can
+// grant them for some instances only. They are reported as unknown if there is no
+// instance to check against.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - instanceID: The instance to check instance actions against (empty for any instance)
+//
+// Returns the result of each check.
+func (a *Adapter) CheckPermissions(ctx context.Context, instanceID string) []PermissionCheck {
+	if instanceID == "" {
+		// Terminated instances can't be acted on
+		filter := CreateFilter("instance-state-name", "pending", "running", "stopping", "stopped")
+		if instances, err := a.ListInstances(ctx, []types.Filter{filter}, 1); err == nil && len(instances) > 0 {
+			instanceID = instances[0].ID
+		}
+	}
+
+	results := make([]PermissionCheck, 0, len(permissionChecks))
+	for _, check := range permissionChecks {
+		result := PermissionCheck{
+			Action:   check.action,
+			Commands: check.commands,
+		}
+
+		if check.instance && instanceID == "" {
+			result.Result = PermissionUnknown
+			result.Detail = "no instance to check against"
+		} else {
+			result.Result, result.Detail = dryRunResult(check.call(ctx, a.client, instanceID))
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// dryRunResult interprets the error returned by a dry-run call
+func dryRunResult(err error) (string, string) {
+	if err == nil {
+		return PermissionAllowed, ""
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.ErrorCode() == "DryRunOperation":
+			return PermissionAllowed, ""
+		case apiErr.ErrorCode() == "UnauthorizedOperation" || strings.HasPrefix(apiErr.ErrorCode(), "AccessDenied"):
+			return PermissionDenied, apiErr.ErrorCode()
+		default:
+			return PermissionUnknown, fmt.Sprintf("%s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+	}
+
+	return PermissionUnknown, err.Error()
+}
@@ -0,0 +1,115 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/cloudwatch"
+	"github.com/ao/awsm/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// MetricsGetter reads CloudWatch metric statistics. It is implemented by
+// cloudwatch.Adapter and allows for easy mocking in tests.
+type MetricsGetter interface {
+	GetMetricStatistics(ctx context.Context, namespace, metricName string, dimensions map[string]string, startTime, endTime time.Time, period time.Duration) ([]cloudwatch.Datapoint, error)
+}
+
+// StaleOptions configures which instances ListStaleInstances reports.
+type StaleOptions struct {
+	RunningDays  int     // Minimum number of days since the instance was launched
+	CPUDays      int     // Number of days of CPU utilization to look at
+	CPUThreshold float64 // Average CPU utilization, in percent, below which an instance is flagged as idle
+}
+
+// StaleInstance represents a long-running EC2 instance with its recent CPU utilization.
+type StaleInstance struct {
+	ID         string  // EC2 instance ID (i-xxxxxxxx)
+	Name       string  // Name tag value if available
+	Type       string  // Instance type (e.g., t2.micro)
+	LaunchTime string  // When the instance was launched (formatted, UTC)
+	AgeDays    int     // Number of full days since the instance was launched
+	AvgCPU     float64 // Average CPU utilization over the CPU window, in percent
+	MaxCPU     float64 // Highest CPU utilization over the CPU window, in percent
+	HasMetrics bool    // Whether CloudWatch returned CPU utilization for the instance
+	Idle       bool    // Whether the average CPU utilization is below the threshold
+}
+
+// ListStaleInstances lists running instances that were launched more than
+// opts.RunningDays days ago, with their average and maximum CPU utilization over
+// the last opts.CPUDays days. Instances whose average is below opts.CPUThreshold
+// are flagged as idle.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - metrics: The CloudWatch metrics source (usually a cloudwatch.Adapter)
+//   - opts: The age, CPU window and CPU threshold to use
+//
+// Returns the instances ordered from lowest to highest average CPU utilization,
+// with instances without metrics last, or an error if the operation fails.
+func (a *Adapter) ListStaleInstances(ctx context.Context, metrics MetricsGetter, opts StaleOptions) ([]StaleInstance, error) {
+	// List the running instances
+	instances, err := a.ListInstances(ctx, []types.Filter{CreateFilter("instance-state-name", "running")}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	startTime := now.AddDate(0, 0, -opts.CPUDays)
+
+	stale := []StaleInstance{}
+	for _, inst := range instances {
+		// Launch times are formatted in UTC, which time.Parse assumes
+		launchTime, err := time.Parse("2006-01-02 15:04:05", inst.LaunchTime)
+		if err != nil {
+			logger.Warn("Skipping EC2 instance %s with unknown launch time %q: %v", inst.ID, inst.LaunchTime, err)
+			continue
+		}
+
+		// Skip instances launched recently
+		ageDays := int(now.Sub(launchTime).Hours() / 24)
+		if ageDays < opts.RunningDays {
+			continue
+		}
+
+		// Get the hourly CPU utilization
+		datapoints, err := metrics.GetMetricStatistics(ctx, cloudwatch.NamespaceEC2, "CPUUtilization",
+			cloudwatch.EC2InstanceDimensions(inst.ID), startTime, now, time.Hour)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CPU utilization for EC2 instance %s: %w", inst.ID, err)
+		}
+
+		result := StaleInstance{
+			ID:         inst.ID,
+			Name:       inst.Name,
+			Type:       inst.Type,
+			LaunchTime: inst.LaunchTime,
+			AgeDays:    ageDays,
+			HasMetrics: len(datapoints) > 0,
+		}
+
+		if result.HasMetrics {
+			var sum float64
+			for _, dp := range datapoints {
+				sum += dp.Average
+				result.MaxCPU = max(result.MaxCPU, dp.Maximum)
+			}
+			result.AvgCPU = sum / float64(len(datapoints))
+			result.Idle = result.AvgCPU < opts.CPUThreshold
+		}
+
+		stale = append(stale, result)
+	}
+
+	// Show the most likely idle instances first
+	sort.SliceStable(stale, func(i, j int) bool {
+		if stale[i].HasMetrics != stale[j].HasMetrics {
+			return stale[i].HasMetrics
+		}
+		return stale[i].AvgCPU < stale[j].AvgCPU
+	})
+
+	return stale, nil
+}
@@ -0,0 +1,318 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Topology represents an EC2 instance together with the resources it is wired to.
+type Topology struct {
+	Instance       Instance        // The instance, including its Elastic IPs
+	VPC            *VPC            // VPC the instance runs in (nil for EC2-Classic instances)
+	Subnet         *Subnet         // Subnet the instance runs in (nil for EC2-Classic instances)
+	SecurityGroups []SecurityGroup // Security groups attached to the instance
+	Volumes        []Volume        // EBS volumes attached to the instance, by device name
+}
+
+// VPC represents an Amazon VPC.
+type VPC struct {
+	ID        string // VPC ID (vpc-xxxxxxxx)
+	Name      string // Name tag value if available
+	CIDR      string // Primary IPv4 CIDR block
+	IsDefault bool   // Whether this is the default VPC of the region
+}
+
+// Subnet represents a VPC subnet.
+type Subnet struct {
+	ID                  string // Subnet ID (subnet-xxxxxxxx)
+	Name                string // Name tag value if available
+	CIDR                string // IPv4 CIDR block
+	AZ                  string // Availability Zone
+	MapPublicIPOnLaunch bool   // Whether instances launched in the subnet get a public IP
+}
+
+// SecurityGroup represents a VPC security group.
+type SecurityGroup struct {
+	ID            string // Security group ID (sg-xxxxxxxx)
+	Name          string // Group name
+	Description   string // Group description
+	InboundRules  int    // Number of inbound permission entries
+	OutboundRules int    // Number of outbound permission entries
+}
+
+// Volume represents an EBS volume attached to an instance.
+type Volume struct {
+	ID                  string // Volume ID (vol-xxxxxxxx)
+	Device              string // Device name the volume is attached as (e.g., /dev/xvda)
+	SizeGiB             int32  // Size in GiB
+	Type                string // Volume type (e.g., gp3)
+	State               string // Volume state (e.g., in-use)
+	Encrypted           bool   // Whether the volume is encrypted
+	DeleteOnTermination bool   // Whether the volume is deleted when the instance terminates
+}
+
+// GetTopology describes an EC2 instance and the resources it is wired to: its
+// VPC, subnet, security groups, EBS volumes and Elastic IPs.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - instanceID: The ID of the instance
+//
+// Returns a pointer to a Topology struct, or an error if the instance or any
+// of its resources cannot be described.
+func (a *Adapter) GetTopology(ctx context.Context, instanceID string) (*Topology, error) {
+	// Describe the instance, including its Elastic IPs
+	instance, err := a.DescribeInstance(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &Topology{Instance: *instance}
+
+	if instance.VpcID != "" {
+		if topology.VPC, err = a.describeVPC(ctx, instance.VpcID); err != nil {
+			return nil, err
+		}
+	}
+
+	if instance.SubnetID != "" {
+		if topology.Subnet, err = a.describeSubnet(ctx, instance.SubnetID); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(instance.SecurityIDs) > 0 {
+		if topology.SecurityGroups, err = a.describeSecurityGroups(ctx, instance.SecurityIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if topology.Volumes, err = a.describeAttachedVolumes(ctx, instanceID); err != nil {
+		return nil, err
+	}
+
+	return topology, nil
+}
+
+// describeVPC describes a single VPC
+func (a *Adapter) describeVPC(ctx context.Context, vpcID string) (*VPC, error) {
+	// Call the DescribeVpcs API
+	output, err := a.client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
+		VpcIds: []string{vpcID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe VPC %s: %w", vpcID, err)
+	}
+	if len(output.Vpcs) == 0 {
+		return nil, fmt.Errorf("VPC %s not found", vpcID)
+	}
+
+	vpc := output.Vpcs[0]
+	return &VPC{
+		ID:        aws.ToString(vpc.VpcId),
+		Name:      nameTag(vpc.Tags),
+		CIDR:      aws.ToString(vpc.CidrBlock),
+		IsDefault: aws.ToBool(vpc.IsDefault),
+	}, nil
+}
+
+// describeSubnet describes a single subnet
+func (a *Adapter) describeSubnet(ctx context.Context, subnetID string) (*Subnet, error) {
+	// Call the DescribeSubnets API
+	output, err := a.client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		SubnetIds: []string{subnetID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe subnet %s: %w", subnetID, err)
+	}
+	if len(output.Subnets) == 0 {
+		return nil, fmt.Errorf("subnet %s not found", subnetID)
+	}
+
+	subnet := output.Subnets[0]
+	return &Subnet{
+		ID:                  aws.ToString(subnet.SubnetId),
+		Name:                nameTag(subnet.Tags),
+		CIDR:                aws.ToString(subnet.CidrBlock),
+		AZ:                  aws.ToString(subnet.AvailabilityZone),
+		MapPublicIPOnLaunch: aws.ToBool(subnet.MapPublicIpOnLaunch),
+	}, nil
+}
+
+// describeSecurityGroups describes security groups, keeping the order of groupIDs
+func (a *Adapter) describeSecurityGroups(ctx context.Context, groupIDs []string) ([]SecurityGroup, error) {
+	// Call the DescribeSecurityGroups API
+	output, err := a.client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: groupIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe security groups %s: %w", strings.Join(groupIDs, ", "), err)
+	}
+
+	byID := make(map[string]SecurityGroup, len(output.SecurityGroups))
+	for _, group := range output.SecurityGroups {
+		byID[aws.ToString(group.GroupId)] = SecurityGroup{
+			ID:            aws.ToString(group.GroupId),
+			Name:          aws.ToString(group.GroupName),
+			Description:   aws.ToString(group.Description),
+			InboundRules:  len(group.IpPermissions),
+			OutboundRules: len(group.IpPermissionsEgress),
+		}
+	}
+
+	groups := make([]SecurityGroup, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if group, ok := byID[id]; ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// describeAttachedVolumes describes the EBS volumes attached to an instance
+func (a *Adapter) describeAttachedVolumes(ctx context.Context, instanceID string) ([]Volume, error) {
+	// Create paginator for the DescribeVolumes API
+	paginator := ec2.NewDescribeVolumesPaginator(a.client, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{CreateFilter("attachment.instance-id", instanceID)},
+	})
+
+	var volumes []Volume
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe volumes of EC2 instance %s: %w", instanceID, err)
+		}
+
+		for _, vol := range output.Volumes {
+			volume := Volume{
+				ID:        aws.ToString(vol.VolumeId),
+				SizeGiB:   aws.ToInt32(vol.Size),
+				Type:      string(vol.VolumeType),
+				State:     string(vol.State),
+				Encrypted: aws.ToBool(vol.Encrypted),
+			}
+			for _, attachment := range vol.Attachments {
+				if aws.ToString(attachment.InstanceId) == instanceID {
+					volume.Device = aws.ToString(attachment.Device)
+					volume.DeleteOnTermination = aws.ToBool(attachment.DeleteOnTermination)
+				}
+			}
+			volumes = append(volumes, volume)
+		}
+	}
+
+	// Order the volumes by device name, so the root device usually comes first
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Device < volumes[j].Device
+	})
+
+	return volumes, nil
+}
+
+// Tree renders the topology as a tree, with the instance at the root
+func (t *Topology) Tree() string {
+	var sb strings.Builder
+
+	inst := t.Instance
+	fmt.Fprintf(&sb, "Instance %s%s %s %s\n", inst.ID, label(inst.Name), inst.Type, inst.State)
+
+	var sections []treeNode
+
+	if t.VPC != nil {
+		vpc := treeNode{text: fmt.Sprintf("VPC %s%s %s", t.VPC.ID, label(t.VPC.Name), t.VPC.CIDR)}
+		if t.VPC.IsDefault {
+			vpc.text += " (default)"
+		}
+		if t.Subnet != nil {
+			subnet := fmt.Sprintf("Subnet %s%s %s %s", t.Subnet.ID, label(t.Subnet.Name), t.Subnet.CIDR, t.Subnet.AZ)
+			if t.Subnet.MapPublicIPOnLaunch {
+				subnet += " (public IPs on launch)"
+			}
+			vpc.children = append(vpc.children, treeNode{text: subnet})
+		}
+		sections = append(sections, vpc)
+	}
+
+	addresses := treeNode{text: "Addresses"}
+	if inst.PrivateIP != "" {
+		addresses.children = append(addresses.children, treeNode{text: "Private " + inst.PrivateIP})
+	}
+	for _, eip := range inst.ElasticIPs {
+		addresses.children = append(addresses.children, treeNode{text: fmt.Sprintf("Elastic IP %s (%s)", eip.PublicIP, eip.AllocationID)})
+	}
+	if inst.PublicIP != "" && len(inst.ElasticIPs) == 0 {
+		addresses.children = append(addresses.children, treeNode{text: "Public " + inst.PublicIP})
+	}
+	if len(addresses.children) > 0 {
+		sections = append(sections, addresses)
+	}
+
+	if len(t.SecurityGroups) > 0 {
+		groups := treeNode{text: "Security groups"}
+		for _, sg := range t.SecurityGroups {
+			groups.children = append(groups.children, treeNode{
+				text: fmt.Sprintf("%s%s %d inbound, %d outbound rules", sg.ID, label(sg.Name), sg.InboundRules, sg.OutboundRules),
+			})
+		}
+		sections = append(sections, groups)
+	}
+
+	if len(t.Volumes) > 0 {
+		volumes := treeNode{text: "Volumes"}
+		for _, vol := range t.Volumes {
+			text := fmt.Sprintf("%s %s %d GiB %s %s", vol.ID, vol.Device, vol.SizeGiB, vol.Type, vol.State)
+			if vol.Encrypted {
+				text += " encrypted"
+			}
+			volumes.children = append(volumes.children, treeNode{text: text})
+		}
+		sections = append(sections, volumes)
+	}
+
+	writeTree(&sb, sections, "")
+	return sb.String()
+}
+
+// treeNode is a line of a rendered tree with the lines nested below it
+type treeNode struct {
+	text     string
+	children []treeNode
+}
+
+// writeTree writes nodes, and recursively their children, as tree lines
+// starting with prefix
+func writeTree(sb *strings.Builder, nodes []treeNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		sb.WriteString(prefix + branch + node.text + "\n")
+		writeTree(sb, node.children, prefix+indent)
+	}
+}
+
+// label formats a resource name for display after its ID, or returns an
+// empty string if the resource has no name
+func label(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
+
+// nameTag returns the value of the Name tag, or an empty string if there is none
+func nameTag(tags []types.Tag) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == "Name" {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
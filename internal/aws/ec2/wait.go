@@ -0,0 +1,89 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// WaitForInstanceState waits until an EC2 instance is running after a start or
+// stopped after a stop, with the InstanceRunning and InstanceStopped waiters
+// of the SDK.
+//
+// Parameters:
+//   - ctx: Context for the API calls; its deadline limits how long to wait
+//   - instanceID: The ID of the EC2 instance
+//   - state: The state to wait for, running or stopped
+//   - interval: How long to wait before the first retry; later retries back off
+//
+// Returns an error if the state cannot be waited for, the instance cannot be
+// described, it reaches a state from which it cannot get to the requested one,
+// such as terminated, or ctx is done first.
+func (a *Adapter) WaitForInstanceState(ctx context.Context, instanceID string, state types.InstanceStateName, interval time.Duration) error {
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: []string{instanceID},
+	}
+
+	var err error
+	switch state {
+	case types.InstanceStateNameRunning:
+		waiter := ec2.NewInstanceRunningWaiter(a.client, func(o *ec2.InstanceRunningWaiterOptions) {
+			o.MinDelay = interval
+			o.MaxDelay = max(o.MaxDelay, interval)
+		})
+		err = waiter.Wait(ctx, input, client.MaxWait(ctx))
+	case types.InstanceStateNameStopped:
+		waiter := ec2.NewInstanceStoppedWaiter(a.client, func(o *ec2.InstanceStoppedWaiterOptions) {
+			o.MinDelay = interval
+			o.MaxDelay = max(o.MaxDelay, interval)
+		})
+		err = waiter.Wait(ctx, input, client.MaxWait(ctx))
+	default:
+		return fmt.Errorf("cannot wait for EC2 instance %s to be %s: only running and stopped are supported", instanceID, state)
+	}
+	if err != nil {
+		return fmt.Errorf("EC2 instance %s did not become %s: %w", instanceID, state, err)
+	}
+	return nil
+}
+
+// WaitForInstanceStatusOK waits until both the instance and system status
+// checks of an EC2 instance pass, like `aws ec2 wait instance-status-ok`, with
+// the InstanceStatusOk and SystemStatusOk waiters of the SDK.
+//
+// Parameters:
+//   - ctx: Context for the API calls; its deadline limits how long to wait
+//   - instanceID: The ID of the EC2 instance
+//   - interval: How long to wait before the first retry; later retries back off
+//
+// Returns an error if the status cannot be described or ctx is done first.
+func (a *Adapter) WaitForInstanceStatusOK(ctx context.Context, instanceID string, interval time.Duration) error {
+	input := &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         []string{instanceID},
+		IncludeAllInstances: aws.Bool(true),
+	}
+
+	// Checks can be impaired for a moment while an instance reboots, which
+	// the waiters retry, so only the deadline ends the wait
+	instanceWaiter := ec2.NewInstanceStatusOkWaiter(a.client, func(o *ec2.InstanceStatusOkWaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	if err := instanceWaiter.Wait(ctx, input, client.MaxWait(ctx)); err != nil {
+		return fmt.Errorf("failed waiting for the instance status checks of EC2 instance %s: %w", instanceID, err)
+	}
+
+	systemWaiter := ec2.NewSystemStatusOkWaiter(a.client, func(o *ec2.SystemStatusOkWaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	if err := systemWaiter.Wait(ctx, input, client.MaxWait(ctx)); err != nil {
+		return fmt.Errorf("failed waiting for the system status checks of EC2 instance %s: %w", instanceID, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// SetReservedConcurrency reserves concurrency for a Lambda function: the
+// function can run at most limit instances at once, and that many are set aside
+// from the account's unreserved concurrency. A limit of 0 throttles all
+// invocations of the function.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - functionName: The name or ARN of the Lambda function
+//   - limit: The number of concurrent executions to reserve
+//
+// Returns an error if the limit is negative or the operation fails.
+func (a *Adapter) SetReservedConcurrency(ctx context.Context, functionName string, limit int32) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid reserved concurrency: %d", limit)
+	}
+
+	_, err := a.client.PutFunctionConcurrency(ctx, &lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 aws.String(functionName),
+		ReservedConcurrentExecutions: aws.Int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set reserved concurrency of Lambda function %s: %w", functionName, err)
+	}
+
+	return nil
+}
+
+// DeleteReservedConcurrency removes the reserved concurrency of a Lambda
+// function, so that it uses the account's unreserved concurrency again.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - functionName: The name or ARN of the Lambda function
+//
+// Returns an error if the operation fails.
+func (a *Adapter) DeleteReservedConcurrency(ctx context.Context, functionName string) error {
+	_, err := a.client.DeleteFunctionConcurrency(ctx, &lambda.DeleteFunctionConcurrencyInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear reserved concurrency of Lambda function %s: %w", functionName, err)
+	}
+
+	return nil
+}
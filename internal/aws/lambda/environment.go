@@ -0,0 +1,152 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// EnvVar is an environment variable of a Lambda function
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvVars returns the environment variables of a function sorted by name, for
+// display as a list.
+func EnvVars(env map[string]string) []EnvVar {
+	vars := make([]EnvVar, 0, len(env))
+	for name, value := range env {
+		vars = append(vars, EnvVar{Name: name, Value: value})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// ParseEnvAssignments parses KEY=VALUE arguments into environment variables.
+// The value may contain '=' and may be empty; later assignments of a key
+// override earlier ones.
+//
+// Returns an error if an argument has no '=' or an empty key.
+func ParseEnvAssignments(assignments []string) (map[string]string, error) {
+	vars := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid environment variable %q (expected KEY=VALUE)", assignment)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// UpdateEnvironment sets environment variables of a Lambda function.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - functionName: The name or ARN of the Lambda function
+//   - vars: The variables to set
+//   - replace: Whether vars replace all variables of the function, rather than
+//     being merged with them
+//
+// When merging, the update is rejected by Lambda if the function was changed
+// since its variables were read, so concurrent changes are not lost.
+//
+// Returns the variables of the function after the update, or an error if the
+// function cannot be read or updated.
+func (a *Adapter) UpdateEnvironment(ctx context.Context, functionName string, vars map[string]string, replace bool) (map[string]string, error) {
+	if replace {
+		return a.putEnvironment(ctx, functionName, vars, "")
+	}
+
+	env, revisionID, err := a.currentEnvironment(ctx, functionName)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range vars {
+		env[name] = value
+	}
+
+	return a.putEnvironment(ctx, functionName, env, revisionID)
+}
+
+// UnsetEnvironment removes environment variables from a Lambda function. Names
+// of variables that are not set are ignored; if none is set, the function is
+// not updated.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - functionName: The name or ARN of the Lambda function
+//   - names: The names of the variables to remove
+//
+// Returns the variables of the function after the update, or an error if the
+// function cannot be read or updated.
+func (a *Adapter) UnsetEnvironment(ctx context.Context, functionName string, names []string) (map[string]string, error) {
+	env, revisionID, err := a.currentEnvironment(ctx, functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, name := range names {
+		if _, ok := env[name]; ok {
+			delete(env, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return env, nil
+	}
+
+	return a.putEnvironment(ctx, functionName, env, revisionID)
+}
+
+// currentEnvironment returns a copy of the environment variables of a function
+// and the revision of its configuration they were read from
+func (a *Adapter) currentEnvironment(ctx context.Context, functionName string) (map[string]string, string, error) {
+	output, err := a.client.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get Lambda function %s: %w", functionName, err)
+	}
+
+	env := make(map[string]string)
+	if output.Configuration == nil {
+		return env, "", nil
+	}
+	if output.Configuration.Environment != nil {
+		for name, value := range output.Configuration.Environment.Variables {
+			env[name] = value
+		}
+	}
+	return env, aws.ToString(output.Configuration.RevisionId), nil
+}
+
+// putEnvironment sets the environment variables of a function to env. A non-empty
+// revisionID makes the update fail if the configuration has changed since.
+func (a *Adapter) putEnvironment(ctx context.Context, functionName string, env map[string]string, revisionID string) (map[string]string, error) {
+	input := &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String(functionName),
+		Environment:  &types.Environment{Variables: env},
+	}
+	if revisionID != "" {
+		input.RevisionId = aws.String(revisionID)
+	}
+
+	output, err := a.client.UpdateFunctionConfiguration(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the environment of Lambda function %s: %w", functionName, err)
+	}
+
+	// Lambda reports the variables it stored
+	if output.Environment != nil && output.Environment.Variables != nil {
+		return output.Environment.Variables, nil
+	}
+	return env, nil
+}
@@ -5,8 +5,15 @@ package lambda
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ao/awsm/internal/aws/client"
@@ -22,6 +29,9 @@ type LambdaClient interface {
 	ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
 	GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
 	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+	UpdateFunctionConfiguration(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error)
+	PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error)
+	DeleteFunctionConcurrency(ctx context.Context, params *lambda.DeleteFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionConcurrencyOutput, error)
 }
 
 // CloudWatchLogsClient defines the interface for CloudWatch Logs client operations.
@@ -42,24 +52,29 @@ type Adapter struct {
 // This is a simplified representation of the AWS Lambda function configuration
 // that includes only the most commonly used fields.
 type Function struct {
-	Name         string            // Name of the Lambda function
-	Description  string            // Description of the function
-	Runtime      string            // Runtime environment (e.g., nodejs14.x, python3.9)
-	Handler      string            // Function handler (e.g., index.handler)
-	Role         string            // IAM role ARN used by the function
-	Size         int64             // Size of the function code in bytes
-	Timeout      int32             // Function timeout in seconds
-	Memory       int32             // Memory allocation in MB
-	LastModified string            // When the function was last modified
-	Version      string            // Function version
-	Environment  map[string]string // Environment variables
-	Tags         map[string]string // Function tags
+	Name                string            // Name of the Lambda function
+	ARN                 string            // ARN of the Lambda function
+	Description         string            // Description of the function
+	Runtime             string            // Runtime environment (e.g., nodejs14.x, python3.9)
+	Deprecated          bool              // Whether the runtime is deprecated by AWS
+	Handler             string            // Function handler (e.g., index.handler)
+	Role                string            // IAM role ARN used by the function
+	Size                int64             // Size of the function code in bytes
+	Timeout             int32             // Function timeout in seconds
+	Memory              int32             // Memory allocation in MB
+	LastModified        string            // When the function was last modified
+	Version             string            // Function version
+	Environment         map[string]string // Environment variables
+	Tags                map[string]string // Function tags
+	ReservedConcurrency *int32            // Reserved concurrent executions, nil if none (set by GetFunction)
 }
 
 // LogEvent represents a CloudWatch log event from a Lambda function execution.
 type LogEvent struct {
-	Timestamp int64  // Unix timestamp in milliseconds
-	Message   string // Log message content
+	Timestamp int64       // Unix timestamp in milliseconds
+	Function  string      `json:"Function,omitempty" yaml:"function,omitempty"` // Function that logged the event, set when the logs of several functions are merged
+	Message   string      // Log message content
+	Data      interface{} // Message parsed as JSON, nil if the message isn't a JSON object or array
 }
 
 // InvokeResult represents the result of a Lambda function invocation.
@@ -115,6 +130,11 @@ func (a *Adapter) ListFunctions(ctx context.Context, maxItems int32) ([]Function
 	// Create the input for the ListFunctions API
 	input := &lambda.ListFunctionsInput{}
 
+	// Don't fetch larger pages than needed (the API returns at most 50 functions per page)
+	if maxItems > 0 {
+		input.MaxItems = aws.Int32(min(maxItems, 50))
+	}
+
 	// Create paginator
 	paginator := lambda.NewListFunctionsPaginator(a.client, input)
 
@@ -173,6 +193,11 @@ func (a *Adapter) GetFunction(ctx context.Context, functionName string) (*Functi
 		function.Tags = output.Tags
 	}
 
+	// Add the reserved concurrency if any is set
+	if output.Concurrency != nil {
+		function.ReservedConcurrency = output.Concurrency.ReservedConcurrentExecutions
+	}
+
 	return &function, nil
 }
 
@@ -210,16 +235,38 @@ func (a *Adapter) InvokeFunction(ctx context.Context, functionName string, paylo
 	return result, nil
 }
 
+// DecodeLogResult decodes the Base64-encoded execution log returned by InvokeFunction.
+// The log holds the last 4KB of output, including the START, END and REPORT lines.
+//
+// Parameters:
+//   - result: The result of a Lambda function invocation
+//
+// Returns the decoded log (empty if the invocation returned no log) and an error
+// if the log is not valid Base64.
+func DecodeLogResult(result *InvokeResult) (string, error) {
+	if result == nil || result.LogResult == "" {
+		return "", nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(result.LogResult)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode execution log: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
 // GetFunctionLogs gets the CloudWatch logs for a Lambda function.
 //
 // Parameters:
 //   - ctx: Context for the API call
 //   - functionName: The name of the Lambda function
 //   - startTime: The start time for log retrieval (zero value for no start time)
+//   - endTime: The end time for log retrieval (zero value for no end time)
 //   - limit: Maximum number of log events to return (0 for no limit)
 //
 // Returns a slice of LogEvent structs and an error if the operation fails.
-func (a *Adapter) GetFunctionLogs(ctx context.Context, functionName string, startTime time.Time, limit int32) ([]LogEvent, error) {
+func (a *Adapter) GetFunctionLogs(ctx context.Context, functionName string, startTime, endTime time.Time, limit int32) ([]LogEvent, error) {
 	// Get the log group name for the Lambda function
 	logGroupName := fmt.Sprintf("/aws/lambda/%s", functionName)
 
@@ -235,6 +282,11 @@ func (a *Adapter) GetFunctionLogs(ctx context.Context, functionName string, star
 		input.StartTime = aws.Int64(startTimeMillis)
 	}
 
+	// Add end time if provided
+	if !endTime.IsZero() {
+		input.EndTime = aws.Int64(endTime.UnixMilli())
+	}
+
 	// Call the FilterLogEvents API
 	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(a.logsClient, input)
 
@@ -256,10 +308,7 @@ func (a *Adapter) GetFunctionLogs(ctx context.Context, functionName string, star
 			}
 
 			// Extract log event information
-			logEvent := LogEvent{
-				Timestamp: aws.ToInt64(event.Timestamp),
-				Message:   aws.ToString(event.Message),
-			}
+			logEvent := newLogEvent(aws.ToInt64(event.Timestamp), aws.ToString(event.Message))
 
 			logEvents = append(logEvents, logEvent)
 			count++
@@ -269,6 +318,78 @@ func (a *Adapter) GetFunctionLogs(ctx context.Context, functionName string, star
 	return logEvents, nil
 }
 
+// GetFunctionsLogs gets the CloudWatch logs of several Lambda functions,
+// merged in timestamp order, with Function set to the name of the function
+// that logged each event.
+//
+// Parameters:
+//   - ctx: Context for the API calls
+//   - functionNames: The names of the Lambda functions
+//   - startTime: The start time for log retrieval (zero value for no start time)
+//   - endTime: The end time for log retrieval (zero value for no end time)
+//   - limit: Maximum number of log events to return in total (0 for no limit)
+//
+// Returns the oldest limit events of all functions, or an error if the logs of
+// any function cannot be read.
+func (a *Adapter) GetFunctionsLogs(ctx context.Context, functionNames []string, startTime, endTime time.Time, limit int32) ([]LogEvent, error) {
+	var logEvents []LogEvent
+	for _, functionName := range functionNames {
+		// The oldest events of each function are enough to find the oldest overall
+		events, err := a.GetFunctionLogs(ctx, functionName, startTime, endTime, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			event.Function = functionName
+			logEvents = append(logEvents, event)
+		}
+	}
+
+	sort.SliceStable(logEvents, func(i, j int) bool {
+		return logEvents[i].Timestamp < logEvents[j].Timestamp
+	})
+	if limit > 0 && len(logEvents) > int(limit) {
+		logEvents = logEvents[:limit]
+	}
+
+	return logEvents, nil
+}
+
+// newLogEvent creates a LogEvent, parsing the message if it is structured JSON
+func newLogEvent(timestamp int64, message string) LogEvent {
+	event := LogEvent{
+		Timestamp: timestamp,
+		Message:   message,
+	}
+
+	// Only whole-message JSON objects and arrays count as structured logs
+	trimmed := strings.TrimSpace(message)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var data interface{}
+		if err := json.Unmarshal([]byte(trimmed), &data); err == nil {
+			event.Data = data
+		}
+	}
+
+	return event
+}
+
+// FormatLogEvent formats a log event as a "[timestamp] message" line, or a
+// "[timestamp] function: message" line if the event names its function.
+//
+// Parameters:
+//   - event: The log event to format
+//
+// Returns the formatted line without a trailing newline.
+func FormatLogEvent(event LogEvent) string {
+	timestamp := time.UnixMilli(event.Timestamp).Format("2006-01-02 15:04:05.000")
+	message := strings.TrimRight(event.Message, "\n")
+	if event.Function != "" {
+		return fmt.Sprintf("[%s] %s: %s", timestamp, event.Function, message)
+	}
+	return fmt.Sprintf("[%s] %s", timestamp, message)
+}
+
 // extractFunctionInfo extracts relevant information from a Lambda function configuration
 // and converts it to our simplified Function struct.
 //
@@ -277,6 +398,7 @@ func extractFunctionInfo(function types.FunctionConfiguration) Function {
 	// Initialize the function
 	fn := Function{
 		Name:         aws.ToString(function.FunctionName),
+		ARN:          aws.ToString(function.FunctionArn),
 		Runtime:      string(function.Runtime),
 		Handler:      aws.ToString(function.Handler),
 		Role:         aws.ToString(function.Role),
@@ -289,6 +411,9 @@ func extractFunctionInfo(function types.FunctionConfiguration) Function {
 		Tags:         make(map[string]string),
 	}
 
+	// Flag deprecated runtimes
+	fn.Deprecated = IsDeprecatedRuntime(fn.Runtime)
+
 	// Extract description if available
 	if function.Description != nil {
 		fn.Description = *function.Description
@@ -317,6 +442,100 @@ func FormatPayload(data interface{}) ([]byte, error) {
 	return payload, nil
 }
 
+// ReadPayload returns the payload for a Lambda function invocation from either an
+// inline JSON string or a file. A path of "-" reads the payload from stdin. When
+// neither is given, the payload is an empty JSON object.
+//
+// Parameters:
+//   - inline: The payload as a JSON string (can be empty)
+//   - path: The path of a file containing the JSON payload (can be empty)
+//   - stdin: The reader used when path is "-"
+//   - lookupEnv: Looks up the environment variables referenced as ${NAME} in the
+//     payload (e.g. os.LookupEnv), or nil to leave the payload unexpanded
+//
+// Returns the payload bytes and an error if both sources are given, the file
+// cannot be read, a referenced variable is not set, or the payload is not
+// well-formed JSON.
+func ReadPayload(inline, path string, stdin io.Reader, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	if inline != "" && path != "" {
+		return nil, fmt.Errorf("an inline payload and a payload file cannot be used together")
+	}
+
+	var payload []byte
+	switch {
+	case path == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload from stdin: %w", err)
+		}
+		payload = data
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload file %s: %w", path, err)
+		}
+		payload = data
+	case inline != "":
+		payload = []byte(inline)
+	default:
+		return FormatPayload(map[string]interface{}{})
+	}
+
+	if lookupEnv != nil {
+		expanded, err := ExpandPayloadEnv(payload, lookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		payload = expanded
+	}
+
+	// Catch malformed input before it reaches the function
+	if !json.Valid(payload) {
+		return nil, fmt.Errorf("payload is not valid JSON")
+	}
+
+	return payload, nil
+}
+
+// envReference matches a ${NAME} environment variable reference
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandPayloadEnv replaces ${NAME} references in a payload with the values of
+// the environment variables. Values are escaped as JSON string content, so they
+// can be used inside strings (e.g. {"user":"${USER}"}) whatever characters they
+// contain, and numeric values can also be used outside strings
+// (e.g. {"count":${COUNT}}). Other uses of $, such as $NAME, are left alone.
+//
+// Parameters:
+//   - payload: The payload to expand
+//   - lookupEnv: Looks up an environment variable (e.g. os.LookupEnv)
+//
+// Returns the expanded payload and an error naming the referenced variables
+// that are not set.
+func ExpandPayloadEnv(payload []byte, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllFunc(payload, func(reference []byte) []byte {
+		name := string(envReference.FindSubmatch(reference)[1])
+		value, ok := lookupEnv(name)
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return reference
+		}
+
+		// Marshal the value as a JSON string and drop the surrounding quotes
+		quoted, _ := json.Marshal(value)
+		return quoted[1 : len(quoted)-1]
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("payload references unset environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
 // ParsePayload parses a Lambda function invocation result payload into
 // a provided Go data structure.
 //
@@ -331,3 +550,61 @@ func ParsePayload(payload []byte, v interface{}) error {
 	}
 	return nil
 }
+
+// UnwrapBody replaces JSON that a response carries as a string with the parsed
+// value, so it can be printed as nested data rather than escaped text. This
+// covers a response that is itself a JSON string containing JSON, and the body
+// field of API Gateway proxy responses, base64-decoded first when the response
+// sets isBase64Encoded.
+//
+// Only strings holding a JSON object or array are unwrapped; other strings,
+// such as a plain-text or HTML body, are left alone.
+//
+// Parameters:
+//   - response: The parsed response payload
+//
+// Returns the response with its nested JSON unwrapped.
+func UnwrapBody(response interface{}) interface{} {
+	if s, ok := response.(string); ok {
+		if nested, ok := parseNestedJSON([]byte(s)); ok {
+			return UnwrapBody(nested)
+		}
+		return response
+	}
+
+	fields, ok := response.(map[string]interface{})
+	if !ok {
+		return response
+	}
+	body, ok := fields["body"].(string)
+	if !ok {
+		return response
+	}
+
+	data := []byte(body)
+	if encoded, _ := fields["isBase64Encoded"].(bool); encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return response
+		}
+		data = decoded
+	}
+	if nested, ok := parseNestedJSON(data); ok {
+		fields["body"] = nested
+	}
+	return response
+}
+
+// parseNestedJSON parses data if it holds a JSON object or array
+func parseNestedJSON(data []byte) (interface{}, bool) {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(trimmed), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -3,6 +3,10 @@ package lambda
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +40,21 @@ func (m *mockLambdaClient) Invoke(ctx context.Context, params *lambda.InvokeInpu
 	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
 }
 
+func (m *mockLambdaClient) UpdateFunctionConfiguration(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*lambda.UpdateFunctionConfigurationOutput), args.Error(1)
+}
+
+func (m *mockLambdaClient) PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*lambda.PutFunctionConcurrencyOutput), args.Error(1)
+}
+
+func (m *mockLambdaClient) DeleteFunctionConcurrency(ctx context.Context, params *lambda.DeleteFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionConcurrencyOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*lambda.DeleteFunctionConcurrencyOutput), args.Error(1)
+}
+
 // mockCloudWatchLogsClient implements the CloudWatchLogsClient interface for testing purposes.
 // It uses the testify/mock package to mock AWS CloudWatch Logs API calls.
 type mockCloudWatchLogsClient struct {
@@ -183,6 +202,42 @@ func TestListFunctions(t *testing.T) {
 	mockLambdaClient.AssertExpectations(t)
 }
 
+// TestListFunctionsLimit tests the ListFunctions method of the Lambda Adapter with a limit.
+// It verifies that the page size is capped to the limit and that no further
+// pages are fetched once the limit is reached.
+func TestListFunctionsLimit(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	mockLogsClient := new(mockCloudWatchLogsClient)
+
+	// Create adapter with mock clients
+	adapter := NewAdapterWithClients(mockLambdaClient, mockLogsClient)
+
+	// Create mock response with more pages available
+	mockResponse := &lambda.ListFunctionsOutput{
+		Functions: []types.FunctionConfiguration{
+			createMockFunctionConfiguration("function-1", "", "python3.12", "index.handler", "", 0, 3, 128, "", "1", nil),
+		},
+		NextMarker: aws.String("next"),
+	}
+
+	// Set up expectations
+	mockLambdaClient.On("ListFunctions", mock.Anything, mock.MatchedBy(func(input *lambda.ListFunctionsInput) bool {
+		return aws.ToInt32(input.MaxItems) == 1
+	}), mock.Anything).Return(mockResponse, nil)
+
+	// Call the function
+	ctx := context.Background()
+	functions, err := adapter.ListFunctions(ctx, 1)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert only the first page was fetched
+	assert.Len(t, functions, 1)
+	mockLambdaClient.AssertNumberOfCalls(t, "ListFunctions", 1)
+}
+
 // TestGetFunction tests the GetFunction method of the Lambda Adapter.
 // It verifies that the adapter correctly processes the AWS API response
 // and returns the expected function details, including tags.
@@ -219,6 +274,7 @@ func TestGetFunction(t *testing.T) {
 	mockResponse := &lambda.GetFunctionOutput{
 		Configuration: &function,
 		Tags:          tags,
+		Concurrency:   &types.Concurrency{ReservedConcurrentExecutions: aws.Int32(25)},
 	}
 
 	// Set up expectations
@@ -244,11 +300,152 @@ func TestGetFunction(t *testing.T) {
 	assert.Equal(t, "value", result.Environment["ENV_VAR"])
 	assert.Equal(t, "test", result.Tags["Environment"])
 	assert.Equal(t, "awsm", result.Tags["Project"])
+	assert.Equal(t, aws.Int32(25), result.ReservedConcurrency)
 
 	// Verify expectations
 	mockLambdaClient.AssertExpectations(t)
 }
 
+// TestUpdateEnvironment tests the UpdateEnvironment method of the Lambda Adapter.
+// It verifies that the variables are merged with the function's current ones
+// at the revision they were read from, and that replace overwrites them
+// without reading the function.
+func TestUpdateEnvironment(t *testing.T) {
+	current := createMockFunctionConfiguration("api", "", "python3.12", "app.handler", "", 0, 3, 128, "", "$LATEST", map[string]string{
+		"STAGE":     "dev",
+		"LOG_LEVEL": "info",
+	})
+	current.RevisionId = aws.String("rev-1")
+
+	t.Run("Merge", func(t *testing.T) {
+		// Create mock clients
+		mockLambdaClient := new(mockLambdaClient)
+		adapter := NewAdapterWithClients(mockLambdaClient, new(mockCloudWatchLogsClient))
+
+		// Set up expectations
+		want := map[string]string{"STAGE": "prod", "LOG_LEVEL": "info", "DEBUG": "1"}
+		mockLambdaClient.On("GetFunction", mock.Anything, mock.Anything, mock.Anything).Return(&lambda.GetFunctionOutput{Configuration: &current}, nil)
+		mockLambdaClient.On("UpdateFunctionConfiguration", mock.Anything, mock.MatchedBy(func(input *lambda.UpdateFunctionConfigurationInput) bool {
+			return aws.ToString(input.FunctionName) == "api" &&
+				aws.ToString(input.RevisionId) == "rev-1" &&
+				assert.ObjectsAreEqual(want, input.Environment.Variables)
+		}), mock.Anything).Return(&lambda.UpdateFunctionConfigurationOutput{
+			Environment: &types.EnvironmentResponse{Variables: want},
+		}, nil)
+
+		// Call the function
+		env, err := adapter.UpdateEnvironment(context.Background(), "api", map[string]string{"STAGE": "prod", "DEBUG": "1"}, false)
+
+		// Assert no error
+		assert.NoError(t, err)
+		assert.Equal(t, want, env)
+
+		// The function's configuration is not modified in place
+		assert.Equal(t, "dev", current.Environment.Variables["STAGE"])
+
+		// Verify expectations
+		mockLambdaClient.AssertExpectations(t)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		// Create mock clients
+		mockLambdaClient := new(mockLambdaClient)
+		adapter := NewAdapterWithClients(mockLambdaClient, new(mockCloudWatchLogsClient))
+
+		// Set up expectations
+		want := map[string]string{"STAGE": "prod"}
+		mockLambdaClient.On("UpdateFunctionConfiguration", mock.Anything, mock.MatchedBy(func(input *lambda.UpdateFunctionConfigurationInput) bool {
+			return input.RevisionId == nil && assert.ObjectsAreEqual(want, input.Environment.Variables)
+		}), mock.Anything).Return(&lambda.UpdateFunctionConfigurationOutput{
+			Environment: &types.EnvironmentResponse{Variables: want},
+		}, nil)
+
+		// Call the function
+		env, err := adapter.UpdateEnvironment(context.Background(), "api", want, true)
+
+		// Assert no error
+		assert.NoError(t, err)
+		assert.Equal(t, want, env)
+
+		// Verify expectations
+		mockLambdaClient.AssertExpectations(t)
+		mockLambdaClient.AssertNotCalled(t, "GetFunction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		// Create mock clients
+		mockLambdaClient := new(mockLambdaClient)
+		adapter := NewAdapterWithClients(mockLambdaClient, new(mockCloudWatchLogsClient))
+
+		// Set up expectations
+		mockLambdaClient.On("GetFunction", mock.Anything, mock.Anything, mock.Anything).Return(&lambda.GetFunctionOutput{Configuration: &current}, nil)
+		mockLambdaClient.On("UpdateFunctionConfiguration", mock.Anything, mock.MatchedBy(func(input *lambda.UpdateFunctionConfigurationInput) bool {
+			return assert.ObjectsAreEqual(map[string]string{"STAGE": "dev"}, input.Environment.Variables)
+		}), mock.Anything).Return(&lambda.UpdateFunctionConfigurationOutput{}, nil).Once()
+
+		// Call the function
+		env, err := adapter.UnsetEnvironment(context.Background(), "api", []string{"LOG_LEVEL", "MISSING"})
+
+		// Assert no error
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"STAGE": "dev"}, env)
+
+		// Unsetting only variables that are not set doesn't update the function
+		_, err = adapter.UnsetEnvironment(context.Background(), "api", []string{"MISSING"})
+		assert.NoError(t, err)
+
+		// Verify expectations
+		mockLambdaClient.AssertExpectations(t)
+		mockLambdaClient.AssertNumberOfCalls(t, "UpdateFunctionConfiguration", 1)
+	})
+}
+
+// TestReservedConcurrency tests the SetReservedConcurrency and
+// DeleteReservedConcurrency methods of the Lambda Adapter.
+// It verifies that the limit is passed to PutFunctionConcurrency, that a
+// negative limit is rejected without a call, and that clearing calls
+// DeleteFunctionConcurrency.
+func TestReservedConcurrency(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	adapter := NewAdapterWithClients(mockLambdaClient, new(mockCloudWatchLogsClient))
+
+	// Set up expectations
+	mockLambdaClient.On("PutFunctionConcurrency", mock.Anything, mock.MatchedBy(func(input *lambda.PutFunctionConcurrencyInput) bool {
+		return aws.ToString(input.FunctionName) == "api" && aws.ToInt32(input.ReservedConcurrentExecutions) == 10
+	}), mock.Anything).Return(&lambda.PutFunctionConcurrencyOutput{ReservedConcurrentExecutions: aws.Int32(10)}, nil)
+	mockLambdaClient.On("DeleteFunctionConcurrency", mock.Anything, mock.MatchedBy(func(input *lambda.DeleteFunctionConcurrencyInput) bool {
+		return aws.ToString(input.FunctionName) == "api"
+	}), mock.Anything).Return(&lambda.DeleteFunctionConcurrencyOutput{}, nil)
+
+	// Call the functions
+	ctx := context.Background()
+	assert.NoError(t, adapter.SetReservedConcurrency(ctx, "api", 10))
+	assert.NoError(t, adapter.DeleteReservedConcurrency(ctx, "api"))
+	assert.Error(t, adapter.SetReservedConcurrency(ctx, "api", -1))
+
+	// Verify expectations
+	mockLambdaClient.AssertExpectations(t)
+	mockLambdaClient.AssertNumberOfCalls(t, "PutFunctionConcurrency", 1)
+}
+
+// TestParseEnvAssignments tests parsing KEY=VALUE arguments.
+// It verifies that values may contain '=' or be empty, and that arguments
+// without a key or '=' are rejected.
+func TestParseEnvAssignments(t *testing.T) {
+	vars, err := ParseEnvAssignments([]string{"STAGE=prod", "URL=https://x?a=b", "EMPTY="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"STAGE": "prod", "URL": "https://x?a=b", "EMPTY": ""}, vars)
+
+	for _, invalid := range []string{"STAGE", "=prod"} {
+		_, err := ParseEnvAssignments([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+
+	// Variables are listed by name
+	assert.Equal(t, []EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, EnvVars(map[string]string{"B": "2", "A": "1"}))
+}
+
 // TestInvokeFunction tests the InvokeFunction method of the Lambda Adapter.
 // It verifies that the adapter correctly calls the AWS API with the
 // expected parameters and processes the response, including status code,
@@ -293,6 +490,28 @@ func TestInvokeFunction(t *testing.T) {
 	mockLambdaClient.AssertExpectations(t)
 }
 
+// TestDecodeLogResult tests the DecodeLogResult function.
+// It verifies that the Base64 execution log returned by an invocation is decoded,
+// that a missing log is not an error, and that invalid Base64 is reported.
+func TestDecodeLogResult(t *testing.T) {
+	// Decode the sample log used in TestInvokeFunction
+	result := &InvokeResult{
+		LogResult: "U1RBUlQgUmVxdWVzdElkOiA0NWJhOTQzYi1mZWY0LTExZTgtOTVmOC02ZmExNGMzMmVkMjAgVmVyc2lvbjogJExBVEVTVAo=",
+	}
+	log, err := DecodeLogResult(result)
+	assert.NoError(t, err)
+	assert.Equal(t, "START RequestId: 45ba943b-fef4-11e8-95f8-6fa14c32ed20 Version: $LATEST\n", log)
+
+	// An empty log is not an error
+	log, err = DecodeLogResult(&InvokeResult{})
+	assert.NoError(t, err)
+	assert.Empty(t, log)
+
+	// Invalid Base64 is reported
+	_, err = DecodeLogResult(&InvokeResult{LogResult: "not base64!"})
+	assert.Error(t, err)
+}
+
 // TestGetFunctionLogs tests the GetFunctionLogs method of the Lambda Adapter.
 // It verifies that the adapter correctly calls the CloudWatch Logs API with
 // the expected parameters and processes the log events.
@@ -323,12 +542,15 @@ func TestGetFunctionLogs(t *testing.T) {
 	}
 
 	// Set up expectations
-	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.Anything, mock.Anything).Return(mockResponse, nil)
+	startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	endTime := startTime.Add(90 * time.Minute)
+	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return aws.ToInt64(input.StartTime) == 1704164645000 && aws.ToInt64(input.EndTime) == 1704170045000
+	}), mock.Anything).Return(mockResponse, nil)
 
 	// Call the function
 	ctx := context.Background()
-	startTime := time.Now().Add(-24 * time.Hour)
-	logs, err := adapter.GetFunctionLogs(ctx, "test-function", startTime, 10)
+	logs, err := adapter.GetFunctionLogs(ctx, "test-function", startTime, endTime, 10)
 
 	// Assert no error
 	assert.NoError(t, err)
@@ -348,6 +570,233 @@ func TestGetFunctionLogs(t *testing.T) {
 	mockLogsClient.AssertExpectations(t)
 }
 
+// TestGetFunctionLogsOpenWindow tests the GetFunctionLogs method without a time window.
+// It verifies that zero start and end times leave the API fields unset.
+func TestGetFunctionLogsOpenWindow(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	mockLogsClient := new(mockCloudWatchLogsClient)
+
+	// Create adapter with mock clients
+	adapter := NewAdapterWithClients(mockLambdaClient, mockLogsClient)
+
+	// Set up expectations
+	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return input.StartTime == nil && input.EndTime == nil
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+
+	// Call the function
+	logs, err := adapter.GetFunctionLogs(context.Background(), "test-function", time.Time{}, time.Time{}, 10)
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+
+	// Verify expectations
+	mockLogsClient.AssertExpectations(t)
+}
+
+// TestTailFunctionLogs tests the log tailing of the Lambda Adapter.
+// It verifies that two overlapping batches returned by CloudWatch Logs are
+// deduplicated by timestamp and event ID, so each event is sent exactly once,
+// and that polling continues from the newest timestamp seen.
+func TestTailFunctionLogs(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	mockLogsClient := new(mockCloudWatchLogsClient)
+
+	// Create adapter with mock clients
+	adapter := NewAdapterWithClients(mockLambdaClient, mockLogsClient)
+
+	// startTimeIs matches FilterLogEvents calls starting at the given timestamp
+	startTimeIs := func(expected int64) interface{} {
+		return mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+			return aws.ToInt64(input.StartTime) == expected && aws.ToString(input.LogGroupName) == "/aws/lambda/test-function"
+		})
+	}
+
+	// Set up expectations: the second batch repeats the last event of the first
+	// one, adds another event with the same timestamp, and a newer event
+	mockLogsClient.On("FilterLogEvents", mock.Anything, startTimeIs(1000), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{EventId: aws.String("e2"), Timestamp: aws.Int64(2000), Message: aws.String("second")},
+			{EventId: aws.String("e1"), Timestamp: aws.Int64(1000), Message: aws.String("first")},
+		},
+	}, nil).Once()
+	mockLogsClient.On("FilterLogEvents", mock.Anything, startTimeIs(2000), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{EventId: aws.String("e2"), Timestamp: aws.Int64(2000), Message: aws.String("second")},
+			{EventId: aws.String("e3"), Timestamp: aws.Int64(2000), Message: aws.String("third")},
+		},
+	}, nil).Once()
+	mockLogsClient.On("FilterLogEvents", mock.Anything, startTimeIs(2000), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{EventId: aws.String("e3"), Timestamp: aws.Int64(2000), Message: aws.String("third")},
+			{EventId: aws.String("e4"), Timestamp: aws.Int64(3000), Message: aws.String("fourth")},
+		},
+	}, nil).Once()
+	mockLogsClient.On("FilterLogEvents", mock.Anything, startTimeIs(3000), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+
+	// Call the function
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := adapter.tailFunctionLogs(ctx, []string{"test-function"}, time.UnixMilli(1000), time.Millisecond)
+
+	// Collect the events
+	var messages []string
+	timeout := time.After(5 * time.Second)
+	for len(messages) < 4 {
+		select {
+		case event := <-events:
+			messages = append(messages, event.Message)
+		case err := <-errs:
+			t.Fatalf("unexpected error: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %v", messages)
+		}
+	}
+
+	// Assert each event was sent once, in order
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, messages)
+
+	// Stop tailing and assert the channels are closed
+	cancel()
+	for range events {
+	}
+	_, open := <-errs
+	assert.False(t, open)
+}
+
+// TestTailFunctionsLogs tests tailing the logs of several functions at once.
+// It verifies that the events of each poll are merged in timestamp order and
+// tagged with the function that logged them.
+func TestTailFunctionsLogs(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	mockLogsClient := new(mockCloudWatchLogsClient)
+
+	// Create adapter with mock clients
+	adapter := NewAdapterWithClients(mockLambdaClient, mockLogsClient)
+
+	// logGroupIs matches FilterLogEvents calls for the log group of a function
+	logGroupIs := func(functionName string) interface{} {
+		return mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+			return aws.ToString(input.LogGroupName) == "/aws/lambda/"+functionName
+		})
+	}
+
+	// Set up expectations: the events of the functions interleave
+	mockLogsClient.On("FilterLogEvents", mock.Anything, logGroupIs("ingest"), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{EventId: aws.String("i1"), Timestamp: aws.Int64(1000), Message: aws.String("received")},
+			{EventId: aws.String("i2"), Timestamp: aws.Int64(3000), Message: aws.String("acknowledged")},
+		},
+	}, nil).Once()
+	mockLogsClient.On("FilterLogEvents", mock.Anything, logGroupIs("load"), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{EventId: aws.String("l1"), Timestamp: aws.Int64(2000), Message: aws.String("stored")},
+		},
+	}, nil).Once()
+	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+
+	// Call the function
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := adapter.tailFunctionLogs(ctx, []string{"ingest", "load"}, time.UnixMilli(1000), time.Millisecond)
+
+	// Collect the events
+	var lines []string
+	timeout := time.After(5 * time.Second)
+	for len(lines) < 3 {
+		select {
+		case event := <-events:
+			lines = append(lines, event.Function+": "+event.Message)
+		case err := <-errs:
+			t.Fatalf("unexpected error: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %v", lines)
+		}
+	}
+
+	// Assert the events were merged in order
+	assert.Equal(t, []string{"ingest: received", "load: stored", "ingest: acknowledged"}, lines)
+
+	// Stop tailing and drain the channels
+	cancel()
+	for range events {
+	}
+}
+
+// TestGetFunctionsLogs tests the GetFunctionsLogs method of the Lambda Adapter.
+// It verifies that the events of several functions are merged in timestamp
+// order, tagged with their function, and limited in total.
+func TestGetFunctionsLogs(t *testing.T) {
+	// Create mock clients
+	mockLambdaClient := new(mockLambdaClient)
+	mockLogsClient := new(mockCloudWatchLogsClient)
+
+	// Create adapter with mock clients
+	adapter := NewAdapterWithClients(mockLambdaClient, mockLogsClient)
+
+	// Set up expectations
+	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return aws.ToString(input.LogGroupName) == "/aws/lambda/ingest"
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{Timestamp: aws.Int64(1000), Message: aws.String("received")},
+			{Timestamp: aws.Int64(3000), Message: aws.String("acknowledged")},
+		},
+	}, nil)
+	mockLogsClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return aws.ToString(input.LogGroupName) == "/aws/lambda/load"
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []cloudwatchlogsTypes.FilteredLogEvent{
+			{Timestamp: aws.Int64(2000), Message: aws.String("stored")},
+		},
+	}, nil)
+
+	// Call the function
+	logs, err := adapter.GetFunctionsLogs(context.Background(), []string{"ingest", "load"}, time.Time{}, time.Time{}, 2)
+
+	// Assert result
+	assert.NoError(t, err)
+	assert.Equal(t, []LogEvent{
+		{Timestamp: 1000, Function: "ingest", Message: "received"},
+		{Timestamp: 2000, Function: "load", Message: "stored"},
+	}, logs)
+
+	// Verify expectations
+	mockLogsClient.AssertExpectations(t)
+}
+
+// TestLogEventJSON tests how log messages are parsed and formatted.
+// It verifies that JSON objects and arrays are parsed into Data, that other
+// messages are left unparsed, and that events format as "[timestamp] message"
+// lines, prefixed with their function if set.
+func TestLogEventJSON(t *testing.T) {
+	// JSON object message
+	event := newLogEvent(0, `{"level":"INFO","message":"started","count":2}`+"\n")
+	assert.Equal(t, map[string]interface{}{"level": "INFO", "message": "started", "count": float64(2)}, event.Data)
+
+	// JSON array message
+	event = newLogEvent(0, `[1, 2]`)
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, event.Data)
+
+	// Plain text and JSON scalars stay unparsed
+	for _, message := range []string{"START RequestId: abc", `"quoted"`, "42", `{"broken":`} {
+		assert.Nil(t, newLogEvent(0, message).Data, message)
+	}
+
+	// Format as a line
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.Local).UnixMilli()
+	line := FormatLogEvent(LogEvent{Timestamp: timestamp, Message: "hello\n"})
+	assert.Equal(t, "[2024-01-02 03:04:05.006] hello", line)
+
+	// Format with the function that logged the event
+	line = FormatLogEvent(LogEvent{Timestamp: timestamp, Function: "ingest", Message: "hello\n"})
+	assert.Equal(t, "[2024-01-02 03:04:05.006] ingest: hello", line)
+}
+
 // TestFormatPayload tests the FormatPayload function.
 // It verifies that the function correctly converts a Go data structure
 // to a JSON payload for Lambda function invocation.
@@ -370,6 +819,164 @@ func TestFormatPayload(t *testing.T) {
 	assert.Equal(t, expectedPayload, payload)
 }
 
+// TestReadPayload tests the ReadPayload function.
+// It verifies that payloads are read from an inline string, a file, or stdin,
+// that an empty object is used by default, and that invalid JSON is rejected.
+func TestReadPayload(t *testing.T) {
+	// Write a payload file
+	payloadFile := filepath.Join(t.TempDir(), "event.json")
+	assert.NoError(t, os.WriteFile(payloadFile, []byte(`{"source":"file"}`), 0644))
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		inline        string
+		path          string
+		stdin         string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Default empty payload",
+			expected: `{}`,
+		},
+		{
+			name:     "Inline payload",
+			inline:   `{"source":"inline"}`,
+			expected: `{"source":"inline"}`,
+		},
+		{
+			name:     "File payload",
+			path:     payloadFile,
+			expected: `{"source":"file"}`,
+		},
+		{
+			name:     "Stdin payload",
+			path:     "-",
+			stdin:    `[1, 2, 3]`,
+			expected: `[1, 2, 3]`,
+		},
+		{
+			name:          "Invalid inline JSON",
+			inline:        `{"source":`,
+			expectedError: "payload is not valid JSON",
+		},
+		{
+			name:          "Invalid stdin JSON",
+			path:          "-",
+			stdin:         `not json`,
+			expectedError: "payload is not valid JSON",
+		},
+		{
+			name:          "Missing file",
+			path:          filepath.Join(t.TempDir(), "missing.json"),
+			expectedError: "failed to read payload file",
+		},
+		{
+			name:          "Both inline and file",
+			inline:        `{}`,
+			path:          payloadFile,
+			expectedError: "cannot be used together",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function
+			payload, err := ReadPayload(tc.inline, tc.path, strings.NewReader(tc.stdin), nil)
+
+			// Assert result
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(payload))
+		})
+	}
+}
+
+// TestExpandPayloadEnv tests the ExpandPayloadEnv function and its use by ReadPayload.
+// It verifies that ${NAME} references are replaced with JSON-escaped values,
+// that other uses of $ are left alone, and that unset variables are reported.
+func TestExpandPayloadEnv(t *testing.T) {
+	env := map[string]string{
+		"USER":  "alice",
+		"COUNT": "3",
+		"QUOTE": `say "hi"\n`,
+		"EMPTY": "",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		payload       string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Variable in a string",
+			payload:  `{"user":"${USER}"}`,
+			expected: `{"user":"alice"}`,
+		},
+		{
+			name:     "Numeric variable outside a string",
+			payload:  `{"count":${COUNT},"label":"${USER}-${COUNT}"}`,
+			expected: `{"count":3,"label":"alice-3"}`,
+		},
+		{
+			name:     "Value with quotes and backslashes",
+			payload:  `{"text":"${QUOTE}"}`,
+			expected: `{"text":"say \"hi\"\\n"}`,
+		},
+		{
+			name:     "Empty variable",
+			payload:  `{"note":"${EMPTY}"}`,
+			expected: `{"note":""}`,
+		},
+		{
+			name:     "Other uses of $ are left alone",
+			payload:  `{"price":"$5","shell":"$USER","braces":"${not a name}"}`,
+			expected: `{"price":"$5","shell":"$USER","braces":"${not a name}"}`,
+		},
+		{
+			name:          "Unset variables",
+			payload:       `{"a":"${MISSING}","b":"${ALSO_MISSING}","c":"${MISSING}"}`,
+			expectedError: "unset environment variables: MISSING, ALSO_MISSING",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function
+			payload, err := ExpandPayloadEnv([]byte(tc.payload), lookupEnv)
+
+			// Assert result
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(payload))
+			assert.True(t, json.Valid(payload))
+		})
+	}
+
+	// ReadPayload expands before validating, so unquoted references are allowed
+	payload, err := ReadPayload(`{"count":${COUNT}}`, "", nil, lookupEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"count":3}`, string(payload))
+
+	// Without a lookup function, references are not expanded
+	payload, err = ReadPayload(`{"user":"${USER}"}`, "", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"user":"${USER}"}`, string(payload))
+}
+
 // TestParsePayload tests the ParsePayload function.
 // It verifies that the function correctly parses a JSON payload
 // from a Lambda function response into a Go data structure.
@@ -390,6 +997,70 @@ func TestParsePayload(t *testing.T) {
 	assert.Equal(t, true, result["key3"])
 }
 
+// TestUnwrapBody tests the UnwrapBody function.
+// It verifies that JSON carried as a string, either as the whole response or as
+// the body of an API Gateway proxy response, is parsed, and that other values
+// are left alone.
+func TestUnwrapBody(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name     string
+		payload  string
+		expected interface{}
+	}{
+		{
+			name:    "Proxy response with a JSON body",
+			payload: `{"statusCode":200,"body":"{\"id\":1,\"tags\":[\"a\"]}"}`,
+			expected: map[string]interface{}{
+				"statusCode": float64(200),
+				"body":       map[string]interface{}{"id": float64(1), "tags": []interface{}{"a"}},
+			},
+		},
+		{
+			name:    "Base64-encoded body",
+			payload: `{"isBase64Encoded":true,"body":"eyJvayI6dHJ1ZX0="}`,
+			expected: map[string]interface{}{
+				"isBase64Encoded": true,
+				"body":            map[string]interface{}{"ok": true},
+			},
+		},
+		{
+			name:     "Plain-text body",
+			payload:  `{"statusCode":200,"body":"hello"}`,
+			expected: map[string]interface{}{"statusCode": float64(200), "body": "hello"},
+		},
+		{
+			name:     "Response that is a JSON string",
+			payload:  `"{\"body\":\"[1,2]\"}"`,
+			expected: map[string]interface{}{"body": []interface{}{float64(1), float64(2)}},
+		},
+		{
+			name:     "String that is not JSON",
+			payload:  `"42"`,
+			expected: "42",
+		},
+		{
+			name:     "Response without a body",
+			payload:  `{"key":"{\"a\":1}"}`,
+			expected: map[string]interface{}{"key": `{"a":1}`},
+		},
+	}
+
+	// Run test cases
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var response interface{}
+			assert.NoError(t, ParsePayload([]byte(tc.payload), &response))
+
+			// Call the function
+			result := UnwrapBody(response)
+
+			// Assert result
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
 // TestExtractFunctionInfo tests the extractFunctionInfo function.
 // It verifies that the function correctly extracts information from
 // an AWS Lambda function configuration and converts it to our simplified
@@ -425,3 +1096,84 @@ func TestExtractFunctionInfo(t *testing.T) {
 	assert.Equal(t, "1", result.Version)
 	assert.Equal(t, "value", result.Environment["ENV_VAR"])
 }
+
+// TestRuntimeDeprecation tests the RuntimeDeprecationDate and IsDeprecatedRuntime functions.
+// It verifies that deprecated runtimes are flagged with their deprecation date,
+// that runtimes are only deprecated from that date, and that supported or
+// unknown runtimes are not.
+func TestRuntimeDeprecation(t *testing.T) {
+	// Deprecated runtimes
+	date, ok := RuntimeDeprecationDate("nodejs14.x")
+	assert.True(t, ok)
+	assert.Equal(t, "2023-12-04", date)
+	assert.True(t, IsDeprecatedRuntime("python3.7"))
+
+	// Runtimes are only deprecated from their deprecation date
+	date, ok = RuntimeDeprecationDate("python3.11")
+	assert.True(t, ok)
+	assert.Equal(t, "2027-06-30", date)
+	assert.False(t, isDeprecatedRuntimeAt("python3.11", time.Date(2027, 6, 29, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, isDeprecatedRuntimeAt("python3.11", time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)))
+
+	// Every deprecation date is valid
+	for runtime, date := range runtimeDeprecations {
+		_, err := time.Parse("2006-01-02", date)
+		assert.NoError(t, err, runtime)
+	}
+
+	// Supported and unknown runtimes
+	for _, runtime := range []string{"nodejs22.x", "python3.13", "provided.al2023", ""} {
+		_, ok := RuntimeDeprecationDate(runtime)
+		assert.False(t, ok, runtime)
+		assert.False(t, IsDeprecatedRuntime(runtime), runtime)
+	}
+
+	// Functions on deprecated runtimes are flagged
+	function := createMockFunctionConfiguration("old", "", "python3.7", "index.handler", "", 0, 3, 128, "", "1", nil)
+	assert.True(t, extractFunctionInfo(function).Deprecated)
+
+	function = createMockFunctionConfiguration("new", "", "python3.12", "index.handler", "", 0, 3, 128, "", "1", nil)
+	assert.False(t, extractFunctionInfo(function).Deprecated)
+}
+
+// TestWaitForFunctionActive tests waiting for a Lambda function to apply an update.
+// It verifies that the SDK waiters poll the function until it is Active and
+// its update has been applied, and that a failed update stops waiting with its
+// reason.
+func TestWaitForFunctionActive(t *testing.T) {
+	// Create mock clients
+	mockClient := new(mockLambdaClient)
+	adapter := NewAdapterWithClients(mockClient, new(mockCloudWatchLogsClient))
+
+	// Set up expectations
+	mockClient.On("GetFunction", mock.Anything, &lambda.GetFunctionInput{FunctionName: aws.String("api")}, mock.Anything).
+		Return(&lambda.GetFunctionOutput{Configuration: &types.FunctionConfiguration{
+			State:            types.StateActive,
+			LastUpdateStatus: types.LastUpdateStatusInProgress,
+		}}, nil).Once()
+	mockClient.On("GetFunction", mock.Anything, &lambda.GetFunctionInput{FunctionName: aws.String("api")}, mock.Anything).
+		Return(&lambda.GetFunctionOutput{Configuration: &types.FunctionConfiguration{
+			State:            types.StateActive,
+			LastUpdateStatus: types.LastUpdateStatusSuccessful,
+		}}, nil).Once()
+	mockClient.On("GetFunction", mock.Anything, &lambda.GetFunctionInput{FunctionName: aws.String("broken")}, mock.Anything).
+		Return(&lambda.GetFunctionOutput{Configuration: &types.FunctionConfiguration{
+			State:                  types.StateActive,
+			LastUpdateStatus:       types.LastUpdateStatusFailed,
+			LastUpdateStatusReason: aws.String("Invalid image"),
+		}}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.WaitForFunctionActive(ctx, "api", time.Millisecond)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertNumberOfCalls(t, "GetFunction", 2)
+
+	// A failed update stops waiting
+	err = adapter.WaitForFunctionActive(ctx, "broken", time.Millisecond)
+	assert.EqualError(t, err, "update of Lambda function broken failed: Invalid image")
+}
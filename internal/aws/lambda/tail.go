@@ -0,0 +1,179 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// DefaultTailInterval is the default time between polls when tailing logs
+const DefaultTailInterval = 2 * time.Second
+
+// TailFunctionLogs follows the CloudWatch logs of a Lambda function, like
+// `aws logs tail --follow`. It polls for events newer than the last one seen
+// and sends each new event once on the returned event channel.
+//
+// Parameters:
+//   - ctx: Context for the API calls; cancel it to stop tailing
+//   - functionName: The name of the Lambda function
+//   - interval: How long to wait between polls
+//
+// Returns a channel of new log events and a channel of polling errors. Errors
+// don't stop the tail; both channels are closed once ctx is done.
+func (a *Adapter) TailFunctionLogs(ctx context.Context, functionName string, interval time.Duration) (<-chan LogEvent, <-chan error) {
+	return a.tailFunctionLogs(ctx, []string{functionName}, time.Now(), interval)
+}
+
+// TailFunctionsLogs follows the CloudWatch logs of several Lambda functions at
+// once, like TailFunctionLogs. Each poll fetches the new events of every
+// function and sends them merged in timestamp order, with Function set to the
+// name of the function that logged them.
+//
+// Parameters:
+//   - ctx: Context for the API calls; cancel it to stop tailing
+//   - functionNames: The names of the Lambda functions
+//   - interval: How long to wait between polls
+//
+// Returns a channel of new log events and a channel of polling errors. An error
+// for one function doesn't stop the others; both channels are closed once ctx
+// is done.
+func (a *Adapter) TailFunctionsLogs(ctx context.Context, functionNames []string, interval time.Duration) (<-chan LogEvent, <-chan error) {
+	return a.tailFunctionLogs(ctx, functionNames, time.Now(), interval)
+}
+
+// tailFunctionLogs implements TailFunctionLogs and TailFunctionsLogs, starting
+// at the given time. Events are tagged with their function only when several
+// functions are tailed.
+func (a *Adapter) tailFunctionLogs(ctx context.Context, functionNames []string, since time.Time, interval time.Duration) (<-chan LogEvent, <-chan error) {
+	events := make(chan LogEvent, 100)
+	errs := make(chan error, 1)
+
+	tailers := make([]*logTailer, len(functionNames))
+	for i, functionName := range functionNames {
+		tailers[i] = &logTailer{
+			client:        a.logsClient,
+			functionName:  functionName,
+			lastTimestamp: since.UnixMilli(),
+			seen:          make(map[string]bool),
+		}
+	}
+	tagged := len(functionNames) > 1
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var newEvents []LogEvent
+			for _, tailer := range tailers {
+				polled, err := tailer.poll(ctx)
+				if err != nil && ctx.Err() == nil {
+					// Drop the error if the previous one hasn't been read yet
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+				for _, event := range polled {
+					if tagged {
+						event.Function = tailer.functionName
+					}
+					newEvents = append(newEvents, event)
+				}
+			}
+
+			// Interleave the events of the functions
+			sort.SliceStable(newEvents, func(i, j int) bool {
+				return newEvents[i].Timestamp < newEvents[j].Timestamp
+			})
+
+			for _, event := range newEvents {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// logTailer keeps track of the events already sent while tailing a log group
+type logTailer struct {
+	client        CloudWatchLogsClient // CloudWatch Logs client used for polling
+	functionName  string               // Lambda function whose logs are tailed
+	lastTimestamp int64                // Timestamp of the newest event sent, in milliseconds
+	seen          map[string]bool      // Events already sent with lastTimestamp
+}
+
+// poll returns the events that arrived since the last poll, oldest first
+func (t *logTailer) poll(ctx context.Context) ([]LogEvent, error) {
+	// Create the input for the FilterLogEvents API; the start time is inclusive,
+	// so events sharing the last timestamp are fetched again and filtered below
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(fmt.Sprintf("/aws/lambda/%s", t.functionName)),
+		StartTime:    aws.Int64(t.lastTimestamp),
+	}
+
+	// Call the FilterLogEvents API
+	var filtered []types.FilteredLogEvent
+	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(t.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get logs for Lambda function %s: %w", t.functionName, err)
+		}
+		filtered = append(filtered, output.Events...)
+	}
+
+	// Events from several log streams are not necessarily in order
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return aws.ToInt64(filtered[i].Timestamp) < aws.ToInt64(filtered[j].Timestamp)
+	})
+
+	var events []LogEvent
+	for _, event := range filtered {
+		timestamp := aws.ToInt64(event.Timestamp)
+		id := logEventID(event)
+
+		// Skip events that were already sent
+		if timestamp < t.lastTimestamp || (timestamp == t.lastTimestamp && t.seen[id]) {
+			continue
+		}
+
+		if timestamp > t.lastTimestamp {
+			t.lastTimestamp = timestamp
+			t.seen = make(map[string]bool)
+		}
+		t.seen[id] = true
+
+		events = append(events, newLogEvent(timestamp, aws.ToString(event.Message)))
+	}
+
+	return events, nil
+}
+
+// logEventID returns a key identifying a log event, falling back to its
+// stream and message when CloudWatch doesn't return an event ID
+func logEventID(event types.FilteredLogEvent) string {
+	if event.EventId != nil {
+		return aws.ToString(event.EventId)
+	}
+	return aws.ToString(event.LogStreamName) + "\x00" + aws.ToString(event.Message)
+}
@@ -0,0 +1,65 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// WaitForFunctionActive waits until a Lambda function is Active and its last
+// update has been applied, so that invocations run the new configuration. It
+// uses the FunctionActiveV2 and then the FunctionUpdatedV2 waiter of the SDK.
+//
+// Parameters:
+//   - ctx: Context for the API calls; its deadline limits how long to wait
+//   - functionName: The name or ARN of the Lambda function
+//   - interval: How long to wait before the first retry; later retries back off
+//
+// Returns an error if the function cannot be read, its creation or update
+// failed, or ctx is done first.
+func (a *Adapter) WaitForFunctionActive(ctx context.Context, functionName string, interval time.Duration) error {
+	input := &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	}
+
+	activeWaiter := lambda.NewFunctionActiveV2Waiter(a.client, func(o *lambda.FunctionActiveV2WaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	if err := activeWaiter.Wait(ctx, input, client.MaxWait(ctx)); err != nil {
+		return a.functionWaitError(ctx, functionName, err)
+	}
+
+	updatedWaiter := lambda.NewFunctionUpdatedV2Waiter(a.client, func(o *lambda.FunctionUpdatedV2WaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	if err := updatedWaiter.Wait(ctx, input, client.MaxWait(ctx)); err != nil {
+		return a.functionWaitError(ctx, functionName, err)
+	}
+	return nil
+}
+
+// functionWaitError returns the error for a failed wait for a Lambda function.
+// The waiters only report that the function failed, so its state is read once
+// more for the reason.
+func (a *Adapter) functionWaitError(ctx context.Context, functionName string, err error) error {
+	output, getErr := a.client.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+	if getErr == nil && output.Configuration != nil {
+		config := output.Configuration
+		if config.State == types.StateFailed {
+			return fmt.Errorf("Lambda function %s failed: %s", functionName, aws.ToString(config.StateReason))
+		}
+		if config.LastUpdateStatus == types.LastUpdateStatusFailed {
+			return fmt.Errorf("update of Lambda function %s failed: %s", functionName, aws.ToString(config.LastUpdateStatusReason))
+		}
+	}
+	return fmt.Errorf("failed waiting for Lambda function %s: %w", functionName, err)
+}
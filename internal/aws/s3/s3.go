@@ -5,16 +5,25 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ao/awsm/internal/aws/client"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 // S3Client defines the interface for S3 client operations.
@@ -22,10 +31,15 @@ import (
 type S3Client interface {
 	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
 	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	RestoreObject(ctx context.Context, params *s3.RestoreObjectInput, optFns ...func(*s3.Options)) (*s3.RestoreObjectOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+	DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error)
 }
 
 // Adapter represents an S3 service adapter that provides
@@ -55,6 +69,19 @@ type Object struct {
 	Owner        string    // Owner of the object
 }
 
+// ObjectMetadata represents the metadata of a single S3 object as returned by HeadObject,
+// including the restore status of archived (Glacier) objects.
+type ObjectMetadata struct {
+	Key           string    // Object key (path within the bucket)
+	Size          int64     // Size of the object in bytes
+	LastModified  time.Time // When the object was last modified
+	ETag          string    // Entity tag for the object (MD5 hash)
+	ContentType   string    // MIME type of the object
+	StorageClass  string    // Storage class of the object
+	RestoreStatus string    // Restore status: "in-progress", "restored", or empty if no restore was requested
+	RestoreExpiry string    // When the restored copy expires (only set once restored)
+}
+
 // NewAdapter creates a new S3 adapter using the AWS credentials
 // from the current context configuration.
 //
@@ -83,10 +110,18 @@ func NewAdapterWithClient(s3Client S3Client) *Adapter {
 	}
 }
 
+// Orders in which SortBuckets can sort buckets
+const (
+	BucketSortName    = "name"    // Alphabetical by bucket name
+	BucketSortCreated = "created" // Oldest bucket first
+	BucketSortRegion  = "region"  // Alphabetical by region
+)
+
 // ListBuckets lists all S3 buckets accessible with the current credentials.
 // It also attempts to determine the region for each bucket.
 //
-// Returns a slice of Bucket structs and an error if the operation fails.
+// Returns a slice of Bucket structs sorted by name, so the order is the same
+// from one call to the next, and an error if the operation fails.
 func (a *Adapter) ListBuckets(ctx context.Context) ([]Bucket, error) {
 	// Call the ListBuckets API
 	output, err := a.client.ListBuckets(ctx, &s3.ListBucketsInput{})
@@ -111,10 +146,68 @@ func (a *Adapter) ListBuckets(ctx context.Context) ([]Bucket, error) {
 		}
 	}
 
+	// Don't depend on the order of the API response
+	if err := SortBuckets(buckets, BucketSortName); err != nil {
+		return nil, err
+	}
+
 	return buckets, nil
 }
 
+// IsValidBucketSortKey reports whether by is one of the orders SortBuckets accepts
+func IsValidBucketSortKey(by string) bool {
+	switch by {
+	case BucketSortName, BucketSortCreated, BucketSortRegion:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortBuckets sorts buckets in place by name, creation date or region.
+// Buckets with the same creation date or region are sorted by name.
+//
+// Parameters:
+//   - buckets: The buckets to sort
+//   - by: The sort order, one of BucketSortName, BucketSortCreated or BucketSortRegion
+//
+// Returns an error if the sort order is not valid.
+func SortBuckets(buckets []Bucket, by string) error {
+	if !IsValidBucketSortKey(by) {
+		return fmt.Errorf("invalid sort order: %s (must be %s, %s or %s)", by, BucketSortName, BucketSortCreated, BucketSortRegion)
+	}
+
+	var less func(a, b Bucket) bool
+	switch by {
+	case BucketSortName:
+		less = func(a, b Bucket) bool { return a.Name < b.Name }
+	case BucketSortCreated:
+		less = func(a, b Bucket) bool {
+			if !a.CreationDate.Equal(b.CreationDate) {
+				return a.CreationDate.Before(b.CreationDate)
+			}
+			return a.Name < b.Name
+		}
+	case BucketSortRegion:
+		less = func(a, b Bucket) bool {
+			if a.Region != b.Region {
+				return a.Region < b.Region
+			}
+			return a.Name < b.Name
+		}
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return less(buckets[i], buckets[j])
+	})
+	return nil
+}
+
 // GetBucketRegion gets the AWS region where an S3 bucket is located.
+// Some buckets, typically in other accounts, deny s3:GetBucketLocation even
+// though they can be listed; when the call is denied the region is read from
+// the x-amz-bucket-region header of a HeadBucket request instead. Other errors
+// are returned as is.
 //
 // Parameters:
 //   - ctx: Context for the API call
@@ -128,6 +221,11 @@ func (a *Adapter) GetBucketRegion(ctx context.Context, bucketName string) (strin
 		Bucket: aws.String(bucketName),
 	})
 	if err != nil {
+		if isAccessDenied(err) {
+			if region := a.probeBucketRegion(ctx, bucketName); region != "" {
+				return region, nil
+			}
+		}
 		return "", fmt.Errorf("failed to get bucket location: %w", err)
 	}
 
@@ -141,6 +239,122 @@ func (a *Adapter) GetBucketRegion(ctx context.Context, bucketName string) (strin
 	return region, nil
 }
 
+// isAccessDenied reports whether err is an AccessDenied error from S3
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.ErrorCode() == "AccessDenied" || apiErr.ErrorCode() == "AccessDeniedException"
+}
+
+// probeBucketRegion gets the region of a bucket from the x-amz-bucket-region
+// header of a HeadBucket response. S3 sends the header even when the request
+// fails because it was denied or sent to the wrong region.
+//
+// Returns an empty string if the region cannot be determined.
+func (a *Adapter) probeBucketRegion(ctx context.Context, bucketName string) string {
+	// Call the HeadBucket API
+	output, err := a.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err == nil {
+		return aws.ToString(output.BucketRegion)
+	}
+
+	// Read the header from the error response
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		return respErr.Response.Header.Get("X-Amz-Bucket-Region")
+	}
+
+	return ""
+}
+
+// CreateBucket creates an S3 bucket in the given region.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the bucket to create
+//   - region: The region to create the bucket in (e.g., "eu-west-1")
+//
+// Returns an error if the bucket cannot be created.
+func (a *Adapter) CreateBucket(ctx context.Context, bucketName, region string) error {
+	// Create the input for the CreateBucket API
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	// us-east-1 is the default location and must not be given as a location constraint
+	if region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+
+	// Call the CreateBucket API
+	_, err := a.client.CreateBucket(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// DeleteBucket deletes an S3 bucket. The bucket must be empty.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the bucket to delete
+//
+// Returns an error if the bucket cannot be deleted.
+func (a *Adapter) DeleteBucket(ctx context.Context, bucketName string) error {
+	// Create the input for the DeleteBucket API
+	input := &s3.DeleteBucketInput{
+		Bucket: aws.String(bucketName),
+	}
+
+	// Call the DeleteBucket API
+	_, err := a.client.DeleteBucket(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket %s: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// EmptyBucket deletes all objects in an S3 bucket.
+// Object versions and delete markers in versioned buckets are not removed.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the bucket to empty
+//
+// Returns the number of deleted objects and an error if an object cannot
+// be listed or deleted.
+func (a *Adapter) EmptyBucket(ctx context.Context, bucketName string) (int, error) {
+	// List all objects in the bucket
+	objects, err := a.ListObjects(ctx, bucketName, "", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	// Delete each object
+	deleted := 0
+	for _, object := range objects {
+		if err := a.DeleteObject(ctx, bucketName, object.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// MaxObjectsPageSize is the largest number of objects the ListObjectsV2 API
+// returns in one page
+const MaxObjectsPageSize = 1000
+
 // ListObjects lists objects in an S3 bucket with optional prefix filtering.
 //
 // Parameters:
@@ -151,6 +365,21 @@ func (a *Adapter) GetBucketRegion(ctx context.Context, bucketName string) (strin
 //
 // Returns a slice of Object structs and an error if the operation fails.
 func (a *Adapter) ListObjects(ctx context.Context, bucketName, prefix string, maxItems int32) ([]Object, error) {
+	return a.ListObjectsWithPageSize(ctx, bucketName, prefix, maxItems, 0)
+}
+
+// ListObjectsWithPageSize lists objects in an S3 bucket like ListObjects,
+// fetching at most pageSize objects per API call.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: Optional prefix to filter objects (can be empty)
+//   - maxItems: Maximum number of objects to return (0 for no limit)
+//   - pageSize: Maximum number of objects per API call (0 for the API maximum of 1000)
+//
+// Returns a slice of Object structs and an error if the operation fails.
+func (a *Adapter) ListObjectsWithPageSize(ctx context.Context, bucketName, prefix string, maxItems, pageSize int32) ([]Object, error) {
 	// Create the input for the ListObjectsV2 API
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucketName),
@@ -161,6 +390,14 @@ func (a *Adapter) ListObjects(ctx context.Context, bucketName, prefix string, ma
 		input.Prefix = aws.String(prefix)
 	}
 
+	// Don't fetch larger pages than needed (the API returns at most 1000 keys per page)
+	if maxItems > 0 && (pageSize <= 0 || pageSize > maxItems) {
+		pageSize = maxItems
+	}
+	if pageSize > 0 {
+		input.MaxKeys = aws.Int32(min(pageSize, MaxObjectsPageSize))
+	}
+
 	// Create paginator
 	paginator := s3.NewListObjectsV2Paginator(a.client, input)
 
@@ -181,26 +418,274 @@ func (a *Adapter) ListObjects(ctx context.Context, bucketName, prefix string, ma
 				break
 			}
 
-			// Extract object information
-			obj := Object{
-				Key:          aws.ToString(object.Key),
-				Size:         aws.ToInt64(object.Size),
-				LastModified: aws.ToTime(object.LastModified),
-				ETag:         strings.Trim(aws.ToString(object.ETag), "\""),
-				StorageClass: string(object.StorageClass),
-			}
+			objects = append(objects, newObject(object))
+			count++
+		}
+	}
 
-			// Extract owner information if available
-			if object.Owner != nil && object.Owner.DisplayName != nil {
-				obj.Owner = aws.ToString(object.Owner.DisplayName)
+	return objects, nil
+}
+
+// CountObjects counts the objects in an S3 bucket with optional prefix
+// filtering. Only the key count of each page is used, so counting a large
+// bucket doesn't hold its objects in memory.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: Optional prefix to filter objects (can be empty)
+//   - maxItems: Maximum number of objects to count (0 for no limit)
+//   - pageSize: Maximum number of objects per API call (0 for the API maximum of 1000)
+//
+// Returns the number of objects, at most maxItems, and an error if the operation fails.
+func (a *Adapter) CountObjects(ctx context.Context, bucketName, prefix string, maxItems, pageSize int32) (int, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	// Don't fetch larger pages than needed, as in ListObjectsWithPageSize
+	if maxItems > 0 && (pageSize <= 0 || pageSize > maxItems) {
+		pageSize = maxItems
+	}
+	if pageSize > 0 {
+		input.MaxKeys = aws.Int32(min(pageSize, MaxObjectsPageSize))
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(a.client, input)
+
+	count := 0
+	for paginator.HasMorePages() && (maxItems == 0 || count < int(maxItems)) {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err)
+		}
+
+		// KeyCount is always set by S3; fall back to the objects of the page otherwise
+		if output.KeyCount != nil {
+			count += int(*output.KeyCount)
+		} else {
+			count += len(output.Contents)
+		}
+	}
+
+	if maxItems > 0 && count > int(maxItems) {
+		count = int(maxItems)
+	}
+	return count, nil
+}
+
+// ListObjectsPage lists one page of objects in an S3 bucket, so that large
+// buckets can be listed a page at a time.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: Optional prefix to filter objects (can be empty)
+//   - token: Continuation token returned for the previous page (empty for the first page)
+//   - pageSize: Maximum number of objects in the page (0 for the API maximum of 1000)
+//
+// Returns the objects of the page, the continuation token of the next page
+// (empty if this is the last page), and an error if the operation fails.
+func (a *Adapter) ListObjectsPage(ctx context.Context, bucketName, prefix, token string, pageSize int32) ([]Object, string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if token != "" {
+		input.ContinuationToken = aws.String(token)
+	}
+	if pageSize > 0 {
+		input.MaxKeys = aws.Int32(min(pageSize, MaxObjectsPageSize))
+	}
+
+	output, err := a.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err)
+	}
+
+	objects := make([]Object, 0, len(output.Contents))
+	for _, object := range output.Contents {
+		objects = append(objects, newObject(object))
+	}
+
+	nextToken := ""
+	if aws.ToBool(output.IsTruncated) {
+		nextToken = aws.ToString(output.NextContinuationToken)
+	}
+
+	return objects, nextToken, nil
+}
+
+// DefaultDelimiter separates the "folders" of an object key
+const DefaultDelimiter = "/"
+
+// ObjectListing is one level of the objects of a bucket, listed with a
+// delimiter: the keys that contain the delimiter after the prefix are grouped
+// into common prefixes, which can be browsed like folders.
+type ObjectListing struct {
+	Prefixes  []string // Common prefixes directly under the prefix, each ending with the delimiter
+	Objects   []Object // Objects directly under the prefix
+	NextToken string   // Continuation token of the next page, empty for the last page
+}
+
+// ListObjectsWithDelimiter lists one level of the objects in an S3 bucket: the
+// common prefixes ("folders") and the objects directly under prefix.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: Prefix of the level to list, usually ending with the delimiter (can be empty for the top level)
+//   - delimiter: Delimiter that separates the levels (empty for DefaultDelimiter)
+//   - maxItems: Maximum number of prefixes and objects to return together (0 for no limit)
+//
+// Returns the common prefixes and objects of the level and an error if the operation fails.
+func (a *Adapter) ListObjectsWithDelimiter(ctx context.Context, bucketName, prefix, delimiter string, maxItems int32) (*ObjectListing, error) {
+	listing := &ObjectListing{}
+	token := ""
+	for {
+		page, err := a.ListObjectsPageWithDelimiter(ctx, bucketName, prefix, delimiter, token, 0)
+		if err != nil {
+			return nil, err
+		}
+		listing.Prefixes = append(listing.Prefixes, page.Prefixes...)
+		listing.Objects = append(listing.Objects, page.Objects...)
+
+		// Stop at the limit, leaving out the surplus of the last page
+		if maxItems > 0 && len(listing.Prefixes)+len(listing.Objects) >= int(maxItems) {
+			if len(listing.Prefixes) > int(maxItems) {
+				listing.Prefixes = listing.Prefixes[:maxItems]
 			}
+			listing.Objects = listing.Objects[:int(maxItems)-len(listing.Prefixes)]
+			return listing, nil
+		}
 
-			objects = append(objects, obj)
-			count++
+		token = page.NextToken
+		if token == "" {
+			return listing, nil
 		}
 	}
+}
 
-	return objects, nil
+// ListObjectsPageWithDelimiter lists one page of one level of the objects in an
+// S3 bucket, like ListObjectsWithDelimiter, so that large levels can be listed a
+// page at a time.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: Prefix of the level to list (can be empty for the top level)
+//   - delimiter: Delimiter that separates the levels (empty for DefaultDelimiter)
+//   - token: Continuation token returned for the previous page (empty for the first page)
+//   - pageSize: Maximum number of prefixes and objects in the page (0 for the API maximum of 1000)
+//
+// Returns the common prefixes and objects of the page with the continuation token
+// of the next page, and an error if the operation fails.
+func (a *Adapter) ListObjectsPageWithDelimiter(ctx context.Context, bucketName, prefix, delimiter, token string, pageSize int32) (*ObjectListing, error) {
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucketName),
+		Delimiter: aws.String(delimiter),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if token != "" {
+		input.ContinuationToken = aws.String(token)
+	}
+	if pageSize > 0 {
+		input.MaxKeys = aws.Int32(min(pageSize, MaxObjectsPageSize))
+	}
+
+	output, err := a.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err)
+	}
+
+	listing := &ObjectListing{
+		Prefixes: make([]string, 0, len(output.CommonPrefixes)),
+		Objects:  make([]Object, 0, len(output.Contents)),
+	}
+	for _, commonPrefix := range output.CommonPrefixes {
+		listing.Prefixes = append(listing.Prefixes, aws.ToString(commonPrefix.Prefix))
+	}
+	for _, object := range output.Contents {
+		// The prefix itself is listed when a "folder" was created in the console
+		if aws.ToString(object.Key) == prefix {
+			continue
+		}
+		listing.Objects = append(listing.Objects, newObject(object))
+	}
+	if aws.ToBool(output.IsTruncated) {
+		listing.NextToken = aws.ToString(output.NextContinuationToken)
+	}
+
+	return listing, nil
+}
+
+// Types of the entries of an object listing
+const (
+	EntryTypePrefix = "PRE" // A common prefix ("folder")
+	EntryTypeObject = "OBJ" // An object
+)
+
+// ListingEntry is a common prefix or an object of an ObjectListing, so that both
+// can be printed as one list
+type ListingEntry struct {
+	Type         string    // EntryTypePrefix or EntryTypeObject
+	Key          string    // Common prefix or object key
+	Size         int64     // Size of the object in bytes (0 for a prefix)
+	LastModified time.Time // When the object was last modified (zero for a prefix)
+	StorageClass string    // Storage class of the object (empty for a prefix)
+}
+
+// Entries returns the common prefixes of the listing followed by its objects
+func (l *ObjectListing) Entries() []ListingEntry {
+	entries := make([]ListingEntry, 0, len(l.Prefixes)+len(l.Objects))
+	for _, prefix := range l.Prefixes {
+		entries = append(entries, ListingEntry{Type: EntryTypePrefix, Key: prefix})
+	}
+	for _, object := range l.Objects {
+		entries = append(entries, ListingEntry{
+			Type:         EntryTypeObject,
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+			StorageClass: object.StorageClass,
+		})
+	}
+	return entries
+}
+
+// newObject converts an object returned by the ListObjectsV2 API to an Object
+func newObject(object types.Object) Object {
+	obj := Object{
+		Key:          aws.ToString(object.Key),
+		Size:         aws.ToInt64(object.Size),
+		LastModified: aws.ToTime(object.LastModified),
+		ETag:         strings.Trim(aws.ToString(object.ETag), "\""),
+		StorageClass: string(object.StorageClass),
+	}
+
+	// Extract owner information if available
+	if object.Owner != nil && object.Owner.DisplayName != nil {
+		obj.Owner = aws.ToString(object.Owner.DisplayName)
+	}
+
+	return obj
+}
+
+// UploadOptions holds optional settings for uploading objects.
+type UploadOptions struct {
+	ACL         string // Canned ACL to apply to the object (e.g., public-read); empty for the bucket default
+	ContentType string // Content type of the object; empty to detect it from the file
 }
 
 // UploadObject uploads a local file to an S3 bucket.
@@ -213,6 +698,28 @@ func (a *Adapter) ListObjects(ctx context.Context, bucketName, prefix string, ma
 //
 // Returns an error if the file cannot be opened or the upload fails.
 func (a *Adapter) UploadObject(ctx context.Context, bucketName, key, filePath string) error {
+	return a.UploadObjectWithOptions(ctx, bucketName, key, filePath, UploadOptions{})
+}
+
+// UploadObjectWithOptions uploads a local file to an S3 bucket with the given options.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - key: The key (path) to store the object under in the bucket
+//   - filePath: The local file path to upload
+//   - opts: Optional upload settings such as the canned ACL and content type
+//
+// Without a content type in opts, it is detected from the file extension or,
+// failing that, the file content.
+//
+// Returns an error if the options are invalid, the file cannot be read or the upload fails.
+func (a *Adapter) UploadObjectWithOptions(ctx context.Context, bucketName, key, filePath string, opts UploadOptions) error {
+	// Validate the ACL before doing any work
+	if opts.ACL != "" && !IsValidACL(opts.ACL) {
+		return fmt.Errorf("invalid ACL %q (valid values: %s)", opts.ACL, strings.Join(ValidACLs(), ", "))
+	}
+
 	// Open the file
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -220,11 +727,27 @@ func (a *Adapter) UploadObject(ctx context.Context, bucketName, key, filePath st
 	}
 	defer file.Close()
 
+	// Detect the content type unless one is given, so that browsers show the
+	// object instead of downloading it
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType, err = detectContentType(file)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the input for the PutObject API
 	input := &s3.PutObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(key),
-		Body:   file,
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String(contentType),
+	}
+
+	// Set the ACL if provided
+	if opts.ACL != "" {
+		input.ACL = types.ObjectCannedACL(opts.ACL)
 	}
 
 	// Call the PutObject API
@@ -236,6 +759,135 @@ func (a *Adapter) UploadObject(ctx context.Context, bucketName, key, filePath st
 	return nil
 }
 
+// detectContentType returns the content type of a file from its extension or,
+// if the extension is unknown, from its first 512 bytes. The file is read from
+// the start again afterwards.
+func detectContentType(file *os.File) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name())); contentType != "" {
+		return contentType, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("failed to read file %s: %w", file.Name(), err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", file.Name(), err)
+	}
+
+	return http.DetectContentType(head[:n]), nil
+}
+
+// ValidACLs returns the canned ACLs that can be applied to uploaded objects.
+func ValidACLs() []string {
+	values := types.ObjectCannedACL("").Values()
+	acls := make([]string, 0, len(values))
+	for _, value := range values {
+		acls = append(acls, string(value))
+	}
+	return acls
+}
+
+// IsValidACL checks if the given canned ACL can be applied to uploaded objects.
+func IsValidACL(acl string) bool {
+	for _, valid := range ValidACLs() {
+		if acl == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// PartFileSuffix is appended to the target path while an object is being downloaded.
+const PartFileSuffix = ".part"
+
+// partETagSuffix is appended to the path of a partial file for the file holding
+// the ETag of the object being downloaded, so that resuming only continues the
+// partial file with the same version of the object
+const partETagSuffix = ".etag"
+
+// maxDownloadAttempts is how many times an interrupted download is resumed
+// from where it stopped before giving up
+const maxDownloadAttempts = 3
+
+// DownloadOptions holds optional settings for downloading objects.
+type DownloadOptions struct {
+	Resume bool   // Continue from an existing partial download instead of starting over
+	Range  string // Part of the object to download, e.g. bytes=0-1023 (empty for the whole object)
+}
+
+// byteRange is the part of an object to download, as in an HTTP Range header
+type byteRange struct {
+	start  int64 // First byte
+	end    int64 // Last byte, -1 for the end of the object
+	suffix int64 // Number of bytes at the end of the object for a suffix range, 0 otherwise
+}
+
+// ParseByteRange checks a byte range such as bytes=0-1023 (the first KB),
+// bytes=1024- (from byte 1024 to the end) or bytes=-500 (the last 500 bytes).
+// The bytes= prefix is optional, and an empty range is the whole object.
+//
+// Returns the range as sent in the Range header of GetObject requests, or an
+// error if it is not a single valid range.
+func ParseByteRange(value string) (string, error) {
+	r, err := parseByteRange(value)
+	if err != nil {
+		return "", err
+	}
+	return r.header(0), nil
+}
+
+// parseByteRange parses a byte range as described for ParseByteRange
+func parseByteRange(value string) (byteRange, error) {
+	if value == "" {
+		return byteRange{end: -1}, nil
+	}
+
+	spec := strings.TrimPrefix(value, "bytes=")
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, fmt.Errorf("invalid range %q: expected bytes=first-last, bytes=first- or bytes=-length", value)
+	}
+
+	// A suffix range: the last bytes of the object
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return byteRange{}, fmt.Errorf("invalid range %q: the length must be a positive number", value)
+		}
+		return byteRange{end: -1, suffix: suffix}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, fmt.Errorf("invalid range %q: the first byte must be a number", value)
+	}
+	end := int64(-1)
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, fmt.Errorf("invalid range %q: the last byte must be a number not below the first", value)
+		}
+	}
+	return byteRange{start: start, end: end}, nil
+}
+
+// header returns the Range header for what is left of the range once skip
+// bytes of it were received, or an empty string for the whole object
+func (r byteRange) header(skip int64) string {
+	switch {
+	case r.suffix > 0:
+		return fmt.Sprintf("bytes=-%d", r.suffix-skip)
+	case r.end >= 0:
+		return fmt.Sprintf("bytes=%d-%d", r.start+skip, r.end)
+	case r.start+skip > 0:
+		return fmt.Sprintf("bytes=%d-", r.start+skip)
+	default:
+		return ""
+	}
+}
+
 // DownloadObject downloads an object from an S3 bucket to a local file.
 // It will create any necessary directories in the file path if they don't exist.
 //
@@ -248,39 +900,406 @@ func (a *Adapter) UploadObject(ctx context.Context, bucketName, key, filePath st
 // Returns an error if the directories cannot be created, the file cannot be created,
 // or the download fails.
 func (a *Adapter) DownloadObject(ctx context.Context, bucketName, key, filePath string) error {
+	return a.DownloadObjectWithOptions(ctx, bucketName, key, filePath, DownloadOptions{})
+}
+
+// DownloadObjectWithOptions downloads an object from an S3 bucket to a local file with
+// the given options. The data is written to filePath plus PartFileSuffix and only
+// renamed to filePath once the download is complete, so the target is never left
+// truncated. An interrupted transfer is retried from where it stopped using Range
+// requests; if it still fails, the partial file is kept so it can be resumed later
+// with opts.Resume. With opts.Range, only that part of the object is downloaded;
+// ranged downloads cannot be resumed.
+//
+// Every request after the first one is made with the ETag of the object in
+// If-Match, and the ETag is saved next to the partial file, so that the data of
+// another version of the object is never appended to it. A download is resumed
+// only if the object still has the saved ETag; otherwise it starts over.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - key: The key (path) of the object in the bucket
+//   - filePath: The local file path to save the object to
+//   - opts: Download options
+//
+// Returns an error if the directories cannot be created, the partial file cannot be
+// written or renamed, or the download fails.
+func (a *Adapter) DownloadObjectWithOptions(ctx context.Context, bucketName, key, filePath string, opts DownloadOptions) error {
+	rng, err := parseByteRange(opts.Range)
+	if err != nil {
+		return err
+	}
+	if opts.Resume && opts.Range != "" {
+		return fmt.Errorf("a ranged download cannot be resumed")
+	}
+
 	// Create the directory if it doesn't exist
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	// Create the file
-	file, err := os.Create(filePath)
+	partPath := filePath + PartFileSuffix
+
+	// Work out where to start when resuming a previous download
+	var offset int64
+	var etag *string
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if opts.Resume {
+		if info, err := os.Stat(partPath); err == nil {
+			metadata, err := a.HeadObject(ctx, bucketName, key)
+			if err != nil {
+				return err
+			}
+
+			// Only continue a partial file of the current version of the object;
+			// one without a saved ETag, of another version, or larger than the
+			// object is started over
+			saved, _ := os.ReadFile(partPath + partETagSuffix)
+			savedETag := strings.TrimSpace(string(saved))
+			if savedETag != "" && strings.Trim(savedETag, "\"") == metadata.ETag && info.Size() <= metadata.Size {
+				offset = info.Size()
+				etag = aws.String(savedETag)
+				flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+
+				// Nothing left to fetch
+				if offset == metadata.Size {
+					return finishDownload(partPath, filePath)
+				}
+			}
+		}
+	}
+
+	// Create or open the partial file
+	file, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+		return fmt.Errorf("failed to create file %s: %w", partPath, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Create the input for the GetObject API
+		input := &s3.GetObjectInput{
+			Bucket:  aws.String(bucketName),
+			Key:     aws.String(key),
+			IfMatch: etag, // Make sure retries fetch the same version of the object
+		}
+		if header := rng.header(offset); header != "" {
+			input.Range = aws.String(header)
+		}
+
+		// Call the GetObject API
+		output, err := a.client.GetObject(ctx, input)
+		if err != nil {
+			file.Close()
+			if offset > 0 && opts.Range == "" {
+				return fmt.Errorf("failed to download object from bucket %s: %w (partial data kept in %s, use --continue to resume)", bucketName, err, partPath)
+			}
+			removePartial(partPath)
+			return fmt.Errorf("failed to download object from bucket %s: %w", bucketName, err)
+		}
+		if etag == nil && output.ETag != nil {
+			etag = output.ETag
+
+			// Remember the version being downloaded in case the download has to
+			// be resumed; without it, a resume just starts over
+			if opts.Range == "" {
+				_ = os.WriteFile(partPath+partETagSuffix, []byte(aws.ToString(etag)), 0644)
+			}
+		}
+
+		// Copy the object data to the file
+		written, err := io.Copy(file, output.Body)
+		output.Body.Close()
+		offset += written
+		if err == nil {
+			break
+		}
+
+		if attempt == maxDownloadAttempts || ctx.Err() != nil {
+			file.Close()
+			if opts.Range != "" {
+				removePartial(partPath)
+				return fmt.Errorf("download of s3://%s/%s interrupted after %d bytes: %w", bucketName, key, offset, err)
+			}
+			return fmt.Errorf("download of s3://%s/%s interrupted after %d bytes (partial data kept in %s, use --continue to resume): %w", bucketName, key, offset, partPath, err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write object data to file: %w", err)
+	}
+
+	return finishDownload(partPath, filePath)
+}
+
+// finishDownload moves a completed partial download to its final path
+func finishDownload(partPath, filePath string) error {
+	if err := os.Rename(partPath, filePath); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", partPath, filePath, err)
+	}
+	os.Remove(partPath + partETagSuffix)
+	return nil
+}
+
+// removePartial removes a partial download and the ETag saved with it
+func removePartial(partPath string) {
+	os.Remove(partPath)
+	os.Remove(partPath + partETagSuffix)
+}
+
+// OpenObject opens an object in an S3 bucket for reading, or the part of it
+// given by byteRange (see ParseByteRange), without downloading it to a file.
+// The caller must close the returned reader.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - key: The key (path) of the object in the bucket
+//   - byteRange: Part of the object to read, e.g. bytes=0-1023 (empty for the whole object)
+//
+// Returns a reader of the object data and an error if the range is invalid or the
+// operation fails.
+func (a *Adapter) OpenObject(ctx context.Context, bucketName, key, byteRange string) (io.ReadCloser, error) {
+	rng, err := parseByteRange(byteRange)
+	if err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
-	// Create the input for the GetObject API
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(key),
 	}
+	if header := rng.header(0); header != "" {
+		input.Range = aws.String(header)
+	}
 
-	// Call the GetObject API
 	output, err := a.client.GetObject(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to download object from bucket %s: %w", bucketName, err)
+		return nil, fmt.Errorf("failed to read object from bucket %s: %w", bucketName, err)
 	}
-	defer output.Body.Close()
 
-	// Copy the object data to the file
-	_, err = io.Copy(file, output.Body)
+	return output.Body, nil
+}
+
+// Transfer represents the outcome of transferring a single file as part of
+// a recursive upload or download.
+type Transfer struct {
+	Source      string // Local path or S3 URL that was read
+	Destination string // S3 URL or local path that was written
+	Error       error  // Error if the transfer failed, nil on success
+}
+
+// UploadDirectory uploads all regular files below a local directory to an S3 bucket,
+// using each file's path relative to the directory as the key below the prefix.
+// Symbolic links are skipped, and an empty directory uploads nothing.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: The key prefix to upload below (can be empty)
+//   - dirPath: The local directory to upload
+//   - opts: Upload options applied to every object
+//
+// Returns one Transfer per file and an error if the directory cannot be read.
+// Failed uploads are reported in the transfers and don't stop the upload.
+func (a *Adapter) UploadDirectory(ctx context.Context, bucketName, prefix, dirPath string, opts UploadOptions) ([]Transfer, error) {
+	prefix = normalizePrefix(prefix)
+
+	var transfers []Transfer
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Only upload regular files; directories are walked and symlinks are skipped
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+
+		key := prefix + filepath.ToSlash(rel)
+		transfers = append(transfers, Transfer{
+			Source:      path,
+			Destination: fmt.Sprintf("s3://%s/%s", bucketName, key),
+			Error:       a.UploadObjectWithOptions(ctx, bucketName, key, path, opts),
+		})
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to write object data to file: %w", err)
+		return transfers, fmt.Errorf("failed to read directory %s: %w", dirPath, err)
 	}
 
-	return nil
+	return transfers, nil
+}
+
+// DownloadPrefix downloads all objects below a prefix in an S3 bucket to a local
+// directory, using each key relative to the prefix as the path below the directory.
+// Folder placeholder objects (keys ending in "/") are skipped.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - prefix: The key prefix to download (can be empty for the whole bucket)
+//   - dirPath: The local directory to download into
+//
+// Returns one Transfer per object and an error if the objects cannot be listed.
+// Failed downloads are reported in the transfers and don't stop the download.
+func (a *Adapter) DownloadPrefix(ctx context.Context, bucketName, prefix, dirPath string) ([]Transfer, error) {
+	prefix = normalizePrefix(prefix)
+
+	// List all objects below the prefix
+	objects, err := a.ListObjects(ctx, bucketName, prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []Transfer
+	for _, object := range objects {
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		transfer := Transfer{
+			Source:      fmt.Sprintf("s3://%s/%s", bucketName, object.Key),
+			Destination: filepath.Join(dirPath, filepath.FromSlash(strings.TrimPrefix(object.Key, prefix))),
+		}
+
+		// Refuse keys that would be written outside the target directory (e.g. "../x")
+		if rel, err := filepath.Rel(dirPath, transfer.Destination); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			transfer.Error = fmt.Errorf("refusing to download %s outside of %s", object.Key, dirPath)
+		} else {
+			transfer.Error = a.DownloadObject(ctx, bucketName, object.Key, transfer.Destination)
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// Sync actions reported by SyncLocalToBucket
+const (
+	SyncActionUpload = "upload" // The local file is new or changed and was uploaded
+	SyncActionSkip   = "skip"   // The remote object is up to date
+	SyncActionDelete = "delete" // The remote object has no local counterpart and was deleted
+)
+
+// SyncOptions holds optional settings for synchronizing a directory to a bucket.
+type SyncOptions struct {
+	Delete bool          // Delete remote objects that have no local counterpart
+	Upload UploadOptions // Options applied to every uploaded object
+}
+
+// SyncAction represents what SyncLocalToBucket did for a single file or object.
+type SyncAction struct {
+	Action string // upload, skip, or delete
+	Path   string // Local file path (empty for deleted objects)
+	Key    string // Object key in the bucket
+	Error  string // Error message if the action failed
+}
+
+// SyncLocalToBucket synchronizes a local directory to a prefix in an S3 bucket.
+// Local files are uploaded if there is no remote object for them, or if the remote
+// object differs in size or is older than the local file; otherwise they are skipped.
+// With opts.Delete, remote objects below the prefix that have no local file are deleted.
+// Symbolic links are skipped.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - localDir: The local directory to synchronize
+//   - bucketName: The name of the S3 bucket
+//   - prefix: The key prefix to synchronize to (can be empty)
+//   - opts: Sync options
+//
+// Returns one SyncAction per file or object and an error if the directory or the
+// existing objects cannot be listed. Failed uploads and deletions are reported in
+// the actions and don't stop the sync.
+func (a *Adapter) SyncLocalToBucket(ctx context.Context, localDir, bucketName, prefix string, opts SyncOptions) ([]SyncAction, error) {
+	prefix = normalizePrefix(prefix)
+
+	// List the existing objects below the prefix
+	objects, err := a.ListObjects(ctx, bucketName, prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := make(map[string]Object, len(objects))
+	for _, object := range objects {
+		remote[object.Key] = object
+	}
+
+	// Upload new and changed local files
+	var actions []SyncAction
+	local := make(map[string]bool)
+	err = filepath.WalkDir(localDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Only sync regular files; directories are walked and symlinks are skipped
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+
+		key := prefix + filepath.ToSlash(rel)
+		local[key] = true
+
+		action := SyncAction{Action: SyncActionSkip, Path: path, Key: key}
+		if object, exists := remote[key]; !exists || object.Size != info.Size() || info.ModTime().After(object.LastModified) {
+			action.Action = SyncActionUpload
+			if err := a.UploadObjectWithOptions(ctx, bucketName, key, path, opts.Upload); err != nil {
+				action.Error = err.Error()
+			}
+		}
+
+		actions = append(actions, action)
+		return nil
+	})
+	if err != nil {
+		return actions, fmt.Errorf("failed to read directory %s: %w", localDir, err)
+	}
+
+	// Delete remote objects that no longer exist locally
+	if opts.Delete {
+		for _, object := range objects {
+			if local[object.Key] || strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+
+			action := SyncAction{Action: SyncActionDelete, Key: object.Key}
+			if err := a.DeleteObject(ctx, bucketName, object.Key); err != nil {
+				action.Error = err.Error()
+			}
+			actions = append(actions, action)
+		}
+	}
+
+	return actions, nil
+}
+
+// normalizePrefix makes sure a non-empty key prefix ends with a slash,
+// so that it only matches whole path segments
+func normalizePrefix(prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		return prefix + "/"
+	}
+	return prefix
 }
 
 // DeleteObject deletes an object from an S3 bucket.
@@ -307,6 +1326,27 @@ func (a *Adapter) DeleteObject(ctx context.Context, bucketName, key string) erro
 	return nil
 }
 
+// ParseS3URL splits an S3 URL of the form s3://bucket/key into its bucket and key.
+// The s3:// scheme is optional. The key is returned verbatim, so keys containing
+// characters such as '?', '#', '%' or spaces are preserved, as is a trailing slash
+// on a key prefix. A URL that names only a bucket (s3://bucket or s3://bucket/)
+// returns an empty key.
+//
+// Parameters:
+//   - raw: The S3 URL to parse
+//
+// Returns the bucket name, the object key and an error if the URL has no bucket.
+func ParseS3URL(raw string) (bucket, key string, err error) {
+	path := strings.TrimPrefix(raw, "s3://")
+
+	bucket, key, _ = strings.Cut(path, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %q: missing bucket name", raw)
+	}
+
+	return bucket, key, nil
+}
+
 // GetObjectURL gets the public URL of an S3 object.
 // Note that this does not check if the object exists or if it's publicly accessible.
 //
@@ -318,3 +1358,101 @@ func (a *Adapter) DeleteObject(ctx context.Context, bucketName, key string) erro
 func (a *Adapter) GetObjectURL(bucketName, key string) string {
 	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucketName, key)
 }
+
+// HeadObject retrieves the metadata of an object in an S3 bucket without downloading it.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - key: The key (path) of the object in the bucket
+//
+// Returns an ObjectMetadata struct and an error if the operation fails.
+func (a *Adapter) HeadObject(ctx context.Context, bucketName, key string) (*ObjectMetadata, error) {
+	// Create the input for the HeadObject API
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	}
+
+	// Call the HeadObject API
+	output, err := a.client.HeadObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata for object %s in bucket %s: %w", key, bucketName, err)
+	}
+
+	metadata := &ObjectMetadata{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		LastModified: aws.ToTime(output.LastModified),
+		ETag:         strings.Trim(aws.ToString(output.ETag), "\""),
+		ContentType:  aws.ToString(output.ContentType),
+		StorageClass: string(output.StorageClass),
+	}
+
+	// S3 omits the storage class header for STANDARD objects
+	if metadata.StorageClass == "" {
+		metadata.StorageClass = "STANDARD"
+	}
+
+	metadata.RestoreStatus, metadata.RestoreExpiry = parseRestoreHeader(aws.ToString(output.Restore))
+
+	return metadata, nil
+}
+
+// RestoreObject initiates a restore of an archived (e.g. Glacier) object so that
+// a temporary copy becomes readable for the given number of days.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - bucketName: The name of the S3 bucket
+//   - key: The key (path) of the archived object
+//   - days: Number of days the restored copy should remain available
+//
+// Returns an error if days is not positive or the restore request fails.
+func (a *Adapter) RestoreObject(ctx context.Context, bucketName, key string, days int) error {
+	if days <= 0 {
+		return fmt.Errorf("restore days must be greater than zero, got %d", days)
+	}
+
+	// Create the input for the RestoreObject API
+	input := &s3.RestoreObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+		RestoreRequest: &types.RestoreRequest{
+			Days: aws.Int32(int32(days)),
+		},
+	}
+
+	// Call the RestoreObject API
+	_, err := a.client.RestoreObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to restore object %s in bucket %s: %w", key, bucketName, err)
+	}
+
+	return nil
+}
+
+// parseRestoreHeader parses the x-amz-restore header returned by HeadObject,
+// e.g. `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`.
+//
+// Returns the restore status ("in-progress", "restored", or empty if no restore
+// was requested) and the expiry date of the restored copy, if any.
+func parseRestoreHeader(header string) (string, string) {
+	if header == "" {
+		return "", ""
+	}
+
+	if strings.Contains(header, `ongoing-request="true"`) {
+		return "in-progress", ""
+	}
+
+	expiry := ""
+	if idx := strings.Index(header, `expiry-date="`); idx >= 0 {
+		rest := header[idx+len(`expiry-date="`):]
+		if end := strings.Index(rest, `"`); end >= 0 {
+			expiry = rest[:end]
+		}
+	}
+
+	return "restored", expiry
+}
@@ -3,14 +3,23 @@ package s3
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,6 +40,11 @@ func (m *mockS3Client) GetBucketLocation(ctx context.Context, params *s3.GetBuck
 	return args.Get(0).(*s3.GetBucketLocationOutput), args.Error(1)
 }
 
+func (m *mockS3Client) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.HeadBucketOutput), args.Error(1)
+}
+
 func (m *mockS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
 	args := m.Called(ctx, params, optFns)
 	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
@@ -51,6 +65,26 @@ func (m *mockS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObject
 	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
 }
 
+func (m *mockS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.HeadObjectOutput), args.Error(1)
+}
+
+func (m *mockS3Client) RestoreObject(ctx context.Context, params *s3.RestoreObjectInput, optFns ...func(*s3.Options)) (*s3.RestoreObjectOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.RestoreObjectOutput), args.Error(1)
+}
+
+func (m *mockS3Client) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.CreateBucketOutput), args.Error(1)
+}
+
+func (m *mockS3Client) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.DeleteBucketOutput), args.Error(1)
+}
+
 // This static assertion verifies at compile time that mockS3Client implements the S3Client interface.
 var _ S3Client = (*mockS3Client)(nil)
 
@@ -122,6 +156,96 @@ func TestListBuckets(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestListBucketsOrder tests the ListBuckets method of the S3 Adapter.
+// It verifies that buckets are sorted by name whatever the API order is.
+func TestListBucketsOrder(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations with buckets out of order
+	mockClient.On("ListBuckets", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListBucketsOutput{
+		Buckets: []types.Bucket{
+			{Name: aws.String("zeta-logs")},
+			{Name: aws.String("alpha-site")},
+			{Name: aws.String("mid-data")},
+		},
+	}, nil)
+	mockClient.On("GetBucketLocation", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	buckets, err := adapter.ListBuckets(ctx)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert buckets are sorted by name
+	assert.Equal(t, "alpha-site", buckets[0].Name)
+	assert.Equal(t, "mid-data", buckets[1].Name)
+	assert.Equal(t, "zeta-logs", buckets[2].Name)
+}
+
+// TestSortBuckets tests the SortBuckets function.
+// It verifies sorting by name, creation date and region, with ties broken
+// by name, and that unknown sort orders are rejected.
+func TestSortBuckets(t *testing.T) {
+	now := time.Now()
+	buckets := []Bucket{
+		{Name: "c-bucket", CreationDate: now.Add(-1 * time.Hour), Region: "us-east-1"},
+		{Name: "a-bucket", CreationDate: now.Add(-3 * time.Hour), Region: "us-east-1"},
+		{Name: "b-bucket", CreationDate: now.Add(-2 * time.Hour), Region: "eu-west-1"},
+	}
+
+	names := func() []string {
+		var result []string
+		for _, bucket := range buckets {
+			result = append(result, bucket.Name)
+		}
+		return result
+	}
+
+	// Sort by name
+	assert.NoError(t, SortBuckets(buckets, BucketSortName))
+	assert.Equal(t, []string{"a-bucket", "b-bucket", "c-bucket"}, names())
+
+	// Sort by creation date, oldest first
+	assert.NoError(t, SortBuckets(buckets, BucketSortCreated))
+	assert.Equal(t, []string{"a-bucket", "b-bucket", "c-bucket"}, names())
+
+	// Sort by region, then name
+	assert.NoError(t, SortBuckets(buckets, BucketSortRegion))
+	assert.Equal(t, []string{"b-bucket", "a-bucket", "c-bucket"}, names())
+
+	// Unknown sort order
+	err := SortBuckets(buckets, "size")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sort order: size")
+}
+
+// TestIsValidBucketSortKey tests the IsValidBucketSortKey function.
+// It verifies that the orders SortBuckets accepts are valid and others are not.
+func TestIsValidBucketSortKey(t *testing.T) {
+	testCases := []struct {
+		key   string
+		valid bool
+	}{
+		{key: BucketSortName, valid: true},
+		{key: BucketSortCreated, valid: true},
+		{key: BucketSortRegion, valid: true},
+		{key: "size", valid: false},
+		{key: "", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.valid, IsValidBucketSortKey(tc.key))
+		})
+	}
+}
+
 // TestGetBucketRegion tests the GetBucketRegion method of the S3 Adapter.
 // It verifies that the adapter correctly processes different location constraints
 // and returns the appropriate region names, including the special case where
@@ -186,6 +310,147 @@ func TestGetBucketRegion(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestGetBucketRegionFallback tests the GetBucketRegion method of the S3 Adapter
+// when GetBucketLocation is denied. It verifies that the region is read from a
+// successful HeadBucket response or from the x-amz-bucket-region header of a
+// failed one, that the original error is returned if both fail, and that other
+// errors are returned without a HeadBucket request.
+func TestGetBucketRegionFallback(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	deniedErr := &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+	otherErr := &smithy.GenericAPIError{Code: "NoSuchBucket", Message: "The specified bucket does not exist"}
+
+	// headError creates a HeadBucket error response with the given region header
+	headError := func(region string) error {
+		header := http.Header{}
+		if region != "" {
+			header.Set("X-Amz-Bucket-Region", region)
+		}
+		return fmt.Errorf("operation error S3: HeadBucket: %w", &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: 403, Header: header}},
+				Err:      errors.New("Forbidden"),
+			},
+		})
+	}
+
+	// Set up expectations
+	mockClient.On("GetBucketLocation", mock.Anything, &s3.GetBucketLocationInput{Bucket: aws.String("gone-bucket")}, mock.Anything).Return((*s3.GetBucketLocationOutput)(nil), otherErr)
+	mockClient.On("GetBucketLocation", mock.Anything, mock.Anything, mock.Anything).Return((*s3.GetBucketLocationOutput)(nil), deniedErr)
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("allowed-bucket")}, mock.Anything).
+		Return(&s3.HeadBucketOutput{BucketRegion: aws.String("eu-central-1")}, nil)
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("denied-bucket")}, mock.Anything).
+		Return((*s3.HeadBucketOutput)(nil), headError("ap-southeast-2"))
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("missing-bucket")}, mock.Anything).
+		Return((*s3.HeadBucketOutput)(nil), headError(""))
+
+	ctx := context.Background()
+
+	// Region from a successful HeadBucket request
+	region, err := adapter.GetBucketRegion(ctx, "allowed-bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-central-1", region)
+
+	// Region from the header of a denied HeadBucket request
+	region, err = adapter.GetBucketRegion(ctx, "denied-bucket")
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-southeast-2", region)
+
+	// No region available
+	_, err = adapter.GetBucketRegion(ctx, "missing-bucket")
+	assert.ErrorIs(t, err, deniedErr)
+
+	// Errors other than AccessDenied don't fall back to HeadBucket
+	_, err = adapter.GetBucketRegion(ctx, "gone-bucket")
+	assert.ErrorIs(t, err, otherErr)
+	mockClient.AssertNotCalled(t, "HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("gone-bucket")}, mock.Anything)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestCreateBucket tests the CreateBucket method of the S3 Adapter.
+// It verifies that the location constraint is set for regions other than
+// us-east-1 and omitted for us-east-1, which rejects an explicit constraint.
+func TestCreateBucket(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("CreateBucket", mock.Anything, mock.MatchedBy(func(input *s3.CreateBucketInput) bool {
+		return aws.ToString(input.Bucket) == "eu-bucket" &&
+			input.CreateBucketConfiguration != nil &&
+			input.CreateBucketConfiguration.LocationConstraint == types.BucketLocationConstraintEuWest1
+	}), mock.Anything).Return(&s3.CreateBucketOutput{}, nil).Once()
+	mockClient.On("CreateBucket", mock.Anything, mock.MatchedBy(func(input *s3.CreateBucketInput) bool {
+		return aws.ToString(input.Bucket) == "us-bucket" && input.CreateBucketConfiguration == nil
+	}), mock.Anything).Return(&s3.CreateBucketOutput{}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.CreateBucket(ctx, "eu-bucket", "eu-west-1")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Call the function for us-east-1
+	err = adapter.CreateBucket(ctx, "us-bucket", "us-east-1")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDeleteBucket tests the DeleteBucket and EmptyBucket methods of the S3 Adapter.
+// It verifies that emptying a bucket deletes every object before the
+// bucket itself is deleted.
+func TestDeleteBucket(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("a.txt")},
+			{Key: aws.String("dir/b.txt")},
+		},
+	}, nil)
+	mockClient.On("DeleteObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.DeleteObjectOutput{}, nil)
+	mockClient.On("DeleteBucket", mock.Anything, mock.MatchedBy(func(input *s3.DeleteBucketInput) bool {
+		return aws.ToString(input.Bucket) == "test-bucket"
+	}), mock.Anything).Return(&s3.DeleteBucketOutput{}, nil)
+
+	// Call the functions
+	ctx := context.Background()
+	deleted, err := adapter.EmptyBucket(ctx, "test-bucket")
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	err = adapter.DeleteBucket(ctx, "test-bucket")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertNumberOfCalls(t, "DeleteObject", 2)
+	mockClient.AssertExpectations(t)
+}
+
 // TestListObjects tests the ListObjects method of the S3 Adapter.
 // It verifies that the adapter correctly processes the AWS API response
 // and returns the expected list of objects with all fields properly populated,
@@ -260,6 +525,275 @@ func TestListObjects(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// TestListObjectsLimit tests the ListObjects method of the S3 Adapter with a limit.
+// It verifies that the page size is capped to the limit and that no further
+// pages are fetched once the limit is reached.
+func TestListObjectsLimit(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Create mock response with more pages available
+	mockResponse := &s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("a.txt")},
+			{Key: aws.String("b.txt")},
+		},
+		IsTruncated:           aws.Bool(true),
+		NextContinuationToken: aws.String("next"),
+	}
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToInt32(input.MaxKeys) == 2
+	}), mock.Anything).Return(mockResponse, nil)
+
+	// Call the function
+	ctx := context.Background()
+	objects, err := adapter.ListObjects(ctx, "test-bucket", "", 2)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert only the first page was fetched
+	assert.Len(t, objects, 2)
+	mockClient.AssertNumberOfCalls(t, "ListObjectsV2", 1)
+}
+
+// TestCountObjects tests the CountObjects method of the S3 Adapter.
+// It verifies that the key counts of all pages are summed, and that counting
+// stops at the limit.
+func TestCountObjects(t *testing.T) {
+	t.Run("AllPages", func(t *testing.T) {
+		// Create mock client
+		mockClient := new(mockS3Client)
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations: two pages, only the key counts are used
+		mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			return input.ContinuationToken == nil
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			KeyCount:              aws.Int32(1000),
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("next"),
+		}, nil)
+		mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			return aws.ToString(input.ContinuationToken) == "next"
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			KeyCount: aws.Int32(234),
+		}, nil)
+
+		// Call the function
+		count, err := adapter.CountObjects(context.Background(), "test-bucket", "", 0, 0)
+
+		// Assert no error
+		assert.NoError(t, err)
+		assert.Equal(t, 1234, count)
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		// Create mock client
+		mockClient := new(mockS3Client)
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations
+		mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			return aws.ToInt32(input.MaxKeys) == 2 && aws.ToString(input.Prefix) == "logs/"
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			KeyCount:              aws.Int32(2),
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("next"),
+		}, nil)
+
+		// Call the function
+		count, err := adapter.CountObjects(context.Background(), "test-bucket", "logs/", 2, 0)
+
+		// Assert no error
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		// Assert only the first page was fetched
+		mockClient.AssertNumberOfCalls(t, "ListObjectsV2", 1)
+	})
+}
+
+// TestListObjectsWithPageSize tests the ListObjectsWithPageSize method of the S3 Adapter.
+// It verifies that each API call asks for at most the page size.
+func TestListObjectsWithPageSize(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToInt32(input.MaxKeys) == 50
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{{Key: aws.String("a.txt")}},
+	}, nil)
+
+	// Call the function
+	objects, err := adapter.ListObjectsWithPageSize(context.Background(), "test-bucket", "", 0, 50)
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListObjectsPage tests the ListObjectsPage method of the S3 Adapter.
+// It verifies that one page is fetched per call, that the continuation token of
+// the first page is passed on the second call, and that no token is returned
+// for the last page.
+func TestListObjectsPage(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations for the first page
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return input.ContinuationToken == nil && aws.ToInt32(input.MaxKeys) == 2 && aws.ToString(input.Prefix) == "logs/"
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("logs/a.log"), Size: aws.Int64(10)},
+			{Key: aws.String("logs/b.log"), Size: aws.Int64(20)},
+		},
+		IsTruncated:           aws.Bool(true),
+		NextContinuationToken: aws.String("page-2"),
+	}, nil).Once()
+
+	// Set up expectations for the second page
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToString(input.ContinuationToken) == "page-2" && aws.ToInt32(input.MaxKeys) == 2
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("logs/c.log"), Size: aws.Int64(30)},
+		},
+		IsTruncated: aws.Bool(false),
+	}, nil).Once()
+
+	// Call the function for the first page
+	ctx := context.Background()
+	objects, token, err := adapter.ListObjectsPage(ctx, "test-bucket", "logs/", "", 2)
+
+	// Assert the first page and the token of the next one
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	assert.Equal(t, "logs/a.log", objects[0].Key)
+	assert.Equal(t, "page-2", token)
+
+	// Call the function for the second page
+	objects, token, err = adapter.ListObjectsPage(ctx, "test-bucket", "logs/", token, 2)
+
+	// Assert the last page
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "logs/c.log", objects[0].Key)
+	assert.Equal(t, int64(30), objects[0].Size)
+	assert.Empty(t, token)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListObjectsWithDelimiter tests the ListObjectsWithDelimiter method of the S3 Adapter.
+// It verifies that the common prefixes and the objects of a level are separated,
+// that the "folder" placeholder object is left out, and that all pages are read.
+func TestListObjectsWithDelimiter(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations for the first page
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToString(input.Delimiter) == "/" && aws.ToString(input.Prefix) == "logs/" && input.ContinuationToken == nil
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("logs/2023/")},
+			{Prefix: aws.String("logs/2024/")},
+		},
+		Contents: []types.Object{
+			{Key: aws.String("logs/")},
+			{Key: aws.String("logs/README.md"), Size: aws.Int64(12)},
+		},
+		IsTruncated:           aws.Bool(true),
+		NextContinuationToken: aws.String("page-2"),
+	}, nil).Once()
+
+	// Set up expectations for the second page
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToString(input.Delimiter) == "/" && aws.ToString(input.ContinuationToken) == "page-2"
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("logs/2025/")}},
+		Contents:       []types.Object{{Key: aws.String("logs/latest.log"), Size: aws.Int64(34)}},
+	}, nil).Once()
+
+	// Call the function
+	listing, err := adapter.ListObjectsWithDelimiter(context.Background(), "test-bucket", "logs/", "", 0)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the prefixes and objects are separated
+	assert.Equal(t, []string{"logs/2023/", "logs/2024/", "logs/2025/"}, listing.Prefixes)
+	assert.Len(t, listing.Objects, 2)
+	assert.Equal(t, "logs/README.md", listing.Objects[0].Key)
+	assert.Equal(t, int64(12), listing.Objects[0].Size)
+	assert.Equal(t, "logs/latest.log", listing.Objects[1].Key)
+	assert.Empty(t, listing.NextToken)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListObjectsWithDelimiterLimit tests the ListObjectsWithDelimiter method of the
+// S3 Adapter with a limit.
+// It verifies that prefixes and objects count towards the limit together and that
+// no further pages are fetched once it is reached.
+func TestListObjectsWithDelimiterLimit(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToString(input.Delimiter) == "|"
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		CommonPrefixes:        []types.CommonPrefix{{Prefix: aws.String("a|")}, {Prefix: aws.String("b|")}},
+		Contents:              []types.Object{{Key: aws.String("c")}, {Key: aws.String("d")}},
+		IsTruncated:           aws.Bool(true),
+		NextContinuationToken: aws.String("page-2"),
+	}, nil).Once()
+
+	// Call the function
+	listing, err := adapter.ListObjectsWithDelimiter(context.Background(), "test-bucket", "", "|", 3)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the listing stops at the limit
+	assert.Equal(t, []string{"a|", "b|"}, listing.Prefixes)
+	assert.Len(t, listing.Objects, 1)
+	assert.Equal(t, "c", listing.Objects[0].Key)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
 // TestDeleteObject tests the DeleteObject method of the S3 Adapter.
 // It verifies that the adapter correctly calls the AWS API with the
 // expected parameters and handles the response.
@@ -287,32 +821,696 @@ func TestDeleteObject(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-// TestGetObjectURL tests the GetObjectURL method of the S3 Adapter.
-// It verifies that the adapter correctly formats the S3 object URL
-// using the bucket name and object key.
-func TestGetObjectURL(t *testing.T) {
+// TestUploadObjectWithOptions tests the UploadObjectWithOptions method of the S3 Adapter.
+// It verifies that the canned ACL is passed to the AWS API and that invalid
+// ACLs are rejected without calling the API.
+func TestUploadObjectWithOptions(t *testing.T) {
+	// Create a file to upload
+	filePath := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(filePath, []byte("<html></html>"), 0644))
+
 	// Create mock client
 	mockClient := new(mockS3Client)
 
 	// Create adapter with mock client
 	adapter := NewAdapterWithClient(mockClient)
 
+	// Set up expectations
+	mockClient.On("PutObject", mock.Anything, mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		return aws.ToString(input.Bucket) == "test-bucket" &&
+			aws.ToString(input.Key) == "site/index.html" &&
+			input.ACL == types.ObjectCannedACLPublicRead
+	}), mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+
 	// Call the function
-	url := adapter.GetObjectURL("test-bucket", "test-object.txt")
+	ctx := context.Background()
+	err := adapter.UploadObjectWithOptions(ctx, "test-bucket", "site/index.html", filePath, UploadOptions{ACL: "public-read"})
 
-	// Assert URL
-	assert.Equal(t, "https://test-bucket.s3.amazonaws.com/test-object.txt", url)
-}
+	// Assert no error
+	assert.NoError(t, err)
 
-// mockReadCloser implements the io.ReadCloser interface for testing purposes.
-// It wraps a string reader and tracks whether Close() has been called.
-// This is used to mock the response body from S3 GetObject operations.
-type mockReadCloser struct {
-	reader io.Reader // Underlying reader (typically a strings.Reader)
-	closed bool      // Whether Close() has been called
-}
+	// Invalid ACL
+	err = adapter.UploadObjectWithOptions(ctx, "test-bucket", "site/index.html", filePath, UploadOptions{ACL: "world-writable"})
+	assert.Error(t, err)
 
-// newMockReadCloser creates a new mockReadCloser with the given string content.
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "PutObject", 1)
+}
+
+// TestUploadObjectContentType tests the content type of uploaded objects.
+// It verifies that the content type is detected from the file extension, or
+// from the content of files with an unknown extension, and that a given
+// content type is used instead.
+func TestUploadObjectContentType(t *testing.T) {
+	dir := t.TempDir()
+	htmlPath := filepath.Join(dir, "index.html")
+	assert.NoError(t, os.WriteFile(htmlPath, []byte("<html></html>"), 0644))
+	blobPath := filepath.Join(dir, "blob")
+	assert.NoError(t, os.WriteFile(blobPath, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644))
+
+	testCases := []struct {
+		name     string
+		filePath string
+		opts     UploadOptions
+		want     string
+	}{
+		{name: "Extension", filePath: htmlPath, want: "text/html"},
+		{name: "Content", filePath: blobPath, want: "image/png"},
+		{name: "Override", filePath: htmlPath, opts: UploadOptions{ContentType: "text/plain"}, want: "text/plain"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := new(mockS3Client)
+			adapter := NewAdapterWithClient(mockClient)
+
+			// Set up expectations, capturing the input
+			var input *s3.PutObjectInput
+			var body []byte
+			mockClient.On("PutObject", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				input = args.Get(1).(*s3.PutObjectInput)
+				body, _ = io.ReadAll(input.Body)
+			}).Return(&s3.PutObjectOutput{}, nil)
+
+			// Call the function
+			err := adapter.UploadObjectWithOptions(context.Background(), "test-bucket", "key", tc.filePath, tc.opts)
+
+			// Assert no error
+			assert.NoError(t, err)
+			// System MIME tables may or may not add a charset
+			contentType, _, _ := strings.Cut(aws.ToString(input.ContentType), ";")
+			assert.Equal(t, tc.want, contentType)
+
+			// The whole file is uploaded after detecting its type
+			content, _ := os.ReadFile(tc.filePath)
+			assert.Equal(t, content, body)
+
+			// Verify expectations
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUploadDirectory tests the UploadDirectory method of the S3 Adapter.
+// It verifies that every regular file is uploaded with its relative path as
+// the key (including keys with spaces), that symlinks are skipped, and that
+// an empty directory uploads nothing.
+func TestUploadDirectory(t *testing.T) {
+	// Create a directory tree to upload
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub dir", "empty"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub dir", "my file.txt"), []byte("hello"), 0644))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "index.html"), filepath.Join(dir, "link.html")))
+
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	var keys []string
+	mockClient.On("PutObject", mock.Anything, mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		return aws.ToString(input.Bucket) == "test-bucket"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		keys = append(keys, aws.ToString(args.Get(1).(*s3.PutObjectInput).Key))
+	}).Return(&s3.PutObjectOutput{}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	transfers, err := adapter.UploadDirectory(ctx, "test-bucket", "site", dir, UploadOptions{})
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert every regular file was uploaded below the prefix
+	assert.ElementsMatch(t, []string{"site/index.html", "site/sub dir/my file.txt"}, keys)
+	assert.Len(t, transfers, 2)
+	for _, transfer := range transfers {
+		assert.NoError(t, transfer.Error)
+	}
+
+	// An empty directory uploads nothing
+	transfers, err = adapter.UploadDirectory(ctx, "test-bucket", "site/", filepath.Join(dir, "sub dir", "empty"), UploadOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, transfers)
+	mockClient.AssertNumberOfCalls(t, "PutObject", 2)
+}
+
+// TestDownloadObjectWithOptions tests the DownloadObjectWithOptions method of the S3 Adapter.
+// It verifies that downloads go through a partial file that is only renamed on success,
+// that interrupted transfers are retried with Range requests, that a failed download
+// keeps the partial data, and that a later download resumes from it only if the
+// object still has the same ETag.
+func TestDownloadObjectWithOptions(t *testing.T) {
+	ctx := context.Background()
+	interrupted := errors.New("connection reset")
+
+	// rangeIs matches GetObject calls requesting the given range (empty for the whole object)
+	rangeIs := func(expected string) interface{} {
+		return mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return aws.ToString(input.Range) == expected
+		})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		target := filepath.Join(t.TempDir(), "file.txt")
+
+		// Create mock client
+		mockClient := new(mockS3Client)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations
+		mockClient.On("GetObject", mock.Anything, rangeIs(""), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("hello world")}, nil).Once()
+
+		// Call the function
+		err := adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{})
+
+		// Assert the file was written and no partial file is left
+		assert.NoError(t, err)
+		data, err := os.ReadFile(target)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+		assert.NoFileExists(t, target+PartFileSuffix)
+	})
+
+	t.Run("Retry after interruption", func(t *testing.T) {
+		target := filepath.Join(t.TempDir(), "file.txt")
+
+		// Create mock client
+		mockClient := new(mockS3Client)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations
+		mockClient.On("GetObject", mock.Anything, rangeIs(""), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: newInterruptedReadCloser("hello ", interrupted),
+			ETag: aws.String("\"v1\""),
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return aws.ToString(input.Range) == "bytes=6-" && aws.ToString(input.IfMatch) == "\"v1\""
+		}), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("world")}, nil).Once()
+
+		// Call the function
+		err := adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{})
+
+		// Assert the download completed
+		assert.NoError(t, err)
+		data, err := os.ReadFile(target)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Failure keeps partial file and resume completes it", func(t *testing.T) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "file.txt")
+
+		// An existing file must not be touched by a failed download
+		assert.NoError(t, os.WriteFile(target, []byte("previous"), 0644))
+
+		// Create mock client
+		mockClient := new(mockS3Client)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations
+		mockClient.On("GetObject", mock.Anything, rangeIs(""), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: newInterruptedReadCloser("hel", interrupted),
+			ETag: aws.String("\"v1\""),
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, rangeIs("bytes=3-"), mock.Anything).Return(&s3.GetObjectOutput{Body: newInterruptedReadCloser("", interrupted)}, nil).Times(maxDownloadAttempts - 1)
+
+		// Call the function
+		err := adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{})
+
+		// Assert the partial data is kept and the target is unchanged
+		assert.ErrorContains(t, err, "use --continue to resume")
+		data, _ := os.ReadFile(target + PartFileSuffix)
+		assert.Equal(t, "hel", string(data))
+		data, _ = os.ReadFile(target)
+		assert.Equal(t, "previous", string(data))
+
+		// Resume the download, only accepting the version of the partial data
+		mockClient.On("HeadObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(11), ETag: aws.String("\"v1\"")}, nil)
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return aws.ToString(input.Range) == "bytes=3-" && aws.ToString(input.IfMatch) == "\"v1\""
+		}), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("lo world")}, nil).Once()
+		err = adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{Resume: true})
+
+		// Assert the download completed
+		assert.NoError(t, err)
+		data, err = os.ReadFile(target)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+		assert.NoFileExists(t, target+PartFileSuffix)
+		assert.NoFileExists(t, target+PartFileSuffix+partETagSuffix)
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Resume starts over when the object changed", func(t *testing.T) {
+		target := filepath.Join(t.TempDir(), "file.txt")
+
+		// Partial data of an older version of the object
+		assert.NoError(t, os.WriteFile(target+PartFileSuffix, []byte("hel"), 0644))
+		assert.NoError(t, os.WriteFile(target+PartFileSuffix+partETagSuffix, []byte("\"v1\""), 0644))
+
+		// Create mock client
+		mockClient := new(mockS3Client)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations: the object now has another ETag, so the whole
+		// object is downloaded again
+		mockClient.On("HeadObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{ContentLength: aws.Int64(11), ETag: aws.String("\"v2\"")}, nil)
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return input.Range == nil && input.IfMatch == nil
+		}), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("HELLO WORLD"), ETag: aws.String("\"v2\"")}, nil).Once()
+
+		// Call the function
+		err := adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{Resume: true})
+
+		// Assert the stale partial data was replaced
+		assert.NoError(t, err)
+		data, err := os.ReadFile(target)
+		assert.NoError(t, err)
+		assert.Equal(t, "HELLO WORLD", string(data))
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		target := filepath.Join(t.TempDir(), "file.txt")
+
+		// Create mock client
+		mockClient := new(mockS3Client)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations, with the range retried from where the transfer stopped
+		mockClient.On("GetObject", mock.Anything, rangeIs("bytes=100-109"), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: newInterruptedReadCloser("hell", interrupted),
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, rangeIs("bytes=104-109"), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: newMockReadCloser("o worl"),
+		}, nil).Once()
+
+		// Call the function
+		err := adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{Range: "bytes=100-109"})
+
+		// Assert only the range was written
+		assert.NoError(t, err)
+		data, err := os.ReadFile(target)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello worl", string(data))
+
+		// Ranged downloads can't be resumed
+		err = adapter.DownloadObjectWithOptions(ctx, "test-bucket", "file.txt", target, DownloadOptions{Range: "bytes=0-9", Resume: true})
+		assert.Error(t, err)
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	})
+}
+
+// TestParseByteRange tests the ParseByteRange function.
+// It verifies that first-last, open-ended and suffix ranges are accepted with or
+// without the bytes= prefix, and that invalid and multiple ranges are rejected.
+func TestParseByteRange(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"bytes=0-1023", "bytes=0-1023", false},
+		{"0-1023", "bytes=0-1023", false},
+		{"bytes=1024-", "bytes=1024-", false},
+		{"bytes=-500", "bytes=-500", false},
+		{"bytes=0-0", "bytes=0-0", false},
+		{"bytes=10-5", "", true},
+		{"bytes=-0", "", true},
+		{"bytes=a-b", "", true},
+		{"bytes=0-1,5-9", "", true},
+		{"bytes=100", "", true},
+		{"lines=0-10", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			header, err := ParseByteRange(tc.value)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, header)
+		})
+	}
+
+	// What is left of a suffix range after an interruption is a shorter suffix
+	r, err := parseByteRange("bytes=-500")
+	assert.NoError(t, err)
+	assert.Equal(t, "bytes=-200", r.header(300))
+}
+
+// TestOpenObject tests the OpenObject method of the S3 Adapter.
+// It verifies that the range is passed to GetObject and the body returned.
+func TestOpenObject(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+		return aws.ToString(input.Key) == "app.log" && aws.ToString(input.Range) == "bytes=0-4"
+	}), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("first")}, nil).Once()
+
+	// Call the function
+	body, err := adapter.OpenObject(context.Background(), "test-bucket", "app.log", "bytes=0-4")
+
+	// Assert no error
+	assert.NoError(t, err)
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+	body.Close()
+
+	// Invalid ranges are rejected before calling S3
+	_, err = adapter.OpenObject(context.Background(), "test-bucket", "app.log", "bytes=5-1")
+	assert.Error(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestDownloadPrefix tests the DownloadPrefix method of the S3 Adapter.
+// It verifies that objects are written below the target directory relative
+// to the prefix, that folder placeholders are skipped, and that keys escaping
+// the target directory are refused.
+func TestDownloadPrefix(t *testing.T) {
+	dir := t.TempDir()
+
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return aws.ToString(input.Prefix) == "logs/"
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("logs/")},
+			{Key: aws.String("logs/app.log")},
+			{Key: aws.String("logs/2024/my log.txt")},
+			{Key: aws.String("logs/../../escape.txt")},
+		},
+	}, nil)
+	for _, key := range []string{"logs/app.log", "logs/2024/my log.txt"} {
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return aws.ToString(input.Key) == key
+		}), mock.Anything).Return(&s3.GetObjectOutput{Body: newMockReadCloser("content of " + key)}, nil).Once()
+	}
+
+	// Call the function
+	ctx := context.Background()
+	transfers, err := adapter.DownloadPrefix(ctx, "test-bucket", "logs", dir)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the objects were written relative to the prefix
+	assert.Len(t, transfers, 3)
+	content, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "content of logs/app.log", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "2024", "my log.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "content of logs/2024/my log.txt", string(content))
+
+	// Assert the escaping key was refused
+	assert.Error(t, transfers[2].Error)
+	mockClient.AssertNumberOfCalls(t, "GetObject", 2)
+}
+
+// TestSyncLocalToBucket tests the SyncLocalToBucket method of the S3 Adapter.
+// It verifies that unchanged files are skipped, that newer and new files are
+// uploaded, and that remote objects without a local file are only deleted
+// when requested.
+func TestSyncLocalToBucket(t *testing.T) {
+	// Create a directory to sync
+	dir := t.TempDir()
+	remoteTime := time.Now().Add(-time.Hour)
+	files := map[string]string{
+		"unchanged.txt": "same",
+		"newer.txt":     "same",
+		"resized.txt":   "longer content",
+		"new.txt":       "new",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		assert.NoError(t, os.Chtimes(path, remoteTime.Add(-time.Hour), remoteTime.Add(-time.Hour)))
+	}
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "newer.txt"), time.Now(), time.Now()))
+
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("site/unchanged.txt"), Size: aws.Int64(4), LastModified: aws.Time(remoteTime)},
+			{Key: aws.String("site/newer.txt"), Size: aws.Int64(4), LastModified: aws.Time(remoteTime)},
+			{Key: aws.String("site/resized.txt"), Size: aws.Int64(4), LastModified: aws.Time(remoteTime)},
+			{Key: aws.String("site/stale.txt"), Size: aws.Int64(4), LastModified: aws.Time(remoteTime)},
+		},
+	}, nil)
+	var uploaded []string
+	mockClient.On("PutObject", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		uploaded = append(uploaded, aws.ToString(args.Get(1).(*s3.PutObjectInput).Key))
+	}).Return(&s3.PutObjectOutput{}, nil)
+	mockClient.On("DeleteObject", mock.Anything, mock.MatchedBy(func(input *s3.DeleteObjectInput) bool {
+		return aws.ToString(input.Key) == "site/stale.txt"
+	}), mock.Anything).Return(&s3.DeleteObjectOutput{}, nil)
+
+	// Call the function without deletion
+	ctx := context.Background()
+	actions, err := adapter.SyncLocalToBucket(ctx, dir, "test-bucket", "site", SyncOptions{})
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert only new and changed files were uploaded
+	byKey := make(map[string]string)
+	for _, action := range actions {
+		assert.Empty(t, action.Error)
+		byKey[action.Key] = action.Action
+	}
+	assert.Equal(t, map[string]string{
+		"site/unchanged.txt": SyncActionSkip,
+		"site/newer.txt":     SyncActionUpload,
+		"site/resized.txt":   SyncActionUpload,
+		"site/new.txt":       SyncActionUpload,
+	}, byKey)
+	assert.ElementsMatch(t, []string{"site/newer.txt", "site/resized.txt", "site/new.txt"}, uploaded)
+	mockClient.AssertNotCalled(t, "DeleteObject", mock.Anything, mock.Anything, mock.Anything)
+
+	// Call the function with deletion
+	actions, err = adapter.SyncLocalToBucket(ctx, dir, "test-bucket", "site/", SyncOptions{Delete: true})
+
+	// Assert the stale object was deleted
+	assert.NoError(t, err)
+	assert.Equal(t, SyncAction{Action: SyncActionDelete, Key: "site/stale.txt"}, actions[len(actions)-1])
+	mockClient.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
+
+// TestRestoreObject tests the RestoreObject method of the S3 Adapter.
+// It verifies that the adapter sends the requested number of days to the
+// AWS API and rejects non-positive day counts without calling the API.
+func TestRestoreObject(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("RestoreObject", mock.Anything, mock.MatchedBy(func(input *s3.RestoreObjectInput) bool {
+		return aws.ToString(input.Bucket) == "test-bucket" &&
+			aws.ToString(input.Key) == "archive/test-object.txt" &&
+			input.RestoreRequest != nil &&
+			aws.ToInt32(input.RestoreRequest.Days) == 3
+	}), mock.Anything).Return(&s3.RestoreObjectOutput{}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.RestoreObject(ctx, "test-bucket", "archive/test-object.txt", 3)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Invalid number of days
+	err = adapter.RestoreObject(ctx, "test-bucket", "archive/test-object.txt", 0)
+	assert.Error(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "RestoreObject", 1)
+}
+
+// TestHeadObject tests the HeadObject method of the S3 Adapter.
+// It verifies that the adapter correctly extracts object metadata and
+// parses the restore status of archived objects.
+func TestHeadObject(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name           string
+		restore        *string
+		expectedStatus string
+		expectedExpiry string
+	}{
+		{
+			name:           "No restore requested",
+			restore:        nil,
+			expectedStatus: "",
+			expectedExpiry: "",
+		},
+		{
+			name:           "Restore in progress",
+			restore:        aws.String(`ongoing-request="true"`),
+			expectedStatus: "in-progress",
+			expectedExpiry: "",
+		},
+		{
+			name:           "Restore completed",
+			restore:        aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`),
+			expectedStatus: "restored",
+			expectedExpiry: "Fri, 21 Dec 2012 00:00:00 GMT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := new(mockS3Client)
+
+			// Create adapter with mock client
+			adapter := NewAdapterWithClient(mockClient)
+
+			// Create mock response
+			mockResponse := &s3.HeadObjectOutput{
+				ContentLength: aws.Int64(1024),
+				ContentType:   aws.String("text/plain"),
+				ETag:          aws.String("\"abc123\""),
+				StorageClass:  types.StorageClassGlacier,
+				Restore:       tc.restore,
+			}
+
+			// Set up expectations
+			mockClient.On("HeadObject", mock.Anything, mock.Anything, mock.Anything).Return(mockResponse, nil)
+
+			// Call the function
+			ctx := context.Background()
+			metadata, err := adapter.HeadObject(ctx, "test-bucket", "test-object.txt")
+
+			// Assert no error
+			assert.NoError(t, err)
+
+			// Assert metadata
+			assert.Equal(t, "test-object.txt", metadata.Key)
+			assert.Equal(t, int64(1024), metadata.Size)
+			assert.Equal(t, "text/plain", metadata.ContentType)
+			assert.Equal(t, "abc123", metadata.ETag)
+			assert.Equal(t, "GLACIER", metadata.StorageClass)
+			assert.Equal(t, tc.expectedStatus, metadata.RestoreStatus)
+			assert.Equal(t, tc.expectedExpiry, metadata.RestoreExpiry)
+
+			// Verify expectations
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetObjectURL tests the GetObjectURL method of the S3 Adapter.
+// It verifies that the adapter correctly formats the S3 object URL
+// using the bucket name and object key.
+func TestGetObjectURL(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Call the function
+	url := adapter.GetObjectURL("test-bucket", "test-object.txt")
+
+	// Assert URL
+	assert.Equal(t, "https://test-bucket.s3.amazonaws.com/test-object.txt", url)
+}
+
+// TestParseS3URL tests the ParseS3URL function.
+// It verifies that bucket-only URLs, trailing slashes, empty keys and keys
+// containing special characters are split correctly.
+func TestParseS3URL(t *testing.T) {
+	testCases := []struct {
+		raw    string
+		bucket string
+		key    string
+	}{
+		{"s3://my-bucket/path/to/file.txt", "my-bucket", "path/to/file.txt"},
+		{"my-bucket/file.txt", "my-bucket", "file.txt"},
+		{"s3://my-bucket", "my-bucket", ""},
+		{"s3://my-bucket/", "my-bucket", ""},
+		{"s3://my-bucket/logs/", "my-bucket", "logs/"},
+		{"s3://my-bucket/a b/c?d#e%20f.txt", "my-bucket", "a b/c?d#e%20f.txt"},
+		{"s3://my-bucket//double", "my-bucket", "/double"},
+	}
+
+	for _, tc := range testCases {
+		bucket, key, err := ParseS3URL(tc.raw)
+		assert.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.bucket, bucket, tc.raw)
+		assert.Equal(t, tc.key, key, tc.raw)
+	}
+
+	// Invalid URLs
+	for _, raw := range []string{"", "s3://", "s3:///key"} {
+		_, _, err := ParseS3URL(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+// mockReadCloser implements the io.ReadCloser interface for testing purposes.
+// It wraps a string reader and tracks whether Close() has been called.
+// This is used to mock the response body from S3 GetObject operations.
+type mockReadCloser struct {
+	reader io.Reader // Underlying reader (typically a strings.Reader)
+	closed bool      // Whether Close() has been called
+}
+
+// newMockReadCloser creates a new mockReadCloser with the given string content.
 // This is a helper function for creating mock S3 object content in tests.
 func newMockReadCloser(content string) *mockReadCloser {
 	return &mockReadCloser{
@@ -321,6 +1519,14 @@ func newMockReadCloser(content string) *mockReadCloser {
 	}
 }
 
+// newInterruptedReadCloser creates a mockReadCloser that returns the given content
+// and then fails with err, like a connection dropped in the middle of a download.
+func newInterruptedReadCloser(content string, err error) *mockReadCloser {
+	return &mockReadCloser{
+		reader: io.MultiReader(strings.NewReader(content), iotest.ErrReader(err)),
+	}
+}
+
 // Read implements the io.Reader interface by delegating to the underlying reader.
 func (m *mockReadCloser) Read(p []byte) (n int, err error) {
 	return m.reader.Read(p)
@@ -332,3 +1538,71 @@ func (m *mockReadCloser) Close() error {
 	m.closed = true
 	return nil
 }
+
+// TestObjectListingEntries tests combining the prefixes and objects of a listing.
+// It verifies that the common prefixes come first, followed by the objects.
+func TestObjectListingEntries(t *testing.T) {
+	listing := &ObjectListing{
+		Prefixes: []string{"logs/"},
+		Objects:  []Object{{Key: "index.html", Size: 42, StorageClass: "STANDARD"}},
+	}
+
+	entries := listing.Entries()
+
+	assert.Equal(t, []ListingEntry{
+		{Type: EntryTypePrefix, Key: "logs/"},
+		{Type: EntryTypeObject, Key: "index.html", Size: 42, StorageClass: "STANDARD"},
+	}, entries)
+}
+
+// TestWaitForBucketCreated tests waiting for a created S3 bucket to exist.
+// It verifies that the bucket is polled while HeadBucket reports it missing.
+func TestWaitForBucketCreated(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("new-bucket")}, mock.Anything).
+		Return((*s3.HeadBucketOutput)(nil), &types.NotFound{}).Once()
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("new-bucket")}, mock.Anything).
+		Return(&s3.HeadBucketOutput{}, nil).Once()
+
+	// Call the function
+	err := adapter.WaitForBucketCreated(context.Background(), "new-bucket", time.Millisecond)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestWaitForBucketDeleted tests waiting for a deleted S3 bucket to be gone.
+// It verifies that the bucket is polled until HeadBucket reports it missing.
+func TestWaitForBucketDeleted(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockS3Client)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	notFound := fmt.Errorf("operation error S3: HeadBucket: %w", &types.NotFound{})
+
+	// Set up expectations
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("old-bucket")}, mock.Anything).
+		Return(&s3.HeadBucketOutput{}, nil).Once()
+	mockClient.On("HeadBucket", mock.Anything, &s3.HeadBucketInput{Bucket: aws.String("old-bucket")}, mock.Anything).
+		Return((*s3.HeadBucketOutput)(nil), notFound).Once()
+
+	// Call the function
+	err := adapter.WaitForBucketDeleted(context.Background(), "old-bucket", time.Millisecond)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
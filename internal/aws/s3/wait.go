@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// WaitForBucketCreated waits until a created S3 bucket exists, with the
+// BucketExists waiter of the SDK, as S3 may not report it right away.
+//
+// Parameters:
+//   - ctx: Context for the API calls; its deadline limits how long to wait
+//   - bucketName: The name of the bucket
+//   - interval: How long to wait before the first retry; later retries back off
+//
+// Returns an error if the bucket cannot be checked or ctx is done before it exists.
+func (a *Adapter) WaitForBucketCreated(ctx context.Context, bucketName string, interval time.Duration) error {
+	waiter := s3.NewBucketExistsWaiter(a.client, func(o *s3.BucketExistsWaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	err := waiter.Wait(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucketName)}, client.MaxWait(ctx))
+	if err != nil {
+		return fmt.Errorf("bucket %s does not exist yet: %w", bucketName, err)
+	}
+	return nil
+}
+
+// WaitForBucketDeleted waits until a deleted S3 bucket no longer exists, with
+// the BucketNotExists waiter of the SDK, as S3 may still report it for a while
+// after the deletion.
+//
+// Parameters:
+//   - ctx: Context for the API calls; its deadline limits how long to wait
+//   - bucketName: The name of the bucket
+//   - interval: How long to wait before the first retry; later retries back off
+//
+// Returns an error if the bucket cannot be checked or ctx is done before it is gone.
+func (a *Adapter) WaitForBucketDeleted(ctx context.Context, bucketName string, interval time.Duration) error {
+	waiter := s3.NewBucketNotExistsWaiter(a.client, func(o *s3.BucketNotExistsWaiterOptions) {
+		o.MinDelay = interval
+		o.MaxDelay = max(o.MaxDelay, interval)
+	})
+	err := waiter.Wait(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucketName)}, client.MaxWait(ctx))
+	if err != nil {
+		return fmt.Errorf("bucket %s still exists: %w", bucketName, err)
+	}
+	return nil
+}
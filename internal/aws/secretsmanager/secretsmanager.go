@@ -0,0 +1,191 @@
+// Package secretsmanager provides functionality for reading AWS Secrets Manager secrets.
+// It includes operations for listing secrets and getting secret values.
+package secretsmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// SecretsManagerClient defines the interface for Secrets Manager client operations.
+// This interface allows for easy mocking in tests.
+type SecretsManagerClient interface {
+	ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+// currentVersionStage is the staging label of the current version of a secret
+const currentVersionStage = "AWSCURRENT"
+
+// Adapter represents a Secrets Manager service adapter that provides
+// higher-level operations for reading secrets.
+type Adapter struct {
+	client SecretsManagerClient // AWS Secrets Manager client implementation
+}
+
+// Secret represents a secret as listed by Secrets Manager, without its value.
+type Secret struct {
+	Name        string // Name of the secret
+	ARN         string // Amazon Resource Name of the secret
+	LastChanged string // When the secret was last changed (formatted, empty if never)
+}
+
+// SecretValue represents the current version of a secret, including its value.
+type SecretValue struct {
+	Name      string // Name of the secret
+	ARN       string // Amazon Resource Name of the secret
+	VersionID string // ID of the secret version
+	Created   string // When the version was created (formatted; when the secret was created if described)
+	Binary    bool   // Whether the secret is binary, in which case Value is base64-encoded (not set if described)
+	Value     string // The secret value (empty if described)
+}
+
+// NewAdapter creates a new Secrets Manager adapter using the AWS credentials
+// from the current context configuration.
+//
+// The context is used for AWS client creation and configuration.
+// Returns an error if the AWS client cannot be created.
+func NewAdapter(ctx context.Context) (*Adapter, error) {
+	// Create AWS client
+	awsClient, err := client.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Create Secrets Manager client
+	smClient := secretsmanager.NewFromConfig(awsClient.Config)
+
+	return &Adapter{
+		client: smClient,
+	}, nil
+}
+
+// NewAdapterWithClient creates a new Secrets Manager adapter with a provided client.
+// This is particularly useful for testing with mock clients.
+func NewAdapterWithClient(smClient SecretsManagerClient) *Adapter {
+	return &Adapter{
+		client: smClient,
+	}
+}
+
+// ListSecrets lists the secrets in the current region. Secret values are not read.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//
+// Returns a slice of Secret structs in the order Secrets Manager returned them
+// and an error if the operation fails.
+func (a *Adapter) ListSecrets(ctx context.Context) ([]Secret, error) {
+	// Create paginator for the ListSecrets API
+	paginator := secretsmanager.NewListSecretsPaginator(a.client, &secretsmanager.ListSecretsInput{})
+
+	var secrets []Secret
+
+	// Iterate through pages
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		for _, entry := range output.SecretList {
+			secret := Secret{
+				Name: aws.ToString(entry.Name),
+				ARN:  aws.ToString(entry.ARN),
+			}
+			if entry.LastChangedDate != nil {
+				secret.LastChanged = entry.LastChangedDate.Format("2006-01-02 15:04:05")
+			}
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets, nil
+}
+
+// GetSecretValue gets the current value of a secret.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - name: The name or ARN of the secret
+//
+// Returns a pointer to a SecretValue struct with the value and version details,
+// or an error if the secret cannot be found or read.
+func (a *Adapter) GetSecretValue(ctx context.Context, name string) (*SecretValue, error) {
+	// Create the input for the GetSecretValue API
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	}
+
+	// Call the GetSecretValue API
+	output, err := a.client.GetSecretValue(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get value of secret %s: %w", name, err)
+	}
+
+	secret := &SecretValue{
+		Name:      aws.ToString(output.Name),
+		ARN:       aws.ToString(output.ARN),
+		VersionID: aws.ToString(output.VersionId),
+		Value:     aws.ToString(output.SecretString),
+	}
+	if output.CreatedDate != nil {
+		secret.Created = output.CreatedDate.Format("2006-01-02 15:04:05")
+	}
+
+	// Secrets stored as binary have no string value
+	if output.SecretString == nil && output.SecretBinary != nil {
+		secret.Binary = true
+		secret.Value = base64.StdEncoding.EncodeToString(output.SecretBinary)
+	}
+
+	return secret, nil
+}
+
+// DescribeSecret gets the details of a secret and its current version without
+// reading the secret value.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - name: The name or ARN of the secret
+//
+// Returns a pointer to a SecretValue struct with an empty value, whose Created
+// time is when the secret was created, or an error if the secret cannot be found
+// or described.
+func (a *Adapter) DescribeSecret(ctx context.Context, name string) (*SecretValue, error) {
+	// Create the input for the DescribeSecret API
+	input := &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(name),
+	}
+
+	// Call the DescribeSecret API
+	output, err := a.client.DescribeSecret(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe secret %s: %w", name, err)
+	}
+
+	secret := &SecretValue{
+		Name: aws.ToString(output.Name),
+		ARN:  aws.ToString(output.ARN),
+	}
+	if output.CreatedDate != nil {
+		secret.Created = output.CreatedDate.Format("2006-01-02 15:04:05")
+	}
+
+	// Find the version GetSecretValue would read
+	for versionID, stages := range output.VersionIdsToStages {
+		if slices.Contains(stages, currentVersionStage) {
+			secret.VersionID = versionID
+			break
+		}
+	}
+
+	return secret, nil
+}
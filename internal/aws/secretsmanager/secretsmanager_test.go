@@ -0,0 +1,207 @@
+// Package secretsmanager provides tests for the Secrets Manager adapter functionality.
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockSecretsManagerClient implements the SecretsManagerClient interface for testing purposes.
+// It uses the testify/mock package to mock AWS Secrets Manager API calls.
+type mockSecretsManagerClient struct {
+	mock.Mock
+}
+
+func (m *mockSecretsManagerClient) ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*secretsmanager.ListSecretsOutput), args.Error(1)
+}
+
+func (m *mockSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*secretsmanager.GetSecretValueOutput), args.Error(1)
+}
+
+func (m *mockSecretsManagerClient) DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*secretsmanager.DescribeSecretOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that the mock client implements the interface.
+var _ SecretsManagerClient = (*mockSecretsManagerClient)(nil)
+
+// TestListSecrets tests the ListSecrets method of the Secrets Manager Adapter.
+// It verifies that secrets from all pages are returned with their name, ARN
+// and last change time.
+func TestListSecrets(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSecretsManagerClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	changed := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	// Set up expectations for two pages
+	mockClient.On("ListSecrets", mock.Anything, mock.MatchedBy(func(input *secretsmanager.ListSecretsInput) bool {
+		return input.NextToken == nil
+	}), mock.Anything).Return(&secretsmanager.ListSecretsOutput{
+		SecretList: []types.SecretListEntry{
+			{Name: aws.String("prod/db"), ARN: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf"), LastChangedDate: &changed},
+		},
+		NextToken: aws.String("page-2"),
+	}, nil).Once()
+	mockClient.On("ListSecrets", mock.Anything, mock.MatchedBy(func(input *secretsmanager.ListSecretsInput) bool {
+		return aws.ToString(input.NextToken) == "page-2"
+	}), mock.Anything).Return(&secretsmanager.ListSecretsOutput{
+		SecretList: []types.SecretListEntry{
+			{Name: aws.String("prod/api-key"), ARN: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/api-key-GhIjKl")},
+		},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	secrets, err := adapter.ListSecrets(ctx)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert secrets
+	assert.Equal(t, []Secret{
+		{Name: "prod/db", ARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf", LastChanged: "2024-05-01 08:30:00"},
+		{Name: "prod/api-key", ARN: "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/api-key-GhIjKl"},
+	}, secrets)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestGetSecretValue tests the GetSecretValue method of the Secrets Manager Adapter.
+// It verifies that string and binary values are returned with the version details.
+func TestGetSecretValue(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSecretsManagerClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	created := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	// Set up expectations
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.SecretId) == "prod/db"
+	}), mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+		Name:         aws.String("prod/db"),
+		ARN:          aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf"),
+		VersionId:    aws.String("v-1"),
+		CreatedDate:  &created,
+		SecretString: aws.String(`{"password":"hunter2"}`),
+	}, nil)
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.SecretId) == "prod/cert"
+	}), mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+		Name:         aws.String("prod/cert"),
+		SecretBinary: []byte{0x01, 0x02, 0x03},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	secret, err := adapter.GetSecretValue(ctx, "prod/db")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert string secret
+	assert.Equal(t, &SecretValue{
+		Name:      "prod/db",
+		ARN:       "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf",
+		VersionID: "v-1",
+		Created:   "2024-05-01 08:30:00",
+		Value:     `{"password":"hunter2"}`,
+	}, secret)
+
+	// Assert binary secret
+	secret, err = adapter.GetSecretValue(ctx, "prod/cert")
+	assert.NoError(t, err)
+	assert.True(t, secret.Binary)
+	assert.Equal(t, "AQID", secret.Value)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestGetSecretValueError tests the GetSecretValue method of the Secrets Manager
+// Adapter when the API call fails. It verifies that the error names the secret.
+func TestGetSecretValueError(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSecretsManagerClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).
+		Return((*secretsmanager.GetSecretValueOutput)(nil), errors.New("ResourceNotFoundException"))
+
+	// Call the function
+	ctx := context.Background()
+	secret, err := adapter.GetSecretValue(ctx, "missing")
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Nil(t, secret)
+	assert.Contains(t, err.Error(), "missing")
+}
+
+// TestDescribeSecret tests the DescribeSecret method of the Secrets Manager Adapter.
+// It verifies that the current version is picked from the version stages and that
+// the secret value is not read.
+func TestDescribeSecret(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSecretsManagerClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	created := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	// Set up expectations
+	mockClient.On("DescribeSecret", mock.Anything, mock.MatchedBy(func(input *secretsmanager.DescribeSecretInput) bool {
+		return aws.ToString(input.SecretId) == "prod/db"
+	}), mock.Anything).Return(&secretsmanager.DescribeSecretOutput{
+		Name:        aws.String("prod/db"),
+		ARN:         aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf"),
+		CreatedDate: &created,
+		VersionIdsToStages: map[string][]string{
+			"v-1": {"AWSPREVIOUS"},
+			"v-2": {"AWSCURRENT", "release"},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	secret, err := adapter.DescribeSecret(ctx, "prod/db")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert the current version without a value
+	assert.Equal(t, &SecretValue{
+		Name:      "prod/db",
+		ARN:       "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf",
+		VersionID: "v-2",
+		Created:   "2024-05-01 08:30:00",
+	}, secret)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "GetSecretValue", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,221 @@
+// Package sqs provides functionality for interacting with Amazon SQS queues.
+// It includes operations for listing queues, sending, receiving and deleting
+// messages, and purging queues.
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// MaxReceiveMessages is the largest number of messages a single ReceiveMessage call can return
+const MaxReceiveMessages = 10
+
+// SQSClient defines the interface for SQS client operations.
+// This interface allows for easy mocking in tests.
+type SQSClient interface {
+	ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	PurgeQueue(ctx context.Context, params *sqs.PurgeQueueInput, optFns ...func(*sqs.Options)) (*sqs.PurgeQueueOutput, error)
+}
+
+// Adapter represents an SQS service adapter that provides
+// higher-level operations for interacting with SQS queues.
+type Adapter struct {
+	client SQSClient // AWS SQS client implementation
+}
+
+// Queue represents an SQS queue.
+type Queue struct {
+	Name string // Queue name (the last part of the URL)
+	URL  string // Queue URL, used to address the queue in API calls
+}
+
+// Message represents a message received from an SQS queue.
+type Message struct {
+	ID            string // Message ID assigned by SQS
+	Body          string // Message body
+	ReceiptHandle string // Handle needed to delete the message or change its visibility
+}
+
+// NewAdapter creates a new SQS adapter using the AWS credentials
+// from the current context configuration.
+//
+// The context is used for AWS client creation and configuration.
+// Returns an error if the AWS client cannot be created.
+func NewAdapter(ctx context.Context) (*Adapter, error) {
+	// Create AWS client
+	awsClient, err := client.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Create SQS client
+	sqsClient := sqs.NewFromConfig(awsClient.Config)
+
+	return &Adapter{
+		client: sqsClient,
+	}, nil
+}
+
+// NewAdapterWithClient creates a new SQS adapter with a provided client.
+// This is particularly useful for testing with mock clients.
+func NewAdapterWithClient(sqsClient SQSClient) *Adapter {
+	return &Adapter{
+		client: sqsClient,
+	}
+}
+
+// ListQueues lists the SQS queues in the current region.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//
+// Returns a slice of Queue structs in the order SQS returned them and an error if the operation fails.
+func (a *Adapter) ListQueues(ctx context.Context) ([]Queue, error) {
+	// Create paginator for the ListQueues API
+	paginator := sqs.NewListQueuesPaginator(a.client, &sqs.ListQueuesInput{})
+
+	var queues []Queue
+
+	// Iterate through pages
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SQS queues: %w", err)
+		}
+
+		for _, url := range output.QueueUrls {
+			queues = append(queues, Queue{
+				Name: QueueName(url),
+				URL:  url,
+			})
+		}
+	}
+
+	return queues, nil
+}
+
+// SendMessage sends a message to an SQS queue.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - queueURL: The URL of the queue
+//   - body: The message body
+//
+// Returns the ID of the sent message and an error if the message cannot be sent.
+func (a *Adapter) SendMessage(ctx context.Context, queueURL, body string) (string, error) {
+	// Create the input for the SendMessage API
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(body),
+	}
+
+	// Call the SendMessage API
+	output, err := a.client.SendMessage(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message to SQS queue %s: %w", QueueName(queueURL), err)
+	}
+
+	return aws.ToString(output.MessageId), nil
+}
+
+// ReceiveMessages receives messages from an SQS queue. The messages stay in the
+// queue, invisible to other consumers until their visibility timeout expires,
+// unless they are deleted with DeleteMessage.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - queueURL: The URL of the queue
+//   - maxMessages: Maximum number of messages to receive (1 to MaxReceiveMessages)
+//
+// Returns the received messages, which may be fewer than maxMessages or none,
+// and an error if the operation fails.
+func (a *Adapter) ReceiveMessages(ctx context.Context, queueURL string, maxMessages int32) ([]Message, error) {
+	if maxMessages < 1 || maxMessages > MaxReceiveMessages {
+		return nil, fmt.Errorf("invalid number of messages: %d (must be between 1 and %d)", maxMessages, MaxReceiveMessages)
+	}
+
+	// Create the input for the ReceiveMessage API
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(queueURL),
+		MaxNumberOfMessages: maxMessages,
+	}
+
+	// Call the ReceiveMessage API
+	output, err := a.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive messages from SQS queue %s: %w", QueueName(queueURL), err)
+	}
+
+	messages := make([]Message, 0, len(output.Messages))
+	for _, msg := range output.Messages {
+		messages = append(messages, Message{
+			ID:            aws.ToString(msg.MessageId),
+			Body:          aws.ToString(msg.Body),
+			ReceiptHandle: aws.ToString(msg.ReceiptHandle),
+		})
+	}
+
+	return messages, nil
+}
+
+// DeleteMessage deletes a received message from an SQS queue.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - queueURL: The URL of the queue
+//   - receiptHandle: The receipt handle of the message, from ReceiveMessages
+//
+// Returns an error if the message cannot be deleted.
+func (a *Adapter) DeleteMessage(ctx context.Context, queueURL, receiptHandle string) error {
+	// Create the input for the DeleteMessage API
+	input := &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	}
+
+	// Call the DeleteMessage API
+	_, err := a.client.DeleteMessage(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to delete message from SQS queue %s: %w", QueueName(queueURL), err)
+	}
+
+	return nil
+}
+
+// PurgeQueue deletes all messages in an SQS queue. SQS allows one purge per
+// queue every 60 seconds.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - queueURL: The URL of the queue
+//
+// Returns an error if the queue cannot be purged.
+func (a *Adapter) PurgeQueue(ctx context.Context, queueURL string) error {
+	// Create the input for the PurgeQueue API
+	input := &sqs.PurgeQueueInput{
+		QueueUrl: aws.String(queueURL),
+	}
+
+	// Call the PurgeQueue API
+	_, err := a.client.PurgeQueue(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to purge SQS queue %s: %w", QueueName(queueURL), err)
+	}
+
+	return nil
+}
+
+// QueueName returns the name of a queue from its URL
+// (e.g. https://sqs.us-east-1.amazonaws.com/123456789012/orders returns orders).
+func QueueName(queueURL string) string {
+	return queueURL[strings.LastIndex(queueURL, "/")+1:]
+}
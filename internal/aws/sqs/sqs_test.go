@@ -0,0 +1,245 @@
+// Package sqs provides tests for the SQS adapter functionality.
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockSQSClient implements the SQSClient interface for testing purposes.
+// It uses the testify/mock package to mock AWS SQS API calls.
+type mockSQSClient struct {
+	mock.Mock
+}
+
+func (m *mockSQSClient) ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.ListQueuesOutput), args.Error(1)
+}
+
+func (m *mockSQSClient) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+func (m *mockSQSClient) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.ReceiveMessageOutput), args.Error(1)
+}
+
+func (m *mockSQSClient) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.DeleteMessageOutput), args.Error(1)
+}
+
+func (m *mockSQSClient) PurgeQueue(ctx context.Context, params *sqs.PurgeQueueInput, optFns ...func(*sqs.Options)) (*sqs.PurgeQueueOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.PurgeQueueOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that the mock client implements the interface.
+var _ SQSClient = (*mockSQSClient)(nil)
+
+const testQueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/orders"
+
+// TestListQueues tests the ListQueues method of the SQS Adapter.
+// It verifies that queue URLs from all pages are returned with their names.
+func TestListQueues(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations for two pages
+	mockClient.On("ListQueues", mock.Anything, mock.MatchedBy(func(input *sqs.ListQueuesInput) bool {
+		return input.NextToken == nil
+	}), mock.Anything).Return(&sqs.ListQueuesOutput{
+		QueueUrls: []string{testQueueURL},
+		NextToken: aws.String("page-2"),
+	}, nil).Once()
+	mockClient.On("ListQueues", mock.Anything, mock.MatchedBy(func(input *sqs.ListQueuesInput) bool {
+		return aws.ToString(input.NextToken) == "page-2"
+	}), mock.Anything).Return(&sqs.ListQueuesOutput{
+		QueueUrls: []string{"https://sqs.us-east-1.amazonaws.com/123456789012/orders-dlq"},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	queues, err := adapter.ListQueues(ctx)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert queues
+	assert.Equal(t, []Queue{
+		{Name: "orders", URL: testQueueURL},
+		{Name: "orders-dlq", URL: "https://sqs.us-east-1.amazonaws.com/123456789012/orders-dlq"},
+	}, queues)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestSendMessage tests the SendMessage method of the SQS Adapter.
+// It verifies that the body is sent to the queue and the message ID returned.
+func TestSendMessage(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("SendMessage", mock.Anything, mock.MatchedBy(func(input *sqs.SendMessageInput) bool {
+		return aws.ToString(input.QueueUrl) == testQueueURL && aws.ToString(input.MessageBody) == `{"id":1}`
+	}), mock.Anything).Return(&sqs.SendMessageOutput{
+		MessageId: aws.String("msg-1"),
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	messageID, err := adapter.SendMessage(ctx, testQueueURL, `{"id":1}`)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert message ID
+	assert.Equal(t, "msg-1", messageID)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestSendMessageError tests the SendMessage method of the SQS Adapter when
+// the API call fails. It verifies that the error names the queue.
+func TestSendMessageError(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("SendMessage", mock.Anything, mock.Anything, mock.Anything).
+		Return((*sqs.SendMessageOutput)(nil), errors.New("AccessDenied"))
+
+	// Call the function
+	ctx := context.Background()
+	_, err := adapter.SendMessage(ctx, testQueueURL, "hello")
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "orders")
+}
+
+// TestReceiveMessages tests the ReceiveMessages method of the SQS Adapter.
+// It verifies that the message ID, body and receipt handle are returned and
+// that the number of messages is validated.
+func TestReceiveMessages(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("ReceiveMessage", mock.Anything, mock.MatchedBy(func(input *sqs.ReceiveMessageInput) bool {
+		return aws.ToString(input.QueueUrl) == testQueueURL && input.MaxNumberOfMessages == 2
+	}), mock.Anything).Return(&sqs.ReceiveMessageOutput{
+		Messages: []types.Message{
+			{MessageId: aws.String("msg-1"), Body: aws.String("first"), ReceiptHandle: aws.String("handle-1")},
+			{MessageId: aws.String("msg-2"), Body: aws.String("second"), ReceiptHandle: aws.String("handle-2")},
+		},
+	}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	messages, err := adapter.ReceiveMessages(ctx, testQueueURL, 2)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert messages
+	assert.Equal(t, []Message{
+		{ID: "msg-1", Body: "first", ReceiptHandle: "handle-1"},
+		{ID: "msg-2", Body: "second", ReceiptHandle: "handle-2"},
+	}, messages)
+
+	// Assert invalid numbers of messages are rejected without calling SQS
+	_, err = adapter.ReceiveMessages(ctx, testQueueURL, 0)
+	assert.Error(t, err)
+	_, err = adapter.ReceiveMessages(ctx, testQueueURL, MaxReceiveMessages+1)
+	assert.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "ReceiveMessage", 1)
+}
+
+// TestDeleteMessage tests the DeleteMessage method of the SQS Adapter.
+// It verifies that the receipt handle is passed to the API.
+func TestDeleteMessage(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DeleteMessage", mock.Anything, mock.MatchedBy(func(input *sqs.DeleteMessageInput) bool {
+		return aws.ToString(input.QueueUrl) == testQueueURL && aws.ToString(input.ReceiptHandle) == "handle-1"
+	}), mock.Anything).Return(&sqs.DeleteMessageOutput{}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.DeleteMessage(ctx, testQueueURL, "handle-1")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestPurgeQueue tests the PurgeQueue method of the SQS Adapter.
+// It verifies that the queue URL is passed to the API and errors are wrapped.
+func TestPurgeQueue(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSQSClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("PurgeQueue", mock.Anything, mock.MatchedBy(func(input *sqs.PurgeQueueInput) bool {
+		return aws.ToString(input.QueueUrl) == testQueueURL
+	}), mock.Anything).Return(&sqs.PurgeQueueOutput{}, nil).Once()
+	mockClient.On("PurgeQueue", mock.Anything, mock.Anything, mock.Anything).
+		Return((*sqs.PurgeQueueOutput)(nil), errors.New("PurgeQueueInProgress")).Once()
+
+	// Call the function
+	ctx := context.Background()
+	err := adapter.PurgeQueue(ctx, testQueueURL)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// A second purge within 60 seconds fails
+	err = adapter.PurgeQueue(ctx, testQueueURL)
+	assert.ErrorContains(t, err, "PurgeQueueInProgress")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestQueueName tests the QueueName function.
+func TestQueueName(t *testing.T) {
+	assert.Equal(t, "orders", QueueName(testQueueURL))
+	assert.Equal(t, "orders.fifo", QueueName("https://sqs.eu-west-1.amazonaws.com/123456789012/orders.fifo"))
+	assert.Equal(t, "orders", QueueName("orders"))
+}
@@ -0,0 +1,205 @@
+// Package ssm provides functionality for interacting with AWS Systems Manager
+// Parameter Store. It includes operations for listing, reading and writing parameters.
+package ssm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// Parameter types supported by Parameter Store
+const (
+	TypeString       = string(types.ParameterTypeString)
+	TypeStringList   = string(types.ParameterTypeStringList)
+	TypeSecureString = string(types.ParameterTypeSecureString)
+)
+
+// SSMClient defines the interface for SSM client operations.
+// This interface allows for easy mocking in tests.
+type SSMClient interface {
+	DescribeParameters(ctx context.Context, params *ssm.DescribeParametersInput, optFns ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error)
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// Adapter represents an SSM service adapter that provides
+// higher-level operations for interacting with Parameter Store.
+type Adapter struct {
+	client SSMClient // AWS SSM client implementation
+}
+
+// Parameter represents a Parameter Store parameter with relevant information.
+// This is a simplified representation of the AWS parameter type
+// that includes only the most commonly used fields.
+type Parameter struct {
+	Name         string // Name of the parameter, including its path
+	Type         string // String, StringList or SecureString
+	Value        string // Parameter value (empty when listing; encrypted for SecureString unless decrypted)
+	Version      int64  // Version number, incremented on every change
+	LastModified string // When the parameter was last changed (formatted)
+}
+
+// NewAdapter creates a new SSM adapter using the AWS credentials
+// from the current context configuration.
+//
+// The context is used for AWS client creation and configuration.
+// Returns an error if the AWS client cannot be created.
+func NewAdapter(ctx context.Context) (*Adapter, error) {
+	// Create AWS client
+	awsClient, err := client.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Create SSM client
+	ssmClient := ssm.NewFromConfig(awsClient.Config)
+
+	return &Adapter{
+		client: ssmClient,
+	}, nil
+}
+
+// NewAdapterWithClient creates a new SSM adapter with a provided client.
+// This is particularly useful for testing with mock clients.
+func NewAdapterWithClient(ssmClient SSMClient) *Adapter {
+	return &Adapter{
+		client: ssmClient,
+	}
+}
+
+// ListParameters lists parameters, without their values.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - pathPrefix: Only list parameters under this path, recursively
+//     (e.g. /prod/app); empty or "/" for all parameters
+//
+// Returns a slice of Parameter structs and an error if the operation fails.
+func (a *Adapter) ListParameters(ctx context.Context, pathPrefix string) ([]Parameter, error) {
+	// Create the input for the DescribeParameters API
+	input := &ssm.DescribeParametersInput{}
+
+	// Filter by path, including nested paths
+	if pathPrefix != "" && pathPrefix != "/" {
+		input.ParameterFilters = []types.ParameterStringFilter{
+			{
+				Key:    aws.String("Path"),
+				Option: aws.String("Recursive"),
+				Values: []string{pathPrefix},
+			},
+		}
+	}
+
+	// Create paginator
+	paginator := ssm.NewDescribeParametersPaginator(a.client, input)
+
+	var parameters []Parameter
+
+	// Iterate through pages
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SSM parameters: %w", err)
+		}
+
+		for _, metadata := range output.Parameters {
+			parameter := Parameter{
+				Name:    aws.ToString(metadata.Name),
+				Type:    string(metadata.Type),
+				Version: metadata.Version,
+			}
+			if metadata.LastModifiedDate != nil {
+				parameter.LastModified = metadata.LastModifiedDate.Format("2006-01-02 15:04:05")
+			}
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	return parameters, nil
+}
+
+// GetParameter gets a parameter with its value.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - name: The name of the parameter
+//   - withDecryption: Whether to decrypt the value of a SecureString parameter
+//
+// Returns a pointer to a Parameter struct or an error if the parameter cannot
+// be found or read.
+func (a *Adapter) GetParameter(ctx context.Context, name string, withDecryption bool) (*Parameter, error) {
+	// Create the input for the GetParameter API
+	input := &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(withDecryption),
+	}
+
+	// Call the GetParameter API
+	output, err := a.client.GetParameter(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSM parameter %s: %w", name, err)
+	}
+	if output.Parameter == nil {
+		return nil, fmt.Errorf("SSM parameter %s not found", name)
+	}
+
+	parameter := &Parameter{
+		Name:    aws.ToString(output.Parameter.Name),
+		Type:    string(output.Parameter.Type),
+		Value:   aws.ToString(output.Parameter.Value),
+		Version: output.Parameter.Version,
+	}
+	if output.Parameter.LastModifiedDate != nil {
+		parameter.LastModified = output.Parameter.LastModifiedDate.Format("2006-01-02 15:04:05")
+	}
+
+	return parameter, nil
+}
+
+// PutParameter creates or updates a parameter.
+//
+// Parameters:
+//   - ctx: Context for the API call
+//   - name: The name of the parameter
+//   - value: The value to store
+//   - paramType: The parameter type (TypeString, TypeStringList or TypeSecureString)
+//   - overwrite: Whether to replace the value of an existing parameter
+//
+// Returns the new version of the parameter and an error if the type is not
+// valid, the parameter exists and overwrite is false, or the call fails.
+func (a *Adapter) PutParameter(ctx context.Context, name, value, paramType string, overwrite bool) (int64, error) {
+	if !IsValidParameterType(paramType) {
+		return 0, fmt.Errorf("invalid parameter type: %s (must be %s, %s or %s)", paramType, TypeString, TypeStringList, TypeSecureString)
+	}
+
+	// Create the input for the PutParameter API
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Value:     aws.String(value),
+		Type:      types.ParameterType(paramType),
+		Overwrite: aws.Bool(overwrite),
+	}
+
+	// Call the PutParameter API
+	output, err := a.client.PutParameter(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to put SSM parameter %s: %w", name, err)
+	}
+
+	return output.Version, nil
+}
+
+// IsValidParameterType reports whether paramType is a Parameter Store parameter type
+func IsValidParameterType(paramType string) bool {
+	switch paramType {
+	case TypeString, TypeStringList, TypeSecureString:
+		return true
+	default:
+		return false
+	}
+}
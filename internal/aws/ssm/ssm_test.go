@@ -0,0 +1,241 @@
+// Package ssm provides tests for the SSM adapter functionality.
+package ssm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockSSMClient implements the SSMClient interface for testing purposes.
+// It uses the testify/mock package to mock AWS SSM API calls.
+type mockSSMClient struct {
+	mock.Mock
+}
+
+func (m *mockSSMClient) DescribeParameters(ctx context.Context, params *ssm.DescribeParametersInput, optFns ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ssm.DescribeParametersOutput), args.Error(1)
+}
+
+func (m *mockSSMClient) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ssm.GetParameterOutput), args.Error(1)
+}
+
+func (m *mockSSMClient) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ssm.PutParameterOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that the mock client implements the interface.
+var _ SSMClient = (*mockSSMClient)(nil)
+
+// TestListParameters tests the ListParameters method of the SSM Adapter.
+// It verifies that the path is passed as a recursive filter and that
+// parameters from all pages are returned.
+func TestListParameters(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSSMClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	modified := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// Set up expectations for two pages
+	isPathFilter := func(input *ssm.DescribeParametersInput) bool {
+		return len(input.ParameterFilters) == 1 &&
+			aws.ToString(input.ParameterFilters[0].Key) == "Path" &&
+			aws.ToString(input.ParameterFilters[0].Option) == "Recursive" &&
+			input.ParameterFilters[0].Values[0] == "/prod/app"
+	}
+	mockClient.On("DescribeParameters", mock.Anything, mock.MatchedBy(func(input *ssm.DescribeParametersInput) bool {
+		return isPathFilter(input) && input.NextToken == nil
+	}), mock.Anything).Return(&ssm.DescribeParametersOutput{
+		Parameters: []types.ParameterMetadata{
+			{Name: aws.String("/prod/app/db-url"), Type: types.ParameterTypeString, Version: 3, LastModifiedDate: &modified},
+		},
+		NextToken: aws.String("page-2"),
+	}, nil).Once()
+	mockClient.On("DescribeParameters", mock.Anything, mock.MatchedBy(func(input *ssm.DescribeParametersInput) bool {
+		return isPathFilter(input) && aws.ToString(input.NextToken) == "page-2"
+	}), mock.Anything).Return(&ssm.DescribeParametersOutput{
+		Parameters: []types.ParameterMetadata{
+			{Name: aws.String("/prod/app/db-password"), Type: types.ParameterTypeSecureString, Version: 1},
+		},
+	}, nil).Once()
+
+	// Call the function
+	ctx := context.Background()
+	parameters, err := adapter.ListParameters(ctx, "/prod/app")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert parameters
+	assert.Equal(t, []Parameter{
+		{Name: "/prod/app/db-url", Type: "String", Version: 3, LastModified: "2024-06-01 09:00:00"},
+		{Name: "/prod/app/db-password", Type: "SecureString", Version: 1},
+	}, parameters)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestListParametersAll tests the ListParameters method of the SSM Adapter
+// without a path. It verifies that no filter is sent.
+func TestListParametersAll(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSSMClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("DescribeParameters", mock.Anything, mock.MatchedBy(func(input *ssm.DescribeParametersInput) bool {
+		return len(input.ParameterFilters) == 0
+	}), mock.Anything).Return(&ssm.DescribeParametersOutput{}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	_, err := adapter.ListParameters(ctx, "/")
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestGetParameterWithDecryption tests the GetParameter method of the SSM Adapter.
+// It verifies that the withDecryption argument is forwarded as WithDecryption.
+func TestGetParameterWithDecryption(t *testing.T) {
+	for _, decrypt := range []bool{false, true} {
+		// Create mock client
+		mockClient := new(mockSSMClient)
+
+		// Create adapter with mock client
+		adapter := NewAdapterWithClient(mockClient)
+
+		// Set up expectations
+		mockClient.On("GetParameter", mock.Anything, mock.MatchedBy(func(input *ssm.GetParameterInput) bool {
+			return aws.ToString(input.Name) == "/prod/app/db-password" &&
+				input.WithDecryption != nil && *input.WithDecryption == decrypt
+		}), mock.Anything).Return(&ssm.GetParameterOutput{
+			Parameter: &types.Parameter{
+				Name:    aws.String("/prod/app/db-password"),
+				Type:    types.ParameterTypeSecureString,
+				Value:   aws.String("hunter2"),
+				Version: 2,
+			},
+		}, nil)
+
+		// Call the function
+		ctx := context.Background()
+		parameter, err := adapter.GetParameter(ctx, "/prod/app/db-password", decrypt)
+
+		// Assert no error
+		assert.NoError(t, err)
+
+		// Assert parameter
+		assert.Equal(t, &Parameter{Name: "/prod/app/db-password", Type: "SecureString", Value: "hunter2", Version: 2}, parameter)
+
+		// Verify expectations
+		mockClient.AssertExpectations(t)
+	}
+}
+
+// TestGetParameterError tests the GetParameter method of the SSM Adapter when
+// the API call fails. It verifies that the error names the parameter.
+func TestGetParameterError(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSSMClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("GetParameter", mock.Anything, mock.Anything, mock.Anything).
+		Return((*ssm.GetParameterOutput)(nil), errors.New("ParameterNotFound"))
+
+	// Call the function
+	ctx := context.Background()
+	parameter, err := adapter.GetParameter(ctx, "/missing", false)
+
+	// Assert error
+	assert.Error(t, err)
+	assert.Nil(t, parameter)
+	assert.Contains(t, err.Error(), "/missing")
+}
+
+// TestPutParameter tests the PutParameter method of the SSM Adapter.
+// It verifies that the type and overwrite setting are forwarded, the new
+// version is returned, and invalid types are rejected without calling SSM.
+func TestPutParameter(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSSMClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	mockClient.On("PutParameter", mock.Anything, mock.MatchedBy(func(input *ssm.PutParameterInput) bool {
+		return aws.ToString(input.Name) == "/prod/app/api-key" &&
+			aws.ToString(input.Value) == "s3cret" &&
+			input.Type == types.ParameterTypeSecureString &&
+			aws.ToBool(input.Overwrite)
+	}), mock.Anything).Return(&ssm.PutParameterOutput{Version: 4}, nil)
+
+	// Call the function
+	ctx := context.Background()
+	version, err := adapter.PutParameter(ctx, "/prod/app/api-key", "s3cret", TypeSecureString, true)
+
+	// Assert no error
+	assert.NoError(t, err)
+
+	// Assert version
+	assert.Equal(t, int64(4), version)
+
+	// Assert invalid types are rejected
+	_, err = adapter.PutParameter(ctx, "/prod/app/api-key", "s3cret", "Secret", true)
+	assert.ErrorContains(t, err, "invalid parameter type")
+	mockClient.AssertNumberOfCalls(t, "PutParameter", 1)
+}
+
+// TestPutParameterWithoutOverwrite tests the PutParameter method of the SSM
+// Adapter when overwrite is false. It verifies that Overwrite is sent as false,
+// so an existing parameter is left unchanged, and that the resulting
+// ParameterAlreadyExists error is returned with the parameter name.
+func TestPutParameterWithoutOverwrite(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockSSMClient)
+
+	// Create adapter with mock client
+	adapter := NewAdapterWithClient(mockClient)
+
+	// Set up expectations
+	existsErr := &types.ParameterAlreadyExists{Message: aws.String("The parameter already exists.")}
+	mockClient.On("PutParameter", mock.Anything, mock.MatchedBy(func(input *ssm.PutParameterInput) bool {
+		return aws.ToString(input.Name) == "/prod/app/log-level" &&
+			input.Overwrite != nil && !*input.Overwrite
+	}), mock.Anything).Return((*ssm.PutParameterOutput)(nil), existsErr)
+
+	// Call the function
+	ctx := context.Background()
+	_, err := adapter.PutParameter(ctx, "/prod/app/log-level", "debug", TypeString, false)
+
+	// Assert the error
+	assert.ErrorIs(t, err, existsErr)
+	assert.Contains(t, err.Error(), "/prod/app/log-level")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
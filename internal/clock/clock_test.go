@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFake tests the fake clock.
+// It verifies that its time only changes when it is advanced or set.
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	// The time doesn't pass by itself
+	assert.Equal(t, start, c.Now())
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
+
+// TestReal tests the system clock.
+// It verifies that it tells the current time.
+func TestReal(t *testing.T) {
+	before := time.Now()
+	now := Real.Now()
+	assert.False(t, now.Before(before))
+	assert.False(t, now.After(time.Now()))
+}
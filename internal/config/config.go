@@ -2,7 +2,8 @@
 //
 // It handles loading, saving, and accessing configuration values such as AWS profiles,
 // regions, output formats, and contexts. The configuration is stored in a YAML file
-// in the user's home directory.
+// in the user's home directory. A project can pin its own context, profile, region,
+// and output format with a local configuration file, see FindLocalConfig.
 package config
 
 import (
@@ -11,7 +12,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -21,9 +25,12 @@ import (
 type Config struct {
 	// AWS specific configuration
 	AWS struct {
-		Profile string
-		Region  string
-		Role    string
+		Profile    string
+		Region     string
+		Role       string
+		MaxRetries int           // Maximum number of retries for AWS API calls
+		RetryMode  string        // standard, adaptive
+		Timeout    time.Duration // Timeout for AWS operations
 	}
 
 	// Output configuration
@@ -33,7 +40,10 @@ type Config struct {
 
 	// Application configuration
 	App struct {
-		Mode string // cli, tui
+		Mode            string        // cli, tui
+		AltScreen       bool          // Whether the TUI uses the terminal's alternate screen
+		Theme           string        // TUI color theme (default, dark, light, high-contrast, solarized or nocolor)
+		RefreshInterval time.Duration // How often the TUI reloads the current view with auto-refresh on
 	}
 
 	// Context configuration
@@ -42,6 +52,12 @@ type Config struct {
 	// Current context name
 	CurrentContext string
 
+	// Whether commands refuse to run until a context other than the default is chosen
+	RequireContext bool
+
+	// Whether mutating commands wait for their changes to complete unless --no-wait is passed
+	Wait bool
+
 	// Recent profiles and regions
 	Recent struct {
 		Profiles []string
@@ -55,24 +71,55 @@ type Config struct {
 	}
 }
 
+// Settings represents the effective configuration values, as shown by `config list`.
+type Settings struct {
+	Context         string `json:"context" yaml:"context"`
+	Profile         string `json:"profile" yaml:"profile"`
+	Region          string `json:"region" yaml:"region"`
+	Output          string `json:"output" yaml:"output"`
+	Mode            string `json:"mode" yaml:"mode"`
+	AltScreen       bool   `json:"alt-screen" yaml:"alt-screen"`
+	Theme           string `json:"theme" yaml:"theme"`
+	RefreshInterval string `json:"refresh-interval" yaml:"refresh-interval"`
+	RequireContext  bool   `json:"require-context" yaml:"require-context"`
+	Wait            bool   `json:"wait" yaml:"wait"`
+	MaxRetries      int    `json:"max-retries" yaml:"max-retries"`
+	RetryMode       string `json:"retry-mode" yaml:"retry-mode"`
+	Timeout         string `json:"timeout" yaml:"timeout"`
+	LocalFile       string `json:"local-file,omitempty" yaml:"local-file,omitempty"`
+}
+
 // Context represents an AWS context (profile + region + optional role)
 type Context struct {
 	Profile string
 	Region  string
 	Role    string
+	Output  string // Output format while the context is current (empty for the global format)
 }
 
+// Retry modes supported for AWS API calls
+const (
+	RetryModeStandard = "standard" // Standard retry mode with exponential backoff
+	RetryModeAdaptive = "adaptive" // Standard retry mode with client-side rate limiting
+)
+
 var (
 	// DefaultConfig holds the default configuration values
 	DefaultConfig = Config{
 		AWS: struct {
-			Profile string
-			Region  string
-			Role    string
+			Profile    string
+			Region     string
+			Role       string
+			MaxRetries int
+			RetryMode  string
+			Timeout    time.Duration
 		}{
-			Profile: "default",
-			Region:  "us-east-1",
-			Role:    "",
+			Profile:    "default",
+			Region:     "us-east-1",
+			Role:       "",
+			MaxRetries: 2,
+			RetryMode:  RetryModeStandard,
+			Timeout:    30 * time.Second,
 		},
 		Output: struct {
 			Format string
@@ -80,9 +127,15 @@ var (
 			Format: "table",
 		},
 		App: struct {
-			Mode string
+			Mode            string
+			AltScreen       bool
+			Theme           string
+			RefreshInterval time.Duration
 		}{
-			Mode: "cli",
+			Mode:            "cli",
+			AltScreen:       true,
+			Theme:           "default",
+			RefreshInterval: 30 * time.Second,
 		},
 		Contexts: map[string]Context{
 			"default": {
@@ -116,8 +169,14 @@ var (
 
 	// GlobalConfig holds the global configuration instance
 	GlobalConfig Config
+
+	// localConfigPath is the project-local configuration file applied by Initialize, if any
+	localConfigPath string
 )
 
+// localConfigKeys are the settings a project-local configuration file can override
+var localConfigKeys = []string{"currentContext", "aws.profile", "aws.region", "aws.role", "output.format"}
+
 // Initialize initializes the configuration system by loading the configuration file
 // and setting default values. If the configuration file doesn't exist, it creates
 // a new one with default values.
@@ -130,43 +189,51 @@ func Initialize() error {
 		return fmt.Errorf("error finding home directory: %w", err)
 	}
 
+	// Start from a clean state so values from a previous Initialize don't leak
+	viper.Reset()
+	GlobalConfig = Config{}
+
 	// Set default configuration values
 	viper.SetDefault("aws.profile", DefaultConfig.AWS.Profile)
 	viper.SetDefault("aws.region", DefaultConfig.AWS.Region)
 	viper.SetDefault("aws.role", DefaultConfig.AWS.Role)
+	viper.SetDefault("aws.maxRetries", DefaultConfig.AWS.MaxRetries)
+	viper.SetDefault("aws.retryMode", DefaultConfig.AWS.RetryMode)
+	viper.SetDefault("aws.timeout", DefaultConfig.AWS.Timeout.String())
 	viper.SetDefault("output.format", DefaultConfig.Output.Format)
 	viper.SetDefault("app.mode", DefaultConfig.App.Mode)
+	viper.SetDefault("app.altScreen", DefaultConfig.App.AltScreen)
+	viper.SetDefault("app.theme", DefaultConfig.App.Theme)
+	viper.SetDefault("app.refreshInterval", DefaultConfig.App.RefreshInterval.String())
 	viper.SetDefault("contexts", DefaultConfig.Contexts)
 	viper.SetDefault("currentContext", DefaultConfig.CurrentContext)
+	viper.SetDefault("requireContext", DefaultConfig.RequireContext)
+	viper.SetDefault("wait", DefaultConfig.Wait)
 	viper.SetDefault("recent.profiles", DefaultConfig.Recent.Profiles)
 	viper.SetDefault("recent.regions", DefaultConfig.Recent.Regions)
 	viper.SetDefault("favorites.profiles", DefaultConfig.Favorites.Profiles)
 	viper.SetDefault("favorites.regions", DefaultConfig.Favorites.Regions)
 
-	// Set configuration file name and type
-	viper.SetConfigName(ConfigFile)
+	// The home configuration file is always the one that is read and saved;
+	// a project-local file only overrides values for the current invocation
+	configPath := GetConfigPath(home)
+	viper.SetConfigFile(configPath)
 	viper.SetConfigType(ConfigType)
 
-	// Add configuration search paths
-	viper.AddConfigPath(home)
-	viper.AddConfigPath(".")
-
 	// Read environment variables
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("AWSM")
 
 	// Read configuration file
-	if err := viper.ReadInConfig(); err != nil {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// If the configuration file doesn't exist, create it with default values
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			configPath := filepath.Join(home, ConfigFile+"."+ConfigType)
-			if err := viper.SafeWriteConfigAs(configPath); err != nil {
-				return fmt.Errorf("error creating default configuration file: %w", err)
-			}
-			fmt.Printf("Created default configuration file at %s\n", configPath)
-		} else {
-			return fmt.Errorf("error reading configuration file: %w", err)
+		if err := viper.SafeWriteConfigAs(configPath); err != nil {
+			return fmt.Errorf("error creating default configuration file: %w", err)
 		}
+		// On stderr, so that output that is evaluated, such as shell-init's, stays valid
+		fmt.Fprintf(os.Stderr, "Created default configuration file at %s\n", configPath)
+	} else if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading configuration file: %w", err)
 	}
 
 	// Unmarshal configuration into GlobalConfig
@@ -174,6 +241,117 @@ func Initialize() error {
 		return fmt.Errorf("error unmarshaling configuration: %w", err)
 	}
 
+	// Use the output format of the current context, if it has one
+	if context, exists := GlobalConfig.Contexts[GlobalConfig.CurrentContext]; exists {
+		GlobalConfig.Output.Format = contextOutputFormat(context)
+	}
+
+	// Apply the project-local configuration file, if there is one
+	localConfigPath = ""
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error finding current directory: %w", err)
+	}
+	if path := FindLocalConfig(cwd, home, configPath); path != "" {
+		if err := applyLocalConfig(path); err != nil {
+			return err
+		}
+		localConfigPath = path
+	}
+
+	return nil
+}
+
+// GetConfigPath returns the path of the home configuration file. ConfigFile is
+// relative to the home directory unless it is an absolute path.
+func GetConfigPath(home string) string {
+	if filepath.IsAbs(ConfigFile) {
+		return ConfigFile + "." + ConfigType
+	}
+	return filepath.Join(home, ConfigFile+"."+ConfigType)
+}
+
+// FindLocalConfig looks for a project-local configuration file (.awsm.yaml) in
+// dir and its parent directories, like direnv or tfenv do. The search stops
+// before stopDir (normally the home directory), so files in the home directory
+// or above it are never used, and at the root for directories outside stopDir.
+// The home configuration file is never treated as local.
+//
+// Returns the path of the local configuration file, or "" if there is none.
+func FindLocalConfig(dir, stopDir, homeConfigPath string) string {
+	name := filepath.Base(ConfigFile) + "." + ConfigType
+	dir = filepath.Clean(dir)
+	stopDir = filepath.Clean(stopDir)
+
+	for {
+		if dir == stopDir {
+			return ""
+		}
+
+		path := filepath.Join(dir, name)
+		if path != homeConfigPath {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// GetLocalConfigPath returns the project-local configuration file applied by
+// Initialize, or "" if none was found.
+func GetLocalConfigPath() string {
+	return localConfigPath
+}
+
+// applyLocalConfig overrides GlobalConfig with the values set in a project-local
+// configuration file. The values are not written to viper, so saving the
+// configuration never copies them into the home configuration file.
+//
+// A pinned context is applied first, so aws.profile, aws.region, and aws.role in
+// the same file take precedence over the context's values.
+// Returns an error if the file cannot be read or names an unknown context.
+func applyLocalConfig(path string) error {
+	local := viper.New()
+	local.SetConfigFile(path)
+	local.SetConfigType(ConfigType)
+	if err := local.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading local configuration file %s: %w", path, err)
+	}
+
+	for _, key := range localConfigKeys {
+		if !local.IsSet(key) {
+			continue
+		}
+		value := local.GetString(key)
+
+		switch key {
+		case "currentContext":
+			context, exists := GlobalConfig.Contexts[value]
+			if !exists {
+				return fmt.Errorf("local configuration file %s: context %s does not exist", path, value)
+			}
+			GlobalConfig.CurrentContext = value
+			GlobalConfig.AWS.Profile = context.Profile
+			GlobalConfig.AWS.Region = context.Region
+			GlobalConfig.AWS.Role = context.Role
+			GlobalConfig.Output.Format = contextOutputFormat(context)
+		case "aws.profile":
+			GlobalConfig.AWS.Profile = value
+		case "aws.region":
+			GlobalConfig.AWS.Region = value
+		case "aws.role":
+			GlobalConfig.AWS.Role = value
+		case "output.format":
+			GlobalConfig.Output.Format = value
+		}
+	}
+
 	return nil
 }
 
@@ -191,9 +369,21 @@ func GetAWSProfile() string {
 
 // SetAWSProfile sets the AWS profile to use for AWS API calls.
 //
-// The profile must exist in the AWS credentials file.
-// Returns an error if the configuration cannot be saved.
+// The profile must exist in the AWS credentials or config file.
+// Returns an error listing close matches if it doesn't, or if the configuration
+// cannot be saved.
 func SetAWSProfile(profile string) error {
+	if err := ValidateAWSProfile(profile); err != nil {
+		return err
+	}
+	return SetAWSProfileUnchecked(profile)
+}
+
+// SetAWSProfileUnchecked sets the AWS profile to use for AWS API calls without
+// checking that it exists, e.g. for a profile only known to the environment.
+//
+// Returns an error if the configuration cannot be saved.
+func SetAWSProfileUnchecked(profile string) error {
 	GlobalConfig.AWS.Profile = profile
 	viper.Set("aws.profile", profile)
 	return Save()
@@ -213,21 +403,173 @@ func SetAWSRegion(region string) error {
 	return Save()
 }
 
+// GetMaxRetries returns the maximum number of retries for AWS API calls.
+func GetMaxRetries() int {
+	return GlobalConfig.AWS.MaxRetries
+}
+
+// SetMaxRetries sets the maximum number of retries for AWS API calls.
+//
+// Returns an error if the value is negative or if the configuration cannot be saved.
+func SetMaxRetries(maxRetries int) error {
+	if maxRetries < 0 {
+		return fmt.Errorf("max retries cannot be negative: %d", maxRetries)
+	}
+
+	GlobalConfig.AWS.MaxRetries = maxRetries
+	viper.Set("aws.maxRetries", maxRetries)
+	return Save()
+}
+
+// GetAWSTimeout returns the timeout for AWS operations.
+// The default timeout is returned if none is configured.
+func GetAWSTimeout() time.Duration {
+	if GlobalConfig.AWS.Timeout <= 0 {
+		return DefaultConfig.AWS.Timeout
+	}
+	return GlobalConfig.AWS.Timeout
+}
+
+// SetAWSTimeout sets the timeout for AWS operations.
+//
+// Returns an error if the timeout is not positive or if the configuration cannot be saved.
+func SetAWSTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("timeout must be positive: %s", timeout)
+	}
+
+	GlobalConfig.AWS.Timeout = timeout
+	viper.Set("aws.timeout", timeout.String())
+	return Save()
+}
+
+// GetRetryMode returns the retry mode for AWS API calls (standard or adaptive).
+func GetRetryMode() string {
+	return GlobalConfig.AWS.RetryMode
+}
+
+// SetRetryMode sets the retry mode for AWS API calls.
+//
+// Valid modes are standard and adaptive.
+// Returns an error if the mode is invalid or if the configuration cannot be saved.
+func SetRetryMode(mode string) error {
+	if !IsValidRetryMode(mode) {
+		return fmt.Errorf("invalid retry mode: %s (must be '%s' or '%s')", mode, RetryModeStandard, RetryModeAdaptive)
+	}
+
+	GlobalConfig.AWS.RetryMode = mode
+	viper.Set("aws.retryMode", mode)
+	return Save()
+}
+
+// IsValidRetryMode checks if the given retry mode is supported.
+func IsValidRetryMode(mode string) bool {
+	return mode == RetryModeStandard || mode == RetryModeAdaptive
+}
+
+// EnvOnlyEnvVar is the environment variable that enables env-only credentials
+// when set to a true value (e.g. AWSM_ENV_ONLY=1).
+const EnvOnlyEnvVar = "AWSM_ENV_ONLY"
+
+// envOnly is set by --no-config-credentials for the current invocation
+var envOnly bool
+
+// SetEnvOnly enables or disables env-only credentials for the current invocation.
+// The setting is not saved to the configuration file.
+func SetEnvOnly(enabled bool) {
+	envOnly = enabled
+}
+
+// IsEnvOnly reports whether AWS clients must only use credentials from the
+// environment and ignore the ~/.aws files and the configured profile.
+// It is enabled by SetEnvOnly or by setting AWSM_ENV_ONLY to a true value.
+func IsEnvOnly() bool {
+	if envOnly {
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(os.Getenv(EnvOnlyEnvVar))
+	return err == nil && enabled
+}
+
+// AWSOnlyNetworkEnvVar is the environment variable that restricts network
+// connections to AWS endpoints when set to a true value (e.g. AWSM_NO_NETWORK_EXCEPT_AWS=1).
+const AWSOnlyNetworkEnvVar = "AWSM_NO_NETWORK_EXCEPT_AWS"
+
+// awsOnlyNetwork is set by --no-network-except-aws for the current invocation
+var awsOnlyNetwork bool
+
+// SetAWSOnlyNetwork enables or disables the restriction of network connections to
+// AWS endpoints for the current invocation. The setting is not saved to the
+// configuration file.
+func SetAWSOnlyNetwork(enabled bool) {
+	awsOnlyNetwork = enabled
+}
+
+// IsAWSOnlyNetwork reports whether AWS clients must refuse to connect to any host
+// that is not an AWS endpoint. It is enabled by SetAWSOnlyNetwork or by setting
+// AWSM_NO_NETWORK_EXCEPT_AWS to a true value.
+func IsAWSOnlyNetwork() bool {
+	if awsOnlyNetwork {
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(os.Getenv(AWSOnlyNetworkEnvVar))
+	return err == nil && enabled
+}
+
 // GetOutputFormat returns the currently configured output format (json, yaml, table, etc.).
 func GetOutputFormat() string {
 	return GlobalConfig.Output.Format
 }
 
-// SetOutputFormat sets the output format for command results.
+// SetOutputFormat sets the global output format for command results. The current
+// context keeps using its own output format, if it has one.
 //
-// Valid formats are json, yaml, table, and text.
+// Valid formats are json, jsonl, yaml, table, wide, text, and csv.
 // Returns an error if the configuration cannot be saved.
 func SetOutputFormat(format string) error {
-	GlobalConfig.Output.Format = format
 	viper.Set("output.format", format)
+	if context, exists := GlobalConfig.Contexts[GlobalConfig.CurrentContext]; !exists || context.Output == "" {
+		GlobalConfig.Output.Format = format
+	}
 	return Save()
 }
 
+// SetContextOutputFormat sets the output format of a context, which is used instead
+// of the global output format while the context is current. An empty format makes
+// the context use the global output format again.
+//
+// Returns an error if the context doesn't exist or if the configuration cannot be saved.
+func SetContextOutputFormat(name, format string) error {
+	// Check if context exists
+	context, exists := GlobalConfig.Contexts[name]
+	if !exists {
+		return fmt.Errorf("context %s does not exist", name)
+	}
+
+	// Update the context
+	context.Output = format
+	GlobalConfig.Contexts[name] = context
+	viper.Set("contexts", GlobalConfig.Contexts)
+
+	// If this is the current context, apply the format now
+	if GlobalConfig.CurrentContext == name {
+		GlobalConfig.Output.Format = contextOutputFormat(context)
+	}
+
+	return Save()
+}
+
+// contextOutputFormat returns the output format to use while a context is current:
+// the context's own format, or the global output format if it has none.
+func contextOutputFormat(context Context) string {
+	if context.Output != "" {
+		return context.Output
+	}
+	return viper.GetString("output.format")
+}
+
 // GetAppMode returns the currently configured application mode (cli or tui).
 func GetAppMode() string {
 	return GlobalConfig.App.Mode
@@ -242,10 +584,120 @@ func SetAppMode(mode string) error {
 	return Save()
 }
 
-// GetAWSCredentialsPath returns the path to the AWS credentials file.
+// GetAltScreen returns whether the TUI uses the terminal's alternate screen.
+// When disabled, the TUI runs inline and its final view remains in the scrollback.
+func GetAltScreen() bool {
+	return GlobalConfig.App.AltScreen
+}
+
+// SetAltScreen sets whether the TUI uses the terminal's alternate screen.
+//
+// Returns an error if the configuration cannot be saved.
+func SetAltScreen(enabled bool) error {
+	GlobalConfig.App.AltScreen = enabled
+	viper.Set("app.altScreen", enabled)
+	return Save()
+}
+
+// GetTheme returns the name of the TUI color theme.
+func GetTheme() string {
+	return GlobalConfig.App.Theme
+}
+
+// SetTheme sets the name of the TUI color theme.
+//
+// Returns an error if the configuration cannot be saved.
+func SetTheme(name string) error {
+	GlobalConfig.App.Theme = name
+	viper.Set("app.theme", name)
+	return Save()
+}
+
+// GetRefreshInterval returns how often the TUI reloads the current view while
+// auto-refresh is on.
+func GetRefreshInterval() time.Duration {
+	if GlobalConfig.App.RefreshInterval <= 0 {
+		return DefaultConfig.App.RefreshInterval
+	}
+	return GlobalConfig.App.RefreshInterval
+}
+
+// SetRefreshInterval sets how often the TUI reloads the current view while
+// auto-refresh is on.
+//
+// Returns an error if the interval is shorter than a second or if the
+// configuration cannot be saved.
+func SetRefreshInterval(interval time.Duration) error {
+	if interval < time.Second {
+		return fmt.Errorf("refresh interval must be at least 1s: %s", interval)
+	}
+
+	GlobalConfig.App.RefreshInterval = interval
+	viper.Set("app.refreshInterval", interval.String())
+	return Save()
+}
+
+// GetRequireContext returns whether commands that use AWS refuse to run while
+// the current context is still the default one.
+func GetRequireContext() bool {
+	return GlobalConfig.RequireContext
+}
+
+// SetRequireContext sets whether commands that use AWS refuse to run while the
+// current context is still the default one.
+//
+// Returns an error if the configuration cannot be saved.
+func SetRequireContext(enabled bool) error {
+	GlobalConfig.RequireContext = enabled
+	viper.Set("requireContext", enabled)
+	return Save()
+}
+
+// GetWait returns whether mutating commands wait for their changes to complete
+// unless --no-wait is passed.
+func GetWait() bool {
+	return GlobalConfig.Wait
+}
+
+// SetWait sets whether mutating commands wait for their changes to complete
+// unless --no-wait is passed.
+//
+// Returns an error if the configuration cannot be saved.
+func SetWait(enabled bool) error {
+	GlobalConfig.Wait = enabled
+	viper.Set("wait", enabled)
+	return Save()
+}
+
+// GetSettings returns the effective configuration values.
+func GetSettings() Settings {
+	return Settings{
+		Context:         GetCurrentContext(),
+		Profile:         GetAWSProfile(),
+		Region:          GetAWSRegion(),
+		Output:          GetOutputFormat(),
+		Mode:            GetAppMode(),
+		AltScreen:       GetAltScreen(),
+		Theme:           GetTheme(),
+		RefreshInterval: GetRefreshInterval().String(),
+		RequireContext:  GetRequireContext(),
+		Wait:            GetWait(),
+		MaxRetries:      GetMaxRetries(),
+		RetryMode:       GetRetryMode(),
+		Timeout:         GetAWSTimeout().String(),
+		LocalFile:       GetLocalConfigPath(),
+	}
+}
+
+// GetAWSCredentialsPath returns the path to the AWS credentials file, which
+// AWS_SHARED_CREDENTIALS_FILE overrides like it does for the AWS CLI.
 //
 // Returns an error if the home directory cannot be determined.
 func GetAWSCredentialsPath() (string, error) {
+	if path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); path != "" {
+		return path, nil
+	}
+
 	home, err := homedir.Dir()
 	if err != nil {
 		return "", fmt.Errorf("error finding home directory: %w", err)
@@ -254,10 +706,15 @@ func GetAWSCredentialsPath() (string, error) {
 	return filepath.Join(home, ".aws", "credentials"), nil
 }
 
-// GetAWSConfigPath returns the path to the AWS config file.
+// GetAWSConfigPath returns the path to the AWS config file, which
+// AWS_CONFIG_FILE overrides like it does for the AWS CLI.
 //
 // Returns an error if the home directory cannot be determined.
 func GetAWSConfigPath() (string, error) {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+
 	home, err := homedir.Dir()
 	if err != nil {
 		return "", fmt.Errorf("error finding home directory: %w", err)
@@ -308,7 +765,8 @@ func GetCurrentContext() string {
 }
 
 // SetCurrentContext sets the current context and updates AWS profile, region, and role
-// based on the context's configuration.
+// based on the context's configuration. If the context has a role, it is checked
+// with CheckContextRole and a warning is given if it cannot be assumed.
 //
 // Returns an error if the context doesn't exist or if the configuration cannot be saved.
 func SetCurrentContext(contextName string) error {
@@ -330,11 +788,24 @@ func SetCurrentContext(contextName string) error {
 	GlobalConfig.AWS.Role = context.Role
 	viper.Set("aws.role", context.Role)
 
+	// Use the context's output format, falling back to the global one
+	GlobalConfig.Output.Format = contextOutputFormat(context)
+
 	// Add to recent profiles and regions
 	addToRecent("profiles", context.Profile)
 	addToRecent("regions", context.Region)
 
-	return Save()
+	if err := Save(); err != nil {
+		return err
+	}
+
+	// Check the role now rather than when it is first used, which can be much
+	// later; the context is switched to even if the role cannot be assumed
+	if err := CheckContextRole(context); err != nil {
+		roleWarning(fmt.Sprintf("the role of context %s cannot be assumed: %v", contextName, err))
+	}
+
+	return nil
 }
 
 // GetContexts returns all available contexts as a map of context name to Context.
@@ -362,21 +833,22 @@ func CreateContext(name, profile, region, role string) error {
 }
 
 // UpdateContext updates an existing context with new profile, region, and role values.
+// The context's output format is kept.
 //
 // If the context is the current context, the AWS profile, region, and role are also updated.
 // Returns an error if the context doesn't exist or if the configuration cannot be saved.
 func UpdateContext(name, profile, region, role string) error {
 	// Check if context exists
-	if _, exists := GlobalConfig.Contexts[name]; !exists {
+	context, exists := GlobalConfig.Contexts[name]
+	if !exists {
 		return fmt.Errorf("context %s does not exist", name)
 	}
 
 	// Update the context
-	GlobalConfig.Contexts[name] = Context{
-		Profile: profile,
-		Region:  region,
-		Role:    role,
-	}
+	context.Profile = profile
+	context.Region = region
+	context.Role = role
+	GlobalConfig.Contexts[name] = context
 	viper.Set("contexts", GlobalConfig.Contexts)
 
 	// If this is the current context, update AWS profile and region
@@ -564,6 +1036,78 @@ func addToRecent(listType string, item string) {
 	}
 }
 
+// IsSSOProfile reports whether a profile in the AWS config file authenticates with
+// AWS IAM Identity Center (SSO), that is, whether it sets sso_start_url or sso_session.
+// It returns false if the config file cannot be read.
+func IsSSOProfile(profile string) bool {
+	settings := awsProfileSettings(profile)
+	return settings["sso_start_url"] != "" || settings["sso_session"] != ""
+}
+
+// GetMFASerial returns the MFA device (mfa_serial) that a profile in the AWS config
+// file uses when assuming roles, or an empty string if it has none.
+func GetMFASerial(profile string) string {
+	return awsProfileSettings(profile)["mfa_serial"]
+}
+
+// awsProfileSettings returns the settings of a profile in the AWS config file,
+// or nil if the config file cannot be read
+func awsProfileSettings(profile string) map[string]string {
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return nil
+	}
+
+	settings, err := readProfileSettings(configPath, profile)
+	if err != nil {
+		return nil
+	}
+	return settings
+}
+
+// readProfileSettings returns the settings of a profile in the AWS config file at
+// path. A missing file or profile has no settings.
+func readProfileSettings(path, profile string) (map[string]string, error) {
+	settings := make(map[string]string)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening AWS config file: %w", err)
+	}
+	defer file.Close()
+
+	// The default profile may be written as [default] or [profile default]
+	sections := map[string]bool{"[profile " + profile + "]": true}
+	if profile == "default" {
+		sections["[default]"] = true
+	}
+
+	inProfile := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inProfile = sections[strings.Join(strings.Fields(line), " ")]
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+
+		if key, value, found := strings.Cut(line, "="); found {
+			settings[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading AWS config file: %w", err)
+	}
+	return settings, nil
+}
+
 // GetAWSProfiles returns a list of all AWS profiles from the AWS config and credentials files.
 //
 // This function reads both the AWS config and credentials files and returns a list of all
@@ -641,6 +1185,90 @@ func GetAWSProfiles() ([]string, error) {
 	for profile := range profileMap {
 		profiles = append(profiles, profile)
 	}
+	sort.Strings(profiles)
 
 	return profiles, nil
 }
+
+// ValidateAWSProfile checks that a profile exists in the AWS credentials or
+// config file.
+//
+// Returns an error listing the profiles with a similar name if it doesn't, or
+// if the files cannot be read.
+func ValidateAWSProfile(profile string) error {
+	profiles, err := GetAWSProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to get AWS profiles: %w", err)
+	}
+
+	for _, known := range profiles {
+		if known == profile {
+			return nil
+		}
+	}
+
+	if matches := closeMatches(profile, profiles); len(matches) > 0 {
+		return fmt.Errorf("profile %s not found in AWS credentials or config file (did you mean %s?)", profile, strings.Join(matches, ", "))
+	}
+	return fmt.Errorf("profile %s not found in AWS credentials or config file", profile)
+}
+
+// maxCloseMatches is the maximum number of close matches closeMatches returns
+const maxCloseMatches = 3
+
+// closeMatches returns the candidates that are likely to be what was meant by
+// name: those containing it or within a few typos of it, closest first
+func closeMatches(name string, candidates []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	lowerName := strings.ToLower(name)
+	maxDistance := max(len(name)/3, 1)
+
+	var matches []match
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		distance := editDistance(lowerName, lowerCandidate)
+		if distance <= maxDistance || (lowerName != "" && strings.Contains(lowerCandidate, lowerName)) {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	names := make([]string, 0, maxCloseMatches)
+	for i := 0; i < len(matches) && i < maxCloseMatches; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b: the number of
+// single-character insertions, deletions and substitutions turning a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,6 +58,14 @@ func TestGetSetAWSProfile(t *testing.T) {
 		ConfigFile = originalConfigFile
 	}()
 
+	// Create AWS credentials and config files with known profiles
+	credentialsFile := filepath.Join(tempDir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("[test-profile]\naws_access_key_id = AKIAEXAMPLE\n"), 0600))
+	configFile := filepath.Join(tempDir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile production]\nregion = us-east-1\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
 	// Initialize the configuration
 	err = Initialize()
 	require.NoError(t, err)
@@ -67,6 +76,35 @@ func TestGetSetAWSProfile(t *testing.T) {
 
 	// Check if the profile was set
 	assert.Equal(t, "test-profile", GetAWSProfile())
+
+	// Profiles from the config file are known too
+	require.NoError(t, SetAWSProfile("production"))
+	assert.Equal(t, "production", GetAWSProfile())
+
+	// A misspelled profile is rejected with the close matches
+	err = SetAWSProfile("prodution")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profile prodution not found")
+	assert.Contains(t, err.Error(), "did you mean production?")
+	assert.Equal(t, "production", GetAWSProfile())
+
+	// An unchecked profile is stored even though it's unknown
+	require.NoError(t, SetAWSProfileUnchecked("env-profile"))
+	assert.Equal(t, "env-profile", GetAWSProfile())
+}
+
+func TestCloseMatches(t *testing.T) {
+	candidates := []string{"default", "dev", "production", "staging", "prod-eu"}
+
+	assert.Equal(t, []string{"production"}, closeMatches("prodution", candidates))
+	assert.Equal(t, []string{"dev"}, closeMatches("deb", candidates))
+	assert.Equal(t, []string{"staging"}, closeMatches("Staging", candidates))
+	assert.Equal(t, []string{"prod-eu", "production"}, closeMatches("prod", candidates))
+	assert.Empty(t, closeMatches("sandbox", candidates))
+
+	assert.Equal(t, 0, editDistance("same", "same"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 4, editDistance("", "four"))
 }
 
 func TestGetSetAWSRegion(t *testing.T) {
@@ -123,6 +161,72 @@ func TestGetSetOutputFormat(t *testing.T) {
 	assert.Equal(t, "json", GetOutputFormat())
 }
 
+func TestContextOutputFormat(t *testing.T) {
+	// Create a temporary directory for the test
+	tempDir, err := os.MkdirTemp("", "awsm-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Save the original config file path
+	originalConfigFile := ConfigFile
+
+	// Set the config file to a temporary file
+	ConfigFile = filepath.Join(tempDir, ".awsm")
+	defer func() {
+		ConfigFile = originalConfigFile
+	}()
+
+	// Initialize the configuration
+	err = Initialize()
+	require.NoError(t, err)
+
+	// Give a new context its own output format
+	err = CreateContext("prod", "prod-profile", "eu-west-1", "")
+	require.NoError(t, err)
+	err = SetContextOutputFormat("prod", "json")
+	require.NoError(t, err)
+
+	// The current context still uses the global format
+	assert.Equal(t, "table", GetOutputFormat())
+
+	// Switching to the context applies its format
+	err = SetCurrentContext("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "json", GetOutputFormat())
+
+	// Changing the global format doesn't override the context's format
+	err = SetOutputFormat("yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "json", GetOutputFormat())
+
+	// Updating the context keeps its format
+	err = UpdateContext("prod", "prod-profile", "eu-west-2", "")
+	require.NoError(t, err)
+	assert.Equal(t, "json", GetContexts()["prod"].Output)
+
+	// The context's format survives a reload
+	err = Initialize()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", GetCurrentContext())
+	assert.Equal(t, "json", GetOutputFormat())
+
+	// Switching back falls back to the global format
+	err = SetCurrentContext("default")
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", GetOutputFormat())
+
+	// Clearing the context's format makes it use the global format
+	err = SetContextOutputFormat("prod", "")
+	require.NoError(t, err)
+	err = SetCurrentContext("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", GetOutputFormat())
+
+	// Unknown contexts are an error
+	err = SetContextOutputFormat("missing", "json")
+	assert.Error(t, err)
+}
+
 func TestGetSetAppMode(t *testing.T) {
 	// Create a temporary directory for the test
 	tempDir, err := os.MkdirTemp("", "awsm-test-*")
@@ -150,6 +254,63 @@ func TestGetSetAppMode(t *testing.T) {
 	assert.Equal(t, "tui", GetAppMode())
 }
 
+func TestGetSetAWSTimeout(t *testing.T) {
+	// Create a temporary directory for the test
+	tempDir, err := os.MkdirTemp("", "awsm-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Save the original config file path
+	originalConfigFile := ConfigFile
+
+	// Set the config file to a temporary file
+	ConfigFile = filepath.Join(tempDir, ".awsm")
+	defer func() {
+		ConfigFile = originalConfigFile
+	}()
+
+	// Initialize the configuration
+	err = Initialize()
+	require.NoError(t, err)
+
+	// Check the default timeout
+	assert.Equal(t, 30*time.Second, GetAWSTimeout())
+
+	// Set a new timeout
+	err = SetAWSTimeout(90 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, GetAWSTimeout())
+
+	// Check that the timeout is read back from the saved file
+	err = Initialize()
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, GetAWSTimeout())
+
+	// Timeouts must be positive
+	assert.Error(t, SetAWSTimeout(0))
+	assert.Equal(t, 90*time.Second, GetAWSTimeout())
+}
+
+func TestIsEnvOnly(t *testing.T) {
+	defer SetEnvOnly(false)
+
+	// Disabled by default
+	t.Setenv(EnvOnlyEnvVar, "")
+	assert.False(t, IsEnvOnly())
+
+	// Enabled by the environment variable
+	t.Setenv(EnvOnlyEnvVar, "1")
+	assert.True(t, IsEnvOnly())
+
+	// Invalid values don't enable it
+	t.Setenv(EnvOnlyEnvVar, "maybe")
+	assert.False(t, IsEnvOnly())
+
+	// Enabled by the flag
+	SetEnvOnly(true)
+	assert.True(t, IsEnvOnly())
+}
+
 func TestGetAWSCredentialsPath(t *testing.T) {
 	// Get the AWS credentials path
 	path, err := GetAWSCredentialsPath()
@@ -168,6 +329,63 @@ func TestGetAWSConfigPath(t *testing.T) {
 	assert.NotEmpty(t, path)
 }
 
+func TestReadProfileSettings(t *testing.T) {
+	// Create a sample AWS config file
+	configPath := filepath.Join(t.TempDir(), "config")
+	configContent := `[default]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 123456789012
+sso_role_name = ReadOnly
+
+[profile  dev]
+sso_session = my-sso
+sso_account_id = 123456789012
+sso_role_name = Developer
+
+[profile static]
+region = eu-west-1
+output = json
+mfa_serial = arn:aws:iam::123456789012:mfa/jane
+
+[sso-session my-sso]
+sso_start_url = https://example.awsapps.com/start
+sso_region = us-east-1
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	testCases := []struct {
+		profile   string
+		ssoKey    string
+		mfaSerial string
+	}{
+		{"default", "sso_start_url", ""},
+		{"dev", "sso_session", ""},
+		{"static", "", "arn:aws:iam::123456789012:mfa/jane"},
+		{"my-sso", "", ""},
+		{"missing", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.profile, func(t *testing.T) {
+			settings, err := readProfileSettings(configPath, tc.profile)
+			require.NoError(t, err)
+			if tc.ssoKey != "" {
+				assert.NotEmpty(t, settings[tc.ssoKey])
+			} else {
+				assert.Empty(t, settings["sso_start_url"])
+				assert.Empty(t, settings["sso_session"])
+			}
+			assert.Equal(t, tc.mfaSerial, settings["mfa_serial"])
+		})
+	}
+
+	// A missing config file has no settings
+	settings, err := readProfileSettings(filepath.Join(t.TempDir(), "config"), "default")
+	require.NoError(t, err)
+	assert.Empty(t, settings)
+}
+
 func TestGetSetAWSRole(t *testing.T) {
 	// Create a temporary directory for the test
 	tempDir, err := os.MkdirTemp("", "awsm-test-*")
@@ -419,3 +637,114 @@ output = table
 	assert.Contains(t, profiles, "test-profile4")
 	assert.Len(t, profiles, 5) // Should have 5 unique profiles
 }
+
+// TestLocalConfig tests the project-local configuration file.
+// It verifies that a .awsm.yaml in a parent of the current directory overrides
+// the context and region, and that saving doesn't copy the overrides into the
+// home configuration file.
+func TestLocalConfig(t *testing.T) {
+	// Create a temporary directory for the test
+	tempDir := t.TempDir()
+
+	// Save the original config file path
+	originalConfigFile := ConfigFile
+
+	// Set the config file to a temporary file with a staging context
+	homeDir := filepath.Join(tempDir, "home")
+	require.NoError(t, os.MkdirAll(homeDir, 0755))
+	ConfigFile = filepath.Join(homeDir, ".awsm")
+	defer func() {
+		ConfigFile = originalConfigFile
+	}()
+	homeConfig := `currentContext: default
+contexts:
+  default:
+    profile: default
+    region: us-east-1
+  staging:
+    profile: staging-profile
+    region: us-west-2
+`
+	require.NoError(t, os.WriteFile(ConfigFile+".yaml", []byte(homeConfig), 0644))
+
+	// Create a project that pins the staging context and overrides the region
+	projectDir := filepath.Join(tempDir, "project")
+	workDir := filepath.Join(projectDir, "app")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	localPath := filepath.Join(projectDir, ".awsm.yaml")
+	require.NoError(t, os.WriteFile(localPath, []byte("currentContext: staging\naws:\n  region: eu-west-1\n"), 0644))
+
+	// Run from a subdirectory of the project
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	defer os.Chdir(originalDir)
+
+	// Initialize the configuration
+	err = Initialize()
+	require.NoError(t, err)
+
+	// Check the local values are applied
+	assert.Equal(t, localPath, GetLocalConfigPath())
+	assert.Equal(t, "staging", GetCurrentContext())
+	assert.Equal(t, "staging-profile", GetAWSProfile())
+	assert.Equal(t, "eu-west-1", GetAWSRegion())
+
+	// Save the configuration and check the home file keeps its own values
+	err = SetOutputFormat("json")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(ConfigFile + ".yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "currentcontext: default")
+	assert.NotContains(t, string(data), "eu-west-1")
+
+	// An unknown context in the local file is an error
+	require.NoError(t, os.WriteFile(localPath, []byte("currentContext: missing\n"), 0644))
+	err = Initialize()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "context missing does not exist")
+
+	// Without a local file only the home configuration is used
+	require.NoError(t, os.Remove(localPath))
+	err = Initialize()
+	require.NoError(t, err)
+	assert.Empty(t, GetLocalConfigPath())
+	assert.Equal(t, "default", GetCurrentContext())
+}
+
+// TestFindLocalConfig tests the search for a project-local configuration file.
+// It verifies that the search walks up from the start directory, stops before
+// the home directory even if a file exists there or above it, skips the home
+// configuration file, and walks to the root for directories outside home.
+func TestFindLocalConfig(t *testing.T) {
+	// Create a layout of <root>/home/project/app and <root>/outside/app
+	root := t.TempDir()
+	homeDir := filepath.Join(root, "home")
+	projectDir := filepath.Join(homeDir, "project")
+	workDir := filepath.Join(projectDir, "app")
+	outsideDir := filepath.Join(root, "outside", "app")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	require.NoError(t, os.MkdirAll(outsideDir, 0755))
+
+	name := filepath.Base(ConfigFile) + "." + ConfigType
+	homeConfigPath := filepath.Join(homeDir, name)
+	require.NoError(t, os.WriteFile(homeConfigPath, []byte("currentContext: default\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte("currentContext: above-home\n"), 0644))
+
+	// Without a project file, neither the home file nor the one above home is used
+	assert.Empty(t, FindLocalConfig(workDir, homeDir, homeConfigPath))
+	assert.Empty(t, FindLocalConfig(homeDir, homeDir, homeConfigPath))
+
+	// A project file in a parent directory is found
+	localPath := filepath.Join(projectDir, name)
+	require.NoError(t, os.WriteFile(localPath, []byte("currentContext: staging\n"), 0644))
+	assert.Equal(t, localPath, FindLocalConfig(workDir, homeDir, homeConfigPath))
+	assert.Equal(t, localPath, FindLocalConfig(projectDir, homeDir, homeConfigPath))
+
+	// Outside the home directory the search goes up to the root
+	assert.Equal(t, filepath.Join(root, name), FindLocalConfig(outsideDir, homeDir, homeConfigPath))
+
+	// The home configuration file is skipped wherever it is
+	assert.Empty(t, FindLocalConfig(outsideDir, filepath.Dir(root), filepath.Join(root, name)))
+}
@@ -7,6 +7,9 @@ import (
 	"os"
 	"regexp"
 	"strings"
+
+	"github.com/ao/awsm/internal/logger"
+	"gopkg.in/yaml.v3"
 )
 
 // ContextInfo provides detailed information about a context including whether
@@ -16,9 +19,66 @@ type ContextInfo struct {
 	Profile string // AWS profile associated with the context
 	Region  string // AWS region associated with the context
 	Role    string // AWS role ARN associated with the context (optional)
+	Output  string // Output format used while the context is current (optional)
 	Current bool   // Whether this is the current active context
 }
 
+// ContextValidation holds the result of validating a context.
+type ContextValidation struct {
+	Name               string   // Name of the context
+	Profile            string   // AWS profile associated with the context
+	Region             string   // AWS region associated with the context
+	Role               string   // AWS role ARN associated with the context (optional)
+	ProfileExists      bool     // Whether the profile exists in the AWS credentials or config file
+	RegionValid        bool     // Whether the region is a well-formed AWS region name
+	RoleValid          bool     // Whether the role, if any, is a well-formed IAM role ARN
+	CredentialsChecked bool     // Whether the credentials were verified against AWS
+	Identity           string   // ARN of the caller identity, if credentials were verified
+	Problems           []string // Human-readable descriptions of any problems found
+}
+
+// Valid reports whether no problems were found while validating the context.
+func (v ContextValidation) Valid() bool {
+	return len(v.Problems) == 0
+}
+
+// ContextDefinition is a context as defined in a file imported with ImportContextsFromFile.
+type ContextDefinition struct {
+	Name    string `json:"name" yaml:"name"`                     // Name of the context
+	Profile string `json:"profile" yaml:"profile"`               // AWS profile associated with the context
+	Region  string `json:"region" yaml:"region"`                 // AWS region associated with the context
+	Role    string `json:"role,omitempty" yaml:"role,omitempty"` // AWS role ARN associated with the context (optional)
+}
+
+// ContextImportResult lists what happened to each context imported from a file.
+type ContextImportResult struct {
+	Created []string `json:"created" yaml:"created"` // Contexts that didn't exist and were created
+	Updated []string `json:"updated" yaml:"updated"` // Existing contexts that were replaced
+	Skipped []string `json:"skipped" yaml:"skipped"` // Existing contexts that were left unchanged
+}
+
+// regionPattern matches AWS region names such as us-east-1, eu-central-2 or us-gov-west-1.
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+
+// rolePattern matches IAM role ARNs (e.g. arn:aws:iam::123456789012:role/admin),
+// in any partition, such as aws-cn, aws-us-gov, aws-iso or aws-iso-b
+var rolePattern = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::[0-9]{12}:role/[\w+=,.@/-]+$`)
+
+// RoleVerifier checks that the role of a context can be assumed, e.g. by
+// assuming it with AWS STS
+type RoleVerifier func(awsContext Context) error
+
+// roleVerifier is used by CheckContextRole, set with SetRoleVerifier
+var roleVerifier RoleVerifier
+
+// roleWarning reports a role that cannot be assumed, set with SetRoleVerifier
+var roleWarning = logRoleWarning
+
+// logRoleWarning logs a role that cannot be assumed
+func logRoleWarning(msg string) {
+	logger.Warn("%s", msg)
+}
+
 // ListContexts returns a list of all available contexts with detailed information.
 // The current context will have its Current field set to true.
 func ListContexts() []ContextInfo {
@@ -32,6 +92,7 @@ func ListContexts() []ContextInfo {
 			Profile: ctx.Profile,
 			Region:  ctx.Region,
 			Role:    ctx.Role,
+			Output:  ctx.Output,
 			Current: name == currentContext,
 		})
 	}
@@ -166,6 +227,79 @@ func ImportContextsFromAWS() (int, error) {
 	return importCount, nil
 }
 
+// ImportContextsFromFile creates contexts from a YAML or JSON file holding a list
+// of context definitions, for example:
+//
+//   - name: prod
+//     profile: production
+//     region: us-east-1
+//     role: arn:aws:iam::123456789012:role/admin
+//
+// All definitions are checked before any context is created, so an invalid file
+// changes nothing. Existing contexts are replaced if update is true and skipped
+// otherwise.
+//
+// Returns what happened to each context, and an error if the file cannot be read,
+// contains an invalid definition, or if the configuration cannot be saved.
+func ImportContextsFromFile(path string, update bool) (ContextImportResult, error) {
+	result := ContextImportResult{
+		Created: []string{},
+		Updated: []string{},
+		Skipped: []string{},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("failed to read contexts file: %w", err)
+	}
+
+	// JSON is valid YAML, so both formats are parsed the same way
+	var definitions []ContextDefinition
+	if err := yaml.Unmarshal(data, &definitions); err != nil {
+		return result, fmt.Errorf("failed to parse contexts file %s: %w", path, err)
+	}
+
+	// Check all definitions before changing anything
+	seen := make(map[string]bool, len(definitions))
+	for i, def := range definitions {
+		switch {
+		case def.Name == "":
+			return result, fmt.Errorf("context %d in %s: name cannot be empty", i+1, path)
+		case seen[def.Name]:
+			return result, fmt.Errorf("context %s is defined more than once in %s", def.Name, path)
+		case def.Profile == "":
+			return result, fmt.Errorf("context %s in %s: profile cannot be empty", def.Name, path)
+		case def.Region == "":
+			return result, fmt.Errorf("context %s in %s: region cannot be empty", def.Name, path)
+		case !IsValidRegion(def.Region):
+			return result, fmt.Errorf("context %s in %s: invalid region %q", def.Name, path, def.Region)
+		}
+		seen[def.Name] = true
+	}
+
+	// Create or update the contexts
+	for _, def := range definitions {
+		if _, exists := GetContexts()[def.Name]; exists {
+			if !update {
+				result.Skipped = append(result.Skipped, def.Name)
+				continue
+			}
+			if err := UpdateContext(def.Name, def.Profile, def.Region, def.Role); err != nil {
+				return result, fmt.Errorf("failed to update context %s: %w", def.Name, err)
+			}
+			result.Updated = append(result.Updated, def.Name)
+			continue
+		}
+
+		if err := CreateContext(def.Name, def.Profile, def.Region, def.Role); err != nil {
+			return result, fmt.Errorf("failed to create context %s: %w", def.Name, err)
+		}
+		result.Created = append(result.Created, def.Name)
+	}
+
+	return result, nil
+}
+
 // ExportContextsToAWS exports contexts to the AWS config file.
 //
 // If overwrite is true, it will overwrite the existing AWS config file.
@@ -241,6 +375,119 @@ func GetCurrentContextInfo() (ContextInfo, error) {
 		Profile: ctx.Profile,
 		Region:  ctx.Region,
 		Role:    ctx.Role,
+		Output:  ctx.Output,
 		Current: true,
 	}, nil
 }
+
+// PromptString returns a compact description of the active context suitable for
+// embedding in a shell prompt, in the form "<context>:<region>" (e.g. "prod:us-east-1").
+//
+// If no context is active, the current AWS profile is used in place of the context name.
+func PromptString() string {
+	name := GetCurrentContext()
+	if name == "" {
+		name = GetAWSProfile()
+	}
+
+	region := GetAWSRegion()
+	if region == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s:%s", name, region)
+}
+
+// IsValidRegion checks if the given string is a well-formed AWS region name.
+func IsValidRegion(region string) bool {
+	return regionPattern.MatchString(region)
+}
+
+// IsValidRoleARN checks if the given string is a well-formed IAM role ARN.
+func IsValidRoleARN(role string) bool {
+	return rolePattern.MatchString(role)
+}
+
+// SetRoleVerifier sets how SetCurrentContext checks the role of the context it
+// switches to. verify checks that the role can be assumed (nil to only check
+// that the role ARN is well-formed) and warn reports a role that cannot be
+// (nil to only log it).
+func SetRoleVerifier(verify RoleVerifier, warn func(msg string)) {
+	roleVerifier = verify
+	if warn == nil {
+		warn = logRoleWarning
+	}
+	roleWarning = warn
+}
+
+// CheckContextRole checks that the role of a context, if it has one, is a
+// well-formed IAM role ARN and, if a RoleVerifier is set, that it can be assumed.
+//
+// Returns an error describing why the role cannot be used.
+func CheckContextRole(awsContext Context) error {
+	if awsContext.Role == "" {
+		return nil
+	}
+	if !IsValidRoleARN(awsContext.Role) {
+		return fmt.Errorf("invalid role ARN %q", awsContext.Role)
+	}
+	if roleVerifier != nil {
+		return roleVerifier(awsContext)
+	}
+	return nil
+}
+
+// ValidateContext checks that the named context is usable: its profile must exist
+// in the AWS credentials or config file, its region must be a valid AWS region and
+// its role, if any, must be a well-formed IAM role ARN. If name is empty, the
+// current context is validated.
+//
+// Returns the validation result, or an error if the context doesn't exist or the
+// AWS profiles cannot be read.
+func ValidateContext(name string) (ContextValidation, error) {
+	if name == "" {
+		name = GetCurrentContext()
+	}
+
+	ctx, exists := GetContexts()[name]
+	if !exists {
+		return ContextValidation{}, fmt.Errorf("context %s does not exist", name)
+	}
+
+	result := ContextValidation{
+		Name:     name,
+		Profile:  ctx.Profile,
+		Region:   ctx.Region,
+		Role:     ctx.Role,
+		Problems: []string{},
+	}
+
+	// Check that the profile exists
+	profiles, err := GetAWSProfiles()
+	if err != nil {
+		return ContextValidation{}, fmt.Errorf("failed to get AWS profiles: %w", err)
+	}
+	for _, profile := range profiles {
+		if profile == ctx.Profile {
+			result.ProfileExists = true
+			break
+		}
+	}
+	if !result.ProfileExists {
+		result.Problems = append(result.Problems, fmt.Sprintf("profile %s not found in AWS credentials or config file", ctx.Profile))
+	}
+
+	// Check that the region is valid
+	result.RegionValid = IsValidRegion(ctx.Region)
+	if !result.RegionValid {
+		result.Problems = append(result.Problems, fmt.Sprintf("invalid region %q", ctx.Region))
+	}
+
+	// Check that the role, if any, is valid
+	result.RoleValid = ctx.Role == "" || IsValidRoleARN(ctx.Role)
+	if !result.RoleValid {
+		result.Problems = append(result.Problems, fmt.Sprintf("invalid role ARN %q", ctx.Role))
+	}
+
+	return result, nil
+}
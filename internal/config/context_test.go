@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/mitchellh/go-homedir"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -295,3 +297,254 @@ output = yaml
 	_, err = os.Stat(filepath.Join(os.Getenv("HOME"), ".aws", "config"))
 	assert.NoError(t, err)
 }
+
+func TestPromptString(t *testing.T) {
+	// Save the original configuration
+	originalConfig := GlobalConfig
+	defer func() {
+		GlobalConfig = originalConfig
+	}()
+
+	// Context and region
+	GlobalConfig = DefaultConfig
+	GlobalConfig.CurrentContext = "prod"
+	GlobalConfig.AWS.Region = "us-east-1"
+	assert.Equal(t, "prod:us-east-1", PromptString())
+
+	// No context falls back to the profile
+	GlobalConfig.CurrentContext = ""
+	GlobalConfig.AWS.Profile = "dev"
+	assert.Equal(t, "dev:us-east-1", PromptString())
+
+	// No region
+	GlobalConfig.AWS.Region = ""
+	assert.Equal(t, "dev", PromptString())
+}
+
+func TestIsValidRegion(t *testing.T) {
+	validRegions := []string{"us-east-1", "eu-central-2", "ap-southeast-4", "us-gov-west-1", "cn-northwest-1", "il-central-1"}
+	for _, region := range validRegions {
+		assert.True(t, IsValidRegion(region), region)
+	}
+
+	invalidRegions := []string{"", "us-east", "useast1", "US-EAST-1", "us-east-1a", "moon-base-1x"}
+	for _, region := range invalidRegions {
+		assert.False(t, IsValidRegion(region), region)
+	}
+}
+
+func TestValidateContext(t *testing.T) {
+	// Use a temporary home directory with an AWS credentials file
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+	originalDisableCache := homedir.DisableCache
+	homedir.DisableCache = true
+	defer func() {
+		homedir.DisableCache = originalDisableCache
+	}()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, ".aws"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".aws", "credentials"), []byte("[prod]\naws_access_key_id = test\n"), 0600))
+
+	// Save the original configuration
+	originalConfig := GlobalConfig
+	defer func() {
+		GlobalConfig = originalConfig
+	}()
+
+	GlobalConfig = DefaultConfig
+	GlobalConfig.CurrentContext = "prod"
+	GlobalConfig.Contexts = map[string]Context{
+		"prod":    {Profile: "prod", Region: "us-east-1"},
+		"broken":  {Profile: "missing", Region: "us-east"},
+		"default": {Profile: "default", Region: "eu-west-1"},
+		"admin":   {Profile: "prod", Region: "us-east-1", Role: "admin"},
+	}
+
+	// Valid context (current context when no name is given)
+	result, err := ValidateContext("")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", result.Name)
+	assert.True(t, result.ProfileExists)
+	assert.True(t, result.RegionValid)
+	assert.True(t, result.Valid())
+
+	// Broken context
+	result, err = ValidateContext("broken")
+	require.NoError(t, err)
+	assert.False(t, result.ProfileExists)
+	assert.False(t, result.RegionValid)
+	assert.False(t, result.Valid())
+	assert.Len(t, result.Problems, 2)
+
+	// The default profile always exists
+	result, err = ValidateContext("default")
+	require.NoError(t, err)
+	assert.True(t, result.Valid())
+
+	// A role must be a role ARN
+	result, err = ValidateContext("admin")
+	require.NoError(t, err)
+	assert.False(t, result.RoleValid)
+	assert.Equal(t, []string{`invalid role ARN "admin"`}, result.Problems)
+
+	// Unknown context
+	_, err = ValidateContext("unknown")
+	assert.Error(t, err)
+}
+
+func TestIsValidRoleARN(t *testing.T) {
+	validRoles := []string{
+		"arn:aws:iam::123456789012:role/admin",
+		"arn:aws:iam::123456789012:role/teams/platform/deploy-role",
+		"arn:aws-cn:iam::123456789012:role/admin",
+		"arn:aws-us-gov:iam::123456789012:role/Admin+Access@corp",
+		"arn:aws-iso:iam::123456789012:role/admin",
+		"arn:aws-iso-b:iam::123456789012:role/admin",
+		"arn:aws-iso-f:iam::123456789012:role/admin",
+	}
+	for _, role := range validRoles {
+		assert.True(t, IsValidRoleARN(role), role)
+	}
+
+	invalidRoles := []string{
+		"",
+		"admin",
+		"arn:aws:iam::12345:role/admin",
+		"arn:aws:iam::123456789012:user/admin",
+		"arn:aws:sts::123456789012:assumed-role/admin/session",
+		"arn:aws:iam::123456789012:role/",
+		"arn:aws-:iam::123456789012:role/admin",
+		"arn:awsiso:iam::123456789012:role/admin",
+	}
+	for _, role := range invalidRoles {
+		assert.False(t, IsValidRoleARN(role), role)
+	}
+}
+
+func TestSetCurrentContextChecksRole(t *testing.T) {
+	// Use a temporary configuration file
+	originalConfigFile := ConfigFile
+	ConfigFile = filepath.Join(t.TempDir(), ".awsm")
+	defer func() {
+		ConfigFile = originalConfigFile
+	}()
+	require.NoError(t, Initialize())
+
+	// Record the contexts the role verifier is called with and the warnings
+	var verified []string
+	var warnings []string
+	SetRoleVerifier(func(awsContext Context) error {
+		verified = append(verified, awsContext.Role)
+		if awsContext.Role == "arn:aws:iam::123456789012:role/missing" {
+			return errors.New("access denied")
+		}
+		return nil
+	}, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	defer SetRoleVerifier(nil, nil)
+
+	require.NoError(t, CreateContext("plain", "default", "us-east-1", ""))
+	require.NoError(t, CreateContext("admin", "default", "us-east-1", "arn:aws:iam::123456789012:role/admin"))
+	require.NoError(t, CreateContext("missing", "default", "us-east-1", "arn:aws:iam::123456789012:role/missing"))
+	require.NoError(t, CreateContext("typo", "default", "us-east-1", "arn:aws:iam::123456789012:admin"))
+
+	// A context without a role isn't checked
+	require.NoError(t, SetCurrentContext("plain"))
+	assert.Empty(t, verified)
+
+	// A role that can be assumed doesn't give a warning
+	require.NoError(t, SetCurrentContext("admin"))
+	assert.Equal(t, []string{"arn:aws:iam::123456789012:role/admin"}, verified)
+	assert.Empty(t, warnings)
+
+	// A role that cannot be assumed gives a warning, but the context is switched to
+	require.NoError(t, SetCurrentContext("missing"))
+	assert.Equal(t, "missing", GetCurrentContext())
+	assert.Equal(t, "arn:aws:iam::123456789012:role/missing", GetAWSRole())
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "the role of context missing cannot be assumed: access denied")
+
+	// A malformed role ARN gives a warning without trying to assume it
+	require.NoError(t, SetCurrentContext("typo"))
+	assert.Len(t, verified, 2)
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[1], `invalid role ARN "arn:aws:iam::123456789012:admin"`)
+}
+
+// TestImportContextsFromFile tests importing context definitions from a file.
+// It verifies that YAML and JSON files are both read, that existing contexts are
+// skipped unless updating is requested, and that a file with an invalid
+// definition is rejected without creating any of its contexts.
+func TestImportContextsFromFile(t *testing.T) {
+	// Create a temporary directory for the test
+	tempDir, err := os.MkdirTemp("", "awsm-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Save the original config file path
+	originalConfigFile := ConfigFile
+
+	// Set the config file to a temporary file
+	ConfigFile = filepath.Join(tempDir, ".awsm")
+	defer func() {
+		ConfigFile = originalConfigFile
+	}()
+
+	// Initialize the configuration
+	err = Initialize()
+	require.NoError(t, err)
+
+	// Create a context that is also defined in the file
+	err = CreateContext("dev", "old-profile", "us-west-2", "")
+	require.NoError(t, err)
+
+	// Write a YAML file with an existing and a new context
+	yamlPath := filepath.Join(tempDir, "contexts.yaml")
+	yamlData := `- name: dev
+  profile: development
+  region: eu-west-1
+- name: prod
+  profile: production
+  region: us-east-1
+  role: arn:aws:iam::123456789012:role/admin
+`
+	require.NoError(t, os.WriteFile(yamlPath, []byte(yamlData), 0644))
+
+	// Import without updating existing contexts
+	result, err := ImportContextsFromFile(yamlPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod"}, result.Created)
+	assert.Equal(t, []string{"dev"}, result.Skipped)
+	assert.Empty(t, result.Updated)
+	assert.Equal(t, "old-profile", GetContexts()["dev"].Profile)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/admin", GetContexts()["prod"].Role)
+
+	// Import from JSON, updating existing contexts
+	jsonPath := filepath.Join(tempDir, "contexts.json")
+	jsonData := `[{"name": "dev", "profile": "development", "region": "eu-west-1"}]`
+	require.NoError(t, os.WriteFile(jsonPath, []byte(jsonData), 0644))
+
+	result, err = ImportContextsFromFile(jsonPath, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, result.Updated)
+	assert.Equal(t, "development", GetContexts()["dev"].Profile)
+	assert.Equal(t, "eu-west-1", GetContexts()["dev"].Region)
+
+	// An invalid definition changes nothing
+	invalidData := `- name: staging
+  profile: staging
+  region: eu-west-1
+- name: broken
+  profile: broken
+  region: moon-1
+`
+	require.NoError(t, os.WriteFile(yamlPath, []byte(invalidData), 0644))
+
+	_, err = ImportContextsFromFile(yamlPath, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.NotContains(t, GetContexts(), "staging")
+}
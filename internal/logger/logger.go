@@ -166,11 +166,10 @@ func writeLog(level LogLevel, component string, message string, data map[string]
 	mu.Lock()
 	defer mu.Unlock()
 
-	// Check if we need to initialize the logger
+	// Nothing is logged until Initialize has been called, so packages can log
+	// freely without creating log files for commands that don't want them
 	if logger == nil && jsonLogger == nil {
-		if err := Initialize(); err != nil {
-			return
-		}
+		return
 	}
 
 	// Check if this log level should be logged
@@ -2,12 +2,15 @@ package tui
 
 import (
 	"fmt"
-	"os"
+	"time"
 
+	"github.com/ao/awsm/internal/clock"
 	"github.com/ao/awsm/internal/config"
 	"github.com/ao/awsm/internal/logger"
 	"github.com/ao/awsm/internal/tui/components"
 	"github.com/ao/awsm/internal/tui/models"
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/ao/awsm/internal/utils"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,6 +23,25 @@ var (
 	CommitHash = "unknown"
 )
 
+// inline forces the TUI to run without the alternate screen, regardless of the configuration
+var inline bool
+
+// SetInline sets whether the TUI runs inline, without the alternate screen.
+// This overrides the app.altScreen configuration setting for the current run.
+func SetInline(enabled bool) {
+	inline = enabled
+}
+
+// accessible turns on accessibility mode for the current run
+var accessible bool
+
+// SetAccessible sets whether the TUI runs in accessibility mode, which uses
+// high-contrast colors, avoids dim text and marks selected rows with a ">"
+// marker in addition to their color.
+func SetAccessible(enabled bool) {
+	accessible = enabled
+}
+
 // SetVersionInfo sets the version information
 func SetVersionInfo(version, buildTime, commitHash string) {
 	if version != "" {
@@ -35,14 +57,6 @@ func SetVersionInfo(version, buildTime, commitHash string) {
 	// Log version information
 	logger.Info("TUI Version set to: %s (built: %s, commit: %s)",
 		Version, BuildTime, CommitHash)
-
-	// Create a debug file to verify this function is being called
-	debugFile, err := os.Create("tui_debug.log")
-	if err == nil {
-		debugFile.WriteString(fmt.Sprintf("Version: %s\nBuildTime: %s\nCommitHash: %s\n",
-			Version, BuildTime, CommitHash))
-		debugFile.Close()
-	}
 }
 
 // App represents the TUI application
@@ -65,6 +79,8 @@ type App struct {
 	regionSelector  *components.RegionSelector
 	logo            *components.Logo
 	resultsPanel    *components.ResultsPanel
+	tabBar          *components.TabBar
+	detailPane      *components.DetailPane
 
 	// State
 	width       int
@@ -72,6 +88,38 @@ type App struct {
 	showHelp    bool
 	keyMap      models.KeyMap
 	initialized bool
+
+	// Command to run after the command palette action that set it
+	paletteCmd tea.Cmd
+
+	// Details shown in the detail pane that are still being loaded
+	detailModel models.Model // Model whose row is shown
+	detailRow   int          // Row whose details are shown
+	detailLoad  int          // Load of the shown details; results of earlier loads are dropped
+	detailCmd   tea.Cmd      // Command that loads the details, set by showDetail
+
+	// Auto-refresh reloads the current view every refreshInterval while on
+	autoRefresh     bool
+	refreshInterval time.Duration
+	nextRefresh     time.Time // When the current view is reloaded next
+	refreshLoop     int       // Tick loop of the current auto-refresh; ticks of earlier loops are dropped
+
+	// Tells the time auto-refresh counts down with
+	clock clock.Clock
+}
+
+// autoRefreshTickMsg is sent every second while auto-refresh is on, to count
+// down to the next refresh
+type autoRefreshTickMsg struct {
+	loop int       // Tick loop that sent the message
+	time time.Time // When the message was sent
+}
+
+// detailLoadedMsg carries the message of a command that loaded the details
+// shown in the detail pane
+type detailLoadedMsg struct {
+	load int     // Load that sent the message
+	msg  tea.Msg // Message for the model whose details are shown
 }
 
 // NewApp creates a new TUI application
@@ -82,9 +130,12 @@ func NewApp() *App {
 		commandPalette: components.NewCommandPalette(),
 		logo:           components.NewLogo(),
 		resultsPanel:   components.NewResultsPanel(),
+		tabBar:         newServiceTabBar(models.DefaultKeyMap()),
+		detailPane:     components.NewDetailPane(),
 		keyMap:         models.DefaultKeyMap(),
 		showHelp:       false,
 		initialized:    false,
+		clock:          clock.Real,
 	}
 }
 
@@ -96,6 +147,8 @@ func (a *App) Init() tea.Cmd {
 	a.commandPalette = components.NewCommandPalette()
 	a.logo = components.NewLogo()
 	a.resultsPanel = components.NewResultsPanel()
+	a.tabBar = newServiceTabBar(a.keyMap)
+	a.refreshInterval = config.GetRefreshInterval()
 
 	// Initialize context switcher with a callback to switch contexts
 	a.contextSwitcher = components.NewContextSwitcher(func(contextName string) {
@@ -126,26 +179,47 @@ func (a *App) Init() tea.Cmd {
 
 	// Add common commands to the command palette
 	a.commandPalette.AddCommand("quit", "Quit the application", func() error {
-		return fmt.Errorf("quit")
+		a.paletteCmd = tea.Quit
+		return nil
 	})
 	a.commandPalette.AddCommand("help", "Toggle help", func() error {
 		a.showHelp = !a.showHelp
 		return nil
 	})
 	a.commandPalette.AddCommand("dashboard", "Go to dashboard", func() error {
-		a.SwitchToModel(a.dashboardModel)
+		a.paletteCmd = a.SwitchToModel(a.dashboardModel)
 		return nil
 	})
 	a.commandPalette.AddCommand("ec2", "Go to EC2 view", func() error {
-		a.SwitchToModel(a.ec2Model)
+		a.paletteCmd = a.SwitchToModel(a.ec2Model)
 		return nil
 	})
 	a.commandPalette.AddCommand("s3", "Go to S3 view", func() error {
-		a.SwitchToModel(a.s3Model)
+		a.paletteCmd = a.SwitchToModel(a.s3Model)
 		return nil
 	})
 	a.commandPalette.AddCommand("lambda", "Go to Lambda view", func() error {
-		a.SwitchToModel(a.lambdaModel)
+		a.paletteCmd = a.SwitchToModel(a.lambdaModel)
+		return nil
+	})
+	a.commandPalette.AddCommand("context", "Switch context", func() error {
+		a.contextSwitcher.Show()
+		return nil
+	})
+	a.commandPalette.AddCommand("profile", "Switch AWS profile", func() error {
+		a.profileSelector.Show()
+		return nil
+	})
+	a.commandPalette.AddCommand("region", "Switch AWS region", func() error {
+		a.regionSelector.Show()
+		return nil
+	})
+	a.commandPalette.AddCommand("refresh", "Reload the current view", func() error {
+		a.paletteCmd = a.currentModel.Init()
+		return nil
+	})
+	a.commandPalette.AddCommand("autorefresh", "Toggle reloading the current view periodically", func() error {
+		a.paletteCmd = a.toggleAutoRefresh()
 		return nil
 	})
 
@@ -170,9 +244,55 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case models.StatusMsg:
+		a.statusBar.SetMessage(msg.Text)
+
+	case models.DashboardEC2Msg, models.DashboardS3Msg, models.DashboardLambdaMsg:
+		// The dashboard's counts are kept even if another view is shown by now
+		a.dashboardModel.Update(msg)
+
+	case detailLoadedMsg:
+		// Details loaded for a pane that has since been closed are dropped
+		if msg.load != a.detailLoad || !a.detailPane.IsVisible() {
+			break
+		}
+		a.detailModel.Update(msg.msg)
+		detail, err := a.detailModel.Detail(a.detailRow)
+		if err != nil {
+			// The error replaces the details that were still loading
+			a.statusBar.SetMessage(fmt.Sprintf("Error: %v", err))
+			a.detailPane.SetContent(fmt.Sprintf("Error: %v", err))
+			break
+		}
+		a.detailPane.SetContent(detail)
+
+	case autoRefreshTickMsg:
+		if !a.autoRefresh || msg.loop != a.refreshLoop {
+			// Auto-refresh was turned off or restarted since the tick was scheduled
+			break
+		}
+		if !msg.time.Before(a.nextRefresh) {
+			a.nextRefresh = msg.time.Add(a.refreshInterval)
+			// Skip this refresh rather than stacking a load on one still running
+			if !a.currentModel.IsLoading() {
+				cmds = append(cmds, a.currentModel.Init())
+			}
+		}
+		cmds = append(cmds, a.autoRefreshTick())
+
 	case tea.KeyMsg:
+		// A status message is shown until the next key press
+		a.statusBar.SetMessage("")
+
 		// Handle global key bindings
 		switch {
+		case a.detailPane.IsVisible():
+			// The detail pane handles all keys but Ctrl+C until it is closed
+			if msg.String() == "ctrl+c" {
+				return a, tea.Quit
+			}
+			_, cmd := a.detailPane.HandleKeyMsg(msg)
+			return a, cmd
 		case a.contextSwitcher.IsVisible():
 			// If context switcher is visible, pass the message to it
 			handled, cmd := a.contextSwitcher.HandleKeyMsg(msg)
@@ -209,12 +329,25 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if cmd != nil {
 					cmds = append(cmds, func() tea.Msg { return cmd })
 				}
+				if a.paletteCmd != nil {
+					cmds = append(cmds, a.paletteCmd)
+					a.paletteCmd = nil
+				}
 				// Close the command palette
 				a.commandPalette.SetActive(false)
 			} else if msg.String() == "esc" {
 				// Close the command palette
 				a.commandPalette.SetActive(false)
 			}
+		case a.currentModel.IsFiltering():
+			// While a filter is being typed, all keys go to the current model
+			newModel, cmd := a.currentModel.Update(msg)
+			if m, ok := newModel.(models.Model); ok {
+				a.currentModel = m
+				if cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
 		case key.Matches(msg, a.keyMap.Quit):
 			return a, tea.Quit
 		case key.Matches(msg, a.keyMap.Help):
@@ -231,24 +364,31 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Show region selector
 			a.regionSelector.Show()
 		case key.Matches(msg, a.keyMap.Dashboard):
-			a.SwitchToModel(a.dashboardModel)
+			cmds = append(cmds, a.SwitchToModel(a.dashboardModel))
 		case key.Matches(msg, a.keyMap.EC2):
-			a.SwitchToModel(a.ec2Model)
+			cmds = append(cmds, a.SwitchToModel(a.ec2Model))
 		case key.Matches(msg, a.keyMap.S3):
-			a.SwitchToModel(a.s3Model)
+			cmds = append(cmds, a.SwitchToModel(a.s3Model))
 		case key.Matches(msg, a.keyMap.Lambda):
-			a.SwitchToModel(a.lambdaModel)
+			cmds = append(cmds, a.SwitchToModel(a.lambdaModel))
 		case key.Matches(msg, a.keyMap.Refresh):
 			cmds = append(cmds, a.currentModel.Init())
+		case key.Matches(msg, a.keyMap.AutoRefresh):
+			cmds = append(cmds, a.toggleAutoRefresh())
+		case key.Matches(msg, a.keyMap.Enter):
+			// The details of the selected row are shown in the detail pane,
+			// and rows without details handle Enter themselves
+			if !a.showDetail() {
+				cmds = append(cmds, a.updateCurrentModel(msg))
+				break
+			}
+			if a.detailCmd != nil {
+				cmds = append(cmds, a.detailCmd)
+				a.detailCmd = nil
+			}
 		default:
 			// Pass the message to the current model
-			newModel, cmd := a.currentModel.Update(msg)
-			if m, ok := newModel.(models.Model); ok {
-				a.currentModel = m
-				if cmd != nil {
-					cmds = append(cmds, cmd)
-				}
-			}
+			cmds = append(cmds, a.updateCurrentModel(msg))
 		}
 
 	case tea.WindowSizeMsg:
@@ -279,6 +419,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			resultsHeight = 10 // Minimum height
 		}
 		a.resultsPanel.SetSize(a.width, resultsHeight)
+		a.detailPane.SetSize(a.width, resultsHeight)
 
 		// Update the size of the models
 		if m, ok := a.dashboardModel.(*models.DashboardModel); ok {
@@ -293,11 +434,59 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m, ok := a.lambdaModel.(*models.LambdaModel); ok {
 			m.BaseModel.SetSize(a.width, resultsHeight)
 		}
+
+	default:
+		// Messages with loaded data, such as the instances of the EC2 view, go
+		// to the current model
+		if a.currentModel != nil {
+			if newModel, cmd := a.currentModel.Update(msg); newModel != nil {
+				a.currentModel = newModel
+				cmds = append(cmds, cmd)
+			}
+		}
 	}
 
 	return a, tea.Batch(cmds...)
 }
 
+// toggleAutoRefresh turns auto-refresh on or off. Turning it on starts a new
+// tick loop, so that ticks still scheduled by an earlier loop are dropped.
+//
+// Returns the command that schedules the first tick, or nil when turned off.
+func (a *App) toggleAutoRefresh() tea.Cmd {
+	a.autoRefresh = !a.autoRefresh
+	a.refreshLoop++
+	if !a.autoRefresh {
+		a.statusBar.SetMessage("Auto-refresh off")
+		return nil
+	}
+
+	a.nextRefresh = a.clock.Now().Add(a.refreshInterval)
+	a.statusBar.SetMessage(fmt.Sprintf("Auto-refresh every %s", a.refreshInterval))
+	return a.autoRefreshTick()
+}
+
+// autoRefreshTick returns the command that sends the next tick of the current
+// auto-refresh loop in a second
+func (a *App) autoRefreshTick() tea.Cmd {
+	loop, c := a.refreshLoop, a.clock
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return autoRefreshTickMsg{loop: loop, time: c.Now()}
+	})
+}
+
+// autoRefreshState returns the auto-refresh state shown in the status bar: the
+// time until the next refresh, or nothing while auto-refresh is off
+func (a *App) autoRefreshState(now time.Time) string {
+	if !a.autoRefresh {
+		return ""
+	}
+	if a.currentModel.IsLoading() {
+		return "loading"
+	}
+	return fmt.Sprintf("%ds", int(max(a.nextRefresh.Sub(now), 0).Round(time.Second)/time.Second))
+}
+
 // View renders the application
 func (a *App) View() string {
 	if !a.initialized {
@@ -321,11 +510,15 @@ func (a *App) View() string {
 		a.resultsPanel.SetContent(a.currentModel.View())
 	}
 
-	// Render the results panel
+	// Render the results panel, or the details of the selected row in its place
 	resultsView := a.resultsPanel.Render()
+	if a.detailPane.IsVisible() {
+		resultsView = a.detailPane.View()
+	}
 
 	// Render the status bar with current config
 	a.statusBar.SetWidth(a.width)
+	a.statusBar.SetAutoRefresh(a.autoRefreshState(a.clock.Now()))
 	statusBarView := a.statusBar.Render()
 
 	// Render the help view if enabled
@@ -363,12 +556,17 @@ func (a *App) View() string {
 
 	// Create AWSM info section for the top left (similar to k9s)
 	awsmInfoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF9900")). // AWS Orange color
+		Foreground(theme.Current().Accent).
 		Bold(true).
 		Padding(1, 2)
 
 	awsmInfo := awsmInfoStyle.Render(fmt.Sprintf("AWSM %s", Version))
 
+	// Show the service switcher below the AWSM info, highlighting the current view
+	a.tabBar.SetActive(a.getCurrentModelTab())
+	tabBarView := lipgloss.NewStyle().PaddingLeft(2).Render(a.tabBar.Render())
+	awsmInfo = lipgloss.JoinVertical(lipgloss.Left, awsmInfo, tabBarView)
+
 	headerContent := lipgloss.JoinHorizontal(
 		lipgloss.Center,
 		lipgloss.NewStyle().
@@ -439,6 +637,59 @@ func (a *App) View() string {
 	return view
 }
 
+// updateCurrentModel passes a message to the current model and keeps the model
+// it returns.
+//
+// Returns the command of the model, if any.
+func (a *App) updateCurrentModel(msg tea.Msg) tea.Cmd {
+	newModel, cmd := a.currentModel.Update(msg)
+	m, ok := newModel.(models.Model)
+	if !ok {
+		return nil
+	}
+	a.currentModel = m
+	return cmd
+}
+
+// showDetail shows the details of the current model's selected row in the
+// detail pane. If the model loads them from AWS, the command that does is left
+// in detailCmd. A failure to get them is shown in the status bar.
+//
+// Returns false if the row has no details, in which case the model handles
+// Enter itself.
+func (a *App) showDetail() bool {
+	if a.currentModel.IsLoading() || a.currentModel.GetError() != nil {
+		return false
+	}
+
+	selected := a.currentModel.Selected()
+	a.detailLoad++
+	if loader, ok := a.currentModel.(models.DetailLoader); ok {
+		if cmd := loader.LoadDetail(selected); cmd != nil {
+			load := a.detailLoad
+			a.detailModel = a.currentModel
+			a.detailRow = selected
+			a.detailCmd = func() tea.Msg {
+				return detailLoadedMsg{load: load, msg: cmd()}
+			}
+		}
+	}
+
+	detail, err := a.currentModel.Detail(selected)
+	if err != nil {
+		a.statusBar.SetMessage(fmt.Sprintf("Error: %v", err))
+		a.detailCmd = nil
+		return true
+	}
+	if detail == "" {
+		a.detailCmd = nil
+		return false
+	}
+
+	a.detailPane.Show(a.getCurrentModelTab()+" Details", detail)
+	return true
+}
+
 // getCurrentModelTitle returns the title of the current model
 func (a *App) getCurrentModelTitle() string {
 	switch a.currentModel {
@@ -455,10 +706,35 @@ func (a *App) getCurrentModelTitle() string {
 	}
 }
 
-// SwitchToModel switches to the specified model
-func (a *App) SwitchToModel(model models.Model) {
+// getCurrentModelTab returns the tab bar title of the current model
+func (a *App) getCurrentModelTab() string {
+	switch a.currentModel {
+	case a.ec2Model:
+		return "EC2"
+	case a.s3Model:
+		return "S3"
+	case a.lambdaModel:
+		return "Lambda"
+	default:
+		return "Dashboard"
+	}
+}
+
+// newServiceTabBar creates the service switcher with the hotkeys from the key map
+func newServiceTabBar(keyMap models.KeyMap) *components.TabBar {
+	return components.NewTabBar(
+		components.Tab{Title: "Dashboard", Key: keyMap.Dashboard.Help().Key},
+		components.Tab{Title: "EC2", Key: keyMap.EC2.Help().Key},
+		components.Tab{Title: "S3", Key: keyMap.S3.Help().Key},
+		components.Tab{Title: "Lambda", Key: keyMap.Lambda.Help().Key},
+	)
+}
+
+// SwitchToModel switches to the specified model and returns the command that
+// loads its data
+func (a *App) SwitchToModel(model models.Model) tea.Cmd {
 	a.currentModel = model
-	a.currentModel.Init()
+	return a.currentModel.Init()
 }
 
 // Run runs the TUI application
@@ -473,8 +749,25 @@ func Run() error {
 	logger.Info("TUI starting with version: %s (built: %s, commit: %s)",
 		Version, BuildTime, CommitHash)
 
+	// Apply the color theme before any styles are created
+	if err := theme.Set(config.GetTheme()); err != nil {
+		logger.Warn("Using the %s theme: %v", theme.DefaultName, err)
+	}
+	theme.SetAccessible(accessible)
+
+	// NO_COLOR (https://no-color.org) turns the colors off whatever the theme
+	if utils.NoColor() {
+		theme.Set(theme.NoColorName)
+	}
+
+	// Use the alternate screen unless running inline, which keeps the final view in the scrollback
+	var opts []tea.ProgramOption
+	if config.GetAltScreen() && !inline {
+		opts = append(opts, tea.WithAltScreen())
+	}
+
 	app := NewApp()
-	p := tea.NewProgram(app, tea.WithAltScreen())
+	p := tea.NewProgram(app, opts...)
 	_, err := p.Run()
 	return err
 }
@@ -2,8 +2,11 @@
 package tui
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/ao/awsm/internal/tui/components"
 	"github.com/ao/awsm/internal/tui/models"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -44,6 +47,27 @@ func (m *mockModel) FullHelp() [][]key.Binding {
 	return args.Get(0).([][]key.Binding)
 }
 
+func (m *mockModel) Selected() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+// mockDetailModel is a mockModel that loads the details of its rows, mocking
+// the methods of the models.DetailLoader interface and Detail
+type mockDetailModel struct {
+	mockModel
+}
+
+func (m *mockDetailModel) Detail(selected int) (string, error) {
+	args := m.Called(selected)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockDetailModel) LoadDetail(selected int) tea.Cmd {
+	args := m.Called(selected)
+	return args.Get(0).(tea.Cmd)
+}
+
 // mockCmd is a simple mock tea.Cmd function that returns nil.
 // It's used as a placeholder when a tea.Cmd is needed in tests but
 // the actual command behavior is not important for the test.
@@ -70,41 +94,29 @@ func TestNewApp(t *testing.T) {
 }
 
 // TestAppInit tests the Init method of the App.
-// It verifies that the App initializes correctly, setting the dashboard model
-// as the current model and marking itself as initialized.
+// It verifies that the App initializes correctly, creating its models and
+// selectors, setting the dashboard model as the current model, loading it and
+// marking itself as initialized.
 func TestAppInit(t *testing.T) {
 	// Create a new app
 	app := NewApp()
 
-	// Create a mock model
-	mockDashboardModel := new(mockModel)
-	mockEC2Model := new(mockModel)
-	mockS3Model := new(mockModel)
-	mockLambdaModel := new(mockModel)
-
-	// Set up expectations
-	mockDashboardModel.On("Init").Return(mockCmd)
-
-	// Set the mock models
-	app.dashboardModel = mockDashboardModel
-	app.ec2Model = mockEC2Model
-	app.s3Model = mockS3Model
-	app.lambdaModel = mockLambdaModel
-
 	// Call Init
 	cmd := app.Init()
 
-	// Assert cmd is not nil
+	// Assert the dashboard is loaded
 	assert.NotNil(t, cmd)
+	assert.True(t, app.currentModel.IsLoading())
 
 	// Assert app is initialized
 	assert.True(t, app.initialized)
+	assert.NotNil(t, app.contextSwitcher)
+	assert.NotNil(t, app.profileSelector)
+	assert.NotNil(t, app.regionSelector)
 
 	// Assert current model is set to dashboard
-	assert.Equal(t, mockDashboardModel, app.currentModel)
-
-	// Verify expectations
-	mockDashboardModel.AssertExpectations(t)
+	assert.IsType(t, &models.DashboardModel{}, app.currentModel)
+	assert.Equal(t, app.dashboardModel, app.currentModel)
 }
 
 // TestAppUpdate tests the Update method of the App.
@@ -118,11 +130,14 @@ func TestAppUpdate(t *testing.T) {
 	mockModel := new(mockModel)
 
 	// Set up expectations
-	mockModel.On("Update", mock.Anything).Return(mockModel, mockCmd)
+	mockModel.On("Update", mock.Anything).Return(mockModel, tea.Cmd(mockCmd))
 
-	// Set the current model
+	// Set the current model and the selectors Init creates
 	app.currentModel = mockModel
 	app.initialized = true
+	app.contextSwitcher = components.NewContextSwitcher(func(string) {})
+	app.profileSelector = components.NewProfileSelector(func(string) {})
+	app.regionSelector = components.NewRegionSelector(func(string) {})
 
 	// Call Update with a key message
 	model, cmd := app.Update(tea.KeyMsg{})
@@ -137,6 +152,33 @@ func TestAppUpdate(t *testing.T) {
 	mockModel.AssertExpectations(t)
 }
 
+// TestAppUpdateLoadedData tests passing messages with loaded data to the current model.
+// It verifies that messages the App doesn't handle itself, such as the
+// results of loading a view, reach the current model.
+func TestAppUpdateLoadedData(t *testing.T) {
+	// Create a new app
+	app := NewApp()
+
+	// Create a mock model
+	mockModel := new(mockModel)
+
+	// Set up expectations
+	msg := models.EC2InstanceMsg{}
+	mockModel.On("Update", msg).Return(mockModel, tea.Cmd(mockCmd))
+
+	// Set the current model
+	app.currentModel = mockModel
+
+	// Call the function
+	_, cmd := app.Update(msg)
+
+	// Assert the command of the model is returned
+	assert.NotNil(t, cmd)
+
+	// Verify expectations
+	mockModel.AssertExpectations(t)
+}
+
 // TestAppView tests the View method of the App.
 // It verifies that the App correctly includes the current model's view
 // in its own view output.
@@ -150,9 +192,12 @@ func TestAppView(t *testing.T) {
 	// Set up expectations
 	mockModel.On("View").Return("Mock model view")
 
-	// Set the current model
+	// Set the current model and the selectors Init creates
 	app.currentModel = mockModel
 	app.initialized = true
+	app.contextSwitcher = components.NewContextSwitcher(func(string) {})
+	app.profileSelector = components.NewProfileSelector(func(string) {})
+	app.regionSelector = components.NewRegionSelector(func(string) {})
 
 	// Call View
 	view := app.View()
@@ -176,7 +221,7 @@ func TestSwitchToModel(t *testing.T) {
 	mockNewModel := new(mockModel)
 
 	// Set up expectations
-	mockNewModel.On("Init").Return(mockCmd)
+	mockNewModel.On("Init").Return(tea.Cmd(mockCmd))
 
 	// Set the current model
 	app.currentModel = mockCurrentModel
@@ -191,6 +236,152 @@ func TestSwitchToModel(t *testing.T) {
 	mockNewModel.AssertExpectations(t)
 }
 
+// TestAppAutoRefresh tests reloading the current view with auto-refresh.
+// It verifies that ticks reload the current model once the interval has
+// passed while auto-refresh is on, and are ignored while it is off, while the
+// model is still loading and after the tick loop was restarted.
+func TestAppAutoRefresh(t *testing.T) {
+	// Create a new app
+	app := NewApp()
+	app.refreshInterval = 30 * time.Second
+
+	// Create a mock model
+	mockModel := new(mockModel)
+
+	// Set up expectations
+	mockModel.On("Init").Return(tea.Cmd(mockCmd))
+
+	// Set the current model
+	app.currentModel = mockModel
+	app.initialized = true
+
+	// Ticks are ignored while auto-refresh is off
+	_, cmd := app.Update(autoRefreshTickMsg{loop: app.refreshLoop, time: time.Now()})
+	assert.Nil(t, cmd)
+	mockModel.AssertNotCalled(t, "Init")
+
+	// Turning auto-refresh on schedules the first tick
+	assert.NotNil(t, app.toggleAutoRefresh())
+	loop := app.refreshLoop
+	due := app.nextRefresh
+
+	// Before the interval has passed, a tick only schedules the next one
+	_, cmd = app.Update(autoRefreshTickMsg{loop: loop, time: due.Add(-time.Second)})
+	assert.NotNil(t, cmd)
+	mockModel.AssertNotCalled(t, "Init")
+
+	// Once it has passed, the current model is reloaded
+	_, cmd = app.Update(autoRefreshTickMsg{loop: loop, time: due})
+	assert.IsType(t, tea.BatchMsg{}, cmd())
+	mockModel.AssertNumberOfCalls(t, "Init", 1)
+	assert.Equal(t, due.Add(30*time.Second), app.nextRefresh)
+
+	// A refresh is skipped while the model is still loading
+	mockModel.SetLoading(true)
+	_, cmd = app.Update(autoRefreshTickMsg{loop: loop, time: app.nextRefresh})
+	assert.NotNil(t, cmd)
+	mockModel.AssertNumberOfCalls(t, "Init", 1)
+	assert.Equal(t, "loading", app.autoRefreshState(time.Now()))
+	mockModel.SetLoading(false)
+
+	// Ticks of a loop that was turned off are dropped
+	assert.Nil(t, app.toggleAutoRefresh())
+	_, cmd = app.Update(autoRefreshTickMsg{loop: loop, time: app.nextRefresh})
+	assert.Nil(t, cmd)
+	assert.Empty(t, app.autoRefreshState(time.Now()))
+
+	// Even once auto-refresh is on again
+	app.toggleAutoRefresh()
+	_, cmd = app.Update(autoRefreshTickMsg{loop: loop, time: app.nextRefresh})
+	assert.Nil(t, cmd)
+	mockModel.AssertNumberOfCalls(t, "Init", 1)
+	assert.Equal(t, "30s", app.autoRefreshState(app.nextRefresh.Add(-30*time.Second)))
+}
+
+// TestAppDetailLoad tests showing details that the current model loads.
+// It verifies that Enter shows the details the model has right away and runs
+// its load, that the loaded message goes to the model before the pane shows
+// its details again, and that a load finishing after the pane was closed is
+// dropped.
+func TestAppDetailLoad(t *testing.T) {
+	// Create a new app
+	app := NewApp()
+
+	// Create a mock model
+	model := new(mockDetailModel)
+
+	// Set up expectations
+	model.On("Selected").Return(1)
+	model.On("LoadDetail", 1).Return(tea.Cmd(func() tea.Msg { return "loaded" }))
+	model.On("Detail", 1).Return("Elastic IPs  loading…", nil).Once()
+	model.On("Update", "loaded").Return(model, tea.Cmd(nil)).Once()
+	model.On("Detail", 1).Return("Elastic IPs  5.5.5.5", nil).Once()
+
+	// Set the current model and the selectors Init creates
+	app.currentModel = model
+	app.initialized = true
+	app.contextSwitcher = components.NewContextSwitcher(func(string) {})
+	app.profileSelector = components.NewProfileSelector(func(string) {})
+	app.regionSelector = components.NewRegionSelector(func(string) {})
+
+	// Enter shows the details the model has and starts loading the rest
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, app.detailPane.IsVisible())
+	assert.Contains(t, app.detailPane.View(), "loading…")
+	msg := cmd()
+	assert.IsType(t, detailLoadedMsg{}, msg)
+
+	// The loaded details replace them
+	app.Update(msg)
+	assert.Contains(t, app.detailPane.View(), "5.5.5.5")
+
+	// A load that finishes after the pane was closed is dropped
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app.Update(msg)
+	assert.False(t, app.detailPane.IsVisible())
+
+	// Verify expectations
+	model.AssertExpectations(t)
+	model.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// TestAppDetailLoadError tests details that fail to load.
+// It verifies that the error replaces the details that were still loading in
+// the detail pane.
+func TestAppDetailLoadError(t *testing.T) {
+	// Create a new app
+	app := NewApp()
+
+	// Create a mock model
+	model := new(mockDetailModel)
+
+	// Set up expectations
+	model.On("Selected").Return(0)
+	model.On("LoadDetail", 0).Return(tea.Cmd(func() tea.Msg { return "failed" }))
+	model.On("Detail", 0).Return("Elastic IPs  loading…", nil).Once()
+	model.On("Update", "failed").Return(model, tea.Cmd(nil)).Once()
+	model.On("Detail", 0).Return("", errors.New("access denied")).Once()
+
+	// Set the current model and the selectors Init creates
+	app.currentModel = model
+	app.initialized = true
+	app.contextSwitcher = components.NewContextSwitcher(func(string) {})
+	app.profileSelector = components.NewProfileSelector(func(string) {})
+	app.regionSelector = components.NewRegionSelector(func(string) {})
+
+	// Call the function
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app.Update(cmd())
+
+	// Assert that the pane shows the error instead of the loading details
+	assert.True(t, app.detailPane.IsVisible())
+	assert.Contains(t, app.detailPane.View(), "access denied")
+	assert.NotContains(t, app.detailPane.View(), "loading…")
+
+	// Verify expectations
+	model.AssertExpectations(t)
+}
+
 // TestRun tests the Run method of the App.
 // This test is skipped because it would require actually starting the TUI,
 // which is not suitable for automated testing. In a more comprehensive test suite,
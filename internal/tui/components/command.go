@@ -1,8 +1,10 @@
 package components
 
 import (
+	"sort"
 	"strings"
 
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,11 +17,17 @@ type Command struct {
 	Action      func() error
 }
 
-// CommandPalette represents a command palette component
+// commandPaletteMaxCommands is the number of commands the palette shows at once
+const commandPaletteMaxCommands = 10
+
+// CommandPalette represents a command palette component. As the user types,
+// commands are fuzzy-matched by name and ranked by how well they match, and the
+// up and down keys select a command in the list.
 type CommandPalette struct {
 	textInput textinput.Model
 	commands  []Command
 	filtered  []Command
+	selected  int
 	active    bool
 	width     int
 	height    int
@@ -33,7 +41,7 @@ func NewCommandPalette() *CommandPalette {
 	ti.CharLimit = 100
 	ti.Width = 40
 	ti.Prompt = ": "
-	ti.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#0066cc"))
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Current().Primary)
 
 	return &CommandPalette{
 		textInput: ti,
@@ -41,8 +49,8 @@ func NewCommandPalette() *CommandPalette {
 		filtered:  []Command{},
 		active:    false,
 		style: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#333333")).
+			Foreground(theme.Current().Text).
+			Background(theme.Current().Surface).
 			Padding(1, 2),
 	}
 }
@@ -67,6 +75,7 @@ func (c *CommandPalette) AddCommand(name, description string, action func() erro
 func (c *CommandPalette) SetActive(active bool) {
 	c.active = active
 	if active {
+		c.textInput.Reset()
 		c.textInput.Focus()
 		c.filter("")
 	} else {
@@ -89,33 +98,80 @@ func (c *CommandPalette) Filter(input string) {
 	c.filter(input)
 }
 
-// filter is the internal implementation of Filter
+// filter is the internal implementation of Filter. It keeps the commands whose
+// name fuzzy-matches the input, best matches first, and selects the first one.
 func (c *CommandPalette) filter(input string) {
+	c.selected = 0
 	if input == "" {
 		c.filtered = c.commands
 		return
 	}
 
-	input = strings.ToLower(input)
-	var filtered []Command
-
+	type match struct {
+		command Command
+		score   int
+	}
+	var matches []match
 	for _, cmd := range c.commands {
-		if strings.Contains(strings.ToLower(cmd.Name), input) ||
-			strings.Contains(strings.ToLower(cmd.Description), input) {
-			filtered = append(filtered, cmd)
+		if score, ok := fuzzyScore(input, cmd.Name); ok {
+			matches = append(matches, match{command: cmd, score: score})
 		}
 	}
 
+	// Commands that match equally well keep the order they were added in
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	filtered := make([]Command, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.command
+	}
 	c.filtered = filtered
 }
 
+// fuzzyScore reports whether the characters of query appear in name in order,
+// ignoring case, and scores how well they match. Matches at the start of the
+// name or of a word and runs of consecutive characters score higher, and
+// characters of the name that are not matched lower the score, so an exact
+// match scores highest.
+func fuzzyScore(query, name string) (int, bool) {
+	query = strings.ToLower(query)
+	name = strings.ToLower(name)
+
+	score := 0
+	previous := -1
+	runes := []rune(name)
+	pos := 0
+	for _, q := range query {
+		for pos < len(runes) && runes[pos] != q {
+			pos++
+		}
+		if pos == len(runes) {
+			return 0, false
+		}
+
+		score += 10
+		if pos == previous+1 {
+			score += 15 // Consecutive characters, including a match at the start
+		}
+		if pos == 0 || strings.ContainsRune(" -_:/", runes[pos-1]) {
+			score += 10 // Start of a word
+		}
+		previous = pos
+		pos++
+	}
+
+	return score - (len(runes) - len([]rune(query))), true
+}
+
 // GetSelectedCommand returns the selected command
 func (c *CommandPalette) GetSelectedCommand() *Command {
 	if len(c.filtered) == 0 {
 		return nil
 	}
 
-	return &c.filtered[0]
+	return &c.filtered[c.selected]
 }
 
 // ExecuteSelected executes the selected command
@@ -128,12 +184,34 @@ func (c *CommandPalette) ExecuteSelected() error {
 	return cmd.Action()
 }
 
-// HandleInput handles input for the command palette
+// HandleInput handles input for the command palette: up and down select a
+// command, escape closes the palette, and other keys edit the query
 func (c *CommandPalette) HandleInput(msg tea.Msg) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "ctrl+p":
+			if c.selected > 0 {
+				c.selected--
+			}
+			return
+		case "down", "ctrl+n":
+			if c.selected < len(c.filtered)-1 {
+				c.selected++
+			}
+			return
+		case "esc":
+			c.SetActive(false)
+			return
+		}
+	}
+
+	query := c.textInput.Value()
 	var cmd tea.Cmd
 	c.textInput, cmd = c.textInput.Update(msg)
 	_ = cmd // Ignore the command for now
-	c.filter(c.textInput.Value())
+	if c.textInput.Value() != query {
+		c.filter(c.textInput.Value())
+	}
 }
 
 // Render renders the command palette
@@ -145,8 +223,8 @@ func (c *CommandPalette) Render() string {
 	// Create a title for the command palette
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1).
 		Render(" Command Palette ")
 
@@ -159,19 +237,20 @@ func (c *CommandPalette) Render() string {
 		commandsView = "No commands found"
 	} else {
 		var commandLines []string
-		maxCommands := 10 // Maximum number of commands to show
-		for i, cmd := range c.filtered {
-			if i >= maxCommands {
-				break
-			}
 
-			// Highlight the first command
+		// Scroll the list so that the selected command is visible
+		first := max(c.selected-commandPaletteMaxCommands+1, 0)
+		last := min(first+commandPaletteMaxCommands, len(c.filtered))
+		for i := first; i < last; i++ {
+			cmd := c.filtered[i]
+
+			// Highlight the selected command
 			style := lipgloss.NewStyle()
-			if i == 0 {
-				style = style.Bold(true).Foreground(lipgloss.Color("#0066cc"))
+			if i == c.selected {
+				style = style.Bold(true).Foreground(theme.Current().Primary)
 			}
 
-			line := style.Render(cmd.Name + " - " + cmd.Description)
+			line := theme.SelectionMarker(i == c.selected) + style.Render(cmd.Name+" - "+cmd.Description)
 			commandLines = append(commandLines, line)
 		}
 		commandsView = strings.Join(commandLines, "\n")
@@ -181,7 +260,7 @@ func (c *CommandPalette) Render() string {
 	paletteView := c.style.Copy().
 		Width(c.width - 4). // Account for border width
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#666666")).
+		BorderForeground(theme.Current().Border).
 		Render(lipgloss.JoinVertical(
 			lipgloss.Left,
 			input,
@@ -96,7 +96,7 @@ func TestCommandPaletteHandleInput(t *testing.T) {
 	// Test more character input
 	cp.HandleInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
 	assert.Equal(t, "co", cp.textInput.Value())
-	assert.Len(t, cp.filtered, 1) // command1
+	assert.Len(t, cp.filtered, 2) // command1 and command2
 
 	// Test backspace
 	cp.HandleInput(tea.KeyMsg{Type: tea.KeyBackspace})
@@ -108,6 +108,111 @@ func TestCommandPaletteHandleInput(t *testing.T) {
 	assert.False(t, cp.IsActive())
 }
 
+// TestCommandPaletteFuzzyFilter tests the fuzzy filtering of the CommandPalette.
+// It verifies that commands are matched by the characters of their name in order
+// and ranked by how well they match.
+func TestCommandPaletteFuzzyFilter(t *testing.T) {
+	// Create a new command palette
+	cp := NewCommandPalette()
+	for _, name := range []string{"status", "s3", "sessions", "region", "refresh"} {
+		cp.AddCommand(name, "", func() error { return nil })
+	}
+
+	// An exact name ranks above other matches
+	cp.Filter("s3")
+	assert.Equal(t, "s3", cp.GetSelectedCommand().Name)
+	cp.Filter("s")
+	assert.Equal(t, "s3", cp.GetSelectedCommand().Name)
+
+	// The characters don't have to be consecutive
+	cp.Filter("rfs")
+	assert.Len(t, cp.filtered, 1)
+	assert.Equal(t, "refresh", cp.GetSelectedCommand().Name)
+
+	// Matching is case-insensitive, and shorter names rank higher
+	cp.Filter("RE")
+	assert.Equal(t, []string{"region", "refresh"}, commandNames(cp.filtered))
+
+	// Nothing matches
+	cp.Filter("xyz")
+	assert.Nil(t, cp.GetSelectedCommand())
+}
+
+// TestFuzzyScore tests the fuzzy matcher of the command palette.
+// It verifies that "s3" ranks above "status" for the query "s3" and that a
+// better match scores higher for a query matching both.
+func TestFuzzyScore(t *testing.T) {
+	s3, ok := fuzzyScore("s3", "s3")
+	assert.True(t, ok)
+	status, ok := fuzzyScore("s3", "status")
+	assert.False(t, ok)
+	assert.Greater(t, s3, status)
+
+	// A prefix ranks above a scattered match, and both above no match
+	prefix, ok := fuzzyScore("st", "status")
+	assert.True(t, ok)
+	scattered, ok := fuzzyScore("st", "sessions-list")
+	assert.True(t, ok)
+	assert.Greater(t, prefix, scattered)
+
+	// The start of a word ranks above the middle of one
+	word, ok := fuzzyScore("l", "ec2-list")
+	assert.True(t, ok)
+	middle, ok := fuzzyScore("l", "cloud")
+	assert.True(t, ok)
+	assert.Greater(t, word, middle)
+}
+
+// TestCommandPaletteNavigation tests selecting a command with the arrow keys.
+// It verifies that up and down move the selection within the filtered commands
+// and that typing selects the best match again.
+func TestCommandPaletteNavigation(t *testing.T) {
+	// Create a new command palette
+	cp := NewCommandPalette()
+	var executed string
+	for _, name := range []string{"ec2", "s3", "lambda"} {
+		cp.AddCommand(name, "", func() error {
+			executed = name
+			return nil
+		})
+	}
+	cp.SetActive(true)
+
+	// Move down to the last command, and no further
+	cp.HandleInput(tea.KeyMsg{Type: tea.KeyDown})
+	cp.HandleInput(tea.KeyMsg{Type: tea.KeyDown})
+	cp.HandleInput(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "lambda", cp.GetSelectedCommand().Name)
+
+	// Move back up
+	cp.HandleInput(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "s3", cp.GetSelectedCommand().Name)
+	assert.Contains(t, cp.Render(), "s3")
+
+	// Execute the selected command
+	assert.NoError(t, cp.ExecuteSelected())
+	assert.Equal(t, "s3", executed)
+
+	// Typing selects the best match
+	cp.HandleInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	assert.Equal(t, "ec2", cp.GetSelectedCommand().Name)
+
+	// Reopening the palette clears the query
+	cp.SetActive(false)
+	cp.SetActive(true)
+	assert.Equal(t, "", cp.textInput.Value())
+	assert.Len(t, cp.filtered, 3)
+}
+
+// commandNames returns the names of the commands
+func commandNames(commands []Command) []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.Name
+	}
+	return names
+}
+
 // TestCommandPaletteExecuteSelected tests the ExecuteSelected method of the CommandPalette.
 // It verifies that the selected command's action function is correctly executed
 // when the ExecuteSelected method is called.
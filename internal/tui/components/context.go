@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/ao/awsm/internal/config"
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -54,15 +55,15 @@ func (i contextItem) Description() string {
 // NewContextSwitcher creates a new context switcher
 func NewContextSwitcher(onSelect func(string)) *ContextSwitcher {
 	// Create list
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l := list.New([]list.Item{}, newListDelegate(), 0, 0)
 	l.Title = "AWS Contexts"
 	l.SetShowHelp(true)
 	l.SetFilteringEnabled(true)
 	l.SetShowStatusBar(false)
 	l.SetShowPagination(true)
 	l.Styles.Title = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#9900cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Context).
 		Padding(0, 1)
 
 	// Custom key bindings
@@ -187,7 +188,7 @@ func (c *ContextSwitcher) View() string {
 		Width(c.width).
 		Height(c.height).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#9900cc")).
+		BorderForeground(theme.Current().Context).
 		Render(c.list.View())
 }
 
@@ -0,0 +1,130 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DetailPane shows the details of the selected row of a view in place of the
+// results, the same way for every service
+type DetailPane struct {
+	width   int
+	height  int
+	title   string
+	lines   []string
+	offset  int // Index of the first line shown
+	visible bool
+}
+
+// NewDetailPane creates a new detail pane
+func NewDetailPane() *DetailPane {
+	return &DetailPane{
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetSize sets the size of the detail pane
+func (d *DetailPane) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.clampOffset()
+}
+
+// Show shows the detail pane with the given title and content
+func (d *DetailPane) Show(title, content string) {
+	d.title = title
+	d.lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	d.offset = 0
+	d.visible = true
+}
+
+// SetContent replaces the content of the detail pane, keeping its title and,
+// as far as the new content allows, its scroll position
+func (d *DetailPane) SetContent(content string) {
+	d.lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	d.clampOffset()
+}
+
+// Hide hides the detail pane
+func (d *DetailPane) Hide() {
+	d.visible = false
+}
+
+// IsVisible returns whether the detail pane is visible
+func (d *DetailPane) IsVisible() bool {
+	return d.visible
+}
+
+// visibleLines returns the number of content lines that fit in the pane: the
+// border and padding take 4 lines, and the title and help text another 3
+func (d *DetailPane) visibleLines() int {
+	return max(d.height-7, 1)
+}
+
+// clampOffset keeps the scroll offset within the content
+func (d *DetailPane) clampOffset() {
+	d.offset = max(min(d.offset, len(d.lines)-d.visibleLines()), 0)
+}
+
+// HandleKeyMsg handles key messages for the detail pane: the arrow keys scroll
+// the content and Esc, Enter or q close the pane. All keys are handled while
+// the pane is visible.
+func (d *DetailPane) HandleKeyMsg(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !d.visible {
+		return false, nil
+	}
+
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "enter", "q"))):
+		d.Hide()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+		d.offset--
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+		d.offset++
+	case key.Matches(msg, key.NewBinding(key.WithKeys("pgup"))):
+		d.offset -= d.visibleLines()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("pgdown", " "))):
+		d.offset += d.visibleLines()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("home", "g"))):
+		d.offset = 0
+	case key.Matches(msg, key.NewBinding(key.WithKeys("end", "G"))):
+		d.offset = len(d.lines)
+	}
+	d.clampOffset()
+	return true, nil
+}
+
+// View renders the detail pane
+func (d *DetailPane) View() string {
+	if !d.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
+		Padding(0, 1)
+
+	end := min(d.offset+d.visibleLines(), len(d.lines))
+	content := strings.Join(d.lines[d.offset:end], "\n")
+
+	help := "Press Esc to go back"
+	if len(d.lines) > d.visibleLines() {
+		help = "Press ↑/↓ to scroll, Esc to go back"
+	}
+	helpText := lipgloss.NewStyle().Foreground(theme.Current().Muted).Render(help)
+
+	return lipgloss.NewStyle().
+		Width(max(d.width-2, 0)).
+		Height(max(d.height-2, 0)).
+		Padding(1, 2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Current().Primary).
+		Render(lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render(d.title), "", content, helpText))
+}
@@ -0,0 +1,67 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDetailPane tests the DetailPane component.
+// It verifies that the pane shows its title and content once shown, that the
+// arrow keys scroll long content, and that Esc closes it.
+func TestDetailPane(t *testing.T) {
+	pane := NewDetailPane()
+	pane.SetSize(80, 12)
+
+	// Keys are not handled while the pane is hidden
+	handled, _ := pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyDown})
+	assert.False(t, handled)
+	assert.Empty(t, pane.View())
+
+	// Show more lines than fit in the pane
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %02d", i)
+	}
+	pane.Show("EC2 Details", strings.Join(lines, "\n"))
+	assert.True(t, pane.IsVisible())
+
+	view := pane.View()
+	assert.Contains(t, view, "EC2 Details")
+	assert.Contains(t, view, "line 00")
+	assert.NotContains(t, view, "line 19")
+
+	// Scroll down one line
+	handled, _ = pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyDown})
+	assert.True(t, handled)
+	assert.NotContains(t, pane.View(), "line 00")
+	assert.Contains(t, pane.View(), "line 01")
+
+	// Scrolling stops at the last line
+	for range lines {
+		pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	assert.Contains(t, pane.View(), "line 19")
+	pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Contains(t, pane.View(), "line 18")
+
+	// Other keys are swallowed while the pane is open
+	handled, _ = pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.True(t, handled)
+	assert.True(t, pane.IsVisible())
+
+	// Replacing the content keeps the scroll position where it still fits
+	pane.SetContent(strings.Join(lines[:15], "\n"))
+	assert.Contains(t, pane.View(), "EC2 Details")
+	assert.Contains(t, pane.View(), "line 14")
+	assert.NotContains(t, pane.View(), "line 00")
+
+	// Esc closes the pane
+	handled, _ = pane.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.True(t, handled)
+	assert.False(t, pane.IsVisible())
+	assert.Empty(t, pane.View())
+}
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
@@ -78,8 +79,8 @@ func NewHelpView() *HelpView {
 	return &HelpView{
 		help: helpModel,
 		style: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#333333")).
+			Foreground(theme.Current().Text).
+			Background(theme.Current().Surface).
 			Padding(1, 2),
 		modal: true, // Default to modal display
 	}
@@ -114,8 +115,8 @@ func (h *HelpView) Render(keyMap help.KeyMap) string {
 	// Create a title for the help view
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1).
 		Render(" Keyboard Shortcuts ")
 
@@ -123,7 +124,7 @@ func (h *HelpView) Render(keyMap help.KeyMap) string {
 	helpView := h.style.Copy().
 		Width(h.width - 4). // Account for border width
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#666666")).
+		BorderForeground(theme.Current().Border).
 		Render(helpText)
 
 	// Combine title and help view
@@ -157,8 +158,8 @@ func (h *HelpView) Render(keyMap help.KeyMap) string {
 		Width(modalWidth).
 		Height(modalHeight).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#0066cc")).
-		Background(lipgloss.Color("#333333")).
+		BorderForeground(theme.Current().Primary).
+		Background(theme.Current().Surface).
 		Align(lipgloss.Center).
 		Padding(1, 2)
 
@@ -177,11 +178,9 @@ func (h *HelpView) Render(keyMap help.KeyMap) string {
 	}
 
 	// Create a full-screen container with the modal centered
-	// Use a semi-transparent background to show content behind
 	containerStyle := lipgloss.NewStyle().
 		Width(h.width).
 		Height(h.height).
-		Background(lipgloss.Color("rgba(0,0,0,0.7)")).
 		Padding(topPadding, leftPadding)
 
 	return containerStyle.Render(modalContent)
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -15,7 +16,7 @@ type Logo struct {
 func NewLogo() *Logo {
 	return &Logo{
 		style: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF9900")). // AWS Orange color
+			Foreground(theme.Current().Accent).
 			Bold(true),
 		width:  25, // Default width
 		height: 5,  // Default height (5 lines)
@@ -53,33 +54,33 @@ func (l *Logo) Render() string {
 	// Apply different colors to different parts of the logo
 	coloredLogo := []string{}
 
-	// AWS Orange for the first line
+	// Accent color for the first line
 	coloredLogo = append(coloredLogo, l.style.Copy().
-		Foreground(lipgloss.Color("#FF9900")).
+		Foreground(theme.Current().Accent).
 		Bold(true).
 		Render(logoLines[0]))
 
-	// AWS Blue for the second line
+	// Secondary color for the second line
 	coloredLogo = append(coloredLogo, l.style.Copy().
-		Foreground(lipgloss.Color("#232F3E")).
+		Foreground(theme.Current().Secondary).
 		Bold(true).
 		Render(logoLines[1]))
 
-	// AWS Orange for the third line
+	// Accent color for the third line
 	coloredLogo = append(coloredLogo, l.style.Copy().
-		Foreground(lipgloss.Color("#FF9900")).
+		Foreground(theme.Current().Accent).
 		Bold(true).
 		Render(logoLines[2]))
 
-	// AWS Blue for the fourth line
+	// Secondary color for the fourth line
 	coloredLogo = append(coloredLogo, l.style.Copy().
-		Foreground(lipgloss.Color("#232F3E")).
+		Foreground(theme.Current().Secondary).
 		Bold(true).
 		Render(logoLines[3]))
 
-	// AWS Orange for the fifth line
+	// Accent color for the fifth line
 	coloredLogo = append(coloredLogo, l.style.Copy().
-		Foreground(lipgloss.Color("#FF9900")).
+		Foreground(theme.Current().Accent).
 		Bold(true).
 		Render(logoLines[4]))
 
@@ -93,7 +94,7 @@ func (l *Logo) Render() string {
 		Align(lipgloss.Right).
 		Width(l.width).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#232F3E")).
+		BorderForeground(theme.Current().Secondary).
 		BorderTop(true).
 		BorderLeft(true).
 		BorderRight(true).
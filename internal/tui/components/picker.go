@@ -0,0 +1,166 @@
+package components
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PickerItem is an entry that can be chosen with RunPicker
+type PickerItem struct {
+	Name   string // Name shown as the item title and used for filtering
+	Detail string // Additional information shown below the name
+}
+
+// FilterValue implements list.Item interface
+func (i PickerItem) FilterValue() string {
+	return i.Name
+}
+
+// Title returns the title of the item
+func (i PickerItem) Title() string {
+	return i.Name
+}
+
+// Description returns the description of the item
+func (i PickerItem) Description() string {
+	return i.Detail
+}
+
+// Picker is a standalone list for choosing a single item, used to bring a quick
+// interactive choice to CLI commands without starting the full TUI
+type Picker struct {
+	list     list.Model
+	choice   int
+	quitting bool
+}
+
+// newListDelegate creates the item delegate for the TUI lists. In accessibility
+// mode, item text uses the terminal's own color instead of dimmed colors, and
+// the selected item is bold with the left border that marks it in the theme's
+// primary color.
+func newListDelegate() list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	if !theme.Accessible() {
+		return d
+	}
+
+	d.Styles.NormalTitle = d.Styles.NormalTitle.UnsetForeground()
+	d.Styles.NormalDesc = d.Styles.NormalDesc.UnsetForeground()
+	d.Styles.DimmedTitle = d.Styles.DimmedTitle.UnsetForeground()
+	d.Styles.DimmedDesc = d.Styles.DimmedDesc.UnsetForeground()
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
+		Bold(true).
+		Foreground(theme.Current().Primary).
+		BorderForeground(theme.Current().Primary)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
+		Foreground(theme.Current().Primary).
+		BorderForeground(theme.Current().Primary)
+	return d
+}
+
+// NewPicker creates a new picker with the given title and items
+func NewPicker(title string, items []PickerItem) *Picker {
+	listItems := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		listItems = append(listItems, item)
+	}
+
+	// Create list
+	l := list.New(listItems, newListDelegate(), 0, 0)
+	l.Title = title
+	l.SetShowHelp(true)
+	l.SetFilteringEnabled(true)
+	l.SetShowStatusBar(true)
+	l.Styles.Title = lipgloss.NewStyle().
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
+		Padding(0, 1)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "select"),
+			),
+		}
+	}
+
+	return &Picker{
+		list:   l,
+		choice: -1,
+	}
+}
+
+// Choice returns the index of the chosen item, or -1 if nothing was chosen
+func (p *Picker) Choice() int {
+	return p.choice
+}
+
+// Init initializes the picker
+func (p *Picker) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles events for the picker
+func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.list.SetSize(msg.Width, msg.Height)
+		return p, nil
+
+	case tea.KeyMsg:
+		// Let the filter input handle keys while the user is typing
+		if p.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch msg.String() {
+		case "enter":
+			if p.list.SelectedItem() != nil {
+				// The global index is the position in the unfiltered items
+				p.choice = p.list.GlobalIndex()
+			}
+			p.quitting = true
+			return p, tea.Quit
+		case "esc", "q", "ctrl+c":
+			// Esc first clears an applied filter
+			if msg.String() == "esc" && p.list.FilterState() == list.FilterApplied {
+				break
+			}
+			p.quitting = true
+			return p, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+	return p, cmd
+}
+
+// View renders the picker
+func (p *Picker) View() string {
+	if p.quitting {
+		return ""
+	}
+	return p.list.View()
+}
+
+// RunPicker shows a picker on the terminal and waits for the user to choose an item.
+// The picker is drawn on stderr so that stdout stays free for the command output.
+//
+// Returns the index of the chosen item, -1 if the picker was cancelled, and an
+// error if the picker cannot be run.
+func RunPicker(title string, items []PickerItem) (int, error) {
+	picker := NewPicker(title, items)
+
+	if _, err := tea.NewProgram(picker, tea.WithAltScreen(), tea.WithOutput(os.Stderr)).Run(); err != nil {
+		return -1, fmt.Errorf("failed to run picker: %w", err)
+	}
+
+	return picker.Choice(), nil
+}
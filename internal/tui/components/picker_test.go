@@ -0,0 +1,36 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPicker tests the Picker component.
+// It verifies that Enter chooses the highlighted item and that Esc
+// cancels the picker without a choice.
+func TestPicker(t *testing.T) {
+	items := []PickerItem{
+		{Name: "logs-bucket", Detail: "us-east-1"},
+		{Name: "site-bucket", Detail: "eu-west-1"},
+	}
+
+	// Choose the second item
+	picker := NewPicker("Buckets", items)
+	picker.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	picker.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, 1, picker.Choice())
+	assert.NotNil(t, cmd)
+	assert.Empty(t, picker.View())
+
+	// Cancel without choosing
+	picker = NewPicker("Buckets", items)
+	picker.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	assert.Contains(t, picker.View(), "logs-bucket")
+	picker.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	assert.Equal(t, -1, picker.Choice())
+}
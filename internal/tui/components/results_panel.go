@@ -4,6 +4,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ao/awsm/internal/clock"
+	"github.com/ao/awsm/internal/tui/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -21,6 +23,7 @@ type ResultsPanel struct {
 	titleStyle       lipgloss.Style
 	loadingStartTime time.Time
 	loadingTimeout   time.Duration
+	clock            clock.Clock // Tells the time the loading timeout is measured with
 }
 
 // NewResultsPanel creates a new results panel
@@ -33,16 +36,17 @@ func NewResultsPanel() *ResultsPanel {
 		loading:        false,
 		error:          nil,
 		loadingTimeout: 30 * time.Second, // Default timeout of 30 seconds
+		clock:          clock.Real,
 		style: lipgloss.NewStyle().
 			Padding(1, 2),
 		borderStyle: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#0066cc")).
+			BorderForeground(theme.Current().Primary).
 			BorderStyle(lipgloss.RoundedBorder()),
 		titleStyle: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#0066cc")).
+			Foreground(theme.Current().Text).
+			Background(theme.Current().Primary).
 			Padding(0, 1),
 	}
 }
@@ -69,10 +73,16 @@ func (p *ResultsPanel) SetContent(content string) {
 func (p *ResultsPanel) SetLoading(loading bool) {
 	p.loading = loading
 	if loading {
-		p.loadingStartTime = time.Now()
+		p.loadingStartTime = p.clock.Now()
 	}
 }
 
+// SetClock sets the clock the loading timeout is measured with, so tests can
+// control the time
+func (p *ResultsPanel) SetClock(c clock.Clock) {
+	p.clock = c
+}
+
 // SetError sets the error state of the panel
 func (p *ResultsPanel) SetError(err error) {
 	p.error = err
@@ -90,7 +100,7 @@ func (p *ResultsPanel) CheckTimeout() tea.Cmd {
 		return nil
 	}
 
-	if time.Since(p.loadingStartTime) > p.loadingTimeout {
+	if p.clock.Now().Sub(p.loadingStartTime) > p.loadingTimeout {
 		return func() tea.Msg {
 			return TimeoutMsg{
 				Message: "Operation timed out",
@@ -115,23 +125,22 @@ func (p *ResultsPanel) Render() string {
 	var displayContent string
 	if p.loading {
 		loadingStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFAA00")).
+			Foreground(theme.Current().Warning).
 			Bold(true)
 		displayContent = loadingStyle.Render("Loading...")
 
 		// Add timeout information if loading for more than 5 seconds
-		if !p.loadingStartTime.IsZero() && time.Since(p.loadingStartTime) > 5*time.Second {
-			elapsed := time.Since(p.loadingStartTime).Round(time.Second)
+		if elapsed := p.clock.Now().Sub(p.loadingStartTime).Round(time.Second); !p.loadingStartTime.IsZero() && elapsed > 5*time.Second {
 			displayContent += "\n\n" + loadingStyle.Render("Operation running for "+elapsed.String())
 		}
 	} else if p.error != nil {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
+			Foreground(theme.Current().Error).
 			Bold(true)
 		displayContent = errorStyle.Render("Error: " + p.error.Error())
 	} else if p.content == "" {
 		displayContent = lipgloss.NewStyle().
-			Faint(true).
+			Faint(!theme.Accessible()).
 			Render("No data available")
 	} else {
 		displayContent = p.content
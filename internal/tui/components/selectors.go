@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/ao/awsm/internal/config"
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -47,15 +48,15 @@ func (i profileItem) Description() string {
 // NewProfileSelector creates a new profile selector
 func NewProfileSelector(onSelect func(string)) *ProfileSelector {
 	// Create list
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l := list.New([]list.Item{}, newListDelegate(), 0, 0)
 	l.Title = "AWS Profiles"
 	l.SetShowHelp(true)
 	l.SetFilteringEnabled(true)
 	l.SetShowStatusBar(false)
 	l.SetShowPagination(true)
 	l.Styles.Title = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1)
 
 	// Custom key bindings
@@ -119,11 +120,14 @@ func (p *ProfileSelector) refreshProfiles() {
 	// Get current profile
 	currentProfile := config.GetAWSProfile()
 
-	// Get all available profiles
+	// Only offer the profiles in the AWS credentials and config files, which
+	// SetAWSProfile accepts
 	allProfiles, err := config.GetAWSProfiles()
 	if err != nil {
-		// If there's an error, fall back to recent profiles
-		allProfiles = config.GlobalConfig.Recent.Profiles
+		p.list.Title = fmt.Sprintf("AWS Profiles (failed to read: %v)", err)
+		allProfiles = nil
+	} else {
+		p.list.Title = "AWS Profiles"
 	}
 
 	// Add profiles to list
@@ -184,7 +188,7 @@ func (p *ProfileSelector) View() string {
 		Width(p.width).
 		Height(p.height).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#0066cc")).
+		BorderForeground(theme.Current().Primary).
 		Render(p.list.View())
 }
 
@@ -241,15 +245,15 @@ func (i regionItem) Description() string {
 // NewRegionSelector creates a new region selector
 func NewRegionSelector(onSelect func(string)) *RegionSelector {
 	// Create list
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l := list.New([]list.Item{}, newListDelegate(), 0, 0)
 	l.Title = "AWS Regions"
 	l.SetShowHelp(true)
 	l.SetFilteringEnabled(true)
 	l.SetShowStatusBar(false)
 	l.SetShowPagination(true)
 	l.Styles.Title = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#00cc66")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Region).
 		Padding(0, 1)
 
 	// Custom key bindings
@@ -437,7 +441,7 @@ func (r *RegionSelector) View() string {
 		Width(r.width).
 		Height(r.height).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#00cc66")).
+		BorderForeground(theme.Current().Region).
 		Render(r.list.View())
 }
 
@@ -4,21 +4,24 @@ import (
 	"fmt"
 
 	"github.com/ao/awsm/internal/config"
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // StatusBar represents the status bar at the bottom of the screen
 type StatusBar struct {
-	width int
-	style lipgloss.Style
+	width       int
+	style       lipgloss.Style
+	message     string
+	autoRefresh string
 }
 
 // NewStatusBar creates a new status bar
 func NewStatusBar() *StatusBar {
 	return &StatusBar{
 		style: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#333333")).
+			Foreground(theme.Current().Text).
+			Background(theme.Current().Surface).
 			Padding(0, 1),
 	}
 }
@@ -28,6 +31,19 @@ func (s *StatusBar) SetWidth(width int) {
 	s.width = width
 }
 
+// SetMessage sets a message shown instead of the connection status, such as
+// the confirmation that a value was copied. An empty message shows the
+// connection status again.
+func (s *StatusBar) SetMessage(message string) {
+	s.message = message
+}
+
+// SetAutoRefresh sets the auto-refresh state shown next to the help hint, such
+// as the time until the next refresh. An empty state hides it.
+func (s *StatusBar) SetAutoRefresh(state string) {
+	s.autoRefresh = state
+}
+
 // Render renders the status bar
 func (s *StatusBar) Render() string {
 	// Get current context, AWS profile and region
@@ -38,15 +54,15 @@ func (s *StatusBar) Render() string {
 
 	// Create status sections
 	contextSection := s.style.Copy().
-		Background(lipgloss.Color("#9900cc")).
+		Background(theme.Current().Context).
 		Render(fmt.Sprintf(" Context: %s ", contextName))
 
 	profileSection := s.style.Copy().
-		Background(lipgloss.Color("#0066cc")).
+		Background(theme.Current().Primary).
 		Render(fmt.Sprintf(" Profile: %s ", profile))
 
 	regionSection := s.style.Copy().
-		Background(lipgloss.Color("#006600")).
+		Background(theme.Current().Success).
 		Render(fmt.Sprintf(" Region: %s ", region))
 
 	// Help section
@@ -58,11 +74,20 @@ func (s *StatusBar) Render() string {
 	usedWidth := lipgloss.Width(contextSection) + lipgloss.Width(profileSection) +
 		lipgloss.Width(regionSection) + lipgloss.Width(helpSection)
 
+	// Add auto-refresh section if auto-refresh is on
+	var autoRefreshSection string
+	if s.autoRefresh != "" {
+		autoRefreshSection = s.style.Copy().
+			Background(theme.Current().Accent).
+			Render(fmt.Sprintf(" Auto-refresh: %s ", s.autoRefresh))
+		usedWidth += lipgloss.Width(autoRefreshSection)
+	}
+
 	// Add role section if role is set
 	var roleSection string
 	if role != "" {
 		roleSection = s.style.Copy().
-			Background(lipgloss.Color("#cc6600")).
+			Background(theme.Current().Role).
 			Render(fmt.Sprintf(" Role: %s ", role))
 		usedWidth += lipgloss.Width(roleSection)
 	}
@@ -71,19 +96,24 @@ func (s *StatusBar) Render() string {
 
 	// Create connection status section
 	connectionStatus := "Connected"
-	statusColor := lipgloss.Color("#006600") // Green for connected
+	statusColor := theme.Current().Success
 
 	// In a real implementation, we would check the actual connection status
 	// For now, we'll assume we're connected if we have a profile and region
 	if profile == "" || region == "" {
 		connectionStatus = "Disconnected"
-		statusColor = lipgloss.Color("#cc0000") // Red for disconnected
+		statusColor = theme.Current().Error
+	}
+
+	status := fmt.Sprintf(" Status: %s ", connectionStatus)
+	if s.message != "" {
+		status = fmt.Sprintf(" %s ", s.message)
 	}
 
 	connectionSection := s.style.Copy().
 		Background(statusColor).
 		Width(remainingWidth).
-		Render(fmt.Sprintf(" Status: %s ", connectionStatus))
+		Render(status)
 
 	// Combine all sections
 	sections := []string{
@@ -97,8 +127,12 @@ func (s *StatusBar) Render() string {
 		sections = append(sections, roleSection)
 	}
 
-	// Add connection and help sections
-	sections = append(sections, connectionSection, helpSection)
+	// Add connection, auto-refresh and help sections
+	sections = append(sections, connectionSection)
+	if autoRefreshSection != "" {
+		sections = append(sections, autoRefreshSection)
+	}
+	sections = append(sections, helpSection)
 
 	return lipgloss.JoinHorizontal(lipgloss.Left, sections...)
 }
@@ -0,0 +1,74 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Tab represents a single entry in the tab bar
+type Tab struct {
+	Title string // Title of the tab (e.g. "EC2")
+	Key   string // Hotkey that switches to the tab (e.g. "2")
+}
+
+// TabBar represents the service switcher shown in the header. It lists the
+// available views with their hotkeys and highlights the current one.
+type TabBar struct {
+	tabs          []Tab
+	active        int
+	activeStyle   lipgloss.Style
+	inactiveStyle lipgloss.Style
+	keyStyle      lipgloss.Style
+}
+
+// NewTabBar creates a new tab bar with the given tabs. The first tab is active.
+func NewTabBar(tabs ...Tab) *TabBar {
+	return &TabBar{
+		tabs: tabs,
+		activeStyle: lipgloss.NewStyle().
+			Foreground(theme.Current().Text).
+			Background(theme.Current().Accent).
+			Bold(true).
+			Padding(0, 1),
+		inactiveStyle: lipgloss.NewStyle().
+			Foreground(theme.Current().Muted).
+			Padding(0, 1),
+		keyStyle: lipgloss.NewStyle().
+			Foreground(theme.Current().Accent),
+	}
+}
+
+// SetActive highlights the tab with the given title. Unknown titles are ignored.
+func (t *TabBar) SetActive(title string) {
+	for i, tab := range t.tabs {
+		if tab.Title == title {
+			t.active = i
+			return
+		}
+	}
+}
+
+// Active returns the title of the active tab, or an empty string if there are no tabs
+func (t *TabBar) Active() string {
+	if len(t.tabs) == 0 {
+		return ""
+	}
+	return t.tabs[t.active].Title
+}
+
+// Render renders the tab bar
+func (t *TabBar) Render() string {
+	sections := make([]string, 0, len(t.tabs))
+	for i, tab := range t.tabs {
+		if i == t.active {
+			sections = append(sections, t.activeStyle.Render(fmt.Sprintf("%s %s", tab.Key, tab.Title)))
+			continue
+		}
+		sections = append(sections, t.inactiveStyle.Render(fmt.Sprintf("%s %s", t.keyStyle.Render(tab.Key), tab.Title)))
+	}
+
+	return strings.Join(sections, " ")
+}
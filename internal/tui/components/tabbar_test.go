@@ -0,0 +1,39 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTabBar tests the TabBar component.
+// It verifies that every tab is rendered with its hotkey and that
+// the active tab can be changed by title.
+func TestTabBar(t *testing.T) {
+	// Create a new tab bar
+	tabBar := NewTabBar(
+		Tab{Title: "Dashboard", Key: "1"},
+		Tab{Title: "EC2", Key: "2"},
+		Tab{Title: "S3", Key: "3"},
+	)
+
+	// The first tab is active by default
+	assert.Equal(t, "Dashboard", tabBar.Active())
+
+	// All tabs and hotkeys are rendered
+	view := tabBar.Render()
+	for _, text := range []string{"Dashboard", "EC2", "S3", "1", "2", "3"} {
+		assert.Contains(t, view, text)
+	}
+
+	// Change the active tab
+	tabBar.SetActive("S3")
+	assert.Equal(t, "S3", tabBar.Active())
+
+	// Unknown titles are ignored
+	tabBar.SetActive("Unknown")
+	assert.Equal(t, "S3", tabBar.Active())
+
+	// An empty tab bar has no active tab
+	assert.Equal(t, "", NewTabBar().Active())
+}
@@ -0,0 +1,158 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Table layout settings
+const (
+	tableColumnGap      = 2 // Spaces between columns
+	tableMinColumnWidth = 4 // Columns are never truncated below this width
+)
+
+// Table represents a table of resources with a header row and a selected row.
+// Columns are aligned to their widest cell and truncated to fit the width, and
+// when there are more rows than fit the height the table scrolls to keep the
+// selected row visible.
+type Table struct {
+	headers     []string
+	rows        [][]string
+	selected    int
+	theme       theme.Theme
+	width       int
+	height      int
+	highlighted map[int]bool
+}
+
+// NewTable creates a new table with the given headers and rows, where selected
+// is the index of the selected row, styled with the given theme
+func NewTable(headers []string, rows [][]string, selected int, t theme.Theme) *Table {
+	return &Table{
+		headers:     headers,
+		rows:        rows,
+		selected:    selected,
+		theme:       t,
+		highlighted: make(map[int]bool),
+	}
+}
+
+// SetSize sets the width and height available to the table, including the
+// header row. Zero leaves the width or height unlimited.
+func (t *Table) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+}
+
+// Highlight shows a row in the theme's warning color while it is not selected
+func (t *Table) Highlight(row int) {
+	t.highlighted[row] = true
+}
+
+// Render renders the table
+func (t *Table) Render() string {
+	widths := t.columnWidths()
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.theme.Foreground)
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.theme.Text).
+		Background(t.theme.Primary)
+	highlightStyle := lipgloss.NewStyle().
+		Foreground(t.theme.Warning)
+
+	lines := []string{theme.SelectionMarker(false) + headerStyle.Render(formatRow(t.headers, widths))}
+
+	first, last := t.visibleRows()
+	for i := first; i < last; i++ {
+		style := lipgloss.NewStyle()
+		if i == t.selected {
+			style = selectedStyle
+		} else if t.highlighted[i] {
+			style = highlightStyle
+		}
+		lines = append(lines, theme.SelectionMarker(i == t.selected)+style.Render(formatRow(t.rows[i], widths)))
+	}
+
+	// Show where the visible rows are when scrolling
+	if first > 0 || last < len(t.rows) {
+		lines = append(lines, fmt.Sprintf("rows %d-%d of %d", first+1, last, len(t.rows)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// columnWidths returns the width of each column: its widest cell, with the
+// widest columns shrunk until the table fits the width
+func (t *Table) columnWidths() []int {
+	widths := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		widths[i] = ansi.StringWidth(header)
+	}
+	for _, row := range t.rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			widths[i] = max(widths[i], ansi.StringWidth(row[i]))
+		}
+	}
+
+	if t.width <= 0 {
+		return widths
+	}
+
+	// Space left for the cells after the selection marker and the gaps
+	available := t.width - ansi.StringWidth(theme.SelectionMarker(false)) - tableColumnGap*(len(widths)-1)
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > available {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= tableMinColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// visibleRows returns the range of rows that fit the height, scrolled so that
+// the selected row is the last visible one when it would be below the range
+func (t *Table) visibleRows() (first, last int) {
+	if t.height <= 0 || len(t.rows) <= t.height-1 {
+		return 0, len(t.rows)
+	}
+
+	// Leave room for the header and the scroll position
+	count := max(t.height-2, 1)
+	if t.selected >= count {
+		first = t.selected - count + 1
+	}
+	return first, min(first+count, len(t.rows))
+}
+
+// formatRow pads or truncates each cell to the width of its column and joins them
+func formatRow(cells []string, widths []int) string {
+	padded := make([]string, len(widths))
+	for i, width := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = ansi.Truncate(cells[i], width, "…")
+		}
+		padded[i] = cell + strings.Repeat(" ", width-ansi.StringWidth(cell))
+	}
+	return strings.Join(padded, strings.Repeat(" ", tableColumnGap))
+}
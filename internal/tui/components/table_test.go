@@ -0,0 +1,62 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableRender tests the Table component.
+// It verifies that columns are aligned, truncated to fit the width, and
+// scrolled to keep the selected row visible.
+func TestTableRender(t *testing.T) {
+	headers := []string{"ID", "NAME"}
+	rows := [][]string{
+		{"i-1", "web"},
+		{"i-2", "a-very-long-instance-name"},
+		{"i-3", "db"},
+		{"i-4", "cache"},
+	}
+
+	// Columns are aligned to the widest cell
+	lines := strings.Split(ansi.Strip(NewTable(headers, rows, 0, theme.Current()).Render()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "ID   NAME                     ", lines[0])
+	assert.Equal(t, "i-2  a-very-long-instance-name", lines[2])
+
+	// Long cells are truncated to fit the width
+	table := NewTable(headers, rows, 0, theme.Current())
+	table.SetSize(20, 0)
+	lines = strings.Split(ansi.Strip(table.Render()), "\n")
+	assert.Equal(t, "i-2  a-very-long-in…", lines[2])
+	for _, line := range lines {
+		assert.LessOrEqual(t, ansi.StringWidth(line), 20)
+	}
+
+	// Only the rows that fit are shown, scrolled to the selected row
+	table = NewTable(headers, rows, 3, theme.Current())
+	table.SetSize(0, 4)
+	lines = strings.Split(ansi.Strip(table.Render()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[1], "i-3")
+	assert.Contains(t, lines[2], "i-4")
+	assert.Equal(t, "rows 3-4 of 4", lines[3])
+}
+
+// TestTableSelectionMarker tests the Table component in accessibility mode.
+// It verifies that the selected row is marked without relying on color.
+func TestTableSelectionMarker(t *testing.T) {
+	theme.SetAccessible(true)
+	t.Cleanup(func() {
+		theme.SetAccessible(false)
+		theme.Set(theme.DefaultName)
+	})
+
+	table := NewTable([]string{"NAME"}, [][]string{{"a"}, {"b"}}, 1, theme.Current())
+	lines := strings.Split(ansi.Strip(table.Render()), "\n")
+	assert.Equal(t, []string{"  NAME", "  a   ", "> b   "}, lines)
+}
@@ -1,10 +1,20 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ao/awsm/internal/clock"
+	"github.com/ao/awsm/internal/logger"
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/ao/awsm/internal/utils"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // TimeoutMsg is a message sent when a loading operation times out
@@ -13,6 +23,66 @@ type TimeoutMsg struct {
 	Source  string
 }
 
+// timeoutCheckInterval is how often a loading model checks whether the loading
+// has timed out
+const timeoutCheckInterval = time.Second
+
+// timeoutCheckMsg asks the model named source to check whether its load-th
+// loading has timed out
+type timeoutCheckMsg struct {
+	source string
+	load   int
+}
+
+// scheduleTimeoutCheck returns a command that asks the model named source to
+// check for a timeout of its load-th loading after timeoutCheckInterval
+func scheduleTimeoutCheck(source string, load int) tea.Cmd {
+	return tea.Tick(timeoutCheckInterval, func(time.Time) tea.Msg {
+		return timeoutCheckMsg{source: source, load: load}
+	})
+}
+
+// StatusMsg is a message with text to show in the status bar, such as the
+// confirmation that a value was copied
+type StatusMsg struct {
+	Text string
+}
+
+// copyToClipboard returns a command that copies value to the clipboard and
+// confirms it in the status bar. Without a clipboard, the value is shown in the
+// status bar instead so it can be copied from the terminal.
+func copyToClipboard(value string) tea.Cmd {
+	return func() tea.Msg {
+		if err := utils.CopyToClipboard(value); err != nil {
+			logger.Debug("Failed to copy to the clipboard: %v", err)
+			return StatusMsg{Text: fmt.Sprintf("%s (no clipboard available)", value)}
+		}
+		return StatusMsg{Text: "copied " + value}
+	}
+}
+
+// friendlyError returns a short explanation of common AWS errors, such as
+// invalid credentials or a timeout, for the view of the given service. Other
+// errors are returned unchanged.
+func friendlyError(err error, service string) error {
+	var errMsg string
+	switch {
+	case strings.Contains(err.Error(), "InvalidAccessKeyId"):
+		errMsg = "invalid AWS credentials: the access key ID is invalid or expired"
+	case strings.Contains(err.Error(), "ExpiredToken"):
+		errMsg = "expired AWS credentials: please refresh your credentials"
+	case strings.Contains(err.Error(), "AccessDenied"):
+		errMsg = "access denied: your AWS credentials don't have permission to access " + service
+	case strings.Contains(err.Error(), "context deadline exceeded") || strings.Contains(err.Error(), "timeout"):
+		errMsg = "connection timeout: unable to connect to AWS"
+	default:
+		return err
+	}
+
+	logger.Error("%s", errMsg)
+	return errors.New(errMsg)
+}
+
 // Model is the interface that all TUI models must implement
 type Model interface {
 	// Init initializes the model
@@ -35,6 +105,29 @@ type Model interface {
 
 	// GetError returns any error that occurred during loading
 	GetError() error
+
+	// IsFiltering returns whether the filter is being typed, in which case the
+	// model handles all keys
+	IsFiltering() bool
+
+	// Detail returns the details of the given row of the model's table, which
+	// the app shows in the detail pane when Enter is pressed. An empty string
+	// means the row has no details and the model handles Enter itself, such as
+	// to open a bucket; BaseModel returns an empty string for every row.
+	Detail(selected int) (string, error)
+
+	// Selected returns the index of the selected row of the model's table
+	Selected() int
+}
+
+// DetailLoader is implemented by models that look up the details of a row in
+// AWS when it is opened in the detail pane. The app calls LoadDetail before
+// Detail, shows what Detail returns right away, and passes the message of the
+// returned command to the model's Update, after which it shows Detail again.
+type DetailLoader interface {
+	// LoadDetail returns the command that loads the details of the given row,
+	// or nil if there is nothing to load
+	LoadDetail(selected int) tea.Cmd
 }
 
 // BaseModel provides common functionality for all models
@@ -45,6 +138,14 @@ type BaseModel struct {
 	err              error
 	loadingStartTime time.Time
 	loadingTimeout   time.Duration
+	filtering        bool   // Whether the filter is being typed
+	filter           string // Text the rows are filtered by
+	filtered         []int  // Indexes of the rows matching the filter, in order
+	sortColumn       int    // Index of the column the rows are sorted by (-1 for the loaded order)
+	sortDesc         bool   // Whether the rows are sorted in descending order
+
+	loadingID int         // Number of the current loading, so checks of earlier ones are ignored
+	clock     clock.Clock // Tells the time loading timeouts are measured with (nil for the system time)
 }
 
 // NewBaseModel creates a new base model
@@ -53,6 +154,7 @@ func NewBaseModel() BaseModel {
 		loading:        false,
 		err:            nil,
 		loadingTimeout: 30 * time.Second,
+		sortColumn:     -1,
 	}
 }
 
@@ -62,6 +164,65 @@ func (m *BaseModel) SetSize(width, height int) {
 	m.Height = height
 }
 
+// tableSize returns the width and height available to a table in the model's
+// view, inside the results panel and below the model's title, with room for
+// the help text and the filter line, if any. Both are zero, meaning unlimited,
+// until the size is known.
+func (m *BaseModel) tableSize() (int, int) {
+	if m.Width == 0 || m.Height == 0 {
+		return 0, 0
+	}
+
+	// The results panel's border and padding and the model's padding take 12
+	// columns; they take 6 lines, and the title and help text another 3
+	height := m.Height - 9
+	if m.filterLine() != "" {
+		height--
+	}
+	return max(m.Width-12, 20), max(height, 3)
+}
+
+// detailLine renders a labeled line of the details of a row, showing "-" for
+// an empty value
+func detailLine(label, value string) string {
+	if value == "" {
+		value = "-"
+	}
+	labelStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Current().Foreground)
+	return labelStyle.Render(fmt.Sprintf("%-16s", label)) + value
+}
+
+// detailMap renders a map of the details of a row, such as tags, as a heading
+// followed by its entries in key order
+func detailMap(heading string, values map[string]string) []string {
+	lines := []string{
+		lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Current().Foreground).
+			Render(heading),
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		lines = append(lines, "  (none)")
+	}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %s = %s", k, values[k]))
+	}
+	return lines
+}
+
+// Detail returns no details, leaving Enter to the model
+func (m *BaseModel) Detail(selected int) (string, error) {
+	return "", nil
+}
+
 // IsLoading returns whether the model is in a loading state
 func (m *BaseModel) IsLoading() bool {
 	return m.loading
@@ -76,8 +237,72 @@ func (m *BaseModel) GetError() error {
 func (m *BaseModel) SetLoading(loading bool) {
 	m.loading = loading
 	if loading {
-		m.loadingStartTime = time.Now()
+		m.loadingStartTime = m.now()
+	}
+}
+
+// SetClock sets the clock loading timeouts are measured with, so tests can
+// control the time
+func (m *BaseModel) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// now returns the current time of the model's clock
+func (m *BaseModel) now() time.Time {
+	if m.clock == nil {
+		return clock.Real.Now()
 	}
+	return m.clock.Now()
+}
+
+// startLoading puts the model in the loading state and returns the command
+// that checks whether the loading times out. source names the model in the
+// TimeoutMsg sent when it does.
+func (m *BaseModel) startLoading(source string) tea.Cmd {
+	m.loading = true
+	m.err = nil
+	m.loadingStartTime = m.now()
+	m.loadingID++
+	return scheduleTimeoutCheck(source, m.loadingID)
+}
+
+// loadingElapsed returns how long the model has been loading
+func (m *BaseModel) loadingElapsed() time.Duration {
+	return m.now().Sub(m.loadingStartTime)
+}
+
+// loadingTimedOut reports whether the model has been loading for longer than
+// its loading timeout
+func (m *BaseModel) loadingTimedOut() bool {
+	return m.loading && !m.loadingStartTime.IsZero() && m.loadingElapsed() > m.loadingTimeout
+}
+
+// checkLoadingTimeout handles a timeout check: it returns a command sending a
+// TimeoutMsg if the loading has timed out, and schedules the next check while
+// it is still within the timeout. Checks of a loading that has ended or been
+// replaced by a new one are dropped.
+func (m *BaseModel) checkLoadingTimeout(msg timeoutCheckMsg) tea.Cmd {
+	if !m.loading || msg.load != m.loadingID {
+		return nil
+	}
+	if m.loadingTimedOut() {
+		return func() tea.Msg {
+			return TimeoutMsg{
+				Message: "Operation timed out after " + m.loadingTimeout.String(),
+				Source:  msg.source,
+			}
+		}
+	}
+	return scheduleTimeoutCheck(msg.source, msg.load)
+}
+
+// loadingText returns the loading message of a view, with the time spent
+// loading once it takes longer than 5 seconds
+func (m *BaseModel) loadingText(what string) string {
+	if elapsed := m.loadingElapsed().Round(time.Second); elapsed > 5*time.Second {
+		return fmt.Sprintf("Loading %s... (%s)", what, elapsed)
+	}
+	return fmt.Sprintf("Loading %s...", what)
 }
 
 // SetError sets the error state of the model
@@ -93,11 +318,7 @@ func (m *BaseModel) SetLoadingTimeout(timeout time.Duration) {
 
 // CheckTimeout checks if the loading has timed out and returns a command if it has
 func (m *BaseModel) CheckTimeout() tea.Cmd {
-	if !m.loading || m.loadingStartTime.IsZero() {
-		return nil
-	}
-
-	if time.Since(m.loadingStartTime) > m.loadingTimeout {
+	if m.loadingTimedOut() {
 		return func() tea.Msg {
 			return TimeoutMsg{
 				Message: "Operation timed out",
@@ -108,27 +329,156 @@ func (m *BaseModel) CheckTimeout() tea.Cmd {
 	return nil
 }
 
+// IsFiltering returns whether the filter is being typed
+func (m *BaseModel) IsFiltering() bool {
+	return m.filtering
+}
+
+// handleFilterKey handles the keys of the filter: / starts typing it, Enter
+// stops typing it and Esc clears it. While it is being typed, all other keys
+// edit it. It returns whether the key was handled, and whether the filter
+// changed so the rows need to be filtered again.
+func (m *BaseModel) handleFilterKey(msg tea.KeyMsg) (handled, changed bool) {
+	if !m.filtering {
+		switch {
+		case key.Matches(msg, DefaultKeyMap().Filter):
+			m.filtering = true
+			return true, false
+		case key.Matches(msg, DefaultKeyMap().Escape) && m.filter != "":
+			m.filter = ""
+			return true, true
+		}
+		return false, false
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+		return true, true
+	case tea.KeyEnter:
+		m.filtering = false
+		return true, false
+	case tea.KeyBackspace:
+		if m.filter == "" {
+			return true, false
+		}
+		runes := []rune(m.filter)
+		m.filter = string(runes[:len(runes)-1])
+		return true, true
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+		return true, true
+	}
+	return true, false
+}
+
+// applyFilter sets the rows shown to the ones whose name contains the filter,
+// ignoring case. names holds the primary name of each row.
+func (m *BaseModel) applyFilter(names []string) {
+	filter := strings.ToLower(m.filter)
+	m.filtered = m.filtered[:0]
+	for i, name := range names {
+		if strings.Contains(strings.ToLower(name), filter) {
+			m.filtered = append(m.filtered, i)
+		}
+	}
+}
+
+// clearFilter clears the filter, e.g. when the rows are replaced by another list
+func (m *BaseModel) clearFilter() {
+	m.filtering = false
+	m.filter = ""
+}
+
+// filterLine returns the line showing the filter above the rows, or an empty
+// string when there is none
+func (m *BaseModel) filterLine() string {
+	switch {
+	case m.filtering:
+		return "/" + m.filter + "█\n"
+	case m.filter != "":
+		return "/" + m.filter + " (Esc to clear)\n"
+	}
+	return ""
+}
+
+// handleSortKey handles the keys that sort the rows: o sorts by the next of
+// the given number of columns and O reverses the order. It returns whether the
+// key was handled, in which case the rows need to be sorted again.
+func (m *BaseModel) handleSortKey(msg tea.KeyMsg, columns int) bool {
+	switch {
+	case key.Matches(msg, DefaultKeyMap().Sort):
+		m.sortColumn = (m.sortColumn + 1) % columns
+		m.sortDesc = false
+		return true
+	case key.Matches(msg, DefaultKeyMap().SortOrder):
+		if m.sortColumn < 0 {
+			m.sortColumn = 0
+		}
+		m.sortDesc = !m.sortDesc
+		return true
+	}
+	return false
+}
+
+// sortHeaders returns the table headers with an arrow after the one of the
+// column the rows are sorted by, showing the sort order
+func (m *BaseModel) sortHeaders(headers []string) []string {
+	if m.sortColumn < 0 || m.sortColumn >= len(headers) {
+		return headers
+	}
+
+	sorted := slices.Clone(headers)
+	if m.sortDesc {
+		sorted[m.sortColumn] += " ↓"
+	} else {
+		sorted[m.sortColumn] += " ↑"
+	}
+	return sorted
+}
+
+// sortRows sorts items with compare, reversed if desc is set. Items that
+// compare equal keep their order.
+func sortRows[T any](items []T, compare func(a, b T) int, desc bool) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
+
 // KeyMap defines the keybindings for the application
 type KeyMap struct {
-	Up        key.Binding
-	Down      key.Binding
-	Left      key.Binding
-	Right     key.Binding
-	Help      key.Binding
-	Quit      key.Binding
-	Enter     key.Binding
-	Escape    key.Binding
-	Tab       key.Binding
-	ShiftTab  key.Binding
-	Command   key.Binding
-	Refresh   key.Binding
-	Dashboard key.Binding
-	EC2       key.Binding
-	S3        key.Binding
-	Lambda    key.Binding
-	Context   key.Binding
-	Profile   key.Binding
-	Region    key.Binding
+	Up          key.Binding
+	Down        key.Binding
+	Left        key.Binding
+	Right       key.Binding
+	Help        key.Binding
+	Quit        key.Binding
+	Enter       key.Binding
+	Escape      key.Binding
+	Tab         key.Binding
+	ShiftTab    key.Binding
+	Command     key.Binding
+	Refresh     key.Binding
+	AutoRefresh key.Binding
+	Filter      key.Binding
+	Yank        key.Binding
+	Sort        key.Binding
+	SortOrder   key.Binding
+	LoadMore    key.Binding
+	Start       key.Binding
+	Stop        key.Binding
+	Logs        key.Binding
+	Dashboard   key.Binding
+	EC2         key.Binding
+	S3          key.Binding
+	Lambda      key.Binding
+	Context     key.Binding
+	Profile     key.Binding
+	Region      key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -182,6 +532,42 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		AutoRefresh: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "toggle auto-refresh"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy ID"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "sort by next column"),
+		),
+		SortOrder: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "reverse sort order"),
+		),
+		LoadMore: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "load more"),
+		),
+		Start: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "start instance"),
+		),
+		Stop: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "stop instance"),
+		),
+		Logs: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "view logs"),
+		),
 		Dashboard: key.NewBinding(
 			key.WithKeys("1"),
 			key.WithHelp("1", "dashboard"),
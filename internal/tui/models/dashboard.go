@@ -1,14 +1,89 @@
 package models
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/ao/awsm/internal/aws/client"
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/ao/awsm/internal/aws/lambda"
+	"github.com/ao/awsm/internal/aws/s3"
+	"github.com/ao/awsm/internal/logger"
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
+// DashboardSummary is the number of resources of each service shown on the
+// dashboard. The error of a service is set if its resources could not be
+// counted.
+type DashboardSummary struct {
+	EC2Instances    int   // Number of EC2 instances
+	EC2Running      int   // Number of running EC2 instances
+	EC2Stopped      int   // Number of stopped EC2 instances
+	S3Buckets       int   // Number of S3 buckets
+	LambdaFunctions int   // Number of Lambda functions
+	EC2Error        error // Why the EC2 instances could not be counted
+	S3Error         error // Why the S3 buckets could not be counted
+	LambdaError     error // Why the Lambda functions could not be counted
+}
+
+// DashboardEC2Msg is a message containing the EC2 instance counts of the dashboard
+type DashboardEC2Msg struct {
+	Total   int
+	Running int
+	Stopped int
+	Error   error
+	Load    int // Load of the dashboard the counts belong to; counts of earlier loads are dropped
+}
+
+// DashboardS3Msg is a message containing the S3 bucket count of the dashboard
+type DashboardS3Msg struct {
+	Buckets int
+	Error   error
+	Load    int // Load of the dashboard the count belongs to; counts of earlier loads are dropped
+}
+
+// DashboardLambdaMsg is a message containing the Lambda function count of the dashboard
+type DashboardLambdaMsg struct {
+	Functions int
+	Error     error
+	Load      int // Load of the dashboard the count belongs to; counts of earlier loads are dropped
+}
+
+// instanceLister lists EC2 instances; it is implemented by ec2.Adapter
+type instanceLister interface {
+	ListInstances(ctx context.Context, filters []types.Filter, maxItems int32) ([]ec2.Instance, error)
+}
+
+// bucketLister lists S3 buckets; it is implemented by s3.Adapter
+type bucketLister interface {
+	ListBuckets(ctx context.Context) ([]s3.Bucket, error)
+}
+
+// functionLister lists Lambda functions; it is implemented by lambda.Adapter
+type functionLister interface {
+	ListFunctions(ctx context.Context, maxItems int32) ([]lambda.Function, error)
+}
+
 // DashboardModel represents the dashboard view
 type DashboardModel struct {
 	BaseModel
-	title string
+	title   string
+	summary DashboardSummary
+
+	// Whether the counts of each service are being loaded
+	loadingEC2    bool
+	loadingS3     bool
+	loadingLambda bool
+
+	// Listers used to count the resources, created from the current
+	// configuration when nil
+	instances instanceLister
+	buckets   bucketLister
+	functions functionLister
 }
 
 // NewDashboardModel creates a new dashboard model
@@ -19,40 +94,252 @@ func NewDashboardModel() *DashboardModel {
 	}
 }
 
-// Init initializes the model
+// Init initializes the model, counting the resources of each service
+// concurrently so that a slow or failing service doesn't hold up the others.
+// The counts of an earlier load still running are dropped when they arrive.
 func (m *DashboardModel) Init() tea.Cmd {
-	// Return a command to load dashboard data
+	m.loadingEC2 = true
+	m.loadingS3 = true
+	m.loadingLambda = true
+	m.loadingID++
+
+	return tea.Batch(m.countInstances(m.loadingID), m.countBuckets(m.loadingID), m.countFunctions(m.loadingID))
+}
+
+// IsLoading returns whether the counts of any service are being loaded
+func (m *DashboardModel) IsLoading() bool {
+	return m.loadingEC2 || m.loadingS3 || m.loadingLambda
+}
+
+// countInstances returns a command that counts the EC2 instances by state for
+// the given load
+func (m *DashboardModel) countInstances(load int) tea.Cmd {
+	lister := m.instances
+	return func() tea.Msg {
+		ctx, cancel := client.WithTimeout(context.Background())
+		defer cancel()
+
+		if lister == nil {
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				return DashboardEC2Msg{Error: friendlyError(err, "EC2"), Load: load}
+			}
+			lister = adapter
+		}
+
+		instances, err := lister.ListInstances(ctx, nil, 0)
+		if err != nil {
+			logger.Error("Error counting EC2 instances: %v", err)
+			return DashboardEC2Msg{Error: friendlyError(err, "EC2"), Load: load}
+		}
+
+		msg := DashboardEC2Msg{Total: len(instances), Load: load}
+		for _, instance := range instances {
+			switch instance.State {
+			case string(types.InstanceStateNameRunning):
+				msg.Running++
+			case string(types.InstanceStateNameStopped):
+				msg.Stopped++
+			}
+		}
+		return msg
+	}
+}
+
+// countBuckets returns a command that counts the S3 buckets for the given load
+func (m *DashboardModel) countBuckets(load int) tea.Cmd {
+	lister := m.buckets
 	return func() tea.Msg {
-		return nil // No data to load yet
+		ctx, cancel := client.WithTimeout(context.Background())
+		defer cancel()
+
+		if lister == nil {
+			adapter, err := s3.NewAdapter(ctx)
+			if err != nil {
+				return DashboardS3Msg{Error: friendlyError(err, "S3"), Load: load}
+			}
+			lister = adapter
+		}
+
+		buckets, err := lister.ListBuckets(ctx)
+		if err != nil {
+			logger.Error("Error counting S3 buckets: %v", err)
+			return DashboardS3Msg{Error: friendlyError(err, "S3"), Load: load}
+		}
+		return DashboardS3Msg{Buckets: len(buckets), Load: load}
 	}
 }
 
+// countFunctions returns a command that counts the Lambda functions for the
+// given load
+func (m *DashboardModel) countFunctions(load int) tea.Cmd {
+	lister := m.functions
+	return func() tea.Msg {
+		ctx, cancel := client.WithTimeout(context.Background())
+		defer cancel()
+
+		if lister == nil {
+			adapter, err := lambda.NewAdapter(ctx)
+			if err != nil {
+				return DashboardLambdaMsg{Error: friendlyError(err, "Lambda"), Load: load}
+			}
+			lister = adapter
+		}
+
+		functions, err := lister.ListFunctions(ctx, 0)
+		if err != nil {
+			logger.Error("Error counting Lambda functions: %v", err)
+			return DashboardLambdaMsg{Error: friendlyError(err, "Lambda"), Load: load}
+		}
+		return DashboardLambdaMsg{Functions: len(functions), Load: load}
+	}
+}
+
+// Summary returns the resource counts loaded so far
+func (m *DashboardModel) Summary() DashboardSummary {
+	return m.summary
+}
+
 // Update updates the model based on messages
 func (m *DashboardModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		// Handle key messages
-		switch {
-		case key.Matches(msg, DefaultKeyMap().Up):
-			// Handle up key
-		case key.Matches(msg, DefaultKeyMap().Down):
-			// Handle down key
+	case DashboardEC2Msg:
+		if msg.Load != m.loadingID {
+			break
 		}
+		m.loadingEC2 = false
+		m.summary.EC2Instances = msg.Total
+		m.summary.EC2Running = msg.Running
+		m.summary.EC2Stopped = msg.Stopped
+		m.summary.EC2Error = msg.Error
+
+	case DashboardS3Msg:
+		if msg.Load != m.loadingID {
+			break
+		}
+		m.loadingS3 = false
+		m.summary.S3Buckets = msg.Buckets
+		m.summary.S3Error = msg.Error
+
+	case DashboardLambdaMsg:
+		if msg.Load != m.loadingID {
+			break
+		}
+		m.loadingLambda = false
+		m.summary.LambdaFunctions = msg.Functions
+		m.summary.LambdaError = msg.Error
 	}
 
 	return m, nil
 }
 
+// dashboardCardWidth is the width of a card, including its border
+const dashboardCardWidth = 26
+
+// dashboardCard is the count of one service shown as a card on the dashboard
+type dashboardCard struct {
+	title   string // Name of the resources
+	count   int    // Number of resources
+	detail  string // Line shown below the count, such as a breakdown by state
+	viewKey string // Key that opens the view of the resources
+	loading bool   // Whether the count is being loaded
+	err     error  // Why the resources could not be counted
+}
+
+// body renders the title, the count (or the loading state or error) and the
+// detail line of the card
+func (c dashboardCard) body(width int) string {
+	t := theme.Current()
+	count := lipgloss.NewStyle().Bold(true).Foreground(t.Foreground).Render(fmt.Sprint(c.count))
+	detail := lipgloss.NewStyle().Foreground(t.Muted).Width(width).Render(c.detail)
+	switch {
+	case c.loading:
+		count = lipgloss.NewStyle().Foreground(t.Warning).Render("Loading...")
+		detail = ""
+	case c.err != nil:
+		count = lipgloss.NewStyle().Foreground(t.Error).Render("Unavailable")
+		detail = lipgloss.NewStyle().Foreground(t.Error).Width(width).Render(c.err.Error())
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(t.Primary).Render(c.title),
+		"",
+		count,
+		detail,
+	)
+}
+
+// renderCards renders the cards with the same height, so that the keys of
+// their views line up at the bottom
+func renderCards(cards []dashboardCard) []string {
+	// The border and padding take 4 columns of a card
+	width := dashboardCardWidth - 4
+
+	bodies := make([]string, len(cards))
+	height := 0
+	for i, card := range cards {
+		bodies[i] = card.body(width)
+		height = max(height, lipgloss.Height(bodies[i]))
+	}
+
+	t := theme.Current()
+	style := lipgloss.NewStyle().
+		Width(dashboardCardWidth-2).
+		Padding(0, 1).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border)
+	hintStyle := lipgloss.NewStyle().Foreground(t.Muted)
+
+	rendered := make([]string, len(cards))
+	for i, card := range cards {
+		rendered[i] = style.Render(lipgloss.JoinVertical(lipgloss.Left,
+			lipgloss.PlaceVertical(height, lipgloss.Top, bodies[i]),
+			"",
+			hintStyle.Render(fmt.Sprintf("Press %s to open", card.viewKey)),
+		))
+	}
+	return rendered
+}
+
 // View renders the model
 func (m *DashboardModel) View() string {
-	// Just return the content without styling, as the ResultsPanel will handle that
-	return `AWS Resources Overview:
+	keys := DefaultKeyMap()
+	s := m.summary
+
+	ec2Detail := fmt.Sprintf("%d running, %d stopped", s.EC2Running, s.EC2Stopped)
+	if other := s.EC2Instances - s.EC2Running - s.EC2Stopped; other > 0 {
+		ec2Detail += fmt.Sprintf(", %d other", other)
+	}
 
-EC2 Instances: Press 2 to view
-S3 Buckets: Press 3 to view
-Lambda Functions: Press 4 to view
+	cards := renderCards([]dashboardCard{
+		{title: "EC2 Instances", count: s.EC2Instances, detail: ec2Detail, viewKey: keys.EC2.Help().Key, loading: m.loadingEC2, err: s.EC2Error},
+		{title: "S3 Buckets", count: s.S3Buckets, viewKey: keys.S3.Help().Key, loading: m.loadingS3, err: s.S3Error},
+		{title: "Lambda Functions", count: s.LambdaFunctions, viewKey: keys.Lambda.Help().Key, loading: m.loadingLambda, err: s.LambdaError},
+	})
+
+	// Show the cards side by side if they fit in the results panel, whose
+	// border and padding take 12 columns, and below each other otherwise
+	var content string
+	if m.Width == 0 || m.Width-12 >= len(cards)*(dashboardCardWidth+1) {
+		row := make([]string, 0, 2*len(cards))
+		for i, card := range cards {
+			if i > 0 {
+				row = append(row, " ")
+			}
+			row = append(row, card)
+		}
+		content = lipgloss.JoinHorizontal(lipgloss.Top, row...)
+	} else {
+		content = lipgloss.JoinVertical(lipgloss.Left, cards...)
+	}
+
+	// Just return the content without styling, as the ResultsPanel will handle that
+	return "AWS Resources Overview:\n\n" + content + "\n\nPress r to refresh, ? for help or : for command palette"
+}
 
-Press ? for help or : for command palette`
+// Selected returns -1, as no row of the dashboard is selected
+func (m *DashboardModel) Selected() int {
+	return -1
 }
 
 // ShortHelp returns the short help text
@@ -60,6 +347,8 @@ func (m *DashboardModel) ShortHelp() []key.Binding {
 	return []key.Binding{
 		DefaultKeyMap().Help,
 		DefaultKeyMap().Quit,
+		DefaultKeyMap().Refresh,
+		DefaultKeyMap().AutoRefresh,
 		DefaultKeyMap().EC2,
 		DefaultKeyMap().S3,
 		DefaultKeyMap().Lambda,
@@ -73,6 +362,8 @@ func (m *DashboardModel) FullHelp() [][]key.Binding {
 		{
 			DefaultKeyMap().Help,
 			DefaultKeyMap().Quit,
+			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Command,
 		},
 		{
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/ao/awsm/internal/aws/lambda"
+	"github.com/ao/awsm/internal/aws/s3"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockListers implements the listers the dashboard counts resources with,
+// using the testify/mock package.
+type mockListers struct {
+	mock.Mock
+}
+
+func (m *mockListers) ListInstances(ctx context.Context, filters []types.Filter, maxItems int32) ([]ec2.Instance, error) {
+	args := m.Called(ctx, filters, maxItems)
+	return args.Get(0).([]ec2.Instance), args.Error(1)
+}
+
+func (m *mockListers) ListBuckets(ctx context.Context) ([]s3.Bucket, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]s3.Bucket), args.Error(1)
+}
+
+func (m *mockListers) ListFunctions(ctx context.Context, maxItems int32) ([]lambda.Function, error) {
+	args := m.Called(ctx, maxItems)
+	return args.Get(0).([]lambda.Function), args.Error(1)
+}
+
+// runBatch runs the commands of a tea.Batch command and passes their messages
+// to the model, like the Bubble Tea runtime does
+func runBatch(t *testing.T, model Model, cmd tea.Cmd) {
+	batch, ok := cmd().(tea.BatchMsg)
+	assert.True(t, ok)
+	for _, c := range batch {
+		model.Update(c())
+	}
+}
+
+// TestDashboardModelSummary tests the resource counts of the dashboard.
+// It verifies that the counts of each service are loaded with the injected
+// adapters, that EC2 instances are counted by state, and that an error of one
+// service doesn't prevent showing the others.
+func TestDashboardModelSummary(t *testing.T) {
+	// Create mock adapters
+	listers := new(mockListers)
+	model := NewDashboardModel()
+	model.instances = listers
+	model.buckets = listers
+	model.functions = listers
+
+	// Set up expectations
+	listers.On("ListInstances", mock.Anything, mock.Anything, int32(0)).Return([]ec2.Instance{
+		{ID: "i-1", State: "running"},
+		{ID: "i-2", State: "running"},
+		{ID: "i-3", State: "stopped"},
+		{ID: "i-4", State: "pending"},
+	}, nil)
+	listers.On("ListBuckets", mock.Anything).Return([]s3.Bucket{{Name: "logs"}, {Name: "assets"}}, nil)
+	listers.On("ListFunctions", mock.Anything, int32(0)).Return([]lambda.Function(nil), errors.New("AccessDeniedException: not allowed"))
+
+	// Call the function
+	runBatch(t, model, model.Init())
+
+	// Assert the aggregated counts
+	summary := model.Summary()
+	assert.Equal(t, 4, summary.EC2Instances)
+	assert.Equal(t, 2, summary.EC2Running)
+	assert.Equal(t, 1, summary.EC2Stopped)
+	assert.Equal(t, 2, summary.S3Buckets)
+	assert.NoError(t, summary.EC2Error)
+	assert.NoError(t, summary.S3Error)
+	assert.EqualError(t, summary.LambdaError, "access denied: your AWS credentials don't have permission to access Lambda")
+
+	view := model.View()
+	assert.Contains(t, view, "2 running, 1 stopped")
+	assert.Contains(t, view, "1 other")
+	assert.Contains(t, view, "Unavailable")
+	assert.NotContains(t, view, "Loading...")
+
+	// Verify expectations
+	listers.AssertExpectations(t)
+}
+
+// TestDashboardModelRefresh tests refreshing the dashboard.
+// It verifies that the model and its cards are loading until all the new
+// counts arrive, and that counts of an earlier load arriving late are dropped.
+func TestDashboardModelRefresh(t *testing.T) {
+	model := NewDashboardModel()
+	model.Init()
+	model.Update(DashboardS3Msg{Buckets: 3, Load: 1})
+	assert.Equal(t, 3, model.Summary().S3Buckets)
+	assert.True(t, model.IsLoading())
+
+	// Refreshing shows the cards as loading again
+	model.Init()
+	assert.True(t, model.IsLoading())
+	assert.Contains(t, model.View(), "Loading...")
+
+	// Counts of the first load are dropped
+	model.Update(DashboardEC2Msg{Total: 7, Load: 1})
+	assert.Equal(t, 0, model.Summary().EC2Instances)
+
+	model.Update(DashboardS3Msg{Buckets: 5, Load: 2})
+	model.Update(DashboardEC2Msg{Load: 2})
+	assert.True(t, model.IsLoading())
+	model.Update(DashboardLambdaMsg{Functions: 1, Load: 2})
+	assert.Equal(t, 5, model.Summary().S3Buckets)
+	assert.False(t, model.IsLoading())
+	assert.NotContains(t, model.View(), "Loading...")
+}
@@ -3,12 +3,13 @@ package models
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
-	"time"
 
+	"github.com/ao/awsm/internal/aws/client"
 	"github.com/ao/awsm/internal/aws/ec2"
 	"github.com/ao/awsm/internal/logger"
+	"github.com/ao/awsm/internal/tui/components"
+	"github.com/ao/awsm/internal/tui/theme"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,95 +21,56 @@ type EC2InstanceMsg struct {
 	Error     error
 }
 
+// EC2InstanceDetailMsg is a message containing the details of a single EC2 instance
+type EC2InstanceDetailMsg struct {
+	InstanceID string
+	Instance   *ec2.Instance
+	Error      error
+}
+
+// EC2ActionMsg is a message with the result of starting or stopping an EC2 instance
+type EC2ActionMsg struct {
+	InstanceID string
+	Action     string // "start" or "stop"
+	Error      error
+}
+
 // EC2Model represents the EC2 view
 type EC2Model struct {
 	BaseModel
-	title            string
-	instances        []ec2.Instance
-	selected         int
-	loading          bool
-	err              error
-	adapter          *ec2.Adapter
-	loadingStartTime time.Time
-	loadingTimeout   time.Duration
+	title       string
+	instances   []ec2.Instance
+	selected    int
+	adapter     *ec2.Adapter
+	confirmStop string            // ID of the instance waiting for the stop to be confirmed
+	pending     map[string]string // Transitional state of instances being started or stopped, by ID
+	status      string            // Result of the last start or stop
+	detailID    string            // ID of the instance whose details are loaded or being loaded
+	detail      *ec2.Instance     // The described instance, once loaded
+	detailErr   error             // Failure to describe the instance
 }
 
 // NewEC2Model creates a new EC2 model
 func NewEC2Model() *EC2Model {
 	return &EC2Model{
-		BaseModel:      NewBaseModel(),
-		title:          "EC2 Instances",
-		instances:      []ec2.Instance{},
-		selected:       0,
-		loading:        false,
-		loadingTimeout: 30 * time.Second, // Default timeout of 30 seconds
+		BaseModel: NewBaseModel(),
+		title:     "EC2 Instances",
+		instances: []ec2.Instance{},
+		pending:   make(map[string]string),
+		selected:  0,
 	}
 }
 
-// SetLoadingTimeout sets the timeout duration for loading operations
-func (m *EC2Model) SetLoadingTimeout(timeout time.Duration) {
-	m.loadingTimeout = timeout
-}
-
-// Init initializes the model
+// Init initializes the model, loading the instances in the background so that
+// the loading can time out
 func (m *EC2Model) Init() tea.Cmd {
 	logger.Debug("EC2Model.Init called")
-	m.loading = true
-	m.loadingStartTime = time.Now()
-
-	// Create a debug file to verify this function is being called
-	f, _ := os.Create("ec2_init_debug.log")
-	if f != nil {
-		f.WriteString("EC2Model.Init called\n")
-		f.Close()
-	}
-
-	// Directly call loadInstances and handle the result
-	result := m.loadInstances()
-
-	// Log the result
-	f2, _ := os.Create("ec2_init_result.log")
-	if f2 != nil {
-		if msg, ok := result.(EC2InstanceMsg); ok {
-			if msg.Error != nil {
-				f2.WriteString(fmt.Sprintf("Error: %v\n", msg.Error))
-			} else {
-				f2.WriteString(fmt.Sprintf("Instances: %d\n", len(msg.Instances)))
-			}
-		} else {
-			f2.WriteString(fmt.Sprintf("Unknown result type: %T\n", result))
-		}
-		f2.Close()
-	}
-
-	// Return a command that returns the result directly
-	return func() tea.Msg {
-		logger.Debug("Returning EC2InstanceMsg from Init command")
-		return result
-	}
-}
-
-// checkTimeout checks if the loading operation has timed out
-func (m *EC2Model) checkTimeout() tea.Msg {
-	if !m.loading || m.loadingStartTime.IsZero() {
-		return nil
-	}
 
-	// Check if we've exceeded the timeout
-	if time.Since(m.loadingStartTime) > m.loadingTimeout {
-		return TimeoutMsg{
-			Message: "Operation timed out",
-			Source:  "EC2Model",
-		}
-	}
+	// Init runs when the view is shown again or reloaded, possibly for another
+	// context, so a stop that was waiting for confirmation is cancelled
+	m.confirmStop = ""
 
-	// Schedule another check in 1 second
-	time.Sleep(1 * time.Second)
-
-	// Continue checking for timeout
-	return tea.Tick(time.Second, func(time.Time) tea.Msg {
-		return m.checkTimeout()
-	})
+	return tea.Batch(m.loadInstances, m.startLoading("EC2Model"))
 }
 
 // loadInstances loads EC2 instances
@@ -116,7 +78,7 @@ func (m *EC2Model) loadInstances() tea.Msg {
 	logger.Debug("EC2Model.loadInstances called")
 
 	// Set a timeout to ensure we don't get stuck in a loading state
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := client.WithTimeout(context.Background())
 	defer cancel()
 
 	// Create EC2 adapter if not already created
@@ -125,26 +87,7 @@ func (m *EC2Model) loadInstances() tea.Msg {
 		adapter, err := ec2.NewAdapter(ctx)
 		if err != nil {
 			logger.Error("Error creating EC2 adapter: %v", err)
-
-			// Return a more user-friendly error message
-			if strings.Contains(err.Error(), "InvalidAccessKeyId") {
-				errMsg := "invalid AWS credentials: the access key ID is invalid or expired"
-				logger.Error(errMsg)
-				return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-			} else if strings.Contains(err.Error(), "ExpiredToken") {
-				errMsg := "expired AWS credentials: please refresh your credentials"
-				logger.Error(errMsg)
-				return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-			} else if strings.Contains(err.Error(), "AccessDenied") {
-				errMsg := "access denied: your AWS credentials don't have permission to access EC2"
-				logger.Error(errMsg)
-				return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-			} else if strings.Contains(err.Error(), "context deadline exceeded") || strings.Contains(err.Error(), "timeout") {
-				errMsg := "connection timeout: unable to connect to AWS"
-				logger.Error(errMsg)
-				return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-			}
-			return EC2InstanceMsg{Error: err}
+			return EC2InstanceMsg{Error: friendlyEC2Error(err)}
 		}
 		logger.Debug("EC2 adapter created successfully")
 		m.adapter = adapter
@@ -155,25 +98,7 @@ func (m *EC2Model) loadInstances() tea.Msg {
 	instances, err := m.adapter.ListInstances(ctx, nil, 0)
 	if err != nil {
 		logger.Error("Error listing EC2 instances: %v", err)
-
-		// Return a more user-friendly error message
-		if strings.Contains(err.Error(), "InvalidAccessKeyId") {
-			errMsg := "invalid AWS credentials: the access key ID is invalid or expired"
-			logger.Error(errMsg)
-			return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-		} else if strings.Contains(err.Error(), "ExpiredToken") {
-			errMsg := "expired AWS credentials: please refresh your credentials"
-			logger.Error(errMsg)
-			return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-		} else if strings.Contains(err.Error(), "AccessDenied") {
-			errMsg := "access denied: your AWS credentials don't have permission to access EC2"
-			logger.Error(errMsg)
-			return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-		} else if strings.Contains(err.Error(), "context deadline exceeded") || strings.Contains(err.Error(), "timeout") {
-			errMsg := "connection timeout: unable to connect to AWS"
-			logger.Error(errMsg)
-			return EC2InstanceMsg{Error: fmt.Errorf(errMsg)}
-		}
+		err = friendlyEC2Error(err)
 	} else {
 		logger.Info("Found %d EC2 instances", len(instances))
 	}
@@ -184,6 +109,140 @@ func (m *EC2Model) loadInstances() tea.Msg {
 	}
 }
 
+// loadInstanceDetail loads the details of an EC2 instance, including its
+// Elastic IPs, which the instance list doesn't look up
+func (m *EC2Model) loadInstanceDetail(instanceID string) tea.Cmd {
+	return func() tea.Msg {
+		logger.Debug("EC2Model.loadInstanceDetail called for instance: %s", instanceID)
+
+		// Use the configured AWS timeout for describing the instance
+		ctx, cancel := client.WithTimeout(context.Background())
+		defer cancel()
+
+		if m.adapter == nil {
+			logger.Debug("Creating EC2 adapter")
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				logger.Error("Error creating EC2 adapter: %v", err)
+				return EC2InstanceDetailMsg{InstanceID: instanceID, Error: friendlyEC2Error(err)}
+			}
+			m.adapter = adapter
+		}
+
+		instance, err := m.adapter.DescribeInstance(ctx, instanceID)
+		if err != nil {
+			logger.Error("Error describing EC2 instance %s: %v", instanceID, err)
+			return EC2InstanceDetailMsg{InstanceID: instanceID, Error: friendlyEC2Error(err)}
+		}
+
+		return EC2InstanceDetailMsg{InstanceID: instanceID, Instance: instance}
+	}
+}
+
+// filterInstances shows the instances whose name (or ID, if they have none)
+// contains the filter, keeping the selection on a shown instance
+func (m *EC2Model) filterInstances() {
+	names := make([]string, len(m.instances))
+	for i, instance := range m.instances {
+		names[i] = instance.Name
+		if names[i] == "" {
+			names[i] = instance.ID
+		}
+	}
+	m.applyFilter(names)
+	m.selected = max(min(m.selected, len(m.filtered)-1), 0)
+}
+
+// ec2Columns are the headers of the instance table
+var ec2Columns = []string{"ID", "NAME", "STATE", "TYPE", "PUBLIC IP"}
+
+// sortInstances sorts instances by the column of the instance table with the
+// given index, in descending order if desc is set. A column of -1 leaves them
+// in the loaded order.
+func sortInstances(instances []ec2.Instance, column int, desc bool) {
+	var field func(ec2.Instance) string
+	switch column {
+	case 0:
+		field = func(i ec2.Instance) string { return i.ID }
+	case 1:
+		field = func(i ec2.Instance) string { return i.Name }
+	case 2:
+		field = func(i ec2.Instance) string { return i.State }
+	case 3:
+		field = func(i ec2.Instance) string { return i.Type }
+	case 4:
+		field = func(i ec2.Instance) string { return i.PublicIP }
+	default:
+		return
+	}
+	sortRows(instances, func(a, b ec2.Instance) int { return strings.Compare(field(a), field(b)) }, desc)
+}
+
+// applySort sorts the instances by the sort column, keeping the selected
+// instance selected
+func (m *EC2Model) applySort() {
+	selected, _ := m.selectedInstance()
+	sortInstances(m.instances, m.sortColumn, m.sortDesc)
+	m.filterInstances()
+	for row, i := range m.filtered {
+		if m.instances[i].ID == selected.ID {
+			m.selected = row
+		}
+	}
+}
+
+// yankValue returns the ID of the selected instance to copy to the clipboard
+func (m *EC2Model) yankValue() string {
+	instance, _ := m.selectedInstance()
+	return instance.ID
+}
+
+// selectedInstance returns the selected instance, and false if no instance is shown
+func (m *EC2Model) selectedInstance() (ec2.Instance, bool) {
+	if len(m.filtered) == 0 {
+		return ec2.Instance{}, false
+	}
+	return m.instances[m.filtered[m.selected]], true
+}
+
+// changeInstanceState starts or stops an EC2 instance
+func (m *EC2Model) changeInstanceState(instanceID, action string) tea.Cmd {
+	return func() tea.Msg {
+		logger.Debug("EC2Model.changeInstanceState: %s %s", action, instanceID)
+
+		ctx, cancel := client.WithTimeout(context.Background())
+		defer cancel()
+
+		if m.adapter == nil {
+			adapter, err := ec2.NewAdapter(ctx)
+			if err != nil {
+				logger.Error("Error creating EC2 adapter: %v", err)
+				return EC2ActionMsg{InstanceID: instanceID, Action: action, Error: friendlyEC2Error(err)}
+			}
+			m.adapter = adapter
+		}
+
+		var err error
+		if action == "start" {
+			err = m.adapter.StartInstance(ctx, instanceID)
+		} else {
+			err = m.adapter.StopInstance(ctx, instanceID)
+		}
+		if err != nil {
+			logger.Error("Error changing state of EC2 instance %s: %v", instanceID, err)
+			err = friendlyEC2Error(err)
+		}
+
+		return EC2ActionMsg{InstanceID: instanceID, Action: action, Error: err}
+	}
+}
+
+// friendlyEC2Error returns a more user-friendly error for common AWS errors,
+// or err itself
+func friendlyEC2Error(err error) error {
+	return friendlyError(err, "EC2")
+}
+
 // Update updates the model based on messages
 func (m *EC2Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -195,8 +254,37 @@ func (m *EC2Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		m.instances = msg.Instances
 		m.err = nil
+		sortInstances(m.instances, m.sortColumn, m.sortDesc)
+		m.filterInstances()
+		// The refreshed list shows the real state of instances being started or stopped
+		m.pending = make(map[string]string)
 		return m, nil
 
+	case EC2InstanceDetailMsg:
+		// Details of an instance that is no longer shown are dropped
+		if msg.InstanceID != m.detailID {
+			return m, nil
+		}
+		m.detail = msg.Instance
+		m.detailErr = msg.Error
+		return m, nil
+
+	case EC2ActionMsg:
+		if msg.Error != nil {
+			delete(m.pending, msg.InstanceID)
+			m.status = fmt.Sprintf("Error: %s", msg.Error)
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Requested %s of %s", msg.Action, msg.InstanceID)
+
+		// Refresh the list to show the new state
+		return m, m.loadInstances
+
+	case timeoutCheckMsg:
+		if msg.source == "EC2Model" {
+			return m, m.checkLoadingTimeout(msg)
+		}
+
 	case TimeoutMsg:
 		if msg.Source == "EC2Model" && m.loading {
 			m.loading = false
@@ -205,22 +293,62 @@ func (m *EC2Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		// While the stop is being confirmed, only y (or Enter) and n (or Esc) are handled
+		if m.confirmStop != "" {
+			instanceID := m.confirmStop
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.confirmStop = ""
+				m.pending[instanceID] = "stopping…"
+				m.status = ""
+				return m, m.changeInstanceState(instanceID, "stop")
+			case "n", "N", "esc":
+				m.confirmStop = ""
+			}
+			return m, nil
+		}
+
+		// Filter the instance list
+		if handled, changed := m.handleFilterKey(msg); handled {
+			if changed {
+				m.selected = 0
+				m.filterInstances()
+			}
+			return m, nil
+		}
+		if m.handleSortKey(msg, len(ec2Columns)) {
+			m.applySort()
+			return m, nil
+		}
+
 		// Handle key messages
+		instance, hasSelection := m.selectedInstance()
 		switch {
+		case key.Matches(msg, DefaultKeyMap().Start):
+			if !m.loading && hasSelection {
+				m.pending[instance.ID] = "starting…"
+				m.status = ""
+				return m, m.changeInstanceState(instance.ID, "start")
+			}
+		case key.Matches(msg, DefaultKeyMap().Stop):
+			if !m.loading && hasSelection {
+				// Stopping interrupts whatever runs on the instance, so ask first
+				m.confirmStop = instance.ID
+			}
+		case key.Matches(msg, DefaultKeyMap().Yank):
+			if value := m.yankValue(); value != "" {
+				return m, copyToClipboard(value)
+			}
 		case key.Matches(msg, DefaultKeyMap().Up):
 			if m.selected > 0 {
 				m.selected--
 			}
 		case key.Matches(msg, DefaultKeyMap().Down):
-			if m.selected < len(m.instances)-1 {
+			if m.selected < len(m.filtered)-1 {
 				m.selected++
 			}
-		case key.Matches(msg, DefaultKeyMap().Enter):
-			// View details of selected instance
-			// (In a real implementation, this would show a detailed view)
 		case key.Matches(msg, DefaultKeyMap().Refresh):
-			m.loading = true
-			return m, m.loadInstances
+			return m, tea.Batch(m.loadInstances, m.startLoading("EC2Model"))
 		}
 	}
 
@@ -232,63 +360,56 @@ func (m *EC2Model) View() string {
 	// Create a title
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1).
 		Render(fmt.Sprintf(" %s ", m.title))
 
 	// Create content
 	var content string
 	if m.loading {
-		elapsed := time.Since(m.loadingStartTime).Round(time.Second)
-		if elapsed > 5*time.Second {
-			content = fmt.Sprintf("Loading EC2 instances... (%s)", elapsed)
-		} else {
-			content = "Loading EC2 instances..."
-		}
+		content = m.loadingText("EC2 instances")
 	} else if m.err != nil {
 		content = fmt.Sprintf("Error: %s\n\nPress 'r' to retry or 'd' to go to dashboard", m.err.Error())
 	} else if len(m.instances) == 0 {
 		content = "No EC2 instances found"
+	} else if len(m.filtered) == 0 {
+		content = m.filterLine() + "No EC2 instances match the filter"
 	} else {
-		// Create a table header
-		header := lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Render("ID\tNAME\tSTATE\tTYPE\tPUBLIC IP")
-
-		// Create table rows
-		var rows []string
-		for i, instance := range m.instances {
-			style := lipgloss.NewStyle()
-			if i == m.selected {
-				style = style.
-					Bold(true).
-					Foreground(lipgloss.Color("#FFFFFF")).
-					Background(lipgloss.Color("#0066cc"))
+		// Create the table of the instances matching the filter
+		rows := make([][]string, 0, len(m.filtered))
+		for _, i := range m.filtered {
+			instance := m.instances[i]
+			state := instance.State
+			if pending, ok := m.pending[instance.ID]; ok {
+				state = pending
 			}
-
-			row := style.Render(fmt.Sprintf(
-				"%s\t%s\t%s\t%s\t%s",
+			rows = append(rows, []string{
 				instance.ID,
 				instance.Name,
-				instance.State,
+				state,
 				instance.Type,
 				instance.PublicIP,
-			))
-			rows = append(rows, row)
+			})
+		}
+		table := components.NewTable(m.sortHeaders(ec2Columns), rows, m.selected, theme.Current())
+		table.SetSize(m.tableSize())
+		for row, i := range m.filtered {
+			if _, ok := m.pending[m.instances[i].ID]; ok {
+				table.Highlight(row)
+			}
 		}
+		content = m.filterLine() + table.Render()
 
-		// Combine header and rows
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			header,
-			strings.Join(rows, "\n"),
-		)
+		if m.confirmStop != "" {
+			content += "\n\n" + renderStopConfirmation(m.confirmStop)
+		} else if m.status != "" {
+			content += "\n\n" + m.status
+		}
 	}
 
 	// Add help text
-	helpText := "\nPress ↑/↓ to navigate, Enter to view details, r to refresh, ? for help"
+	helpText := "\nPress ↑/↓ to navigate, / to filter, o/O to sort, y to copy the ID, Enter to view details, s to start, x to stop, r to refresh, ? for help"
 
 	// Style the content
 	styledContent := lipgloss.NewStyle().
@@ -303,6 +424,80 @@ func (m *EC2Model) View() string {
 	)
 }
 
+// LoadDetail starts describing the instance in the given row of the table, so
+// that its details include its Elastic IPs and its current state
+func (m *EC2Model) LoadDetail(selected int) tea.Cmd {
+	if m.confirmStop != "" || selected < 0 || selected >= len(m.filtered) {
+		return nil
+	}
+	m.detailID = m.instances[m.filtered[selected]].ID
+	m.detail = nil
+	m.detailErr = nil
+	return m.loadInstanceDetail(m.detailID)
+}
+
+// Detail returns the details of the instance in the given row of the table:
+// those of the list until the instance has been described. While a stop is
+// being confirmed it returns nothing, so that Enter confirms it.
+func (m *EC2Model) Detail(selected int) (string, error) {
+	if m.confirmStop != "" || selected < 0 || selected >= len(m.filtered) {
+		return "", nil
+	}
+	instance := m.instances[m.filtered[selected]]
+	if instance.ID != m.detailID {
+		return renderInstanceDetail(instance, ""), nil
+	}
+
+	switch {
+	case m.detailErr != nil:
+		return "", fmt.Errorf("failed to describe instance %s: %w", instance.ID, m.detailErr)
+	case m.detail == nil:
+		return renderInstanceDetail(instance, "loading…"), nil
+	}
+	elasticIPs := make([]string, 0, len(m.detail.ElasticIPs))
+	for _, eip := range m.detail.ElasticIPs {
+		elasticIPs = append(elasticIPs, eip.PublicIP)
+	}
+	return renderInstanceDetail(*m.detail, strings.Join(elasticIPs, ", ")), nil
+}
+
+// Selected returns the selected row of the instance table
+func (m *EC2Model) Selected() int {
+	return m.selected
+}
+
+// renderStopConfirmation renders the overlay asking to confirm stopping an instance
+func renderStopConfirmation(instanceID string) string {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Current().Warning).
+		Padding(0, 1).
+		Render(fmt.Sprintf("Stop instance %s? (y/n)", instanceID))
+}
+
+// renderInstanceDetail renders the fields of an instance as labeled lines, with
+// the given Elastic IPs since only a described instance has them
+func renderInstanceDetail(instance ec2.Instance, elasticIPs string) string {
+	lines := []string{
+		detailLine("ID", instance.ID),
+		detailLine("Name", instance.Name),
+		detailLine("State", instance.State),
+		detailLine("Type", instance.Type),
+		detailLine("AZ", instance.AZ),
+		detailLine("VPC", instance.VpcID),
+		detailLine("Subnet", instance.SubnetID),
+		detailLine("Public IP", instance.PublicIP),
+		detailLine("Private IP", instance.PrivateIP),
+		detailLine("Elastic IPs", elasticIPs),
+		detailLine("Launch time", instance.LaunchTime),
+		detailLine("Security groups", strings.Join(instance.SecurityIDs, ", ")),
+		"",
+	}
+	lines = append(lines, detailMap("Tags", instance.Tags)...)
+
+	return strings.Join(lines, "\n")
+}
+
 // ShortHelp returns the short help text
 func (m *EC2Model) ShortHelp() []key.Binding {
 	return []key.Binding{
@@ -311,7 +506,13 @@ func (m *EC2Model) ShortHelp() []key.Binding {
 		DefaultKeyMap().Up,
 		DefaultKeyMap().Down,
 		DefaultKeyMap().Enter,
+		DefaultKeyMap().Filter,
+		DefaultKeyMap().Yank,
+		DefaultKeyMap().Sort,
+		DefaultKeyMap().Start,
+		DefaultKeyMap().Stop,
 		DefaultKeyMap().Refresh,
+		DefaultKeyMap().AutoRefresh,
 		DefaultKeyMap().Dashboard,
 		DefaultKeyMap().Command,
 	}
@@ -329,9 +530,18 @@ func (m *EC2Model) FullHelp() [][]key.Binding {
 			DefaultKeyMap().Up,
 			DefaultKeyMap().Down,
 			DefaultKeyMap().Enter,
+			DefaultKeyMap().Filter,
+			DefaultKeyMap().Yank,
+			DefaultKeyMap().Sort,
+			DefaultKeyMap().SortOrder,
+		},
+		{
+			DefaultKeyMap().Start,
+			DefaultKeyMap().Stop,
 		},
 		{
 			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Dashboard,
 		},
 	}
@@ -0,0 +1,452 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/ao/awsm/internal/clock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEC2Client implements the ec2.EC2Client interface for testing purposes.
+// It uses the testify/mock package to mock AWS EC2 API calls.
+type mockEC2Client struct {
+	mock.Mock
+}
+
+func (m *mockEC2Client) DescribeInstances(ctx context.Context, params *awsec2.DescribeInstancesInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) StartInstances(ctx context.Context, params *awsec2.StartInstancesInput, optFns ...func(*awsec2.Options)) (*awsec2.StartInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.StartInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) StopInstances(ctx context.Context, params *awsec2.StopInstancesInput, optFns ...func(*awsec2.Options)) (*awsec2.StopInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.StopInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) RebootInstances(ctx context.Context, params *awsec2.RebootInstancesInput, optFns ...func(*awsec2.Options)) (*awsec2.RebootInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.RebootInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) CreateTags(ctx context.Context, params *awsec2.CreateTagsInput, optFns ...func(*awsec2.Options)) (*awsec2.CreateTagsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.CreateTagsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeKeyPairs(ctx context.Context, params *awsec2.DescribeKeyPairsInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeKeyPairsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeKeyPairsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeInstanceTypes(ctx context.Context, params *awsec2.DescribeInstanceTypesInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeInstanceTypesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeInstanceTypesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeAddresses(ctx context.Context, params *awsec2.DescribeAddressesInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeAddressesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeAddressesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeVpcs(ctx context.Context, params *awsec2.DescribeVpcsInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeVpcsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeVpcsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeSubnets(ctx context.Context, params *awsec2.DescribeSubnetsInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeSubnetsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeSubnetsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeSecurityGroups(ctx context.Context, params *awsec2.DescribeSecurityGroupsInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeSecurityGroupsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeSecurityGroupsOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeVolumes(ctx context.Context, params *awsec2.DescribeVolumesInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeVolumesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeVolumesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeReservedInstances(ctx context.Context, params *awsec2.DescribeReservedInstancesInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeReservedInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeReservedInstancesOutput), args.Error(1)
+}
+
+func (m *mockEC2Client) DescribeInstanceStatus(ctx context.Context, params *awsec2.DescribeInstanceStatusInput, optFns ...func(*awsec2.Options)) (*awsec2.DescribeInstanceStatusOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*awsec2.DescribeInstanceStatusOutput), args.Error(1)
+}
+
+// This static assertion verifies at compile time that mockEC2Client implements the ec2.EC2Client interface.
+var _ ec2.EC2Client = (*mockEC2Client)(nil)
+
+// TestEC2ModelDetail tests the details of the selected EC2 instance.
+// It verifies that Detail renders all fields of the instance in the given
+// row, and that it returns nothing while a stop is being confirmed so that
+// Enter confirms it.
+func TestEC2ModelDetail(t *testing.T) {
+	model := NewEC2Model()
+
+	// Receive instances and select the second one
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{
+		{ID: "i-1"},
+		{
+			ID:          "i-2",
+			Name:        "web-2",
+			State:       "running",
+			Type:        "t3.micro",
+			AZ:          "us-east-1a",
+			VpcID:       "vpc-123",
+			SubnetID:    "subnet-123",
+			PrivateIP:   "10.0.0.2",
+			PublicIP:    "3.3.3.3",
+			SecurityIDs: []string{"sg-123"},
+			Tags:        map[string]string{"Name": "web-2", "team": "payments"},
+		},
+	}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 1, model.Selected())
+
+	// Call the function
+	detail, err := model.Detail(model.Selected())
+
+	// Assert no error
+	require.NoError(t, err)
+	for _, want := range []string{"i-2", "web-2", "us-east-1a", "vpc-123", "subnet-123", "10.0.0.2", "3.3.3.3", "sg-123", "team = payments"} {
+		assert.True(t, strings.Contains(detail, want), "detail should contain %q", want)
+	}
+
+	// Rows out of range have no details
+	detail, err = model.Detail(2)
+	require.NoError(t, err)
+	assert.Empty(t, detail)
+
+	// While a stop is being confirmed, Enter is left to the model
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	detail, err = model.Detail(model.Selected())
+	require.NoError(t, err)
+	assert.Empty(t, detail)
+}
+
+// TestEC2ModelDetailView tests loading the details of the selected EC2 instance.
+// It verifies that LoadDetail describes the selected instance, that Detail
+// shows the list's fields until the instance has been described and then all
+// fields of the described instance with its Elastic IPs, and that a failure
+// to describe it is returned.
+func TestEC2ModelDetailView(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Set up expectations
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *awsec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-2"
+	}), mock.Anything).Return(&awsec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{
+			Instances: []types.Instance{{
+				InstanceId:       aws.String("i-2"),
+				InstanceType:     types.InstanceTypeT3Micro,
+				State:            &types.InstanceState{Name: types.InstanceStateNameRunning},
+				Placement:        &types.Placement{AvailabilityZone: aws.String("us-east-1a")},
+				VpcId:            aws.String("vpc-123"),
+				SubnetId:         aws.String("subnet-123"),
+				PrivateIpAddress: aws.String("10.0.0.2"),
+				PublicIpAddress:  aws.String("3.3.3.3"),
+				SecurityGroups:   []types.GroupIdentifier{{GroupId: aws.String("sg-123")}},
+				Tags: []types.Tag{
+					{Key: aws.String("Name"), Value: aws.String("web-2")},
+					{Key: aws.String("team"), Value: aws.String("payments")},
+				},
+			}},
+		}},
+	}, nil).Once()
+	mockClient.On("DescribeAddresses", mock.Anything, mock.Anything, mock.Anything).Return(&awsec2.DescribeAddressesOutput{
+		Addresses: []types.Address{{
+			InstanceId:   aws.String("i-2"),
+			PublicIp:     aws.String("5.5.5.5"),
+			AllocationId: aws.String("eipalloc-123"),
+		}},
+	}, nil).Once()
+
+	model := NewEC2Model()
+	model.adapter = ec2.NewAdapterWithClient(mockClient)
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1"}, {ID: "i-2", Name: "web-2"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// Call the function
+	cmd := model.LoadDetail(model.Selected())
+	require.NotNil(t, cmd)
+
+	// The list's fields are shown while the instance is described
+	detail, err := model.Detail(model.Selected())
+	require.NoError(t, err)
+	assert.Contains(t, detail, "web-2")
+	assert.Contains(t, detail, "loading…")
+
+	// Run the command that describes the instance
+	model.Update(cmd())
+
+	// Assert the described instance is shown
+	detail, err = model.Detail(model.Selected())
+	require.NoError(t, err)
+	for _, want := range []string{"i-2", "web-2", "running", "us-east-1a", "vpc-123", "subnet-123", "10.0.0.2", "3.3.3.3", "5.5.5.5", "sg-123", "team = payments"} {
+		assert.True(t, strings.Contains(detail, want), "detail should contain %q", want)
+	}
+
+	// A failure to describe the instance is returned
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything, mock.Anything).
+		Return((*awsec2.DescribeInstancesOutput)(nil), errors.New("throttled")).Once()
+	model.Update(model.LoadDetail(model.Selected())())
+	_, err = model.Detail(model.Selected())
+	assert.ErrorContains(t, err, "throttled")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestEC2ModelStopInstance tests stopping an instance from the EC2 view.
+// It verifies that x asks for confirmation, that confirming stops the selected
+// instance through the adapter, and that the instance shows as stopping until
+// the list is refreshed.
+func TestEC2ModelStopInstance(t *testing.T) {
+	// Create mock client
+	mockClient := new(mockEC2Client)
+
+	// Set up expectations
+	mockClient.On("StopInstances", mock.Anything, mock.MatchedBy(func(input *awsec2.StopInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-2"
+	}), mock.Anything).Return(&awsec2.StopInstancesOutput{}, nil)
+
+	model := NewEC2Model()
+	model.adapter = ec2.NewAdapterWithClient(mockClient)
+
+	// Receive instances and select the second one
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1", State: "running"}, {ID: "i-2", State: "running"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// x asks for confirmation without stopping anything
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.Nil(t, cmd)
+	assert.Contains(t, model.View(), "Stop instance i-2? (y/n)")
+
+	// n cancels
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.NotContains(t, model.View(), "Stop instance i-2?")
+
+	// Call the function
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	require.NotNil(t, cmd)
+	assert.Contains(t, model.View(), "stopping…")
+
+	// Run the command that stops the instance
+	msg := cmd()
+	action, ok := msg.(EC2ActionMsg)
+	require.True(t, ok)
+
+	// Assert no error
+	require.NoError(t, action.Error)
+	assert.Equal(t, "i-2", action.InstanceID)
+	assert.Equal(t, "stop", action.Action)
+
+	// The model refreshes the list, still showing the transitional state
+	_, cmd = model.Update(msg)
+	assert.NotNil(t, cmd)
+	assert.Contains(t, model.View(), "stopping…")
+
+	// The refreshed list shows the new state
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1", State: "running"}, {ID: "i-2", State: "stopping"}}})
+	assert.NotContains(t, model.View(), "stopping…")
+
+	// Verify expectations
+	mockClient.AssertExpectations(t)
+}
+
+// TestEC2ModelStopConfirmationReset tests leaving a stop confirmation open.
+// It verifies that showing the view again, which calls Init, cancels the stop.
+func TestEC2ModelStopConfirmationReset(t *testing.T) {
+	model := NewEC2Model()
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1", State: "running"}}})
+
+	// x asks for confirmation
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.Contains(t, model.View(), "Stop instance i-1? (y/n)")
+
+	// Call the function, as when switching back to the view
+	model.Init()
+
+	// Assert the confirmation is gone
+	assert.Empty(t, model.confirmStop)
+	assert.NotContains(t, model.View(), "Stop instance i-1?")
+}
+
+// TestEC2ModelFilter tests filtering the instance list.
+// It verifies that only the instances whose name contains the filter are
+// shown, that unnamed instances are matched by ID, and that Esc clears it.
+func TestEC2ModelFilter(t *testing.T) {
+	model := NewEC2Model()
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{
+		{ID: "i-0aaa", Name: "web-1"},
+		{ID: "i-0bbb", Name: "db-1"},
+		{ID: "i-0ccc"},
+	}})
+
+	// Call the function
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("WEB")})
+
+	// Assert only the matching instance is shown
+	view := model.View()
+	assert.Contains(t, view, "web-1")
+	assert.NotContains(t, view, "db-1")
+	assert.NotContains(t, view, "i-0ccc")
+
+	// Unnamed instances are matched by ID
+	model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ccc")})
+	view = model.View()
+	assert.Contains(t, view, "i-0ccc")
+	assert.NotContains(t, view, "web-1")
+
+	// Esc clears the filter
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	view = model.View()
+	for _, want := range []string{"web-1", "db-1", "i-0ccc"} {
+		assert.Contains(t, view, want)
+	}
+}
+
+// TestEC2ModelSort tests sorting the instance list.
+// It verifies that o sorts by the next column, O reverses the order, the
+// header shows the sort column, and the selection follows the instance.
+func TestEC2ModelSort(t *testing.T) {
+	model := NewEC2Model()
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{
+		{ID: "i-0bbb", Name: "web", State: "running", Type: "t3.large"},
+		{ID: "i-0ccc", Name: "api", State: "stopped", Type: "t3.micro"},
+		{ID: "i-0aaa", Name: "db", State: "pending", Type: "m5.large"},
+	}})
+
+	// Select the second instance in the loaded order (api)
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// Call the function: sort by ID, then by name
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+
+	// Assert the rows are ordered by name and the header shows it
+	view := model.View()
+	assert.Contains(t, view, "NAME ↑")
+	assertOrder(t, view, "api", "db", "web")
+
+	// The selection followed the instance
+	instance, ok := model.selectedInstance()
+	require.True(t, ok)
+	assert.Equal(t, "i-0ccc", instance.ID)
+
+	// O reverses the order
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	view = model.View()
+	assert.Contains(t, view, "NAME ↓")
+	assertOrder(t, view, "web", "db", "api")
+
+	// Refreshed instances are sorted too
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1", Name: "alpha"}, {ID: "i-2", Name: "zulu"}}})
+	assertOrder(t, model.View(), "zulu", "alpha")
+}
+
+// assertOrder asserts that each of the strings appears in the view after the previous one
+func assertOrder(t *testing.T, view string, want ...string) {
+	t.Helper()
+	last := -1
+	for _, s := range want {
+		i := strings.Index(view, s)
+		require.NotEqual(t, -1, i, "view should contain %q", s)
+		assert.Greater(t, i, last, "%q should come after %v", s, want)
+		last = i
+	}
+}
+
+// TestEC2ModelYankValue tests the value copied with y in the EC2 view.
+// It verifies that the ID of the selected instance is copied.
+func TestEC2ModelYankValue(t *testing.T) {
+	model := NewEC2Model()
+	assert.Empty(t, model.yankValue())
+
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-12345", Name: "web"}, {ID: "i-67890", Name: "db"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// Call the function
+	assert.Equal(t, "i-67890", model.yankValue())
+}
+
+// TestEC2ModelLoadingTimeout tests the loading timeout of the EC2 view.
+// It verifies, with a fake clock, that loading times out only once the timeout
+// has passed, and that checks of an earlier or finished loading are dropped.
+func TestEC2ModelLoadingTimeout(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	model := NewEC2Model()
+	model.SetClock(clk)
+	model.SetLoadingTimeout(10 * time.Second)
+
+	// Init starts loading the instances (the load itself is not run)
+	assert.NotNil(t, model.Init())
+	assert.True(t, model.IsLoading())
+	check := timeoutCheckMsg{source: "EC2Model", load: 1}
+
+	// Within the timeout, the next check is scheduled
+	clk.Advance(5 * time.Second)
+	_, cmd := model.Update(check)
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.Contains(t, model.View(), "Loading EC2 instances...")
+	assert.NotContains(t, model.View(), "(5s)")
+
+	// The time spent loading is shown after 5 seconds
+	clk.Advance(time.Second)
+	assert.Contains(t, model.View(), "Loading EC2 instances... (6s)")
+
+	// Past the timeout, the check reports it
+	clk.Advance(5 * time.Second)
+	_, cmd = model.Update(check)
+	require.NotNil(t, cmd)
+	msg := cmd()
+	assert.Equal(t, TimeoutMsg{Message: "Operation timed out after 10s", Source: "EC2Model"}, msg)
+
+	model.Update(msg)
+	assert.False(t, model.IsLoading())
+	assert.EqualError(t, model.GetError(), "operation timed out after 10s")
+
+	// Refreshing starts a new loading, which checks of the first one don't time out
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.NoError(t, model.GetError())
+	clk.Advance(time.Minute)
+	_, cmd = model.Update(check)
+	assert.Nil(t, cmd)
+
+	// Once loaded, checks stop
+	model.Update(EC2InstanceMsg{Instances: []ec2.Instance{{ID: "i-1", State: "running"}}})
+	_, cmd = model.Update(timeoutCheckMsg{source: "EC2Model", load: 2})
+	assert.Nil(t, cmd)
+	assert.False(t, model.IsLoading())
+}
@@ -1,14 +1,18 @@
 package models
 
 import (
+	"cmp"
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/ao/awsm/internal/aws/client"
 	"github.com/ao/awsm/internal/aws/lambda"
 	"github.com/ao/awsm/internal/logger"
+	"github.com/ao/awsm/internal/tui/components"
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/ao/awsm/internal/utils"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -26,106 +30,59 @@ type LambdaLogMsg struct {
 	Error error
 }
 
+// LambdaLogTailMsg is a message containing a new log event of the function whose logs are viewed
+type LambdaLogTailMsg struct {
+	Function string
+	Event    lambda.LogEvent
+}
+
+// LambdaLogTailErrorMsg is a message containing an error polling the logs of the function whose logs are viewed
+type LambdaLogTailErrorMsg struct {
+	Function string
+	Error    error
+}
+
+// maxTailedLogs is the number of log events kept in the log view while tailing
+const maxTailedLogs = 1000
+
 // LambdaModel represents the Lambda view
 type LambdaModel struct {
 	BaseModel
-	title            string
-	functions        []lambda.Function
-	logs             []lambda.LogEvent
-	selected         int
-	viewingLogs      bool
-	currentFunction  string
-	loading          bool
-	err              error
-	adapter          *lambda.Adapter
-	loadingStartTime time.Time
-	loadingTimeout   time.Duration
+	title           string
+	functions       []lambda.Function
+	logs            []lambda.LogEvent
+	selected        int
+	viewingLogs     bool
+	currentFunction string
+	adapter         *lambda.Adapter
+	tailEvents      <-chan lambda.LogEvent // New log events while viewing logs
+	tailErrs        <-chan error           // Polling errors while viewing logs
+	tailCancel      context.CancelFunc     // Stops tailing the logs
 }
 
 // NewLambdaModel creates a new Lambda model
 func NewLambdaModel() *LambdaModel {
 	return &LambdaModel{
-		BaseModel:      NewBaseModel(),
-		title:          "Lambda Functions",
-		functions:      []lambda.Function{},
-		logs:           []lambda.LogEvent{},
-		selected:       0,
-		viewingLogs:    false,
-		loading:        false,
-		loadingTimeout: 30 * time.Second, // Default timeout of 30 seconds
+		BaseModel:   NewBaseModel(),
+		title:       "Lambda Functions",
+		functions:   []lambda.Function{},
+		logs:        []lambda.LogEvent{},
+		selected:    0,
+		viewingLogs: false,
 	}
 }
 
-// SetLoadingTimeout sets the timeout duration for loading operations
-func (m *LambdaModel) SetLoadingTimeout(timeout time.Duration) {
-	m.loadingTimeout = timeout
-}
-
-// Init initializes the model
+// Init initializes the model, loading the functions in the background so that
+// the loading can time out
 func (m *LambdaModel) Init() tea.Cmd {
 	logger.Debug("LambdaModel.Init called")
-	m.loading = true
-	m.loadingStartTime = time.Now()
-
-	// Create a debug file to verify this function is being called
-	f, _ := os.Create("lambda_init_debug.log")
-	if f != nil {
-		f.WriteString("LambdaModel.Init called\n")
-		f.Close()
-	}
-
-	// Directly call loadFunctions and handle the result
-	result := m.loadFunctions()
-
-	// Log the result
-	f2, _ := os.Create("lambda_init_result.log")
-	if f2 != nil {
-		if msg, ok := result.(LambdaFunctionMsg); ok {
-			if msg.Error != nil {
-				f2.WriteString(fmt.Sprintf("Error: %v\n", msg.Error))
-			} else {
-				f2.WriteString(fmt.Sprintf("Functions: %d\n", len(msg.Functions)))
-			}
-		} else {
-			f2.WriteString(fmt.Sprintf("Unknown result type: %T\n", result))
-		}
-		f2.Close()
-	}
-
-	// Return a command that returns the result directly
-	return func() tea.Msg {
-		logger.Debug("Returning LambdaFunctionMsg from Init command")
-		return result
-	}
-}
-
-// checkTimeout checks if the loading operation has timed out
-func (m *LambdaModel) checkTimeout() tea.Msg {
-	if !m.loading || m.loadingStartTime.IsZero() {
-		return nil
-	}
-
-	// Check if we've exceeded the timeout
-	if time.Since(m.loadingStartTime) > m.loadingTimeout {
-		return TimeoutMsg{
-			Message: "Operation timed out",
-			Source:  "LambdaModel",
-		}
-	}
-
-	// Schedule another check in 1 second
-	time.Sleep(1 * time.Second)
-
-	// Continue checking for timeout
-	return tea.Tick(time.Second, func(time.Time) tea.Msg {
-		return m.checkTimeout()
-	})
+	return tea.Batch(m.loadFunctions, m.startLoading("LambdaModel"))
 }
 
 // loadFunctions loads Lambda functions
 func (m *LambdaModel) loadFunctions() tea.Msg {
 	// Set a timeout to ensure we don't get stuck in a loading state
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := client.WithTimeout(context.Background())
 	defer cancel()
 
 	// Create Lambda adapter if not already created
@@ -176,7 +133,7 @@ func (m *LambdaModel) loadLogs() tea.Cmd {
 		logger.Debug("LambdaModel.loadLogs called for function: %s", m.currentFunction)
 
 		// Set a timeout to ensure we don't get stuck in a loading state
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := client.WithTimeout(context.Background())
 		defer cancel()
 
 		if m.adapter == nil {
@@ -211,7 +168,7 @@ func (m *LambdaModel) loadLogs() tea.Cmd {
 
 		// Get logs for the current function (last 100 events)
 		logger.Debug("Getting logs for function: %s", m.currentFunction)
-		logs, err := m.adapter.GetFunctionLogs(ctx, m.currentFunction, time.Time{}, 100)
+		logs, err := m.adapter.GetFunctionLogs(ctx, m.currentFunction, time.Time{}, time.Time{}, 100)
 		if err != nil {
 			logger.Error("Error getting logs for function %s: %v", m.currentFunction, err)
 
@@ -245,6 +202,129 @@ func (m *LambdaModel) loadLogs() tea.Cmd {
 	}
 }
 
+// startTail starts following new log events of the current function
+func (m *LambdaModel) startTail() tea.Cmd {
+	m.stopTail()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.tailCancel = cancel
+	m.tailEvents, m.tailErrs = m.adapter.TailFunctionLogs(ctx, m.currentFunction, lambda.DefaultTailInterval)
+
+	return tea.Batch(
+		waitForLogTail(m.currentFunction, m.tailEvents),
+		waitForLogTailError(m.currentFunction, m.tailErrs),
+	)
+}
+
+// stopTail stops following log events
+func (m *LambdaModel) stopTail() {
+	if m.tailCancel != nil {
+		m.tailCancel()
+		m.tailCancel = nil
+		m.tailEvents = nil
+		m.tailErrs = nil
+	}
+}
+
+// waitForLogTail waits for the next tailed log event of a function
+func waitForLogTail(function string, events <-chan lambda.LogEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return LambdaLogTailMsg{Function: function, Event: event}
+	}
+}
+
+// waitForLogTailError waits for the next polling error of a function's log tail
+func waitForLogTailError(function string, errs <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		err, ok := <-errs
+		if !ok {
+			return nil
+		}
+		return LambdaLogTailErrorMsg{Function: function, Error: err}
+	}
+}
+
+// lambdaColumns are the headers of the function table
+var lambdaColumns = []string{"NAME", "RUNTIME", "MEMORY", "TIMEOUT", "CODE SIZE", "LAST MODIFIED"}
+
+// sortFunctions sorts functions by the column of the function table with the
+// given index, in descending order if desc is set. A column of -1 leaves them
+// in the loaded order.
+func sortFunctions(functions []lambda.Function, column int, desc bool) {
+	var compare func(a, b lambda.Function) int
+	switch column {
+	case 0:
+		compare = func(a, b lambda.Function) int { return strings.Compare(a.Name, b.Name) }
+	case 1:
+		compare = func(a, b lambda.Function) int { return strings.Compare(a.Runtime, b.Runtime) }
+	case 2:
+		compare = func(a, b lambda.Function) int { return cmp.Compare(a.Memory, b.Memory) }
+	case 3:
+		compare = func(a, b lambda.Function) int { return cmp.Compare(a.Timeout, b.Timeout) }
+	case 4:
+		compare = func(a, b lambda.Function) int { return cmp.Compare(a.Size, b.Size) }
+	case 5:
+		compare = func(a, b lambda.Function) int { return strings.Compare(a.LastModified, b.LastModified) }
+	default:
+		return
+	}
+	sortRows(functions, compare, desc)
+}
+
+// applySort sorts the functions by the sort column, keeping the selected
+// function selected
+func (m *LambdaModel) applySort() {
+	selected := ""
+	if len(m.filtered) > 0 {
+		selected = m.functions[m.filtered[m.selected]].Name
+	}
+	sortFunctions(m.functions, m.sortColumn, m.sortDesc)
+	m.filterFunctions()
+	for row, i := range m.filtered {
+		if m.functions[i].Name == selected {
+			m.selected = row
+		}
+	}
+}
+
+// yankValue returns the ARN of the selected function, or of the function whose
+// logs are shown, to copy to the clipboard
+func (m *LambdaModel) yankValue() string {
+	var function lambda.Function
+	if m.viewingLogs {
+		for _, f := range m.functions {
+			if f.Name == m.currentFunction {
+				function = f
+			}
+		}
+		if function.Name == "" {
+			return m.currentFunction
+		}
+	} else if len(m.filtered) > 0 {
+		function = m.functions[m.filtered[m.selected]]
+	}
+
+	if function.ARN == "" {
+		return function.Name
+	}
+	return function.ARN
+}
+
+// filterFunctions shows the functions whose name contains the filter, keeping
+// the selection on a shown function
+func (m *LambdaModel) filterFunctions() {
+	names := make([]string, len(m.functions))
+	for i, function := range m.functions {
+		names[i] = function.Name
+	}
+	m.applyFilter(names)
+	m.selected = max(min(m.selected, len(m.filtered)-1), 0)
+}
+
 // Update updates the model based on messages
 func (m *LambdaModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -256,6 +336,8 @@ func (m *LambdaModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		m.functions = msg.Functions
 		m.err = nil
+		sortFunctions(m.functions, m.sortColumn, m.sortDesc)
+		m.filterFunctions()
 		return m, nil
 
 	case LambdaLogMsg:
@@ -266,8 +348,41 @@ func (m *LambdaModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		m.logs = msg.Logs
 		m.err = nil
+
+		// Keep the log view up to date while it is open
+		if m.viewingLogs && m.adapter != nil {
+			return m, m.startTail()
+		}
 		return m, nil
 
+	case LambdaLogTailMsg:
+		// Ignore events of a tail that has been stopped
+		if !m.viewingLogs || msg.Function != m.currentFunction || m.tailEvents == nil {
+			return m, nil
+		}
+		m.logs = append(m.logs, msg.Event)
+		if len(m.logs) > maxTailedLogs {
+			m.logs = m.logs[len(m.logs)-maxTailedLogs:]
+		}
+		return m, waitForLogTail(m.currentFunction, m.tailEvents)
+
+	case LambdaLogTailErrorMsg:
+		// Ignore errors of a tail that has been stopped
+		if !m.viewingLogs || msg.Function != m.currentFunction || m.tailErrs == nil {
+			return m, nil
+		}
+		// Polling errors don't stop the tail, so show them and keep waiting
+		status := StatusMsg{Text: fmt.Sprintf("Error tailing logs: %v", msg.Error)}
+		return m, tea.Batch(
+			func() tea.Msg { return status },
+			waitForLogTailError(m.currentFunction, m.tailErrs),
+		)
+
+	case timeoutCheckMsg:
+		if msg.source == "LambdaModel" {
+			return m, m.checkLoadingTimeout(msg)
+		}
+
 	case TimeoutMsg:
 		if msg.Source == "LambdaModel" && m.loading {
 			m.loading = false
@@ -276,8 +391,27 @@ func (m *LambdaModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		// Filter the function list
+		if !m.viewingLogs {
+			if handled, changed := m.handleFilterKey(msg); handled {
+				if changed {
+					m.selected = 0
+					m.filterFunctions()
+				}
+				return m, nil
+			}
+			if m.handleSortKey(msg, len(lambdaColumns)) {
+				m.applySort()
+				return m, nil
+			}
+		}
+
 		// Handle key messages
 		switch {
+		case key.Matches(msg, DefaultKeyMap().Yank):
+			if value := m.yankValue(); value != "" {
+				return m, copyToClipboard(value)
+			}
 		case key.Matches(msg, DefaultKeyMap().Up):
 			if m.viewingLogs {
 				// No selection in logs view
@@ -290,33 +424,31 @@ func (m *LambdaModel) Update(msg tea.Msg) (Model, tea.Cmd) {
 			if m.viewingLogs {
 				// No selection in logs view
 			} else {
-				if m.selected < len(m.functions)-1 {
+				if m.selected < len(m.filtered)-1 {
 					m.selected++
 				}
 			}
-		case key.Matches(msg, DefaultKeyMap().Enter):
-			if !m.viewingLogs && len(m.functions) > 0 {
+		case key.Matches(msg, DefaultKeyMap().Logs):
+			if !m.viewingLogs && len(m.filtered) > 0 {
 				// View logs for the selected function
 				m.viewingLogs = true
-				m.currentFunction = m.functions[m.selected].Name
+				m.currentFunction = m.functions[m.filtered[m.selected]].Name
 				m.title = fmt.Sprintf("Lambda Logs: %s", m.currentFunction)
-				m.loading = true
-				return m, m.loadLogs()
+				return m, tea.Batch(m.loadLogs(), m.startLoading("LambdaModel"))
 			}
 		case key.Matches(msg, DefaultKeyMap().Escape):
 			if m.viewingLogs {
 				// Go back to function list
+				m.stopTail()
 				m.viewingLogs = false
 				m.title = "Lambda Functions"
 			}
 		case key.Matches(msg, DefaultKeyMap().Refresh):
-			m.loading = true
 			if m.viewingLogs {
-				return m, m.loadLogs()
+				m.stopTail()
+				return m, tea.Batch(m.loadLogs(), m.startLoading("LambdaModel"))
 			} else {
-				return m, func() tea.Msg {
-					return m.loadFunctions()
-				}
+				return m, tea.Batch(m.loadFunctions, m.startLoading("LambdaModel"))
 			}
 		}
 	}
@@ -329,20 +461,15 @@ func (m *LambdaModel) View() string {
 	// Create a title with consistent styling across all views
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1).
 		Render(fmt.Sprintf(" %s ", m.title))
 
 	// Create content
 	var content string
 	if m.loading {
-		elapsed := time.Since(m.loadingStartTime).Round(time.Second)
-		if elapsed > 5*time.Second {
-			content = fmt.Sprintf("Loading Lambda data... (%s)", elapsed)
-		} else {
-			content = "Loading Lambda data..."
-		}
+		content = m.loadingText("Lambda data")
 	} else if m.err != nil {
 		content = fmt.Sprintf("Error: %s\n\nPress 'r' to retry or 'd' to go to dashboard", m.err.Error())
 	} else if m.viewingLogs {
@@ -352,12 +479,7 @@ func (m *LambdaModel) View() string {
 			// Create log entries
 			var logEntries []string
 			for _, log := range m.logs {
-				// Format timestamp
-				timestamp := time.Unix(0, log.Timestamp*int64(time.Millisecond)).Format("2006-01-02 15:04:05.000")
-
-				// Format log entry
-				entry := fmt.Sprintf("[%s] %s", timestamp, log.Message)
-				logEntries = append(logEntries, entry)
+				logEntries = append(logEntries, lambda.FormatLogEvent(log))
 			}
 
 			// Combine log entries
@@ -366,50 +488,44 @@ func (m *LambdaModel) View() string {
 	} else {
 		if len(m.functions) == 0 {
 			content = "No Lambda functions found"
+		} else if len(m.filtered) == 0 {
+			content = m.filterLine() + "No Lambda functions match the filter"
 		} else {
-			// Create a table header
-			header := lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Render("NAME\tRUNTIME\tMEMORY\tTIMEOUT\tLAST MODIFIED")
-
-			// Create table rows
-			var rows []string
-			for i, function := range m.functions {
-				style := lipgloss.NewStyle()
-				if i == m.selected {
-					style = style.
-						Bold(true).
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#0066cc"))
+			// Create the table of the functions matching the filter
+			rows := make([][]string, 0, len(m.filtered))
+			for _, i := range m.filtered {
+				function := m.functions[i]
+				runtime := function.Runtime
+				if function.Deprecated {
+					runtime += " (deprecated)"
 				}
-
-				row := style.Render(fmt.Sprintf(
-					"%s\t%s\t%d MB\t%d sec\t%s",
+				rows = append(rows, []string{
 					function.Name,
-					function.Runtime,
-					function.Memory,
-					function.Timeout,
+					runtime,
+					fmt.Sprintf("%d MB", function.Memory),
+					fmt.Sprintf("%d sec", function.Timeout),
+					utils.HumanizeBytes(function.Size),
 					function.LastModified,
-				))
-				rows = append(rows, row)
+				})
 			}
-
-			// Combine header and rows
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				header,
-				strings.Join(rows, "\n"),
-			)
+			table := components.NewTable(m.sortHeaders(lambdaColumns), rows, m.selected, theme.Current())
+			table.SetSize(m.tableSize())
+			for row, i := range m.filtered {
+				// Highlight functions on deprecated runtimes
+				if m.functions[i].Deprecated {
+					table.Highlight(row)
+				}
+			}
+			content = m.filterLine() + table.Render()
 		}
 	}
 
 	// Add help text
 	var helpText string
 	if m.viewingLogs {
-		helpText = "\nPress Esc to go back, r to refresh, ? for help"
+		helpText = "\nFollowing new log events. Press Esc to go back, y to copy the ARN, r to refresh, ? for help"
 	} else {
-		helpText = "\nPress ↑/↓ to navigate, Enter to view logs, r to refresh, ? for help"
+		helpText = "\nPress ↑/↓ to navigate, / to filter, o/O to sort, y to copy the ARN, Enter to view details, L to view logs, r to refresh, ? for help"
 	}
 
 	// Style the content
@@ -425,6 +541,44 @@ func (m *LambdaModel) View() string {
 	)
 }
 
+// Detail returns the details of the function in the given row of the table.
+// While the logs are shown it returns nothing.
+func (m *LambdaModel) Detail(selected int) (string, error) {
+	if m.viewingLogs || selected < 0 || selected >= len(m.filtered) {
+		return "", nil
+	}
+	function := m.functions[m.filtered[selected]]
+
+	runtime := function.Runtime
+	if function.Deprecated {
+		runtime += " (deprecated)"
+	}
+	lines := []string{
+		detailLine("Name", function.Name),
+		detailLine("ARN", function.ARN),
+		detailLine("Description", function.Description),
+		detailLine("Runtime", runtime),
+		detailLine("Handler", function.Handler),
+		detailLine("Memory", fmt.Sprintf("%d MB", function.Memory)),
+		detailLine("Timeout", fmt.Sprintf("%d sec", function.Timeout)),
+		detailLine("Code size", utils.HumanizeBytes(function.Size)),
+		detailLine("Version", function.Version),
+		detailLine("Last modified", function.LastModified),
+		detailLine("Role", function.Role),
+		"",
+	}
+	lines = append(lines, detailMap("Environment", function.Environment)...)
+	lines = append(lines, "")
+	lines = append(lines, detailMap("Tags", function.Tags)...)
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// Selected returns the selected row of the function table
+func (m *LambdaModel) Selected() int {
+	return m.selected
+}
+
 // ShortHelp returns the short help text
 func (m *LambdaModel) ShortHelp() []key.Binding {
 	if m.viewingLogs {
@@ -432,7 +586,9 @@ func (m *LambdaModel) ShortHelp() []key.Binding {
 			DefaultKeyMap().Help,
 			DefaultKeyMap().Quit,
 			DefaultKeyMap().Escape,
+			DefaultKeyMap().Yank,
 			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Dashboard,
 			DefaultKeyMap().Command,
 		}
@@ -443,7 +599,12 @@ func (m *LambdaModel) ShortHelp() []key.Binding {
 		DefaultKeyMap().Up,
 		DefaultKeyMap().Down,
 		DefaultKeyMap().Enter,
+		DefaultKeyMap().Logs,
+		DefaultKeyMap().Filter,
+		DefaultKeyMap().Yank,
+		DefaultKeyMap().Sort,
 		DefaultKeyMap().Refresh,
+		DefaultKeyMap().AutoRefresh,
 		DefaultKeyMap().Dashboard,
 		DefaultKeyMap().Command,
 	}
@@ -460,7 +621,9 @@ func (m *LambdaModel) FullHelp() [][]key.Binding {
 			},
 			{
 				DefaultKeyMap().Escape,
+				DefaultKeyMap().Yank,
 				DefaultKeyMap().Refresh,
+				DefaultKeyMap().AutoRefresh,
 				DefaultKeyMap().Dashboard,
 			},
 		}
@@ -475,9 +638,15 @@ func (m *LambdaModel) FullHelp() [][]key.Binding {
 			DefaultKeyMap().Up,
 			DefaultKeyMap().Down,
 			DefaultKeyMap().Enter,
+			DefaultKeyMap().Logs,
+			DefaultKeyMap().Filter,
+			DefaultKeyMap().Yank,
+			DefaultKeyMap().Sort,
+			DefaultKeyMap().SortOrder,
 		},
 		{
 			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Dashboard,
 		},
 	}
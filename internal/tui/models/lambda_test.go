@@ -0,0 +1,202 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/lambda"
+	"github.com/ao/awsm/internal/clock"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLambdaModelYankValue tests the value copied with y in the Lambda view.
+// It verifies that the ARN of the selected function is copied, falling back
+// to its name when the ARN is unknown.
+func TestLambdaModelYankValue(t *testing.T) {
+	model := NewLambdaModel()
+	model.Update(LambdaFunctionMsg{Functions: []lambda.Function{
+		{Name: "api", ARN: "arn:aws:lambda:us-east-1:123456789012:function:api"},
+		{Name: "worker"},
+	}})
+
+	// Call the function
+	assert.Equal(t, "arn:aws:lambda:us-east-1:123456789012:function:api", model.yankValue())
+
+	// Without an ARN the name is copied
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "worker", model.yankValue())
+}
+
+// TestLambdaModelDetail tests the details of the selected Lambda function.
+// It verifies that Detail renders the function's configuration, environment
+// and tags, and that L opens the logs instead.
+func TestLambdaModelDetail(t *testing.T) {
+	model := NewLambdaModel()
+	model.Update(LambdaFunctionMsg{Functions: []lambda.Function{{
+		Name:        "api",
+		Runtime:     "python3.12",
+		Handler:     "app.handler",
+		Memory:      256,
+		Timeout:     30,
+		Size:        1536,
+		Environment: map[string]string{"STAGE": "prod"},
+		Tags:        map[string]string{"team": "payments"},
+	}}})
+
+	// Call the function
+	detail, err := model.Detail(model.Selected())
+
+	// Assert no error
+	assert.NoError(t, err)
+	for _, want := range []string{"api", "python3.12", "app.handler", "256 MB", "30 sec", "1.50 KB", "STAGE = prod", "team = payments"} {
+		assert.Contains(t, detail, want)
+	}
+
+	// L opens the logs, which have no details
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.viewingLogs)
+	detail, err = model.Detail(model.Selected())
+	assert.NoError(t, err)
+	assert.Empty(t, detail)
+}
+
+// TestLambdaModelFilter tests filtering the function list.
+// It verifies that only the functions whose name contains the filter are
+// shown, that the selection moves through them only, and that Esc clears it.
+func TestLambdaModelFilter(t *testing.T) {
+	model := NewLambdaModel()
+	model.Update(LambdaFunctionMsg{Functions: []lambda.Function{
+		{Name: "orders-api", Runtime: "go1.x"},
+		{Name: "billing-worker", Runtime: "python3.12"},
+		{Name: "users-API", Runtime: "nodejs20.x"},
+	}})
+
+	// Type a filter
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	assert.True(t, model.IsFiltering())
+	for _, r := range "api" {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+
+	// Assert only the matching functions are shown
+	view := model.View()
+	assert.Contains(t, view, "/api")
+	assert.Contains(t, view, "orders-api")
+	assert.Contains(t, view, "users-API")
+	assert.NotContains(t, view, "billing-worker")
+
+	// Enter stops typing and keeps the filter
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, model.IsFiltering())
+	assert.NotContains(t, model.View(), "billing-worker")
+
+	// The selection moves through the shown functions only
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "users-API", model.currentFunction)
+
+	// Back in the function list, Esc clears the filter
+	model.Update(LambdaLogMsg{})
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	assert.Contains(t, model.View(), "No Lambda functions match the filter")
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, model.IsFiltering())
+	assert.Contains(t, model.View(), "billing-worker")
+}
+
+// TestLambdaModelLogTailError tests polling errors of the log tail. It
+// verifies that an error is shown in the status bar while the tail keeps
+// running, and that errors of a stopped tail are ignored.
+func TestLambdaModelLogTailError(t *testing.T) {
+	errs := make(chan error)
+	close(errs)
+
+	model := NewLambdaModel()
+	model.viewingLogs = true
+	model.currentFunction = "api"
+	model.tailErrs = errs
+
+	// An error of the current tail is shown and the tail keeps being watched
+	_, cmd := model.Update(LambdaLogTailErrorMsg{Function: "api", Error: errors.New("throttled")})
+	assert.NotNil(t, cmd)
+	batch, ok := cmd().(tea.BatchMsg)
+	assert.True(t, ok)
+	assert.Len(t, batch, 2)
+	assert.Equal(t, StatusMsg{Text: "Error tailing logs: throttled"}, batch[0]())
+
+	// Errors of another function's tail are ignored
+	_, cmd = model.Update(LambdaLogTailErrorMsg{Function: "worker", Error: errors.New("throttled")})
+	assert.Nil(t, cmd)
+
+	// Errors after leaving the log view are ignored
+	model.viewingLogs = false
+	_, cmd = model.Update(LambdaLogTailErrorMsg{Function: "api", Error: errors.New("throttled")})
+	assert.Nil(t, cmd)
+}
+
+// TestLambdaModelLoadingTimeout tests the loading timeout of the Lambda view with a
+// fake clock.
+// It verifies that checks within the timeout schedule the next one, that the
+// time spent loading is shown after 5 seconds, that a check past the timeout
+// reports it, and that checks of a loading replaced by a refresh are dropped.
+func TestLambdaModelLoadingTimeout(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	model := NewLambdaModel()
+	model.SetClock(clk)
+	model.SetLoadingTimeout(10 * time.Second)
+
+	// Init starts loading the functions (the load itself is not run)
+	assert.NotNil(t, model.Init())
+	assert.True(t, model.IsLoading())
+	check := timeoutCheckMsg{source: "LambdaModel", load: 1}
+
+	// Within the timeout, the next check is scheduled
+	clk.Advance(5 * time.Second)
+	_, cmd := model.Update(check)
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.Contains(t, model.View(), "Loading Lambda data...")
+	assert.NotContains(t, model.View(), "(5s)")
+
+	// The time spent loading is shown after 5 seconds
+	clk.Advance(time.Second)
+	assert.Contains(t, model.View(), "Loading Lambda data... (6s)")
+
+	// Checks of another view are ignored
+	_, cmd = model.Update(timeoutCheckMsg{source: "S3Model", load: 1})
+	assert.Nil(t, cmd)
+
+	// Past the timeout, the check reports it
+	clk.Advance(5 * time.Second)
+	_, cmd = model.Update(check)
+	require.NotNil(t, cmd)
+	msg := cmd()
+	assert.Equal(t, TimeoutMsg{Message: "Operation timed out after 10s", Source: "LambdaModel"}, msg)
+
+	model.Update(msg)
+	assert.False(t, model.IsLoading())
+	assert.EqualError(t, model.GetError(), "operation timed out after 10s")
+
+	// Refreshing starts a new loading, which checks of the first one don't time out
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.NoError(t, model.GetError())
+	clk.Advance(time.Minute)
+	_, cmd = model.Update(check)
+	assert.Nil(t, cmd)
+
+	// Once loaded, checks stop
+	model.Update(LambdaFunctionMsg{Functions: []lambda.Function{{Name: "api"}}})
+	_, cmd = model.Update(timeoutCheckMsg{source: "LambdaModel", load: 2})
+	assert.Nil(t, cmd)
+	assert.False(t, model.IsLoading())
+}
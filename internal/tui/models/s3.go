@@ -1,14 +1,17 @@
 package models
 
 import (
+	"cmp"
 	"context"
 	"fmt"
-	"os"
 	"strings"
-	"time"
 
+	"github.com/ao/awsm/internal/aws/client"
 	"github.com/ao/awsm/internal/aws/s3"
 	"github.com/ao/awsm/internal/logger"
+	"github.com/ao/awsm/internal/tui/components"
+	"github.com/ao/awsm/internal/tui/theme"
+	"github.com/ao/awsm/internal/utils"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,27 +23,35 @@ type S3BucketMsg struct {
 	Error   error
 }
 
-// S3ObjectMsg is a message containing S3 object data
+// S3ObjectMsg is a message containing a page of S3 object data
 type S3ObjectMsg struct {
-	Objects []s3.Object
-	Error   error
+	Bucket    string   // Name of the listed bucket
+	Prefix    string   // Prefix of the listed level of the bucket
+	Prefixes  []string // Common prefixes ("folders") directly under the prefix
+	Objects   []s3.Object
+	NextToken string // Continuation token of the next page, empty for the last page
+	More      bool   // Whether the objects are a further page of the listed objects
+	Error     error
 }
 
+// s3ObjectPageSize is the number of objects loaded at a time
+const s3ObjectPageSize = 1000
+
 // S3Model represents the S3 view
 type S3Model struct {
 	BaseModel
-	title            string
-	buckets          []s3.Bucket
-	objects          []s3.Object
-	selectedBucket   int
-	selectedObject   int
-	currentBucket    string
-	viewingObjects   bool
-	loading          bool
-	err              error
-	adapter          *s3.Adapter
-	loadingStartTime time.Time
-	loadingTimeout   time.Duration
+	title          string
+	buckets        []s3.Bucket
+	objects        []s3.Object
+	selectedBucket int
+	selectedObject int
+	currentBucket  string
+	prefix         string   // Prefix of the level of the bucket being viewed
+	prefixes       []string // Common prefixes ("folders") directly under the prefix
+	viewingObjects bool
+	page           int    // Number of object pages loaded
+	nextToken      string // Continuation token of the next object page
+	adapter        *s3.Adapter
 }
 
 // NewS3Model creates a new S3 model
@@ -55,111 +66,26 @@ func NewS3Model() *S3Model {
 		selectedBucket: 0,
 		selectedObject: 0,
 		viewingObjects: false,
-		loading:        false,
-		loadingTimeout: 30 * time.Second, // Default timeout of 30 seconds
 	}
 }
 
-// IsLoading returns whether the model is in a loading state
-func (m *S3Model) IsLoading() bool {
-	return m.loading
-}
-
-// GetError returns any error that occurred during loading
-func (m *S3Model) GetError() error {
-	return m.err
-}
-
-// SetLoadingTimeout sets the timeout duration for loading operations
-func (m *S3Model) SetLoadingTimeout(timeout time.Duration) {
-	m.loadingTimeout = timeout
-}
-
 // Init initializes the model
 func (m *S3Model) Init() tea.Cmd {
 	logger.Debug("S3Model.Init called")
 
-	m.loading = true
-	m.loadingStartTime = time.Now()
-	m.err = nil
-
-	logger.Debug("S3Model.Init returning commands")
-
-	// Create a debug file to verify this function is being called
-	f, _ := os.Create("s3_init_debug_new.log")
-	if f != nil {
-		f.WriteString(fmt.Sprintf("S3Model.Init called at %s\n", time.Now().String()))
-		f.Close()
-	}
-
 	// Return a command that will load buckets asynchronously
 	return tea.Batch(
 		m.asyncLoadBuckets,
-		m.startTimeoutCheck,
+		m.startLoading("S3Model"),
 	)
 }
 
-// startTimeoutCheck starts a periodic check for timeout
-func (m *S3Model) startTimeoutCheck() tea.Msg {
-	// Check every second if we've exceeded the timeout
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			if !m.loading {
-				// If we're no longer loading, stop checking
-				return nil
-			}
-
-			if time.Since(m.loadingStartTime) > m.loadingTimeout {
-				// We've exceeded the timeout
-				return TimeoutMsg{
-					Message: "Operation timed out after " + m.loadingTimeout.String(),
-					Source:  "S3Model",
-				}
-			}
-		}
-	}
-}
-
-// checkTimeout checks if the loading operation has timed out
-func (m *S3Model) checkTimeout() tea.Msg {
-	if !m.loading || m.loadingStartTime.IsZero() {
-		return nil
-	}
-
-	// Check if we've exceeded the timeout
-	if time.Since(m.loadingStartTime) > m.loadingTimeout {
-		return TimeoutMsg{
-			Message: "Operation timed out",
-			Source:  "S3Model",
-		}
-	}
-
-	// Schedule another check in 1 second
-	time.Sleep(1 * time.Second)
-
-	// Continue checking for timeout
-	return tea.Tick(time.Second, func(time.Time) tea.Msg {
-		return m.checkTimeout()
-	})
-}
-
 // asyncLoadBuckets loads S3 buckets asynchronously
 func (m *S3Model) asyncLoadBuckets() tea.Msg {
 	logger.Debug("S3Model.asyncLoadBuckets called")
 
-	// Create a debug file to verify this function is being called
-	f, _ := os.Create("s3_asyncload_debug.log")
-	if f != nil {
-		f.WriteString(fmt.Sprintf("S3Model.asyncLoadBuckets called at %s\n", time.Now().String()))
-		f.Close()
-	}
-
-	// Use a longer timeout since we know the operation can take ~17.5 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Listing buckets can take a while, so use the configured AWS timeout
+	ctx, cancel := client.WithTimeout(context.Background())
 	defer cancel()
 
 	// Create S3 adapter if not already created
@@ -219,15 +145,8 @@ func (m *S3Model) asyncLoadBuckets() tea.Msg {
 		}
 	} else {
 		logger.Info("Found %d S3 buckets", len(buckets))
-		
-		// Create a debug file with the results
-		f, _ := os.Create("s3_buckets_result.log")
-		if f != nil {
-			f.WriteString(fmt.Sprintf("Found %d buckets at %s\n", len(buckets), time.Now().String()))
-			for _, bucket := range buckets {
-				f.WriteString(fmt.Sprintf("- %s (%s)\n", bucket.Name, bucket.Region))
-			}
-			f.Close()
+		for _, bucket := range buckets {
+			logger.Debug("- %s (%s)", bucket.Name, bucket.Region)
 		}
 	}
 
@@ -237,21 +156,16 @@ func (m *S3Model) asyncLoadBuckets() tea.Msg {
 	}
 }
 
-// loadObjects loads S3 objects for the current bucket
-func (m *S3Model) loadObjects() tea.Cmd {
+// loadObjects loads a page of the common prefixes and S3 objects at the current
+// level of the current bucket, starting at the page with the given continuation
+// token (empty for the first page)
+func (m *S3Model) loadObjects(token string) tea.Cmd {
+	bucket, prefix := m.currentBucket, m.prefix
 	return func() tea.Msg {
-		logger.Debug("S3Model.loadObjects called for bucket: %s", m.currentBucket)
-
-		// Create a debug file to verify this function is being called
-		f, _ := os.Create("s3_loadobjects_debug.log")
-		if f != nil {
-			f.WriteString(fmt.Sprintf("S3Model.loadObjects called for bucket %s at %s\n",
-				m.currentBucket, time.Now().String()))
-			f.Close()
-		}
+		logger.Debug("S3Model.loadObjects called for bucket: %s, prefix: %s", bucket, prefix)
 
-		// Use a longer timeout since we know the operation can take time
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// Use the configured AWS timeout for listing the objects
+		ctx, cancel := client.WithTimeout(context.Background())
 		defer cancel()
 
 		if m.adapter == nil {
@@ -284,45 +198,190 @@ func (m *S3Model) loadObjects() tea.Cmd {
 			m.adapter = adapter
 		}
 
-		// List objects in the current bucket
-		logger.Debug("Listing objects in bucket: %s", m.currentBucket)
-		objects, err := m.adapter.ListObjects(ctx, m.currentBucket, "", 0)
+		// List the prefixes and objects at the current level of the bucket
+		logger.Debug("Listing objects in bucket: %s", bucket)
+		listing, err := m.adapter.ListObjectsPageWithDelimiter(ctx, bucket, prefix, s3.DefaultDelimiter, token, s3ObjectPageSize)
 		if err != nil {
-			logger.Error("Error listing objects in bucket %s: %v", m.currentBucket, err)
-			
-			// Create a debug file with the error
-			f, _ := os.Create("s3_objects_error.log")
-			if f != nil {
-				f.WriteString(fmt.Sprintf("Error listing objects in bucket %s: %v at %s\n",
-					m.currentBucket, err, time.Now().String()))
-				f.Close()
-			}
-		} else {
-			logger.Info("Found %d objects in bucket %s", len(objects), m.currentBucket)
-			
-			// Create a debug file with the results
-			f, _ := os.Create("s3_objects_result.log")
-			if f != nil {
-				f.WriteString(fmt.Sprintf("Found %d objects in bucket %s at %s\n",
-					len(objects), m.currentBucket, time.Now().String()))
-				if len(objects) > 0 {
-					f.WriteString("First 5 objects:\n")
-					for i, obj := range objects {
-						if i >= 5 {
-							break
-						}
-						f.WriteString(fmt.Sprintf("- %s (%d bytes)\n", obj.Key, obj.Size))
-					}
-				}
-				f.Close()
+			logger.Error("Error listing objects in bucket %s: %v", bucket, err)
+			return S3ObjectMsg{Bucket: bucket, Prefix: prefix, More: token != "", Error: err}
+		}
+
+		logger.Info("Found %d prefixes and %d objects in bucket %s", len(listing.Prefixes), len(listing.Objects), bucket)
+		for i, obj := range listing.Objects {
+			if i >= 5 {
+				break
 			}
+			logger.Debug("- %s (%d bytes)", obj.Key, obj.Size)
 		}
 
 		return S3ObjectMsg{
-			Objects: objects,
-			Error:   err,
+			Bucket:    bucket,
+			Prefix:    prefix,
+			Prefixes:  listing.Prefixes,
+			Objects:   listing.Objects,
+			NextToken: listing.NextToken,
+			More:      token != "",
+		}
+	}
+}
+
+// openPrefix shows the level of the current bucket under prefix and loads its
+// first page
+func (m *S3Model) openPrefix(prefix string) tea.Cmd {
+	m.prefix = prefix
+	m.prefixes = nil
+	m.objects = nil
+	m.page = 0
+	m.nextToken = ""
+	m.selectedObject = 0
+	m.clearFilter()
+	m.filterRows()
+	m.title = fmt.Sprintf("S3 Objects: %s/%s", m.currentBucket, prefix)
+	logger.Debug("Opening %s/%s", m.currentBucket, prefix)
+
+	return tea.Batch(
+		m.loadObjects(""),
+		m.startLoading("S3Model"),
+	)
+}
+
+// parentPrefix returns the prefix of the level above prefix, e.g. "logs/" for
+// "logs/2024/" and "" for "logs/"
+func parentPrefix(prefix string) string {
+	trimmed := strings.TrimSuffix(prefix, s3.DefaultDelimiter)
+	i := strings.LastIndex(trimmed, s3.DefaultDelimiter)
+	if i < 0 {
+		return ""
+	}
+	return trimmed[:i+len(s3.DefaultDelimiter)]
+}
+
+// objectRowKey returns the key of the prefix or object with the given index in
+// the object list, where the prefixes come before the objects
+func (m *S3Model) objectRowKey(i int) string {
+	if i < len(m.prefixes) {
+		return m.prefixes[i]
+	}
+	return m.objects[i-len(m.prefixes)].Key
+}
+
+// Headers of the bucket and object tables
+var (
+	s3BucketColumns = []string{"NAME", "REGION", "CREATION DATE"}
+	s3ObjectColumns = []string{"KEY", "SIZE", "LAST MODIFIED"}
+)
+
+// sortBuckets sorts buckets by the column of the bucket table with the given
+// index, in descending order if desc is set. A column of -1 leaves them in the
+// loaded order.
+func sortBuckets(buckets []s3.Bucket, column int, desc bool) {
+	var compare func(a, b s3.Bucket) int
+	switch column {
+	case 0:
+		compare = func(a, b s3.Bucket) int { return strings.Compare(a.Name, b.Name) }
+	case 1:
+		compare = func(a, b s3.Bucket) int { return strings.Compare(a.Region, b.Region) }
+	case 2:
+		compare = func(a, b s3.Bucket) int { return a.CreationDate.Compare(b.CreationDate) }
+	default:
+		return
+	}
+	sortRows(buckets, compare, desc)
+}
+
+// sortObjects sorts objects by the column of the object table with the given
+// index, in descending order if desc is set. A column of -1 leaves them in the
+// loaded order.
+func sortObjects(objects []s3.Object, column int, desc bool) {
+	var compare func(a, b s3.Object) int
+	switch column {
+	case 0:
+		compare = func(a, b s3.Object) int { return strings.Compare(a.Key, b.Key) }
+	case 1:
+		compare = func(a, b s3.Object) int { return cmp.Compare(a.Size, b.Size) }
+	case 2:
+		compare = func(a, b s3.Object) int { return a.LastModified.Compare(b.LastModified) }
+	default:
+		return
+	}
+	sortRows(objects, compare, desc)
+}
+
+// applySort sorts the buckets, or the objects when viewing a bucket, by the
+// sort column, keeping the selected row selected
+func (m *S3Model) applySort() {
+	if m.viewingObjects {
+		selected := ""
+		if len(m.filtered) > 0 {
+			selected = m.objectRowKey(m.filtered[m.selectedObject])
+		}
+		sortObjects(m.objects, m.sortColumn, m.sortDesc)
+		m.filterRows()
+		for row, i := range m.filtered {
+			if m.objectRowKey(i) == selected {
+				m.selectedObject = row
+			}
+		}
+		return
+	}
+
+	selected := ""
+	if len(m.filtered) > 0 {
+		selected = m.buckets[m.filtered[m.selectedBucket]].Name
+	}
+	sortBuckets(m.buckets, m.sortColumn, m.sortDesc)
+	m.filterRows()
+	for row, i := range m.filtered {
+		if m.buckets[i].Name == selected {
+			m.selectedBucket = row
+		}
+	}
+}
+
+// yankValue returns the name of the selected bucket, or bucket/key of the
+// selected object or prefix when viewing a bucket, to copy to the clipboard
+func (m *S3Model) yankValue() string {
+	if len(m.filtered) == 0 {
+		return ""
+	}
+	if m.viewingObjects {
+		return m.currentBucket + "/" + m.objectRowKey(m.filtered[m.selectedObject])
+	}
+	return m.buckets[m.filtered[m.selectedBucket]].Name
+}
+
+// pageLine returns the number of object pages loaded and whether there are more
+func (m *S3Model) pageLine() string {
+	loaded := fmt.Sprintf("%d objects", len(m.objects))
+	if len(m.prefixes) > 0 {
+		loaded = fmt.Sprintf("%d folders, %s", len(m.prefixes), loaded)
+	}
+	if m.nextToken == "" {
+		return fmt.Sprintf("page %d, %s", m.page, loaded)
+	}
+	return fmt.Sprintf("page %d, %s loaded, press n to load more", m.page, loaded)
+}
+
+// filterRows shows the buckets, or the prefixes and objects when viewing a
+// bucket, whose name contains the filter, keeping the selection on a shown row
+func (m *S3Model) filterRows() {
+	if m.viewingObjects {
+		// Match the names within the current level
+		keys := make([]string, len(m.prefixes)+len(m.objects))
+		for i := range keys {
+			keys[i] = strings.TrimPrefix(m.objectRowKey(i), m.prefix)
 		}
+		m.applyFilter(keys)
+		m.selectedObject = max(min(m.selectedObject, len(m.filtered)-1), 0)
+		return
+	}
+
+	names := make([]string, len(m.buckets))
+	for i, bucket := range m.buckets {
+		names[i] = bucket.Name
 	}
+	m.applyFilter(names)
+	m.selectedBucket = max(min(m.selectedBucket, len(m.filtered)-1), 0)
 }
 
 // Update updates the model based on messages
@@ -332,78 +391,94 @@ func (m *S3Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case S3BucketMsg:
 		logger.Debug("Received S3BucketMsg")
-		
-		// Create a debug file to verify message handling
-		f, _ := os.Create("s3_update_bucket_msg.log")
-		if f != nil {
-			if msg.Error != nil {
-				f.WriteString(fmt.Sprintf("Error: %v at %s\n", msg.Error, time.Now().String()))
-			} else {
-				f.WriteString(fmt.Sprintf("Received %d buckets at %s\n", len(msg.Buckets), time.Now().String()))
-			}
-			f.Close()
-		}
-		
+
 		m.loading = false
 		if msg.Error != nil {
 			logger.Error("S3BucketMsg error: %v", msg.Error)
 			m.err = msg.Error
 			return m, nil
 		}
-		
+
 		logger.Debug("S3BucketMsg contains %d buckets", len(msg.Buckets))
 		m.buckets = msg.Buckets
 		m.err = nil
+		sortBuckets(m.buckets, m.sortColumn, m.sortDesc)
+		m.filterRows()
 		return m, nil
 
 	case S3ObjectMsg:
 		logger.Debug("Received S3ObjectMsg")
-		
-		// Create a debug file to verify message handling
-		f, _ := os.Create("s3_update_object_msg.log")
-		if f != nil {
-			if msg.Error != nil {
-				f.WriteString(fmt.Sprintf("Error: %v at %s\n", msg.Error, time.Now().String()))
-			} else {
-				f.WriteString(fmt.Sprintf("Received %d objects at %s\n", len(msg.Objects), time.Now().String()))
-			}
-			f.Close()
-		}
-		
+
 		m.loading = false
 		if msg.Error != nil {
 			logger.Error("S3ObjectMsg error: %v", msg.Error)
 			m.err = msg.Error
 			return m, nil
 		}
-		
-		logger.Debug("S3ObjectMsg contains %d objects", len(msg.Objects))
-		m.objects = msg.Objects
+
+		// Ignore the objects of a bucket or level that was left while they loaded
+		if !m.viewingObjects || msg.Bucket != m.currentBucket || msg.Prefix != m.prefix {
+			return m, nil
+		}
+
+		logger.Debug("S3ObjectMsg contains %d prefixes and %d objects", len(msg.Prefixes), len(msg.Objects))
+		if msg.More {
+			m.prefixes = append(m.prefixes, msg.Prefixes...)
+			m.objects = append(m.objects, msg.Objects...)
+			m.page++
+		} else {
+			m.prefixes = msg.Prefixes
+			m.objects = msg.Objects
+			m.page = 1
+		}
+		m.nextToken = msg.NextToken
 		m.err = nil
+		sortObjects(m.objects, m.sortColumn, m.sortDesc)
+		m.filterRows()
 		return m, nil
 
+	case timeoutCheckMsg:
+		if msg.source == "S3Model" {
+			return m, m.checkLoadingTimeout(msg)
+		}
+
 	case TimeoutMsg:
 		logger.Debug("Received TimeoutMsg: %s", msg.Message)
 		if msg.Source == "S3Model" && m.loading {
 			logger.Warn("S3Model operation timed out after %v", m.loadingTimeout)
 			m.loading = false
 			m.err = fmt.Errorf("operation timed out after %v", m.loadingTimeout)
-			
-			// Create a debug file for the timeout
-			f, _ := os.Create("s3_timeout.log")
-			if f != nil {
-				f.WriteString(fmt.Sprintf("Operation timed out after %v at %s\n",
-					m.loadingTimeout, time.Now().String()))
-				f.Close()
-			}
-			
 			return m, nil
 		}
 
 	case tea.KeyMsg:
 		logger.Debug("Received KeyMsg: %s", msg.String())
+
+		// Filter the bucket or object list
+		if handled, changed := m.handleFilterKey(msg); handled {
+			if changed {
+				if m.viewingObjects {
+					m.selectedObject = 0
+				} else {
+					m.selectedBucket = 0
+				}
+				m.filterRows()
+			}
+			return m, nil
+		}
+
+		// Sort the bucket or object list; both have the same number of columns
+		if m.handleSortKey(msg, len(s3BucketColumns)) {
+			m.applySort()
+			return m, nil
+		}
+
 		// Handle key messages
 		switch {
+		case key.Matches(msg, DefaultKeyMap().Yank):
+			if value := m.yankValue(); value != "" {
+				return m, copyToClipboard(value)
+			}
 		case key.Matches(msg, DefaultKeyMap().Up):
 			if m.viewingObjects {
 				if m.selectedObject > 0 {
@@ -416,65 +491,59 @@ func (m *S3Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 		case key.Matches(msg, DefaultKeyMap().Down):
 			if m.viewingObjects {
-				if m.selectedObject < len(m.objects)-1 {
+				if m.selectedObject < len(m.filtered)-1 {
 					m.selectedObject++
 				}
 			} else {
-				if m.selectedBucket < len(m.buckets)-1 {
+				if m.selectedBucket < len(m.filtered)-1 {
 					m.selectedBucket++
 				}
 			}
 		case key.Matches(msg, DefaultKeyMap().Enter):
-			if !m.viewingObjects && len(m.buckets) > 0 {
-				// View objects in the selected bucket
+			if !m.viewingObjects && len(m.filtered) > 0 {
+				// View the top level of the selected bucket
 				m.viewingObjects = true
-				m.currentBucket = m.buckets[m.selectedBucket].Name
-				m.title = fmt.Sprintf("S3 Objects: %s", m.currentBucket)
-				m.loading = true
-				m.loadingStartTime = time.Now()
-				m.err = nil
-				
-				// Create a debug file for entering bucket view
-				f, _ := os.Create("s3_enter_bucket.log")
-				if f != nil {
-					f.WriteString(fmt.Sprintf("Entering bucket %s at %s\n",
-						m.currentBucket, time.Now().String()))
-					f.Close()
-				}
-				
+				m.currentBucket = m.buckets[m.filtered[m.selectedBucket]].Name
+				return m, m.openPrefix("")
+			}
+			if m.viewingObjects && len(m.filtered) > 0 && m.filtered[m.selectedObject] < len(m.prefixes) {
+				// Descend into the selected prefix
+				return m, m.openPrefix(m.prefixes[m.filtered[m.selectedObject]])
+			}
+		case key.Matches(msg, DefaultKeyMap().LoadMore):
+			if m.viewingObjects && m.nextToken != "" && !m.loading {
+				// Load the next page of objects
+				logger.Debug("Loading page %d of bucket %s", m.page+1, m.currentBucket)
+
 				return m, tea.Batch(
-					m.loadObjects(),
-					m.startTimeoutCheck,
+					m.loadObjects(m.nextToken),
+					m.startLoading("S3Model"),
 				)
 			}
 		case key.Matches(msg, DefaultKeyMap().Escape):
+			if m.viewingObjects && m.prefix != "" {
+				// Go up one level
+				return m, m.openPrefix(parentPrefix(m.prefix))
+			}
 			if m.viewingObjects {
 				// Go back to bucket list
 				m.viewingObjects = false
 				m.title = "S3 Buckets"
 				m.selectedObject = 0
+				m.filterRows()
 			}
 		case key.Matches(msg, DefaultKeyMap().Refresh):
-			m.loading = true
-			m.loadingStartTime = time.Now()
-			m.err = nil
-			
-			// Create a debug file for refresh action
-			f, _ := os.Create("s3_refresh.log")
-			if f != nil {
-				f.WriteString(fmt.Sprintf("Refreshing at %s\n", time.Now().String()))
-				f.Close()
-			}
-			
+			logger.Debug("Refreshing S3 view")
+
 			if m.viewingObjects {
 				return m, tea.Batch(
-					m.loadObjects(),
-					m.startTimeoutCheck,
+					m.loadObjects(""),
+					m.startLoading("S3Model"),
 				)
 			} else {
 				return m, tea.Batch(
 					m.asyncLoadBuckets,
-					m.startTimeoutCheck,
+					m.startLoading("S3Model"),
 				)
 			}
 		}
@@ -488,116 +557,74 @@ func (m *S3Model) View() string {
 	// Create a title with consistent styling across all views
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#0066cc")).
+		Foreground(theme.Current().Text).
+		Background(theme.Current().Primary).
 		Padding(0, 1).
 		Render(fmt.Sprintf(" %s ", m.title))
 
 	// Create content
 	var content string
 	if m.loading {
-		elapsed := time.Since(m.loadingStartTime).Round(time.Second)
-		if elapsed > 5*time.Second {
-			content = fmt.Sprintf("Loading S3 data... (%s)", elapsed)
-		} else {
-			content = "Loading S3 data..."
-		}
+		content = m.loadingText("S3 data")
 	} else if m.err != nil {
 		content = fmt.Sprintf("Error: %s\n\nPress 'r' to retry or 'd' to go to dashboard", m.err.Error())
 	} else if m.viewingObjects {
-		if len(m.objects) == 0 {
+		if len(m.prefixes) == 0 && len(m.objects) == 0 {
 			content = "No objects found in this bucket"
+			if m.prefix != "" {
+				content = "No objects found under " + m.prefix
+			}
+		} else if len(m.filtered) == 0 {
+			content = m.filterLine() + "No objects match the filter"
 		} else {
-			// Create a table header
-			header := lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Render("KEY\tSIZE\tLAST MODIFIED")
-
-			// Create table rows
-			var rows []string
-			for i, object := range m.objects {
-				// Format size
-				size := fmt.Sprintf("%d B", object.Size)
-				if object.Size > 1024*1024*1024 {
-					size = fmt.Sprintf("%.2f GB", float64(object.Size)/(1024*1024*1024))
-				} else if object.Size > 1024*1024 {
-					size = fmt.Sprintf("%.2f MB", float64(object.Size)/(1024*1024))
-				} else if object.Size > 1024 {
-					size = fmt.Sprintf("%.2f KB", float64(object.Size)/1024)
+			// Create the table of the prefixes and objects matching the filter,
+			// named relative to the current level
+			rows := make([][]string, 0, len(m.filtered))
+			for _, i := range m.filtered {
+				if i < len(m.prefixes) {
+					rows = append(rows, []string{strings.TrimPrefix(m.prefixes[i], m.prefix), "DIR", ""})
+					continue
 				}
-
-				style := lipgloss.NewStyle()
-				if i == m.selectedObject {
-					style = style.
-						Bold(true).
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#0066cc"))
-				}
-
-				// Format row
-				row := style.Render(fmt.Sprintf(
-					"%s\t%s\t%s",
-					object.Key,
-					size,
+				object := m.objects[i-len(m.prefixes)]
+				rows = append(rows, []string{
+					strings.TrimPrefix(object.Key, m.prefix),
+					utils.HumanizeBytes(object.Size),
 					object.LastModified.Format("2006-01-02 15:04:05"),
-				))
-				rows = append(rows, row)
+				})
 			}
-
-			// Combine header and rows
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				header,
-				strings.Join(rows, "\n"),
-			)
+			table := components.NewTable(m.sortHeaders(s3ObjectColumns), rows, m.selectedObject, theme.Current())
+			width, height := m.tableSize()
+			table.SetSize(width, height-1) // Leave room for the page line
+			content = m.filterLine() + table.Render() + "\n" + m.pageLine()
 		}
 	} else {
 		if len(m.buckets) == 0 {
 			content = "No S3 buckets found"
+		} else if len(m.filtered) == 0 {
+			content = m.filterLine() + "No S3 buckets match the filter"
 		} else {
-			// Create a table header
-			header := lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Render("NAME\tREGION\tCREATION DATE")
-
-			// Create table rows
-			var rows []string
-			for i, bucket := range m.buckets {
-				style := lipgloss.NewStyle()
-				if i == m.selectedBucket {
-					style = style.
-						Bold(true).
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#0066cc"))
-				}
-
-				// Format row
-				row := style.Render(fmt.Sprintf(
-					"%s\t%s\t%s",
+			// Create the table of the buckets matching the filter
+			rows := make([][]string, 0, len(m.filtered))
+			for _, i := range m.filtered {
+				bucket := m.buckets[i]
+				rows = append(rows, []string{
 					bucket.Name,
 					bucket.Region,
 					bucket.CreationDate.Format("2006-01-02"),
-				))
-				rows = append(rows, row)
+				})
 			}
-
-			// Combine header and rows
-			content = lipgloss.JoinVertical(
-				lipgloss.Left,
-				header,
-				strings.Join(rows, "\n"),
-			)
+			table := components.NewTable(m.sortHeaders(s3BucketColumns), rows, m.selectedBucket, theme.Current())
+			table.SetSize(m.tableSize())
+			content = m.filterLine() + table.Render()
 		}
 	}
 
 	// Add help text with consistent styling across all views
 	var helpText string
 	if m.viewingObjects {
-		helpText = "\nPress ↑/↓ to navigate, Esc to go back, r to refresh, ? for help"
+		helpText = "\nPress ↑/↓ to navigate, Enter to open a folder or view an object's details, / to filter, o/O to sort, y to copy the name, n to load more, Esc to go up, r to refresh, ? for help"
 	} else {
-		helpText = "\nPress ↑/↓ to navigate, Enter to view objects, r to refresh, ? for help"
+		helpText = "\nPress ↑/↓ to navigate, / to filter, o/O to sort, y to copy the name, Enter to view objects, r to refresh, ? for help"
 	}
 
 	// Style the content
@@ -613,6 +640,39 @@ func (m *S3Model) View() string {
 	)
 }
 
+// Detail returns the details of the object in the given row of the object
+// table. Buckets and folders have none, so Enter opens them.
+func (m *S3Model) Detail(selected int) (string, error) {
+	if !m.viewingObjects || selected < 0 || selected >= len(m.filtered) {
+		return "", nil
+	}
+	i := m.filtered[selected]
+	if i < len(m.prefixes) {
+		return "", nil
+	}
+	object := m.objects[i-len(m.prefixes)]
+
+	lines := []string{
+		detailLine("Bucket", m.currentBucket),
+		detailLine("Key", object.Key),
+		detailLine("URL", fmt.Sprintf("s3://%s/%s", m.currentBucket, object.Key)),
+		detailLine("Size", fmt.Sprintf("%s (%d bytes)", utils.HumanizeBytes(object.Size), object.Size)),
+		detailLine("Last modified", object.LastModified.Format("2006-01-02 15:04:05")),
+		detailLine("ETag", object.ETag),
+		detailLine("Storage class", object.StorageClass),
+		detailLine("Owner", object.Owner),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Selected returns the selected row of the bucket or object table
+func (m *S3Model) Selected() int {
+	if m.viewingObjects {
+		return m.selectedObject
+	}
+	return m.selectedBucket
+}
+
 // ShortHelp returns the short help text
 func (m *S3Model) ShortHelp() []key.Binding {
 	if m.viewingObjects {
@@ -621,8 +681,14 @@ func (m *S3Model) ShortHelp() []key.Binding {
 			DefaultKeyMap().Quit,
 			DefaultKeyMap().Up,
 			DefaultKeyMap().Down,
+			DefaultKeyMap().Enter,
 			DefaultKeyMap().Escape,
+			DefaultKeyMap().Filter,
+			DefaultKeyMap().Yank,
+			DefaultKeyMap().Sort,
+			DefaultKeyMap().LoadMore,
 			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Dashboard,
 			DefaultKeyMap().Command,
 		}
@@ -633,7 +699,11 @@ func (m *S3Model) ShortHelp() []key.Binding {
 		DefaultKeyMap().Up,
 		DefaultKeyMap().Down,
 		DefaultKeyMap().Enter,
+		DefaultKeyMap().Filter,
+		DefaultKeyMap().Yank,
+		DefaultKeyMap().Sort,
 		DefaultKeyMap().Refresh,
+		DefaultKeyMap().AutoRefresh,
 		DefaultKeyMap().Dashboard,
 		DefaultKeyMap().Command,
 	}
@@ -651,10 +721,17 @@ func (m *S3Model) FullHelp() [][]key.Binding {
 			{
 				DefaultKeyMap().Up,
 				DefaultKeyMap().Down,
+				DefaultKeyMap().Enter,
 				DefaultKeyMap().Escape,
+				DefaultKeyMap().Filter,
+				DefaultKeyMap().Yank,
+				DefaultKeyMap().Sort,
+				DefaultKeyMap().SortOrder,
+				DefaultKeyMap().LoadMore,
 			},
 			{
 				DefaultKeyMap().Refresh,
+				DefaultKeyMap().AutoRefresh,
 				DefaultKeyMap().Dashboard,
 			},
 		}
@@ -669,9 +746,14 @@ func (m *S3Model) FullHelp() [][]key.Binding {
 			DefaultKeyMap().Up,
 			DefaultKeyMap().Down,
 			DefaultKeyMap().Enter,
+			DefaultKeyMap().Filter,
+			DefaultKeyMap().Yank,
+			DefaultKeyMap().Sort,
+			DefaultKeyMap().SortOrder,
 		},
 		{
 			DefaultKeyMap().Refresh,
+			DefaultKeyMap().AutoRefresh,
 			DefaultKeyMap().Dashboard,
 		},
 	}
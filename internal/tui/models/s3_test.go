@@ -0,0 +1,376 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ao/awsm/internal/aws/s3"
+	"github.com/ao/awsm/internal/clock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3ModelNoStrayFiles tests the S3Model Init/Update cycle.
+// It verifies that handling data, errors, timeouts and key presses doesn't
+// create any files in the current working directory.
+func TestS3ModelNoStrayFiles(t *testing.T) {
+	// Run the model in an empty working directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	model := NewS3Model()
+
+	// Init only returns the commands that load the buckets
+	assert.NotNil(t, model.Init())
+
+	// Receive buckets and enter the first one
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "logs-bucket", Region: "us-east-1"}}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+
+	// Receive objects, an error, a timeout and refresh
+	model.Update(S3ObjectMsg{Bucket: "logs-bucket", Objects: []s3.Object{{Key: "app.log", Size: 42}}})
+	model.Update(S3ObjectMsg{Error: errors.New("access denied")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	model.Update(TimeoutMsg{Message: "Operation timed out", Source: "S3Model"})
+
+	assert.Equal(t, "logs-bucket", model.currentBucket)
+	assert.Error(t, model.GetError())
+
+	// Assert no files were created
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestS3ModelFilter tests filtering the bucket list.
+// It verifies that typing after / only shows the buckets whose name contains
+// the filter, that Enter opens a shown bucket, and that Esc clears the filter.
+func TestS3ModelFilter(t *testing.T) {
+	model := NewS3Model()
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{
+		{Name: "app-logs", Region: "us-east-1"},
+		{Name: "backups", Region: "us-east-1"},
+		{Name: "cdn-LOGS", Region: "eu-west-1"},
+	}})
+
+	// Type a filter
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	assert.True(t, model.IsFiltering())
+	for _, r := range "logs" {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+
+	// Assert only the matching buckets are shown
+	view := model.View()
+	assert.Contains(t, view, "/logs")
+	assert.Contains(t, view, "app-logs")
+	assert.Contains(t, view, "cdn-LOGS")
+	assert.NotContains(t, view, "backups")
+
+	// Enter stops typing and keeps the filter
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, model.IsFiltering())
+	assert.NotContains(t, model.View(), "backups")
+
+	// The selection moves through the shown buckets only
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "cdn-LOGS", model.currentBucket)
+
+	// Back in the bucket list, Esc clears the filter
+	model.Update(S3ObjectMsg{Bucket: "cdn-LOGS", Objects: []s3.Object{{Key: "index.html"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	assert.Contains(t, model.View(), "No S3 buckets match the filter")
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, model.IsFiltering())
+	assert.Contains(t, model.View(), "backups")
+}
+
+// TestS3ModelSortObjects tests sorting the object list.
+// It verifies that the objects of a bucket can be sorted by size.
+func TestS3ModelSortObjects(t *testing.T) {
+	model := NewS3Model()
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "assets", Region: "us-east-1"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(S3ObjectMsg{Bucket: "assets", Objects: []s3.Object{
+		{Key: "medium.png", Size: 2048},
+		{Key: "large.png", Size: 4096},
+		{Key: "small.png", Size: 1024},
+	}})
+
+	// Call the function: sort by key, then by size
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	assertOrder(t, model.View(), "large.png", "medium.png", "small.png")
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+
+	// Assert the rows are ordered by size
+	view := model.View()
+	assert.Contains(t, view, "SIZE ↑")
+	assertOrder(t, view, "small.png", "medium.png", "large.png")
+}
+
+// TestS3ModelYankValue tests the value copied with y in the S3 view.
+// It verifies that the bucket name is copied in the bucket list, and
+// bucket/key in the object list.
+func TestS3ModelYankValue(t *testing.T) {
+	model := NewS3Model()
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "assets"}, {Name: "logs"}}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// Call the function
+	assert.Equal(t, "logs", model.yankValue())
+
+	// Enter the bucket and select an object
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, model.yankValue())
+	model.Update(S3ObjectMsg{Bucket: "logs", Objects: []s3.Object{{Key: "2024/app.log"}}})
+	assert.Equal(t, "logs/2024/app.log", model.yankValue())
+}
+
+// TestS3ModelDetail tests the details of the selected row in the S3 view.
+// It verifies that objects have details while buckets and folders have none,
+// so that Enter still opens them.
+func TestS3ModelDetail(t *testing.T) {
+	model := NewS3Model()
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "logs"}}})
+
+	// Buckets have no details
+	detail, err := model.Detail(model.Selected())
+	assert.NoError(t, err)
+	assert.Empty(t, detail)
+
+	// Enter the bucket, which has a folder and an object
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(S3ObjectMsg{
+		Bucket:   "logs",
+		Prefixes: []string{"2024/"},
+		Objects:  []s3.Object{{Key: "app.log", Size: 2048, ETag: `"abc"`, StorageClass: "STANDARD"}},
+	})
+
+	// Folders have no details
+	detail, err = model.Detail(model.Selected())
+	assert.NoError(t, err)
+	assert.Empty(t, detail)
+
+	// Call the function
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	detail, err = model.Detail(model.Selected())
+
+	// Assert no error
+	assert.NoError(t, err)
+	assert.Contains(t, detail, "s3://logs/app.log")
+	assert.Contains(t, detail, "2.00 KB (2048 bytes)")
+	assert.Contains(t, detail, `"abc"`)
+	assert.Contains(t, detail, "STANDARD")
+}
+
+// pagedS3Client is an S3 client that returns a page of objects for each
+// continuation token and records the tokens it was called with. Only
+// ListObjectsV2 is implemented.
+type pagedS3Client struct {
+	s3.S3Client
+	pages  map[string]*awss3.ListObjectsV2Output
+	tokens []string
+}
+
+func (c *pagedS3Client) ListObjectsV2(ctx context.Context, params *awss3.ListObjectsV2Input, optFns ...func(*awss3.Options)) (*awss3.ListObjectsV2Output, error) {
+	token := aws.ToString(params.ContinuationToken)
+	c.tokens = append(c.tokens, token)
+	return c.pages[token], nil
+}
+
+// TestS3ModelLoadMoreObjects tests loading the objects of a bucket a page at a time.
+// It verifies that only the first page is loaded when entering a bucket, that n
+// loads the next page with the continuation token of the first, and that the
+// page line shows whether there are more objects.
+func TestS3ModelLoadMoreObjects(t *testing.T) {
+	client := &pagedS3Client{pages: map[string]*awss3.ListObjectsV2Output{
+		"": {
+			Contents:              []types.Object{{Key: aws.String("a.log")}, {Key: aws.String("b.log")}},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("page-2"),
+		},
+		"page-2": {
+			Contents: []types.Object{{Key: aws.String("c.log")}},
+		},
+	}}
+	model := NewS3Model()
+	model.adapter = s3.NewAdapterWithClient(client)
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "logs-bucket"}}})
+
+	// Entering the bucket loads the first page
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(model.loadObjects("")())
+	assert.Len(t, model.objects, 2)
+	assert.Equal(t, "page 1, 2 objects loaded, press n to load more", model.pageLine())
+
+	// n loads the next page
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	model.Update(model.loadObjects(model.nextToken)())
+
+	// Assert the objects of both pages are shown
+	assert.Equal(t, []string{"a.log", "b.log", "c.log"}, []string{model.objects[0].Key, model.objects[1].Key, model.objects[2].Key})
+	assert.Equal(t, "page 2, 3 objects", model.pageLine())
+	assert.Equal(t, []string{"", "page-2"}, client.tokens)
+
+	// There is nothing more to load
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.Nil(t, cmd)
+}
+
+// folderS3Client is an S3 client that lists the levels of a bucket with
+// folders, keyed by prefix. Only ListObjectsV2 is implemented.
+type folderS3Client struct {
+	s3.S3Client
+	levels map[string]*awss3.ListObjectsV2Output
+}
+
+func (c *folderS3Client) ListObjectsV2(ctx context.Context, params *awss3.ListObjectsV2Input, optFns ...func(*awss3.Options)) (*awss3.ListObjectsV2Output, error) {
+	if aws.ToString(params.Delimiter) != "/" {
+		return nil, errors.New("expected a delimiter")
+	}
+	return c.levels[aws.ToString(params.Prefix)], nil
+}
+
+// TestS3ModelFolders tests browsing the folders of a bucket.
+// It verifies that common prefixes are shown as folders before the objects of a
+// level, that Enter on a folder descends into it, that Esc goes up one level
+// and then back to the bucket list, and that pages of a level or bucket that
+// was left are ignored.
+func TestS3ModelFolders(t *testing.T) {
+	client := &folderS3Client{levels: map[string]*awss3.ListObjectsV2Output{
+		"": {
+			CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("logs/")}},
+			Contents:       []types.Object{{Key: aws.String("index.html")}},
+		},
+		"logs/": {
+			CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("logs/2024/")}},
+			Contents:       []types.Object{{Key: aws.String("logs/")}, {Key: aws.String("logs/app.log")}},
+		},
+	}}
+	model := NewS3Model()
+	model.adapter = s3.NewAdapterWithClient(client)
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "site"}}})
+
+	// The top level shows the folder before the object
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(model.loadObjects("")())
+	assert.Equal(t, []string{"logs/"}, model.prefixes)
+	assert.Equal(t, "site/logs/", model.yankValue())
+	assert.Equal(t, "page 1, 1 folders, 1 objects", model.pageLine())
+
+	// Enter descends into the folder, whose placeholder object isn't shown
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "logs/", model.prefix)
+	model.Update(model.loadObjects("")())
+	assert.Equal(t, []string{"logs/2024/"}, model.prefixes)
+	require.Len(t, model.objects, 1)
+	assert.Equal(t, "logs/app.log", model.objects[0].Key)
+
+	// Names are shown relative to the folder
+	model.SetSize(100, 30)
+	view := model.View()
+	assert.Contains(t, view, "2024/")
+	assert.Contains(t, view, "app.log")
+	assert.NotContains(t, view, "logs/app.log")
+
+	// The objects of a level that was left are ignored
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, "", model.prefix)
+	model.Update(S3ObjectMsg{Bucket: "site", Prefix: "logs/", Objects: []s3.Object{{Key: "logs/app.log"}}})
+	assert.Empty(t, model.objects)
+
+	// So are the objects of a bucket that was left
+	model.Update(S3ObjectMsg{Bucket: "other", Objects: []s3.Object{{Key: "other.html"}}})
+	assert.Empty(t, model.objects)
+
+	// Esc at the top level goes back to the bucket list
+	model.Update(model.loadObjects("")())
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, model.viewingObjects)
+}
+
+// TestParentPrefix tests finding the level above a prefix.
+func TestParentPrefix(t *testing.T) {
+	assert.Equal(t, "logs/", parentPrefix("logs/2024/"))
+	assert.Equal(t, "", parentPrefix("logs/"))
+	assert.Equal(t, "", parentPrefix(""))
+	assert.Equal(t, "a/b/", parentPrefix("a/b/c/"))
+}
+
+// TestS3ModelLoadingTimeout tests the loading timeout of the S3 view with a
+// fake clock.
+// It verifies that checks within the timeout schedule the next one, that the
+// time spent loading is shown after 5 seconds, that a check past the timeout
+// reports it, and that checks of a loading replaced by a refresh are dropped.
+func TestS3ModelLoadingTimeout(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	model := NewS3Model()
+	model.SetClock(clk)
+	model.SetLoadingTimeout(10 * time.Second)
+
+	// Init starts loading the buckets (the load itself is not run)
+	assert.NotNil(t, model.Init())
+	assert.True(t, model.IsLoading())
+	check := timeoutCheckMsg{source: "S3Model", load: 1}
+
+	// Within the timeout, the next check is scheduled
+	clk.Advance(5 * time.Second)
+	_, cmd := model.Update(check)
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.Contains(t, model.View(), "Loading S3 data...")
+	assert.NotContains(t, model.View(), "(5s)")
+
+	// The time spent loading is shown after 5 seconds
+	clk.Advance(time.Second)
+	assert.Contains(t, model.View(), "Loading S3 data... (6s)")
+
+	// Checks of another view are ignored
+	_, cmd = model.Update(timeoutCheckMsg{source: "LambdaModel", load: 1})
+	assert.Nil(t, cmd)
+
+	// Past the timeout, the check reports it
+	clk.Advance(5 * time.Second)
+	_, cmd = model.Update(check)
+	require.NotNil(t, cmd)
+	msg := cmd()
+	assert.Equal(t, TimeoutMsg{Message: "Operation timed out after 10s", Source: "S3Model"}, msg)
+
+	model.Update(msg)
+	assert.False(t, model.IsLoading())
+	assert.EqualError(t, model.GetError(), "operation timed out after 10s")
+
+	// Refreshing starts a new loading, which checks of the first one don't time out
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.IsLoading())
+	assert.NoError(t, model.GetError())
+	clk.Advance(time.Minute)
+	_, cmd = model.Update(check)
+	assert.Nil(t, cmd)
+
+	// Once loaded, checks stop
+	model.Update(S3BucketMsg{Buckets: []s3.Bucket{{Name: "logs"}}})
+	_, cmd = model.Update(timeoutCheckMsg{source: "S3Model", load: 2})
+	assert.Nil(t, cmd)
+	assert.False(t, model.IsLoading())
+}
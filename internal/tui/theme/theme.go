@@ -0,0 +1,210 @@
+// Package theme provides the color themes used by the terminal user interface.
+// All TUI styles take their colors from the current theme, which is selected
+// with the app.theme configuration setting.
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultName is the name of the theme used when none is configured
+const DefaultName = "default"
+
+// NoColorName is the name of the theme without colors, for terminals that
+// cannot show them
+const NoColorName = "nocolor"
+
+// Theme holds the colors used by the TUI styles
+type Theme struct {
+	Name       string         // Name of the theme, as used in the configuration
+	Primary    lipgloss.Color // Selected items, titles and focused borders
+	Accent     lipgloss.Color // Logo and active tab
+	Text       lipgloss.Color // Text on colored backgrounds
+	Foreground lipgloss.Color // Text on the terminal background, such as table headers (empty for the terminal's color)
+	Muted      lipgloss.Color // Inactive tabs
+	Border     lipgloss.Color // Unfocused borders
+	Surface    lipgloss.Color // Status bar and command bar backgrounds
+	Secondary  lipgloss.Color // Secondary logo color and logo border
+	Context    lipgloss.Color // Context selector and context status section
+	Region     lipgloss.Color // Region selector
+	Role       lipgloss.Color // Role status section
+	Success    lipgloss.Color // Region status section and connected status
+	Warning    lipgloss.Color // Loading messages and highlighted values
+	Error      lipgloss.Color // Error messages and disconnected status
+}
+
+// themes are the built-in themes, by name
+var themes = map[string]Theme{
+	DefaultName: {
+		Name:       DefaultName,
+		Primary:    lipgloss.Color("#0066cc"),
+		Accent:     lipgloss.Color("#FF9900"), // AWS Orange color
+		Text:       lipgloss.Color("#FFFFFF"),
+		Foreground: lipgloss.Color("#FFFFFF"),
+		Muted:      lipgloss.Color("#AAAAAA"),
+		Border:     lipgloss.Color("#666666"),
+		Surface:    lipgloss.Color("#333333"),
+		Secondary:  lipgloss.Color("#232F3E"), // AWS Squid Ink color
+		Context:    lipgloss.Color("#9900cc"),
+		Region:     lipgloss.Color("#00cc66"),
+		Role:       lipgloss.Color("#cc6600"),
+		Success:    lipgloss.Color("#006600"),
+		Warning:    lipgloss.Color("#FFAA00"),
+		Error:      lipgloss.Color("#cc0000"),
+	},
+	// Brighter colors for terminals with a dark background
+	"dark": {
+		Name:       "dark",
+		Primary:    lipgloss.Color("#3399FF"),
+		Accent:     lipgloss.Color("#FF9900"),
+		Text:       lipgloss.Color("#FFFFFF"),
+		Foreground: lipgloss.Color("#E4E4E4"),
+		Muted:      lipgloss.Color("#8A8A8A"),
+		Border:     lipgloss.Color("#585858"),
+		Surface:    lipgloss.Color("#262626"),
+		Secondary:  lipgloss.Color("#4E5D6C"),
+		Context:    lipgloss.Color("#C678DD"),
+		Region:     lipgloss.Color("#5FD787"),
+		Role:       lipgloss.Color("#E5A050"),
+		Success:    lipgloss.Color("#5FAF5F"),
+		Warning:    lipgloss.Color("#FFD75F"),
+		Error:      lipgloss.Color("#FF5F5F"),
+	},
+	// Darker colors for terminals with a light background
+	"light": {
+		Name:       "light",
+		Primary:    lipgloss.Color("#0055AA"),
+		Accent:     lipgloss.Color("#C05F00"),
+		Text:       lipgloss.Color("#FFFFFF"),
+		Foreground: lipgloss.Color("#1C1C1C"),
+		Muted:      lipgloss.Color("#6C6C6C"),
+		Border:     lipgloss.Color("#A8A8A8"),
+		Surface:    lipgloss.Color("#4E4E4E"),
+		Secondary:  lipgloss.Color("#232F3E"),
+		Context:    lipgloss.Color("#7A00A8"),
+		Region:     lipgloss.Color("#007A3D"),
+		Role:       lipgloss.Color("#9A4A00"),
+		Success:    lipgloss.Color("#006600"),
+		Warning:    lipgloss.Color("#AF5F00"),
+		Error:      lipgloss.Color("#B00000"),
+	},
+	// No colors at all, for dumb terminals: every color is left to the
+	// terminal, and selected rows are marked with SelectionMarker instead
+	NoColorName: {
+		Name: NoColorName,
+	},
+	// Saturated colors that stay readable on both light and dark terminals
+	"high-contrast": {
+		Name:       "high-contrast",
+		Primary:    lipgloss.Color("#0000D7"),
+		Accent:     lipgloss.Color("#D75F00"),
+		Text:       lipgloss.Color("#FFFFFF"),
+		Foreground: lipgloss.Color(""),
+		Muted:      lipgloss.Color("#808080"),
+		Border:     lipgloss.Color("#808080"),
+		Surface:    lipgloss.Color("#000000"),
+		Secondary:  lipgloss.Color("#808080"),
+		Context:    lipgloss.Color("#8700AF"),
+		Region:     lipgloss.Color("#008700"),
+		Role:       lipgloss.Color("#AF5F00"),
+		Success:    lipgloss.Color("#008700"),
+		Warning:    lipgloss.Color("#D78700"),
+		Error:      lipgloss.Color("#D70000"),
+	},
+	// The Solarized palette (https://ethanschoonover.com/solarized/)
+	"solarized": {
+		Name:       "solarized",
+		Primary:    lipgloss.Color("#268bd2"),
+		Accent:     lipgloss.Color("#cb4b16"),
+		Text:       lipgloss.Color("#fdf6e3"),
+		Foreground: lipgloss.Color("#657b83"),
+		Muted:      lipgloss.Color("#93a1a1"),
+		Border:     lipgloss.Color("#657b83"),
+		Surface:    lipgloss.Color("#073642"),
+		Secondary:  lipgloss.Color("#586e75"),
+		Context:    lipgloss.Color("#6c71c4"),
+		Region:     lipgloss.Color("#2aa198"),
+		Role:       lipgloss.Color("#b58900"),
+		Success:    lipgloss.Color("#859900"),
+		Warning:    lipgloss.Color("#b58900"),
+		Error:      lipgloss.Color("#dc322f"),
+	},
+}
+
+// current is the theme used by the TUI styles
+var current = themes[DefaultName]
+
+// accessible reports whether accessibility mode is on
+var accessible bool
+
+// Current returns the theme used by the TUI styles
+func Current() Theme {
+	return current
+}
+
+// Set makes the named built-in theme the current theme.
+//
+// Returns an error listing the available themes if there is no theme with that name.
+func Set(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	current = t
+	return nil
+}
+
+// SetAccessible turns accessibility mode on or off. Accessibility mode switches
+// to the high-contrast theme, shows muted text such as inactive tabs in the
+// terminal's own color, turns off faint text, and marks the selected row of
+// lists with SelectionMarker so it doesn't rely on color alone.
+//
+// Turning it off leaves the high-contrast theme in place until Set is called.
+func SetAccessible(enabled bool) {
+	accessible = enabled
+	if enabled {
+		t := themes["high-contrast"]
+		t.Muted = t.Foreground
+		current = t
+	}
+}
+
+// Accessible reports whether accessibility mode is on
+func Accessible() bool {
+	return accessible
+}
+
+// SelectionMarker returns the prefix for a row of a list: "> " for the
+// selected row and two spaces for the others in accessibility mode or with the
+// nocolor theme, where the selection cannot be told by its color, or an empty
+// string otherwise.
+func SelectionMarker(selected bool) string {
+	switch {
+	case !accessible && current.Name != NoColorName:
+		return ""
+	case selected:
+		return "> "
+	default:
+		return "  "
+	}
+}
+
+// IsValid reports whether name is the name of a built-in theme
+func IsValid(name string) bool {
+	_, ok := themes[name]
+	return ok
+}
+
+// Names returns the names of the built-in themes in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,112 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSet tests switching between the built-in themes.
+// It verifies that known themes become current and unknown ones are rejected
+// without changing the current theme.
+func TestSet(t *testing.T) {
+	t.Cleanup(func() { Set(DefaultName) })
+
+	// The default theme is current initially
+	assert.Equal(t, DefaultName, Current().Name)
+
+	// Switch to another theme
+	assert.NoError(t, Set("solarized"))
+	assert.Equal(t, "solarized", Current().Name)
+
+	// Unknown themes are rejected
+	err := Set("neon")
+	assert.ErrorContains(t, err, "dark, default, high-contrast, light, nocolor, solarized")
+	assert.Equal(t, "solarized", Current().Name)
+}
+
+// TestThemesComplete tests the built-in themes.
+// It verifies that every theme but nocolor sets all of its colors, except
+// Foreground which may be left to the terminal.
+func TestThemesComplete(t *testing.T) {
+	assert.Equal(t, []string{"dark", "default", "high-contrast", "light", "nocolor", "solarized"}, Names())
+
+	for _, name := range Names() {
+		th := themes[name]
+		assert.True(t, IsValid(name))
+		assert.Equal(t, name, th.Name)
+		if name == NoColorName {
+			continue
+		}
+		for _, c := range []string{
+			string(th.Primary), string(th.Accent), string(th.Text), string(th.Muted), string(th.Border),
+			string(th.Surface), string(th.Secondary), string(th.Context), string(th.Region), string(th.Role),
+			string(th.Success), string(th.Warning), string(th.Error),
+		} {
+			assert.NotEmpty(t, c, name)
+		}
+	}
+}
+
+// TestSetAccessible tests accessibility mode.
+// It verifies that it switches to the high-contrast theme without muted colors
+// and marks the selected row.
+func TestSetAccessible(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		Set(DefaultName)
+	})
+
+	// Rows are not marked outside of accessibility mode
+	assert.False(t, Accessible())
+	assert.Empty(t, SelectionMarker(true))
+	assert.Empty(t, SelectionMarker(false))
+
+	SetAccessible(true)
+	assert.True(t, Accessible())
+	assert.Equal(t, "high-contrast", Current().Name)
+	assert.Equal(t, Current().Foreground, Current().Muted)
+	assert.Equal(t, "> ", SelectionMarker(true))
+	assert.Equal(t, "  ", SelectionMarker(false))
+}
+
+// TestNoColor tests the nocolor theme.
+// It verifies that styles using its colors render without ANSI color codes,
+// even on a terminal that supports colors, and that selected rows are marked.
+func TestNoColor(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		Set(DefaultName)
+	})
+
+	// render renders text with every color of the current theme
+	render := func() string {
+		th := Current()
+		var b strings.Builder
+		for _, c := range []lipgloss.Color{
+			th.Primary, th.Accent, th.Text, th.Foreground, th.Muted, th.Border, th.Surface,
+			th.Secondary, th.Context, th.Region, th.Role, th.Success, th.Warning, th.Error,
+		} {
+			b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(c).Background(c).Render("text"))
+			b.WriteString(lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(c).Render("text"))
+		}
+		return b.String()
+	}
+
+	// Other themes do use color codes
+	assert.Contains(t, render(), "\x1b[38;2;")
+	assert.Empty(t, SelectionMarker(true))
+
+	assert.NoError(t, Set(NoColorName))
+	output := render()
+	assert.Contains(t, output, "text")
+	assert.NotContains(t, output, "\x1b[38;")
+	assert.NotContains(t, output, "\x1b[48;")
+	assert.Equal(t, "> ", SelectionMarker(true))
+	assert.Equal(t, "  ", SelectionMarker(false))
+}
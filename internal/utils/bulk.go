@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BulkFailure describes an item that failed in a bulk operation
+type BulkFailure struct {
+	Item  string `json:"item" yaml:"item"`   // Item that failed (e.g. an instance ID or S3 URL)
+	Error string `json:"error" yaml:"error"` // Error message for the item
+}
+
+// BulkResult collects the outcome of an operation applied to many items
+type BulkResult struct {
+	Succeeded []string      `json:"succeeded" yaml:"succeeded"` // Items that succeeded
+	Failed    []BulkFailure `json:"failed" yaml:"failed"`       // Items that failed, with their errors
+}
+
+// Record adds the outcome of the operation for an item
+func (r *BulkResult) Record(item string, err error) {
+	if err != nil {
+		r.Failed = append(r.Failed, BulkFailure{Item: item, Error: err.Error()})
+		return
+	}
+	r.Succeeded = append(r.Succeeded, item)
+}
+
+// Total returns the number of items processed
+func (r *BulkResult) Total() int {
+	return len(r.Succeeded) + len(r.Failed)
+}
+
+// Summary returns a human-readable summary: the succeeded and failed counts,
+// followed by one line per failed item
+func (r *BulkResult) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d succeeded, %d failed", len(r.Succeeded), len(r.Failed))
+
+	if len(r.Failed) > 0 {
+		sb.WriteString("\nFailed:")
+		for _, failure := range r.Failed {
+			fmt.Fprintf(&sb, "\n  %s: %s", failure.Item, failure.Error)
+		}
+	}
+
+	return sb.String()
+}
+
+// Err returns an error if any item failed, or nil if all items succeeded
+func (r *BulkResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d operations failed", len(r.Failed), r.Total())
+}
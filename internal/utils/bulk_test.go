@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBulkResult tests the BulkResult type.
+// It verifies that outcomes are recorded per item and that the summary
+// lists every failed item with its error.
+func TestBulkResult(t *testing.T) {
+	result := &BulkResult{}
+
+	// No items processed yet
+	assert.Equal(t, 0, result.Total())
+	assert.NoError(t, result.Err())
+
+	// Record a mix of successes and failures
+	result.Record("i-1", nil)
+	result.Record("i-2", errors.New("instance not found"))
+	result.Record("i-3", nil)
+	result.Record("i-4", errors.New("access denied"))
+
+	assert.Equal(t, []string{"i-1", "i-3"}, result.Succeeded)
+	assert.Equal(t, []BulkFailure{
+		{Item: "i-2", Error: "instance not found"},
+		{Item: "i-4", Error: "access denied"},
+	}, result.Failed)
+	assert.Equal(t, 4, result.Total())
+	assert.EqualError(t, result.Err(), "2 of 4 operations failed")
+	assert.Equal(t, "2 succeeded, 2 failed\nFailed:\n  i-2: instance not found\n  i-4: access denied", result.Summary())
+
+	// All items succeeded
+	result = &BulkResult{}
+	result.Record("i-1", nil)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, "1 succeeded, 0 failed", result.Summary())
+}
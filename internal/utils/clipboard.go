@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/atotto/clipboard"
+)
+
+// ErrNoClipboard is returned by CopyToClipboard when there is no system clipboard,
+// e.g. on a Linux server without xclip, xsel or wl-clipboard
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// CopyToClipboard copies text to the system clipboard
+func CopyToClipboard(text string) error {
+	if clipboard.Unsupported {
+		return ErrNoClipboard
+	}
+
+	if err := clipboard.WriteAll(text); err != nil {
+		return fmt.Errorf("%w: %v", ErrNoClipboard, err)
+	}
+
+	return nil
+}
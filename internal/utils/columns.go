@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+)
+
+// columnSet is the columns of the table output of a record type
+type columnSet struct {
+	normal []string // Columns shown in table output
+	wide   []string // Columns added after them in wide output
+}
+
+// tableColumns are the column sets of the record types whose table output
+// doesn't show all their fields, keyed by the type name (package.Type). The
+// fields left out of both sets are only shown with --columns or in JSON, YAML
+// and CSV output; types not listed show all their fields.
+var tableColumns = map[string]columnSet{
+	"ec2.Instance": {
+		normal: []string{"ID", "Name", "Type", "State", "PublicIP", "PrivateIP"},
+		wide:   []string{"AZ", "VpcID", "SubnetID", "LaunchTime", "SecurityIDs"},
+	},
+	"ec2.InstanceWithSpecs": {
+		normal: []string{"ID", "Name", "Type", "State", "PublicIP", "PrivateIP", "VCPUs", "MemoryMiB"},
+		wide:   []string{"AZ", "VpcID", "SubnetID", "LaunchTime", "SecurityIDs"},
+	},
+	"s3.Object": {
+		normal: []string{"Key", "Size", "LastModified"},
+		wide:   []string{"ETag", "StorageClass", "Owner"},
+	},
+	"s3.ListingEntry": {
+		normal: []string{"Type", "Key", "Size", "LastModified"},
+		wide:   []string{"StorageClass"},
+	},
+	"lambda.Function": {
+		normal: []string{"Name", "Runtime", "Memory", "Timeout", "LastModified"},
+		wide:   []string{"Handler", "Size", "Version", "Role"},
+	},
+}
+
+// defaultColumns returns the columns shown in table output of data, a record
+// or a slice of records, including the wide columns if wide is set.
+//
+// Returns nil if all fields are shown.
+func defaultColumns(data interface{}, wide bool) []string {
+	t := reflect.TypeOf(data)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+
+	set, ok := tableColumns[t.String()]
+	if !ok {
+		return nil
+	}
+
+	columns := append([]string(nil), set.normal...)
+	if wide {
+		columns = append(columns, set.wide...)
+	}
+	return columns
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/ao/awsm/internal/aws/lambda"
+	"github.com/ao/awsm/internal/aws/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWideOutput tests the table and wide output formats.
+// It verifies that table output leaves out the wide columns of a record type,
+// that wide output adds them after the default columns, and that --columns
+// still selects from all fields.
+func TestWideOutput(t *testing.T) {
+	instances := []ec2.Instance{{
+		ID:         "i-12345",
+		Name:       "web",
+		State:      "running",
+		AZ:         "us-east-1a",
+		VpcID:      "vpc-12345",
+		SubnetID:   "subnet-12345",
+		LaunchTime: "2024-01-01 12:00:00",
+	}}
+
+	// Table output shows the default columns only
+	output, err := FormatOutput(instances, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "i-12345")
+	assert.Contains(t, output, "running")
+	assert.NotContains(t, output, "vpc-12345")
+	assert.NotContains(t, output, "subnet-12345")
+	assert.NotContains(t, output, "us-east-1a")
+	assert.NotContains(t, output, "2024-01-01")
+
+	// Wide output adds the wide columns after them
+	output, err = FormatOutput(instances, "wide")
+	require.NoError(t, err)
+	assert.Contains(t, output, "running")
+	assert.Contains(t, output, "vpc-12345")
+	assert.Contains(t, output, "subnet-12345")
+	assert.Contains(t, output, "us-east-1a")
+	assert.Contains(t, output, "2024-01-01")
+	assert.Less(t, strings.Index(output, "running"), strings.Index(output, "vpc-12345"))
+
+	// Fields that are in neither set are left out of both
+	assert.NotContains(t, strings.ToUpper(output), "ELASTIC")
+
+	// S3 objects
+	objects := []s3.Object{{Key: "index.html", Size: 42, ETag: "abc123", StorageClass: "GLACIER", Owner: "jane"}}
+	output, err = FormatOutput(objects, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "index.html")
+	assert.NotContains(t, output, "abc123")
+	assert.NotContains(t, output, "GLACIER")
+	assert.NotContains(t, output, "jane")
+
+	output, err = FormatOutput(objects, "wide")
+	require.NoError(t, err)
+	assert.Contains(t, output, "abc123")
+	assert.Contains(t, output, "GLACIER")
+	assert.Contains(t, output, "jane")
+
+	// Types without column sets show all their fields in both formats
+	summary := []ec2.AZSummary{{AZ: "us-east-1a", Count: 2, Percentage: 100}}
+	table, err := FormatOutput(summary, "table")
+	require.NoError(t, err)
+	wide, err := FormatOutput(summary, "wide")
+	require.NoError(t, err)
+	assert.Equal(t, table, wide)
+
+	// Selected columns override the column sets
+	SetColumns([]string{"ID", "ElasticIPs"})
+	defer SetColumns(nil)
+	output, err = FormatOutput(instances, "table")
+	require.NoError(t, err)
+	assert.Contains(t, strings.ToUpper(output), "ELASTIC")
+	assert.NotContains(t, output, "running")
+
+	// The format is accepted as an output format
+	assert.True(t, IsValidOutputFormat("wide"))
+}
+
+// TestTableColumnsExist tests the column sets of tableColumns.
+// It verifies that every column is a field of its record type, so that a
+// renamed field doesn't break table output.
+func TestTableColumnsExist(t *testing.T) {
+	records := []interface{}{
+		ec2.Instance{},
+		ec2.InstanceWithSpecs{},
+		s3.Object{},
+		s3.ListingEntry{},
+		lambda.Function{},
+	}
+
+	require.Len(t, records, len(tableColumns))
+	for _, record := range records {
+		name := reflect.TypeOf(record).String()
+		set, ok := tableColumns[name]
+		require.True(t, ok, name)
+
+		fields := structFields(reflect.TypeOf(record))
+		for _, column := range append(set.normal, set.wide...) {
+			assert.Contains(t, fields, column, name)
+		}
+	}
+}
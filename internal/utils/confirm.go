@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// IsInteractive reports whether stdin is attached to a terminal
+func IsInteractive() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// ConfirmDestructive asks the user to type the name of the resource to confirm
+// a destructive operation. It only returns true on an exact match; an empty
+// answer, a mismatch, or an interrupted read (e.g. Ctrl+D) cancel the operation.
+func ConfirmDestructive(name string) bool {
+	return confirmDestructive(os.Stdin, os.Stderr, name)
+}
+
+// confirmDestructive implements ConfirmDestructive with the given input and output
+func confirmDestructive(in io.Reader, out io.Writer, name string) bool {
+	fmt.Fprintf(out, "This operation cannot be undone. Type %q to confirm: ", name)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		// Input was closed or interrupted before an answer was given
+		fmt.Fprintln(out)
+		return false
+	}
+
+	return strings.TrimRight(answer, "\r\n") == name
+}
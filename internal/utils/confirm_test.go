@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfirmDestructive tests the confirmDestructive function.
+// It verifies that only an exact match of the resource name confirms the operation.
+func TestConfirmDestructive(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Exact match", input: "my-bucket\n", expected: true},
+		{name: "Exact match with CRLF", input: "my-bucket\r\n", expected: true},
+		{name: "Exact match without newline", input: "my-bucket", expected: true},
+		{name: "Yes is not enough", input: "y\n", expected: false},
+		{name: "Case mismatch", input: "My-Bucket\n", expected: false},
+		{name: "Surrounding whitespace", input: " my-bucket\n", expected: false},
+		{name: "Empty answer", input: "\n", expected: false},
+		{name: "Closed input", input: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+
+			result := confirmDestructive(strings.NewReader(tc.input), out, "my-bucket")
+
+			assert.Equal(t, tc.expected, result)
+			assert.Contains(t, out.String(), `Type "my-bucket" to confirm`)
+		})
+	}
+}
@@ -2,12 +2,16 @@ package utils
 
 import (
 	"bytes"
-	"encoding/json"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/hokaccha/go-prettyjson"
+	"github.com/mattn/go-isatty"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v3"
 )
@@ -19,20 +23,138 @@ const (
 	// FormatJSON outputs data in JSON format
 	FormatJSON OutputFormat = "json"
 
+	// FormatJSONL outputs data as JSON lines: one JSON value per line for each
+	// element of a slice, for streaming into tools such as jq
+	FormatJSONL OutputFormat = "jsonl"
+
 	// FormatYAML outputs data in YAML format
 	FormatYAML OutputFormat = "yaml"
 
 	// FormatTable outputs data in table format
 	FormatTable OutputFormat = "table"
 
+	// FormatWide outputs data in table format with extra columns
+	FormatWide OutputFormat = "wide"
+
 	// FormatText outputs data in plain text format
 	FormatText OutputFormat = "text"
+
+	// FormatCSV outputs data as comma-separated values with a header row
+	FormatCSV OutputFormat = "csv"
 )
 
+// outputWriter is where PrintOutput writes formatted output (nil for os.Stdout)
+var outputWriter io.Writer
+
+// SetOutputWriter redirects PrintOutput to the given writer. Color codes are
+// only kept when the writer is a terminal, so output captured in a file or
+// buffer is plain text. Passing nil restores the default of writing to stdout.
+func SetOutputWriter(w io.Writer) {
+	outputWriter = w
+}
+
+// isTerminal reports whether f is a terminal; tests replace it to simulate one
+var isTerminal = func(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// NoColor reports whether the NO_COLOR environment variable is set to a
+// non-empty value, asking for output without colors (https://no-color.org)
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// ColorEnabled reports whether output written to w may contain color and other
+// ANSI styling codes: w must be a terminal, so that redirected output stays
+// plain text, and NO_COLOR must not be set.
+func ColorEnabled(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && !NoColor() && isTerminal(f)
+}
+
+// OutputWriter returns the writer that command output goes to: the writer set
+// with SetOutputWriter, or stdout, resolved at call time so a replaced os.Stdout
+// is honored. Commands printing lines of their own write them here, so that
+// --output-file captures all of their output.
+func OutputWriter() io.Writer {
+	if outputWriter == nil {
+		return os.Stdout
+	}
+	return outputWriter
+}
+
+// outputColumns lists the columns shown in table and CSV output (empty for all)
+var outputColumns []string
+
+// SetColumns selects which columns table and CSV output show, and in which order.
+// Names are matched case-insensitively against the struct field names; an empty
+// list shows all columns.
+func SetColumns(columns []string) {
+	outputColumns = columns
+}
+
+// noHeaders suppresses the header row in table and CSV output
+var noHeaders bool
+
+// SetNoHeaders sets whether table and CSV output omit the header row, so that
+// the output of several commands can be concatenated or appended to a file
+func SetNoHeaders(enabled bool) {
+	noHeaders = enabled
+}
+
+// compactJSON controls whether JSON output is printed on a single line
+var compactJSON bool
+
+// SetCompactJSON sets whether JSON output is printed on a single line instead
+// of being indented with two spaces
+func SetCompactJSON(enabled bool) {
+	compactJSON = enabled
+}
+
+// yamlFlowStyle controls whether YAML output uses flow style instead of block style
+var yamlFlowStyle bool
+
+// SetYAMLFlowStyle sets whether YAML output uses compact flow style
+// (e.g. {name: web, tags: [a, b]}) instead of the default block style
+func SetYAMLFlowStyle(enabled bool) {
+	yamlFlowStyle = enabled
+}
+
+// listEnvelope controls whether PrintList wraps JSON and YAML output in a ListEnvelope
+var listEnvelope bool
+
+// SetEnvelope sets whether PrintList wraps the results of list commands in a
+// ListEnvelope in JSON and YAML output
+func SetEnvelope(enabled bool) {
+	listEnvelope = enabled
+}
+
+// ListEnvelope wraps the results of a list command in JSON and YAML output when
+// enabled with SetEnvelope, so that scripts can tell whether a limit cut them short
+type ListEnvelope struct {
+	Items     interface{} `json:"items" yaml:"items"`                               // Results of the command
+	Count     int         `json:"count" yaml:"count"`                               // Number of results
+	Limit     int32       `json:"limit,omitempty" yaml:"limit,omitempty"`           // Maximum number of results requested (0 for none)
+	Truncated bool        `json:"truncated" yaml:"truncated"`                       // Whether there are more results than the ones in Items
+	NextToken string      `json:"next_token,omitempty" yaml:"next_token,omitempty"` // Token that resumes the command after the last result, if it supports one
+}
+
 // IsValidOutputFormat checks if the given format is valid
 func IsValidOutputFormat(format string) bool {
 	switch OutputFormat(format) {
-	case FormatJSON, FormatYAML, FormatTable, FormatText:
+	case FormatJSON, FormatJSONL, FormatYAML, FormatTable, FormatWide, FormatText, FormatCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStructuredFormat checks if the given format is one of the structured
+// formats (JSON, JSON lines or YAML), in which commands print their results as
+// records rather than as text meant to be read
+func IsStructuredFormat(format string) bool {
+	switch OutputFormat(format) {
+	case FormatJSON, FormatJSONL, FormatYAML:
 		return true
 	default:
 		return false
@@ -44,23 +166,38 @@ func FormatOutput(data interface{}, format string) (string, error) {
 	switch OutputFormat(format) {
 	case FormatJSON:
 		return formatJSON(data)
+	case FormatJSONL:
+		return formatJSONL(data)
 	case FormatYAML:
 		return formatYAML(data)
 	case FormatTable:
-		return formatTable(data)
+		return formatTable(data, false)
+	case FormatWide:
+		return formatTable(data, true)
 	case FormatText:
 		return formatText(data)
+	case FormatCSV:
+		return formatCSV(data)
 	default:
 		return "", fmt.Errorf("unsupported output format: %s", format)
 	}
 }
 
-// formatJSON formats data as JSON
-func formatJSON(data interface{}) (string, error) {
-	// Convert data to JSON with pretty formatting
+// jsonFormatter returns the formatter of JSON output, indented with two spaces
+// unless compact and colored when the output allows it
+func jsonFormatter(compact bool) *prettyjson.Formatter {
 	formatter := prettyjson.NewFormatter()
+	formatter.DisabledColor = !ColorEnabled(OutputWriter())
+	if compact {
+		formatter.Indent = 0
+		formatter.Newline = ""
+	}
+	return formatter
+}
 
-	output, err := formatter.Marshal(data)
+// formatJSON formats data as JSON
+func formatJSON(data interface{}) (string, error) {
+	output, err := jsonFormatter(compactJSON).Marshal(data)
 	if err != nil {
 		return "", fmt.Errorf("error formatting JSON: %w", err)
 	}
@@ -68,8 +205,37 @@ func formatJSON(data interface{}) (string, error) {
 	return string(output), nil
 }
 
+// formatJSONL formats data as JSON lines: each element of a slice is written
+// as compact JSON on a line of its own, and any other value on a single line
+func formatJSONL(data interface{}) (string, error) {
+	formatter := jsonFormatter(true)
+
+	v := indirectValue(reflect.ValueOf(data))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		output, err := formatter.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("error formatting JSON: %w", err)
+		}
+		return string(output), nil
+	}
+
+	lines := make([]string, v.Len())
+	for i := range lines {
+		output, err := formatter.Marshal(v.Index(i).Interface())
+		if err != nil {
+			return "", fmt.Errorf("error formatting JSON: %w", err)
+		}
+		lines[i] = string(output)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 // formatYAML formats data as YAML
 func formatYAML(data interface{}) (string, error) {
+	if yamlFlowStyle {
+		return formatYAMLFlow(data)
+	}
+
 	// Convert data to YAML
 	output, err := yaml.Marshal(data)
 	if err != nil {
@@ -79,57 +245,42 @@ func formatYAML(data interface{}) (string, error) {
 	return string(output), nil
 }
 
-// formatTable formats data as a table
-func formatTable(data interface{}) (string, error) {
-	// Convert data to a slice of maps for table formatting
-	var rows []map[string]interface{}
-
-	// Handle different input types
-	switch v := data.(type) {
-	case []map[string]interface{}:
-		rows = v
-	case map[string]interface{}:
-		rows = []map[string]interface{}{v}
-	case []interface{}:
-		for _, item := range v {
-			if m, ok := item.(map[string]interface{}); ok {
-				rows = append(rows, m)
-			} else {
-				// Convert item to JSON and then to map
-				jsonData, err := json.Marshal(item)
-				if err != nil {
-					continue
-				}
-
-				var m map[string]interface{}
-				if err := json.Unmarshal(jsonData, &m); err == nil {
-					rows = append(rows, m)
-				}
-			}
-		}
-	default:
-		// Convert to JSON and then to map
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return "", fmt.Errorf("error converting data to JSON: %w", err)
-		}
+// formatYAMLFlow formats data as flow-style YAML
+func formatYAMLFlow(data interface{}) (string, error) {
+	// Convert data to a YAML node so the style can be changed
+	var node yaml.Node
+	if err := node.Encode(data); err != nil {
+		return "", fmt.Errorf("error formatting YAML: %w", err)
+	}
 
-		var m map[string]interface{}
-		if err := json.Unmarshal(jsonData, &m); err != nil {
-			return "", fmt.Errorf("error converting JSON to map: %w", err)
-		}
+	// Flow style on the root applies to all nested collections
+	node.Style = yaml.FlowStyle
 
-		rows = []map[string]interface{}{m}
+	output, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", fmt.Errorf("error formatting YAML: %w", err)
 	}
 
+	return string(output), nil
+}
+
+// formatTable formats data as a table. Slices of structs are shown with one
+// column per field, and maps such as Tags are flattened into a single cell.
+// Record types listed in tableColumns only show their default columns, and
+// their wide columns too if wide is set. Sizes in bytes are humanized.
+func formatTable(data interface{}, wide bool) (string, error) {
+	headers, rows := recordTable(data, true)
 	if len(rows) == 0 {
 		return "No data to display", nil
 	}
 
-	// Extract headers from the first row
-	var headers []string
-	for k := range rows[0] {
-		headers = append(headers, k)
+	columns := outputColumns
+	if len(columns) == 0 {
+		columns = defaultColumns(data, wide)
+	}
+	headers, rows, err := selectColumns(headers, rows, columns)
+	if err != nil {
+		return "", err
 	}
 
 	// Create a buffer to store the table output
@@ -137,11 +288,9 @@ func formatTable(data interface{}) (string, error) {
 
 	// Create a new table writer
 	table := tablewriter.NewWriter(buf)
-
-	// Configure the table with options
-	table = tablewriter.NewWriter(buf)
-	opts := []tablewriter.Option{
-		tablewriter.WithHeader(headers),
+	var opts []tablewriter.Option
+	if !noHeaders {
+		opts = append(opts, tablewriter.WithHeader(headers))
 	}
 
 	for _, opt := range opts {
@@ -150,15 +299,7 @@ func formatTable(data interface{}) (string, error) {
 
 	// Add rows to the table
 	for _, row := range rows {
-		var values []string
-		for _, h := range headers {
-			if val, ok := row[h]; ok {
-				values = append(values, fmt.Sprintf("%v", val))
-			} else {
-				values = append(values, "")
-			}
-		}
-		table.Append(values)
+		table.Append(row)
 	}
 
 	// Render the table
@@ -181,18 +322,315 @@ func formatText(data interface{}) (string, error) {
 	}
 }
 
-// PrintOutput prints the formatted output to stdout
+// formatCSV formats data as CSV. Slices of structs are written as a header row
+// of field names followed by one row per element; a single struct is written as
+// one row. Maps in a field (such as Tags) are flattened to key=value;key=value
+// and slices to a;b in a single cell.
+func formatCSV(data interface{}) (string, error) {
+	headers, rows := recordTable(data, false)
+	if len(rows) == 0 {
+		return "", nil
+	}
+
+	headers, rows, err := selectColumns(headers, rows, outputColumns)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if !noHeaders {
+		if err := w.Write(headers); err != nil {
+			return "", fmt.Errorf("error formatting CSV: %w", err)
+		}
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("error formatting CSV: %w", err)
+	}
+
+	// PrintOutput adds the final newline
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// sizeFields are the names of the struct fields holding a size in bytes, such
+// as the Size of an S3 object or Lambda function and the SizeBytes of a
+// DynamoDB table
+var sizeFields = map[string]bool{
+	"Size":      true,
+	"SizeBytes": true,
+}
+
+// recordTable converts data into a header row and one row of cells per record.
+// Slices of structs have one column per exported field, maps have one column
+// per key found in any record (sorted), and other values are shown in a single
+// Value column. If humanize is set, the struct fields listed in sizeFields are
+// shown with HumanizeBytes rather than as a number of bytes.
+func recordTable(data interface{}, humanize bool) ([]string, [][]string) {
+	// Collect the records
+	var records []reflect.Value
+	v := indirectValue(reflect.ValueOf(data))
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			records = append(records, indirectValue(v.Index(i)))
+		}
+	case reflect.Invalid:
+	default:
+		records = []reflect.Value{v}
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Extract headers from the first record
+	var headers []string
+	switch first := records[0]; first.Kind() {
+	case reflect.Struct:
+		headers = structFields(first.Type())
+	case reflect.Map:
+		// Records such as DynamoDB items don't all have the same keys
+		seen := make(map[string]bool)
+		for _, record := range records {
+			if record.Kind() != reflect.Map {
+				continue
+			}
+			for _, key := range record.MapKeys() {
+				name := fmt.Sprint(key.Interface())
+				if !seen[name] {
+					seen[name] = true
+					headers = append(headers, name)
+				}
+			}
+		}
+		sort.Strings(headers)
+	default:
+		headers = []string{"Value"}
+	}
+
+	// Build one row per record
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		row := make([]string, len(headers))
+		for i, h := range headers {
+			switch record.Kind() {
+			case reflect.Struct:
+				field := record.FieldByName(h)
+				switch {
+				case !field.IsValid():
+				case humanize && sizeFields[h] && field.Kind() == reflect.Int64:
+					row[i] = HumanizeBytes(field.Int())
+				default:
+					row[i] = cellValue(field)
+				}
+			case reflect.Map:
+				if val := record.MapIndex(reflect.ValueOf(h)); val.IsValid() {
+					row[i] = cellValue(val)
+				}
+			default:
+				row[i] = cellValue(record)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return headers, rows
+}
+
+// selectColumns keeps only the given columns, such as those set with
+// SetColumns, in the requested order. Column names are matched
+// case-insensitively; no columns keeps them all.
+//
+// Returns an error listing the available columns if a name is unknown.
+func selectColumns(headers []string, rows [][]string, columns []string) ([]string, [][]string, error) {
+	if len(columns) == 0 {
+		return headers, rows, nil
+	}
+
+	// Find the index of each requested column
+	indexes := make([]int, 0, len(columns))
+	selected := make([]string, 0, len(columns))
+	for _, column := range columns {
+		index := -1
+		for i, h := range headers {
+			if strings.EqualFold(h, column) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, nil, fmt.Errorf("unknown column: %s (available columns: %s)", column, strings.Join(headers, ", "))
+		}
+		indexes = append(indexes, index)
+		selected = append(selected, headers[index])
+	}
+
+	// Pick the cells of the requested columns
+	selectedRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		selectedRow := make([]string, len(indexes))
+		for i, index := range indexes {
+			selectedRow[i] = row[index]
+		}
+		selectedRows = append(selectedRows, selectedRow)
+	}
+
+	return selected, selectedRows, nil
+}
+
+// structFields returns the exported field names of a struct type, with the
+// fields of embedded structs promoted like they are in Go
+func structFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(field.Type)...)
+			continue
+		}
+		if field.IsExported() {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
+
+// cellValue formats a single value for a table or CSV cell
+func cellValue(v reflect.Value) string {
+	v = indirectValue(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return ""
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, fmt.Sprintf("%v=%s", key.Interface(), cellValue(v.MapIndex(key))))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ";")
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%s", v.Interface())
+		}
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, cellValue(v.Index(i)))
+		}
+		return strings.Join(items, ";")
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+// indirectValue follows pointers and interfaces to the underlying value
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// PrintRaw writes data unmodified to stdout, or to the writer set with SetOutputWriter
+func PrintRaw(data []byte) error {
+	if _, err := OutputWriter().Write(data); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	return nil
+}
+
+// PrintOutput prints the formatted output to stdout, or to the writer set with SetOutputWriter.
+// The query set with SetQuery, if any, is applied to the data first. If a JSONPath
+// template is set with SetJSONPath, it is rendered instead of formatting the data.
+// Query and formatting errors (such as an unknown column) are also printed to stderr.
 func PrintOutput(data interface{}, format string) error {
-	output, err := FormatOutput(data, format)
+	data, err := ApplyQuery(data)
 	if err != nil {
+		PrintError(err)
 		return err
 	}
 
-	fmt.Println(output)
+	output, ok, err := ApplyJSONPath(data)
+	if !ok {
+		output, err = FormatOutput(data, format)
+	}
+	if err != nil {
+		PrintError(err)
+		return err
+	}
+
+	// JSON lines of an empty list are no lines at all rather than an empty one
+	if !ok && OutputFormat(format) == FormatJSONL && output == "" {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(OutputWriter(), output); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
 	return nil
 }
 
+// PrintList prints the results of a list command, a slice of items, like
+// PrintOutput. limit is the maximum number of results that was requested (0 for
+// no limit) and truncated whether the listing found more results than it
+// printed; when SetEnvelope is enabled, JSON and YAML output wrap the items in a
+// ListEnvelope that tells so. Reaching the limit alone doesn't mark the results
+// as truncated, since there may be exactly limit results. Table, CSV, text and
+// JSON lines output are never wrapped.
+func PrintList(items interface{}, limit int32, truncated bool, format string) error {
+	return printList(items, limit, truncated, "", format)
+}
+
+// PrintListPage prints one page of the results of a list command like PrintList.
+// nextToken resumes the command after the last result; it is included in the
+// ListEnvelope, and a non-empty token marks the results as truncated.
+func PrintListPage(items interface{}, limit int32, nextToken string, format string) error {
+	return printList(items, limit, nextToken != "", nextToken, format)
+}
+
+// printList prints the results of a list command for PrintList and PrintListPage
+func printList(items interface{}, limit int32, truncated bool, nextToken string, format string) error {
+	if !listEnvelope || (OutputFormat(format) != FormatJSON && OutputFormat(format) != FormatYAML) {
+		return PrintOutput(items, format)
+	}
+
+	count := 0
+	if v := indirectValue(reflect.ValueOf(items)); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		count = v.Len()
+	}
+
+	return PrintOutput(ListEnvelope{
+		Items:     items,
+		Count:     count,
+		Limit:     limit,
+		Truncated: truncated,
+		NextToken: nextToken,
+	}, format)
+}
+
 // PrintError prints an error message to stderr
 func PrintError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
 }
+
+// PrintNotice prints a message to stderr, in bold when stderr is a terminal
+// and NO_COLOR is not set
+func PrintNotice(msg string) {
+	if ColorEnabled(os.Stderr) {
+		fmt.Fprintf(os.Stderr, "\033[1m%s\033[0m\n", msg)
+		return
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+// PrintWarning prints a warning message to stderr, highlighted in yellow
+// when stderr is a terminal and NO_COLOR is not set
+func PrintWarning(msg string) {
+	if ColorEnabled(os.Stderr) {
+		fmt.Fprintf(os.Stderr, "\033[33mWarning: %s\033[0m\n", msg)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+}
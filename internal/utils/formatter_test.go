@@ -0,0 +1,376 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestFormatYAMLFlowStyle tests YAML output in block and flow style.
+// It verifies that flow style renders nested collections inline and
+// that both styles decode to the same data.
+func TestFormatYAMLFlowStyle(t *testing.T) {
+	data := map[string]interface{}{
+		"name": "web",
+		"tags": []string{"a", "b"},
+		"network": map[string]string{
+			"vpc": "vpc-1",
+		},
+	}
+	defer SetYAMLFlowStyle(false)
+
+	// Block style (default)
+	SetYAMLFlowStyle(false)
+	block, err := FormatOutput(data, "yaml")
+	require.NoError(t, err)
+	assert.Contains(t, block, "tags:\n    - a\n    - b\n")
+
+	// Flow style
+	SetYAMLFlowStyle(true)
+	flow, err := FormatOutput(data, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "{name: web, network: {vpc: vpc-1}, tags: [a, b]}\n", flow)
+
+	// Both styles contain the same data
+	var fromBlock, fromFlow map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(block), &fromBlock))
+	require.NoError(t, yaml.Unmarshal([]byte(flow), &fromFlow))
+	assert.Equal(t, fromBlock, fromFlow)
+}
+
+// TestSetOutputWriter tests redirecting PrintOutput to another writer.
+// It verifies that the output is written to the writer without color codes.
+func TestSetOutputWriter(t *testing.T) {
+	defer SetOutputWriter(nil)
+
+	buf := new(bytes.Buffer)
+	SetOutputWriter(buf)
+
+	err := PrintOutput(map[string]string{"name": "web"}, "json")
+	require.NoError(t, err)
+
+	assert.Equal(t, "{\n  \"name\": \"web\"\n}\n", buf.String())
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+// TestColorOutput tests when PrintOutput uses color codes.
+// It verifies that output to a terminal may be colored unless NO_COLOR is set,
+// and that output redirected to a file is not.
+func TestColorOutput(t *testing.T) {
+	defer SetOutputWriter(nil)
+	terminal := isTerminal
+	defer func() { isTerminal = terminal }()
+
+	data := []ec2.Instance{{ID: "i-1", Name: "web", State: "running"}}
+
+	// render prints data in the format to a file and returns what was written
+	render := func(format string) string {
+		f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+		require.NoError(t, err)
+		defer f.Close()
+		SetOutputWriter(f)
+		require.NoError(t, PrintOutput(data, format))
+		written, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		return string(written)
+	}
+
+	// Output redirected to a file is not colored
+	isTerminal = func(*os.File) bool { return false }
+	t.Setenv("NO_COLOR", "")
+	assert.NotContains(t, render("json"), "\x1b[")
+
+	// Output to a terminal may be colored
+	isTerminal = func(*os.File) bool { return true }
+	assert.True(t, ColorEnabled(os.Stderr))
+
+	// NO_COLOR turns colors off, also in tables
+	t.Setenv("NO_COLOR", "1")
+	assert.True(t, NoColor())
+	assert.False(t, ColorEnabled(os.Stderr))
+	for _, format := range []string{"json", "table", "wide", "text"} {
+		output := render(format)
+		assert.Contains(t, output, "i-1", format)
+		assert.NotContains(t, output, "\x1b[", format)
+	}
+
+	// Writers other than files are never terminals
+	assert.False(t, ColorEnabled(new(bytes.Buffer)))
+}
+
+// TestFormatJSONL tests JSON lines output.
+// It verifies that each element of a slice is printed as JSON on a line of its
+// own, and that other values are printed on a single line.
+func TestFormatJSONL(t *testing.T) {
+	instances := []ec2.Instance{
+		{ID: "i-1", Name: "web", Tags: map[string]string{"env": "prod"}},
+		{ID: "i-2", Name: "db"},
+		{ID: "i-3", Name: "cache"},
+	}
+
+	output, err := FormatOutput(instances, "jsonl")
+	require.NoError(t, err)
+	lines := strings.Split(output, "\n")
+	require.Len(t, lines, len(instances))
+	for i, line := range lines {
+		var instance ec2.Instance
+		require.NoError(t, json.Unmarshal([]byte(line), &instance))
+		assert.Equal(t, instances[i], instance)
+	}
+
+	// A value that is not a slice is a single line
+	output, err = FormatOutput(map[string]string{"name": "web"}, "jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"web"}`, output)
+
+	// An empty slice has no lines, and nothing is printed for it
+	output, err = FormatOutput([]ec2.Instance{}, "jsonl")
+	require.NoError(t, err)
+	assert.Empty(t, output)
+
+	buf := new(bytes.Buffer)
+	SetOutputWriter(buf)
+	defer SetOutputWriter(nil)
+	require.NoError(t, PrintOutput([]ec2.Instance{}, "jsonl"))
+	assert.Empty(t, buf.String())
+
+	// The format is accepted as an output format
+	assert.True(t, IsValidOutputFormat("jsonl"))
+}
+
+// TestIsStructuredFormat tests recognizing the structured output formats.
+// It verifies that JSON, JSON lines and YAML are structured and that the
+// formats meant to be read are not.
+func TestIsStructuredFormat(t *testing.T) {
+	for format, want := range map[string]bool{
+		"json":  true,
+		"jsonl": true,
+		"yaml":  true,
+		"table": false,
+		"wide":  false,
+		"text":  false,
+		"csv":   false,
+		"":      false,
+	} {
+		assert.Equal(t, want, IsStructuredFormat(format), format)
+	}
+}
+
+// TestSetCompactJSON tests compact JSON output.
+// It verifies that JSON is indented with two spaces by default and printed on a
+// single line with SetCompactJSON, with the same data.
+func TestSetCompactJSON(t *testing.T) {
+	defer SetCompactJSON(false)
+	data := map[string]interface{}{
+		"name": "web",
+		"tags": []string{"a", "b"},
+	}
+
+	// Indented by default
+	indented, err := FormatOutput(data, "json")
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"name\": \"web\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}", indented)
+
+	// Compact
+	SetCompactJSON(true)
+	compact, err := FormatOutput(data, "json")
+	require.NoError(t, err)
+	assert.NotContains(t, compact, "\n")
+	assert.Equal(t, `{"name":"web","tags":["a","b"]}`, compact)
+	assert.JSONEq(t, indented, compact)
+}
+
+// TestFormatCSV tests CSV output of a slice of structs.
+// It verifies that the header lists the struct fields and that maps
+// and slices are flattened into a single cell.
+func TestFormatCSV(t *testing.T) {
+	instances := []ec2.Instance{
+		{
+			ID:          "i-12345",
+			Name:        "web, primary",
+			Type:        "t3.micro",
+			State:       "running",
+			PrivateIP:   "10.0.0.1",
+			Tags:        map[string]string{"Name": "web, primary", "Env": "prod"},
+			SecurityIDs: []string{"sg-1", "sg-2"},
+		},
+	}
+
+	output, err := FormatOutput(instances, "csv")
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "ID,Name,Type,State,PublicIP,PrivateIP,LaunchTime,AZ,VpcID,SubnetID,Tags,SecurityIDs,ElasticIPs", lines[0])
+	assert.Equal(t, `i-12345,"web, primary",t3.micro,running,,10.0.0.1,,,,,"Env=prod;Name=web, primary",sg-1;sg-2,`, lines[1])
+
+	// The format is accepted as an output format
+	assert.True(t, IsValidOutputFormat("csv"))
+}
+
+// TestFormatSizes tests the output of sizes in bytes.
+// It verifies that the Size and SizeBytes fields are humanized in table
+// output, while CSV and JSON output keep the number of bytes.
+func TestFormatSizes(t *testing.T) {
+	records := []struct {
+		Name      string
+		Size      int64
+		SizeBytes int64
+		Count     int64
+	}{
+		{Name: "large", Size: 1536, SizeBytes: 3 * 1024 * 1024, Count: 2048},
+	}
+
+	// Table output
+	output, err := FormatOutput(records, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "1.50 KB")
+	assert.Contains(t, output, "3.00 MB")
+	assert.Contains(t, output, "2048")
+
+	// CSV output
+	output, err = FormatOutput(records, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Name,Size,SizeBytes,Count\nlarge,1536,3145728,2048", output)
+
+	// JSON output
+	output, err = FormatOutput(records, "json")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"Size": 1536`)
+}
+
+// TestSetColumns tests selecting columns for table and CSV output.
+// It verifies that only the requested columns appear, in the requested
+// order, matched case-insensitively, and that unknown columns are rejected.
+func TestSetColumns(t *testing.T) {
+	defer SetColumns(nil)
+
+	instances := []ec2.Instance{
+		{ID: "i-12345", Name: "web", State: "running", PrivateIP: "10.0.0.1", Type: "t3.micro"},
+		{ID: "i-67890", Name: "db", State: "stopped", PrivateIP: "10.0.0.2", Type: "r5.large"},
+	}
+
+	// CSV output with a subset of columns
+	SetColumns([]string{"name", "ID", "privateip"})
+	output, err := FormatOutput(instances, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Name,ID,PrivateIP\nweb,i-12345,10.0.0.1\ndb,i-67890,10.0.0.2", output)
+
+	// Table output with the same columns
+	output, err = FormatOutput(instances, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "web")
+	assert.Contains(t, output, "10.0.0.2")
+	assert.NotContains(t, output, "running")
+	assert.NotContains(t, output, "t3.micro")
+	assert.Less(t, strings.Index(strings.ToUpper(output), "NAME"), strings.Index(strings.ToUpper(output), "PRIVATE"))
+
+	// Unknown columns are rejected
+	SetColumns([]string{"ID", "Color"})
+	_, err = FormatOutput(instances, "table")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown column: Color")
+
+	// Without columns, the default columns are shown
+	SetColumns(nil)
+	output, err = FormatOutput(instances, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "running")
+	assert.Contains(t, output, "t3.micro")
+}
+
+// TestSetNoHeaders tests omitting the header row from table and CSV output.
+// It verifies that only the data rows are written when headers are disabled.
+func TestSetNoHeaders(t *testing.T) {
+	defer SetNoHeaders(false)
+
+	instances := []ec2.Instance{
+		{ID: "i-12345", Name: "web", State: "running"},
+	}
+
+	// CSV output without the header row
+	SetNoHeaders(true)
+	output, err := FormatOutput(instances, "csv")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "i-12345,web,"))
+
+	// Table output without the header row
+	output, err = FormatOutput(instances, "table")
+	require.NoError(t, err)
+	assert.Contains(t, output, "i-12345")
+	assert.NotContains(t, strings.ToUpper(output), "PRIVATE")
+
+	// Headers are shown by default
+	SetNoHeaders(false)
+	output, err = FormatOutput(instances, "csv")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output, "ID,Name,"))
+}
+
+// TestPrintListEnvelope tests wrapping list output with SetEnvelope.
+// It verifies that JSON and YAML output tell whether there are more results,
+// that exactly as many results as the limit without more aren't truncated, and
+// that other formats and disabled envelopes print the items as they are.
+func TestPrintListEnvelope(t *testing.T) {
+	defer SetOutputWriter(nil)
+	defer SetEnvelope(false)
+
+	instances := []ec2.Instance{
+		{ID: "i-1", State: "running"},
+		{ID: "i-2", State: "stopped"},
+	}
+	buf := new(bytes.Buffer)
+	SetOutputWriter(buf)
+
+	// Without the envelope, the items are printed as they are
+	require.NoError(t, PrintList(instances, 2, true, "json"))
+	assert.True(t, strings.HasPrefix(buf.String(), "["))
+
+	// The listing found more results than the limit
+	SetEnvelope(true)
+	buf.Reset()
+	require.NoError(t, PrintList(instances, 2, true, "json"))
+	var envelope struct {
+		Items     []ec2.Instance `json:"items"`
+		Count     int            `json:"count"`
+		Limit     int32          `json:"limit"`
+		Truncated bool           `json:"truncated"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &envelope))
+	assert.Equal(t, instances, envelope.Items)
+	assert.Equal(t, 2, envelope.Count)
+	assert.Equal(t, int32(2), envelope.Limit)
+	assert.True(t, envelope.Truncated)
+
+	// Exactly as many results as the limit, and nothing more, are complete
+	buf.Reset()
+	require.NoError(t, PrintList(instances, 2, false, "yaml"))
+	assert.Contains(t, buf.String(), "count: 2\nlimit: 2\ntruncated: false\n")
+
+	// Fewer results than the limit, or no limit, are complete
+	buf.Reset()
+	require.NoError(t, PrintList(instances, 10, false, "yaml"))
+	assert.Contains(t, buf.String(), "count: 2\nlimit: 10\ntruncated: false\n")
+
+	buf.Reset()
+	require.NoError(t, PrintList(instances, 0, false, "yaml"))
+	assert.Contains(t, buf.String(), "count: 2\ntruncated: false\n")
+
+	// A page with a next token is truncated, whatever the limit
+	buf.Reset()
+	require.NoError(t, PrintListPage(instances, 10, "abc", "yaml"))
+	assert.Contains(t, buf.String(), "count: 2\nlimit: 10\ntruncated: true\nnext_token: abc\n")
+
+	// Table output is never wrapped
+	buf.Reset()
+	require.NoError(t, PrintList(instances, 2, true, "table"))
+	assert.NotContains(t, buf.String(), "truncated")
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// byteUnits are the units used by HumanizeBytes, each 1024 times the previous one
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+
+// HumanizeBytes formats a size in bytes with the largest binary unit (1 KB =
+// 1024 B) up to PB in which it is at least 1, e.g. "1023 B", "1.50 KB" or
+// "2.00 TB". Sizes in KB and above have two decimals, and a size that rounds up
+// to 1024 of a unit is shown in the next unit ("1.00 MB" rather than "1024.00 KB").
+func HumanizeBytes(n int64) string {
+	if n > -1024 && n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	sign := ""
+	value := float64(n)
+	if n < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	unit := 0
+	for unit < len(byteUnits)-1 && math.Round(value*100)/100 >= 1024 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%s%.2f %s", sign, value, byteUnits[unit])
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHumanizeBytes tests the HumanizeBytes function.
+// It verifies the boundaries of each unit, rounding to two decimals and that
+// sizes past PB stay in PB.
+func TestHumanizeBytes(t *testing.T) {
+	const (
+		kb = int64(1024)
+		mb = kb * 1024
+		gb = mb * 1024
+		tb = gb * 1024
+		pb = tb * 1024
+	)
+
+	testCases := []struct {
+		name     string
+		bytes    int64
+		expected string
+	}{
+		{"zero", 0, "0 B"},
+		{"bytes", 1, "1 B"},
+		{"largest bytes", 1023, "1023 B"},
+		{"one kilobyte", 1024, "1.00 KB"},
+		{"kilobytes", 1536, "1.50 KB"},
+		{"rounds to two decimals", 1024 + 5, "1.00 KB"},
+		{"rounds up", 1024 + 6, "1.01 KB"},
+		{"largest kilobytes", mb - 1, "1.00 MB"},
+		{"one megabyte", mb, "1.00 MB"},
+		{"megabytes", 10*mb + 512*kb, "10.50 MB"},
+		{"largest megabytes", gb - 1, "1.00 GB"},
+		{"one gigabyte", gb, "1.00 GB"},
+		{"largest gigabytes", tb - 1, "1.00 TB"},
+		{"one terabyte", tb, "1.00 TB"},
+		{"terabytes", 5 * tb, "5.00 TB"},
+		{"one petabyte", pb, "1.00 PB"},
+		{"past petabytes", 2048 * pb, "2048.00 PB"},
+		{"largest size", math.MaxInt64, "8192.00 PB"},
+		{"negative", -1536, "-1.50 KB"},
+		{"negative bytes", -1023, "-1023 B"},
+		{"smallest size", math.MinInt64, "-8192.00 PB"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, HumanizeBytes(tc.bytes))
+		})
+	}
+}
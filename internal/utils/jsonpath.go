@@ -0,0 +1,625 @@
+package utils
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// outputJSONPath is the JSONPath template applied by PrintOutput (nil for none)
+var outputJSONPath *jsonPathTemplate
+
+// SetJSONPath sets a JSONPath template, like the kubectl -o jsonpath option,
+// that PrintOutput renders against the JSON representation of the data instead
+// of formatting it. Expressions are written in braces, e.g. {.items[*].name},
+// and everything outside braces is copied as is; a template without braces is
+// treated as a single expression. An empty template disables JSONPath output.
+//
+// Supported are fields (.name, ['name']), recursive descent (..name), indexes
+// and slices ([0], [-1], [0,2], [1:3]), wildcards ([*]), filters
+// ([?(@.State=="running")], with $ for the root of the data), string literals
+// ({"\n"}) and {range}...{end}.
+//
+// Returns an error if the template is not valid.
+func SetJSONPath(template string) error {
+	if template == "" {
+		outputJSONPath = nil
+		return nil
+	}
+
+	parsed, err := parseJSONPathTemplate(template)
+	if err != nil {
+		return fmt.Errorf("invalid JSONPath %q: %w", template, err)
+	}
+
+	outputJSONPath = parsed
+	return nil
+}
+
+// ApplyJSONPath renders the template set with SetJSONPath against data. The data
+// is converted to its JSON representation first, so field names are the same as
+// in JSON output and with --query. The values of an expression are separated by
+// spaces; strings are written as is and other values as JSON.
+//
+// Returns false if no template is set.
+func ApplyJSONPath(data interface{}) (string, bool, error) {
+	if outputJSONPath == nil {
+		return "", false, nil
+	}
+
+	value, err := toJSONValue(data)
+	if err != nil {
+		return "", true, err
+	}
+
+	var out strings.Builder
+	if err := outputJSONPath.execute(&out, value, value); err != nil {
+		return "", true, err
+	}
+	return out.String(), true, nil
+}
+
+// toJSONValue converts data to its generic JSON representation
+func toJSONValue(data interface{}) (interface{}, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error converting data to JSON: %w", err)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(jsonData, &value); err != nil {
+		return nil, fmt.Errorf("error converting data to JSON: %w", err)
+	}
+	return value, nil
+}
+
+// jsonPathTemplate is a parsed JSONPath template: a sequence of literal text,
+// expressions and range blocks
+type jsonPathTemplate struct {
+	parts []jsonPathPart
+}
+
+// jsonPathPart is one element of a template. Exactly one field is set.
+type jsonPathPart struct {
+	text      *string           // Literal text, from outside braces or a string literal
+	path      *jsonPath         // Expression whose values are written
+	rangePath *jsonPath         // Expression whose values the body is rendered for
+	body      *jsonPathTemplate // Body of a range block
+}
+
+// execute writes the template rendered against current, with root the whole data
+func (t *jsonPathTemplate) execute(out *strings.Builder, root, current interface{}) error {
+	for _, part := range t.parts {
+		switch {
+		case part.text != nil:
+			out.WriteString(*part.text)
+		case part.path != nil:
+			values := part.path.eval(root, current)
+			for i, value := range values {
+				if i > 0 {
+					out.WriteByte(' ')
+				}
+				s, err := jsonPathString(value)
+				if err != nil {
+					return err
+				}
+				out.WriteString(s)
+			}
+		default:
+			for _, item := range part.rangePath.eval(root, current) {
+				if err := part.body.execute(out, root, item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonPathString formats a value for template output
+func jsonPathString(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("error formatting JSONPath result: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseJSONPathTemplate parses a template into literal text and actions
+func parseJSONPathTemplate(template string) (*jsonPathTemplate, error) {
+	if !strings.Contains(template, "{") {
+		template = "{" + template + "}"
+	}
+
+	// Split the template into literal text and the actions in braces
+	type token struct {
+		text   string
+		action bool
+	}
+	var tokens []token
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			tokens = append(tokens, token{text: template})
+			break
+		}
+		if start > 0 {
+			tokens = append(tokens, token{text: template[:start]})
+		}
+
+		end := findClosing(template, start+1, '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { at offset %d", start)
+		}
+		tokens = append(tokens, token{text: strings.TrimSpace(template[start+1 : end]), action: true})
+		template = template[end+1:]
+	}
+
+	// Build the template, nesting range blocks
+	stack := []*jsonPathTemplate{{}}
+	for _, tok := range tokens {
+		current := stack[len(stack)-1]
+		text := tok.text
+
+		switch {
+		case !tok.action:
+			current.parts = append(current.parts, jsonPathPart{text: &text})
+		case text == "end":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			stack = stack[:len(stack)-1]
+		case strings.HasPrefix(text, "range ") || text == "range":
+			path, err := parseJSONPath(strings.TrimSpace(strings.TrimPrefix(text, "range")))
+			if err != nil {
+				return nil, err
+			}
+			body := &jsonPathTemplate{}
+			current.parts = append(current.parts, jsonPathPart{rangePath: path, body: body})
+			stack = append(stack, body)
+		case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+			literal, err := unquoteJSONPathString(text)
+			if err != nil {
+				return nil, err
+			}
+			current.parts = append(current.parts, jsonPathPart{text: &literal})
+		default:
+			path, err := parseJSONPath(text)
+			if err != nil {
+				return nil, err
+			}
+			current.parts = append(current.parts, jsonPathPart{path: path})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return stack[0], nil
+}
+
+// findClosing returns the index of the closing character that ends the
+// bracket opened before s[start], skipping quoted strings and nested brackets,
+// or -1 if there is none
+func findClosing(s string, start int, closing byte) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(' || c == '{':
+			depth++
+		case c == closing && depth == 0:
+			return i
+		case c == ']' || c == ')' || c == '}':
+			depth--
+		}
+	}
+	return -1
+}
+
+// unquoteJSONPathString returns the value of a single or double quoted string
+func unquoteJSONPathString(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	value, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return value, nil
+}
+
+// jsonPath is a parsed JSONPath expression
+type jsonPath struct {
+	fromRoot bool // Whether the expression starts at the root ($) rather than the current value
+	steps    []jsonPathStep
+}
+
+// jsonPathStep returns the values one step of an expression selects from value,
+// with root the whole data for filters that refer to it
+type jsonPathStep func(root, value interface{}) []interface{}
+
+// eval returns the values the expression selects, starting at current
+func (p *jsonPath) eval(root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	if p.fromRoot {
+		values = []interface{}{root}
+	}
+	for _, step := range p.steps {
+		var selected []interface{}
+		for _, value := range values {
+			selected = append(selected, step(root, value)...)
+		}
+		values = selected
+	}
+	return values
+}
+
+// parseJSONPath parses an expression such as .items[*].name or $..id
+func parseJSONPath(expr string) (*jsonPath, error) {
+	path := &jsonPath{}
+	rest := expr
+	switch {
+	case strings.HasPrefix(rest, "$"):
+		path.fromRoot = true
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "@"):
+		rest = rest[1:]
+	}
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := readJSONPathName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after .. in %q", expr)
+			}
+			path.steps = append(path.steps, recursiveStep(name))
+			rest = remaining
+		case rest[0] == '.':
+			// A lone dot selects the current value
+			name, remaining := readJSONPathName(rest[1:])
+			if name != "" {
+				path.steps = append(path.steps, fieldStep(name))
+			}
+			rest = remaining
+		case rest[0] == '[':
+			end := findClosing(rest, 1, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", expr)
+			}
+			step, err := parseJSONPathBracket(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, expr)
+			}
+			path.steps = append(path.steps, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q (fields start with a dot)", rest, expr)
+		}
+	}
+
+	return path, nil
+}
+
+// readJSONPathName reads a field name up to the next dot or bracket
+func readJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// parseJSONPathBracket parses the content of brackets: a wildcard, a filter, a
+// quoted field name, a slice, or one or more indexes
+func parseJSONPathBracket(content string) (jsonPathStep, error) {
+	switch {
+	case content == "*":
+		return wildcardStep, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		return parseJSONPathFilter(strings.TrimSpace(content[2 : len(content)-1]))
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, `"`):
+		name, err := unquoteJSONPathString(content)
+		if err != nil {
+			return nil, err
+		}
+		return fieldStep(name), nil
+	case strings.Contains(content, ":"):
+		bounds := strings.Split(content, ":")
+		if len(bounds) > 3 {
+			return nil, fmt.Errorf("invalid slice [%s]", content)
+		}
+		var start, end *int
+		for i, bound := range bounds[:2] {
+			bound = strings.TrimSpace(bound)
+			if bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice [%s]", content)
+			}
+			if i == 0 {
+				start = &n
+			} else {
+				end = &n
+			}
+		}
+		return sliceStep(start, end), nil
+	default:
+		var indexes []int
+		for _, item := range strings.Split(content, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("invalid index [%s]", content)
+			}
+			indexes = append(indexes, n)
+		}
+		return indexStep(indexes), nil
+	}
+}
+
+// children returns the elements of an array or the values of an object in key order
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(v))
+		for _, key := range keys {
+			values = append(values, v[key])
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// fieldStep selects a field of an object by name
+func fieldStep(name string) jsonPathStep {
+	return func(_, value interface{}) []interface{} {
+		if object, ok := value.(map[string]interface{}); ok {
+			if field, exists := object[name]; exists {
+				return []interface{}{field}
+			}
+		}
+		return nil
+	}
+}
+
+// recursiveStep selects the fields with a name at any depth
+func recursiveStep(name string) jsonPathStep {
+	var walk func(root, value interface{}) []interface{}
+	walk = func(root, value interface{}) []interface{} {
+		result := fieldStep(name)(root, value)
+		for _, child := range children(value) {
+			result = append(result, walk(root, child)...)
+		}
+		return result
+	}
+	return walk
+}
+
+// wildcardStep selects all elements of an array or all values of an object
+func wildcardStep(_, value interface{}) []interface{} {
+	return children(value)
+}
+
+// indexStep selects array elements by index, counting from the end when negative
+func indexStep(indexes []int) jsonPathStep {
+	return func(_, value interface{}) []interface{} {
+		array, _ := value.([]interface{})
+		var result []interface{}
+		for _, index := range indexes {
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				result = append(result, array[index])
+			}
+		}
+		return result
+	}
+}
+
+// sliceStep selects a range of array elements, like a Go or Python slice.
+// Negative bounds count from the end and bounds beyond either end are clamped.
+func sliceStep(start, end *int) jsonPathStep {
+	clamp := func(bound *int, fallback, length int) int {
+		if bound == nil {
+			return fallback
+		}
+		index := *bound
+		if index < 0 {
+			index += length
+		}
+		return min(max(index, 0), length)
+	}
+
+	return func(_, value interface{}) []interface{} {
+		array, _ := value.([]interface{})
+		from, to := clamp(start, 0, len(array)), clamp(end, len(array), len(array))
+		if from >= to {
+			return nil
+		}
+		return array[from:to]
+	}
+}
+
+// jsonPathOperators are the comparison operators of filters, two-character
+// operators first so they are matched before their prefixes
+var jsonPathOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseJSONPathFilter parses a filter condition such as @.State=="running" or
+// @.PublicIP into a step selecting the elements for which it holds
+func parseJSONPathFilter(condition string) (jsonPathStep, error) {
+	// Find the first operator outside quoted strings
+	var quote byte
+	left, op, right := condition, "", ""
+scan:
+	for i := 0; i < len(condition); i++ {
+		c := condition[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, candidate := range jsonPathOperators {
+				if strings.HasPrefix(condition[i:], candidate) {
+					left, op, right = condition[:i], candidate, condition[i+len(candidate):]
+					break scan
+				}
+			}
+		}
+	}
+
+	leftValue, err := parseJSONPathOperand(strings.TrimSpace(left))
+	if err != nil {
+		return nil, err
+	}
+	var rightValue jsonPathOperand
+	if op != "" {
+		if rightValue, err = parseJSONPathOperand(strings.TrimSpace(right)); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(root, value interface{}) []interface{} {
+		var result []interface{}
+		for _, item := range children(value) {
+			if matchesJSONPathFilter(root, item, leftValue, op, rightValue) {
+				result = append(result, item)
+			}
+		}
+		return result
+	}, nil
+}
+
+// jsonPathOperand is one side of a filter comparison: an expression relative
+// to the filtered value or to the root, or a literal
+type jsonPathOperand struct {
+	path    *jsonPath
+	literal interface{}
+}
+
+// parseJSONPathOperand parses an expression starting with @ or $, a quoted
+// string, a number, true, false or null
+func parseJSONPathOperand(s string) (jsonPathOperand, error) {
+	switch {
+	case s == "":
+		return jsonPathOperand{}, fmt.Errorf("empty filter operand")
+	case s[0] == '@' || s[0] == '$':
+		path, err := parseJSONPath(s)
+		if err != nil {
+			return jsonPathOperand{}, err
+		}
+		return jsonPathOperand{path: path}, nil
+	case s[0] == '"' || s[0] == '\'':
+		value, err := unquoteJSONPathString(s)
+		if err != nil {
+			return jsonPathOperand{}, err
+		}
+		return jsonPathOperand{literal: value}, nil
+	}
+
+	var literal interface{}
+	if err := json.Unmarshal([]byte(s), &literal); err != nil {
+		return jsonPathOperand{}, fmt.Errorf("invalid filter operand %s", s)
+	}
+	return jsonPathOperand{literal: literal}, nil
+}
+
+// value returns the value of the operand for item, and false if it selects nothing
+func (o jsonPathOperand) value(root, item interface{}) (interface{}, bool) {
+	if o.path == nil {
+		return o.literal, true
+	}
+	values := o.path.eval(root, item)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// matchesJSONPathFilter reports whether the filter condition holds for item.
+// Without an operator, it checks that the left operand is set.
+func matchesJSONPathFilter(root, item interface{}, left jsonPathOperand, op string, right jsonPathOperand) bool {
+	leftValue, ok := left.value(root, item)
+	if !ok {
+		return false
+	}
+	if op == "" {
+		// Fields tagged omitempty are left out of the JSON representation when
+		// they are empty, so null, false and an empty string count as not set
+		// too, whether or not the field is tagged
+		return leftValue != nil && leftValue != false && leftValue != ""
+	}
+
+	rightValue, ok := right.value(root, item)
+	if !ok {
+		return false
+	}
+
+	// Numbers and strings are ordered; other values can only be equal or not
+	var order int
+	switch l := leftValue.(type) {
+	case float64:
+		r, ok := rightValue.(float64)
+		if !ok {
+			return op == "!="
+		}
+		order = cmp.Compare(l, r)
+	case string:
+		r, ok := rightValue.(string)
+		if !ok {
+			return op == "!="
+		}
+		order = strings.Compare(l, r)
+	default:
+		equal := reflect.DeepEqual(leftValue, rightValue)
+		return (op == "==" && equal) || (op == "!=" && !equal)
+	}
+
+	switch op {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetJSONPath tests JSONPath templates on output data.
+// It verifies fields, wildcards, filters, indexes, slices, recursive descent
+// and range blocks against a slice of instances, and that invalid templates
+// are rejected up front.
+func TestSetJSONPath(t *testing.T) {
+	defer SetJSONPath("")
+
+	instances := []ec2.Instance{
+		{ID: "i-11111", State: "running", Tags: map[string]string{"app": "web"}, SecurityIDs: []string{"sg-1", "sg-2"}},
+		{ID: "i-22222", State: "stopped", Tags: map[string]string{"app": "db"}, SecurityIDs: []string{"sg-3"}},
+		{ID: "i-33333", State: "running", PublicIP: "3.3.3.3"},
+	}
+
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{"{.[*].ID}", "i-11111 i-22222 i-33333"},
+		{".[*].ID", "i-11111 i-22222 i-33333"},
+		{`{.[?(@.State=="running")].ID}`, "i-11111 i-33333"},
+		{`{.[?(@.State!='running')].ID}`, "i-22222"},
+		{"{.[?(@.PublicIP)].ID}", "i-33333"},
+		{"{.[0].ID}", "i-11111"},
+		{"{.[-1].ID}", "i-33333"},
+		{"{.[0,2].ID}", "i-11111 i-33333"},
+		{"{.[1:].ID}", "i-22222 i-33333"},
+		{"{.[0].Tags.app}", "web"},
+		{"{.[0].Tags['app']}", "web"},
+		{"{..SecurityIDs[*]}", "sg-1 sg-2 sg-3"},
+		{"{.[0].SecurityIDs}", `["sg-1","sg-2"]`},
+		{`{range .[*]}{.ID}{"\t"}{.State}{"\n"}{end}`, "i-11111\trunning\ni-22222\tstopped\ni-33333\trunning\n"},
+		{"IDs: {.[*].ID}", "IDs: i-11111 i-22222 i-33333"},
+		{"{.[*].Missing}", ""},
+	}
+
+	for _, tc := range testCases {
+		require.NoError(t, SetJSONPath(tc.template), tc.template)
+		output, ok, err := ApplyJSONPath(instances)
+		require.NoError(t, err, tc.template)
+		assert.True(t, ok)
+		assert.Equal(t, tc.expected, output, tc.template)
+	}
+
+	// Invalid templates are rejected
+	for _, template := range []string{"{.[0", "{range .[*]}{.ID}", "{end}", "{.[abc]}", "{ID}"} {
+		err := SetJSONPath(template)
+		assert.Error(t, err, template)
+	}
+
+	// Without a template the data is formatted as usual
+	require.NoError(t, SetJSONPath(""))
+	_, ok, err := ApplyJSONPath(instances)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// jsonPathTestData is the data the JSONPath filter and slice tests run against
+var jsonPathTestData = map[string]interface{}{
+	"threshold": 2,
+	"state":     "running",
+	"items": []map[string]interface{}{
+		{"name": "a", "count": 1, "state": "running"},
+		{"name": "b", "count": 2, "state": "stopped"},
+		{"name": "c", "count": 3, "state": "running"},
+		{"name": "d", "count": 10},
+	},
+}
+
+// TestJSONPathFilters tests filter expressions in JSONPath templates.
+// It verifies that numbers are compared by value rather than as text, that
+// numbers and strings don't order against each other, and that $ inside a
+// filter refers to the root of the data rather than the filtered element.
+func TestJSONPathFilters(t *testing.T) {
+	defer SetJSONPath("")
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"greater than", "{.items[?(@.count>2)].name}", "c d"},
+		{"greater or equal", "{.items[?(@.count>=2)].name}", "b c d"},
+		{"less than compares numbers by value", "{.items[?(@.count<3)].name}", "a b"},
+		{"less or equal", "{.items[?(@.count<=2)].name}", "a b"},
+		{"equal", "{.items[?(@.count==2)].name}", "b"},
+		{"not equal", "{.items[?(@.count!=2)].name}", "a c d"},
+		{"decimal literal", "{.items[?(@.count>2.5)].name}", "c d"},
+		{"negative literal", "{.items[?(@.count>-1)].name}", "a b c d"},
+		{"literal on the left", "{.items[?(3<=@.count)].name}", "c d"},
+		{"number against string", `{.items[?(@.count>"2")].name}`, ""},
+		{"string ordering", `{.items[?(@.name>="c")].name}`, "c d"},
+		{"root number", "{.items[?(@.count>=$.threshold)].name}", "b c d"},
+		{"root string", "{.items[?(@.state==$.state)].name}", "a c"},
+		{"root only", "{.items[?($.threshold==2)].name}", "a b c d"},
+		{"missing root field", "{.items[?(@.count==$.missing)].name}", ""},
+		{"missing element field", "{.items[?(@.state)].name}", "a b c"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, SetJSONPath(tc.template))
+			output, ok, err := ApplyJSONPath(jsonPathTestData)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
+
+// TestJSONPathNegativeSlices tests negative indexes and slice bounds in JSONPath
+// templates. It verifies that they count from the end of the array and that
+// bounds beyond either end are clamped instead of failing.
+func TestJSONPathNegativeSlices(t *testing.T) {
+	defer SetJSONPath("")
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"last elements", "{.items[-2:].name}", "c d"},
+		{"all but the last", "{.items[:-1].name}", "a b c"},
+		{"both bounds negative", "{.items[-3:-1].name}", "b c"},
+		{"start before the array", "{.items[-10:1].name}", "a"},
+		{"end before start", "{.items[1:-10].name}", ""},
+		{"empty range", "{.items[-1:-1].name}", ""},
+		{"last index", "{.items[-1].name}", "d"},
+		{"index before the array", "{.items[-5].name}", ""},
+		{"negative union", "{.items[-1,0].name}", "d a"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, SetJSONPath(tc.template))
+			output, _, err := ApplyJSONPath(jsonPathTestData)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
+
+// TestJSONPathMalformed tests malformed JSONPath templates.
+// It verifies that each is rejected by SetJSONPath with an error describing
+// the problem, and that the previous template is left unchanged.
+func TestJSONPathMalformed(t *testing.T) {
+	defer SetJSONPath("")
+
+	testCases := []struct {
+		name     string
+		template string
+		errMsg   string
+	}{
+		{"unclosed brace", "{.items[0}", "unclosed {"},
+		{"unclosed bracket", "{.items[0}}", "unclosed ["},
+		{"unclosed quote", "{.items[?(@.name=='a)]}", "unclosed {"},
+		{"missing field after descent", "{..}", "missing field name after .."},
+		{"field without a dot", "{items}", "fields start with a dot"},
+		{"empty filter operand", "{.items[?(@.count>)]}", "empty filter operand"},
+		{"bare word operand", "{.items[?(@.count>abc)]}", "invalid filter operand abc"},
+		{"too many slice bounds", "{.items[1:2:3:4]}", "invalid slice"},
+		{"non-numeric slice bound", "{.items[a:2]}", "invalid slice"},
+		{"non-numeric index", "{.items[-x]}", "invalid index"},
+		{"invalid string literal", `{"\q"}`, "invalid string"},
+		{"range without end", "{range .items[*]}{.name}", "{range} without {end}"},
+		{"end without range", "{.name}{end}", "{end} without {range}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, SetJSONPath("{.state}"))
+
+			err := SetJSONPath(tc.template)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+
+			output, ok, err := ApplyJSONPath(jsonPathTestData)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "running", output)
+		})
+	}
+}
+
+// TestPrintOutputJSONPath tests PrintOutput with a JSONPath template.
+// It verifies that the template output replaces the formatted output.
+func TestPrintOutputJSONPath(t *testing.T) {
+	defer SetJSONPath("")
+	defer SetOutputWriter(nil)
+
+	var buf bytes.Buffer
+	SetOutputWriter(&buf)
+	require.NoError(t, SetJSONPath("{.[*].ID}"))
+
+	err := PrintOutput([]ec2.Instance{{ID: "i-11111"}, {ID: "i-22222"}}, "table")
+	require.NoError(t, err)
+	assert.Equal(t, "i-11111 i-22222\n", buf.String())
+}
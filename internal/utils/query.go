@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// outputQuery is the JMESPath expression applied by PrintOutput (nil for none)
+var outputQuery *jmespath.JMESPath
+
+// SetQuery sets a JMESPath expression, like the AWS CLI --query option, that
+// PrintOutput applies to the JSON representation of the data before formatting
+// it. An empty expression disables the query.
+//
+// Returns an error pointing at the problem if the expression is not valid.
+func SetQuery(expression string) error {
+	if expression == "" {
+		outputQuery = nil
+		return nil
+	}
+
+	query, err := jmespath.Compile(expression)
+	if err != nil {
+		var syntaxErr jmespath.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("invalid query: %w\n%s", err, syntaxErr.HighlightLocation())
+		}
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	outputQuery = query
+	return nil
+}
+
+// ApplyQuery applies the expression set with SetQuery to data. The data is
+// converted to its JSON representation first, so field names are the same as
+// in JSON output and the query works the same way for every service.
+//
+// Returns data unchanged if no query is set.
+func ApplyQuery(data interface{}) (interface{}, error) {
+	if outputQuery == nil {
+		return data, nil
+	}
+
+	// Convert data to its JSON representation
+	value, err := toJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := outputQuery.Search(value)
+	if err != nil {
+		return nil, fmt.Errorf("error applying query: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/ao/awsm/internal/aws/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetQuery tests JMESPath queries on output data.
+// It verifies filter projections and flattening against a slice of
+// instances, and that invalid expressions are rejected up front.
+func TestSetQuery(t *testing.T) {
+	defer SetQuery("")
+
+	instances := []ec2.Instance{
+		{ID: "i-11111", State: "running", SecurityIDs: []string{"sg-1", "sg-2"}},
+		{ID: "i-22222", State: "stopped", SecurityIDs: []string{"sg-3"}},
+		{ID: "i-33333", State: "running"},
+	}
+
+	// Filter projection
+	require.NoError(t, SetQuery("[?State=='running'].ID"))
+	result, err := ApplyQuery(instances)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"i-11111", "i-33333"}, result)
+
+	// Flattening
+	require.NoError(t, SetQuery("[].SecurityIDs[]"))
+	result, err = ApplyQuery(instances)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"sg-1", "sg-2", "sg-3"}, result)
+
+	// The query result is what gets formatted
+	output, err := FormatOutput(result, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Value\nsg-1\nsg-2\nsg-3", output)
+
+	// Invalid expressions are rejected with the location of the error
+	err = SetQuery("[?State=='running'")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid query")
+	assert.Contains(t, err.Error(), "^")
+
+	// Without a query the data is unchanged
+	require.NoError(t, SetQuery(""))
+	result, err = ApplyQuery(instances)
+	require.NoError(t, err)
+	assert.Equal(t, instances, result)
+}
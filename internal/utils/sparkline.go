@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// sparkTicks are the characters used by Sparkline, from the lowest to the highest value
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a single line of block characters, one per value,
+// scaled between the smallest and the largest value. If all values are equal
+// they are rendered at the lowest level.
+//
+// Returns an empty string if there are no values.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if highest > lowest {
+			level = int((v - lowest) / (highest - lowest) * float64(len(sparkTicks)-1))
+		}
+		sb.WriteRune(sparkTicks[level])
+	}
+
+	return sb.String()
+}
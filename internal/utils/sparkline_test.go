@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSparkline tests the Sparkline function.
+// It verifies that values are scaled between the lowest and highest tick and
+// that empty and flat series are handled.
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", Sparkline(nil))
+	assert.Equal(t, "▁▁▁", Sparkline([]float64{5, 5, 5}))
+	assert.Equal(t, "▁▄█", Sparkline([]float64{0, 50, 100}))
+	assert.Equal(t, "█▁", Sparkline([]float64{-1, -3}))
+	assert.Len(t, []rune(Sparkline([]float64{1, 2, 3, 4})), 4)
+}
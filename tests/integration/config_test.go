@@ -67,6 +67,12 @@ func TestConfigSave(t *testing.T) {
 		config.ConfigFile = originalConfigFile
 	}()
 
+	// Create an AWS credentials file with the profile
+	credentialsFile := filepath.Join(tempDir, "credentials")
+	err = os.WriteFile(credentialsFile, []byte("[test-profile]\naws_access_key_id = AKIAEXAMPLE\n"), 0600)
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
 	// Initialize the configuration
 	err = config.Initialize()
 	require.NoError(t, err)